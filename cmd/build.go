@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/i18n"
 	"github.com/wusher/volcano/internal/output"
 	"github.com/wusher/volcano/internal/styles"
 )
@@ -97,11 +98,14 @@ func Build(args []string, stdout, stderr io.Writer) error {
 	fs.BoolVar(&cfg.ShowBreadcrumbs, "breadcrumbs", cfg.ShowBreadcrumbs, "Show breadcrumb navigation")
 	fs.StringVar(&cfg.Theme, "theme", cfg.Theme, "Theme name (docs, blog, presentation, readable, vanilla)")
 	fs.StringVar(&cfg.CSSPath, "css", cfg.CSSPath, "Path to custom CSS file")
+	fs.StringVar(&cfg.LayoutsDir, "layouts", cfg.LayoutsDir, "Directory with template overrides (default: <input>/_layouts)")
 	fs.StringVar(&cfg.AccentColor, "accent-color", cfg.AccentColor, "Accent color: Tailwind name, hex, or two-color gradient ('lime-sky', '#444444-#555555')")
+	fs.StringVar(&cfg.AccentColorDark, "accent-color-dark", cfg.AccentColorDark, "Dark-mode accent color, same syntax as --accent-color (default: derived for contrast)")
 	fs.BoolVar(&cfg.InstantNav, "instant-nav", cfg.InstantNav, "Enable instant navigation with hover prefetching")
 	fs.BoolVar(&cfg.InlineAssets, "inline-assets", cfg.InlineAssets, "Embed CSS/JS inline instead of external files")
 	fs.BoolVar(&cfg.PWA, "pwa", cfg.PWA, "Enable PWA manifest and service worker for offline support")
 	fs.BoolVar(&cfg.Search, "search", cfg.Search, "Enable site search with Cmd+K command palette")
+	fs.BoolVar(&cfg.Print, "print", cfg.Print, "Generate printable books (print.html and <folder>/print/)")
 	fs.BoolVar(&cfg.AllowBrokenLinks, "allow-broken-links", cfg.AllowBrokenLinks, "Don't fail build on broken internal links")
 	fs.BoolVar(&viewTransitionsFlag, "view-transitions", false, "Deprecated: view transitions are now enabled by default")
 	fs.BoolVar(&cfg.Quiet, "q", cfg.Quiet, "Suppress non-error output")
@@ -185,6 +189,14 @@ func Build(args []string, stdout, stderr io.Writer) error {
 		cfg.Theme = ""
 	}
 
+	// Validate layouts directory if provided
+	if cfg.LayoutsDir != "" {
+		if err := validateLayoutsDir(cfg.LayoutsDir); err != nil {
+			errLogger.Error("%v", err)
+			return err
+		}
+	}
+
 	// Validate favicon path if provided
 	if cfg.FaviconPath != "" {
 		if _, err := os.Stat(cfg.FaviconPath); err != nil {
@@ -231,7 +243,9 @@ func initDefaultTracker(tracker *configTracker, cfg *Config) {
 	tracker.set("author", cfg.Author, sourceDefault)
 	tracker.set("theme", cfg.Theme, sourceDefault)
 	tracker.set("css", cfg.CSSPath, sourceDefault)
+	tracker.set("layouts", cfg.LayoutsDir, sourceDefault)
 	tracker.set("accentColor", cfg.AccentColor, sourceDefault)
+	tracker.set("accentColorDark", cfg.AccentColorDark, sourceDefault)
 	tracker.set("favicon", cfg.FaviconPath, sourceDefault)
 	tracker.set("ogImage", cfg.OGImage, sourceDefault)
 	tracker.set("topNav", cfg.TopNav, sourceDefault)
@@ -241,6 +255,7 @@ func initDefaultTracker(tracker *configTracker, cfg *Config) {
 	tracker.set("inlineAssets", cfg.InlineAssets, sourceDefault)
 	tracker.set("pwa", cfg.PWA, sourceDefault)
 	tracker.set("search", cfg.Search, sourceDefault)
+	tracker.set("print", cfg.Print, sourceDefault)
 	tracker.set("allowBrokenLinks", cfg.AllowBrokenLinks, sourceDefault)
 }
 
@@ -253,7 +268,9 @@ func copyConfigValues(cfg *Config) map[string]interface{} {
 		"author":           cfg.Author,
 		"theme":            cfg.Theme,
 		"css":              cfg.CSSPath,
+		"layouts":          cfg.LayoutsDir,
 		"accentColor":      cfg.AccentColor,
+		"accentColorDark":  cfg.AccentColorDark,
 		"favicon":          cfg.FaviconPath,
 		"ogImage":          cfg.OGImage,
 		"topNav":           cfg.TopNav,
@@ -263,6 +280,7 @@ func copyConfigValues(cfg *Config) map[string]interface{} {
 		"inlineAssets":     cfg.InlineAssets,
 		"pwa":              cfg.PWA,
 		"search":           cfg.Search,
+		"print":            cfg.Print,
 		"allowBrokenLinks": cfg.AllowBrokenLinks,
 	}
 }
@@ -281,7 +299,9 @@ func detectCLIOverrides(cfg *Config, preCLI map[string]interface{}, tracker *con
 	checkOverride("author", preCLI["author"], cfg.Author)
 	checkOverride("theme", preCLI["theme"], cfg.Theme)
 	checkOverride("css", preCLI["css"], cfg.CSSPath)
+	checkOverride("layouts", preCLI["layouts"], cfg.LayoutsDir)
 	checkOverride("accentColor", preCLI["accentColor"], cfg.AccentColor)
+	checkOverride("accentColorDark", preCLI["accentColorDark"], cfg.AccentColorDark)
 	checkOverride("favicon", preCLI["favicon"], cfg.FaviconPath)
 	checkOverride("ogImage", preCLI["ogImage"], cfg.OGImage)
 	checkOverride("topNav", preCLI["topNav"], cfg.TopNav)
@@ -291,6 +311,7 @@ func detectCLIOverrides(cfg *Config, preCLI map[string]interface{}, tracker *con
 	checkOverride("inlineAssets", preCLI["inlineAssets"], cfg.InlineAssets)
 	checkOverride("pwa", preCLI["pwa"], cfg.PWA)
 	checkOverride("search", preCLI["search"], cfg.Search)
+	checkOverride("print", preCLI["print"], cfg.Print)
 	checkOverride("allowBrokenLinks", preCLI["allowBrokenLinks"], cfg.AllowBrokenLinks)
 }
 
@@ -308,7 +329,9 @@ func printCLIOverrides(logger *output.Logger, tracker *configTracker, hasConfigF
 		"author":           "--author",
 		"theme":            "--theme",
 		"css":              "--css",
+		"layouts":          "--layouts",
 		"accentColor":      "--accent-color",
+		"accentColorDark":  "--accent-color-dark",
 		"favicon":          "--favicon",
 		"ogImage":          "--og-image",
 		"topNav":           "--top-nav",
@@ -318,6 +341,7 @@ func printCLIOverrides(logger *output.Logger, tracker *configTracker, hasConfigF
 		"inlineAssets":     "--inline-assets",
 		"pwa":              "--pwa",
 		"search":           "--search",
+		"print":            "--print",
 		"allowBrokenLinks": "--allow-broken-links",
 	}
 
@@ -345,9 +369,37 @@ func printBuildConfig(logger *output.Logger, cfg *Config) {
 	if cfg.CSSPath != "" {
 		logger.Println("  css:         %s", cfg.CSSPath)
 	}
+	if cfg.LayoutsDir != "" {
+		logger.Println("  layouts:     %s", cfg.LayoutsDir)
+	}
+	if len(cfg.Folders) > 0 {
+		logger.Println("  folders:     %d rule(s)", len(cfg.Folders))
+	}
+	if n := len(cfg.Menus[config.MainMenu]); n > 0 {
+		logger.Println("  menu:        %d item(s)", n)
+	}
+	if cfg.Versions != nil {
+		logger.Println("  versions:    %s", versionsSummary(cfg.Versions))
+	}
+	if n := cfg.Fonts.FileCount(); n > 0 {
+		logger.Println("  fonts:       %d file(s)", n)
+	}
+	for _, inc := range []struct{ name, path string }{
+		{"header", cfg.HeaderPath}, {"footer", cfg.FooterPath}, {"banner", cfg.BannerPath},
+	} {
+		if inc.path != "" {
+			logger.Println("  %s:      %s", inc.name, inc.path)
+		}
+	}
+	if cfg.Language != "" || len(cfg.Languages) > 0 {
+		logger.Println("  language:    %s", strings.Join(append([]string{i18n.Normalize(cfg.Language)}, cfg.Languages...), ", "))
+	}
 	if cfg.AccentColor != "" {
 		logger.Println("  accentColor: %s", cfg.AccentColor)
 	}
+	if cfg.AccentColorDark != "" {
+		logger.Println("  accentColorDark: %s", cfg.AccentColorDark)
+	}
 	if cfg.FaviconPath != "" {
 		logger.Println("  favicon:     %s", cfg.FaviconPath)
 	}
@@ -366,6 +418,15 @@ func printBuildConfig(logger *output.Logger, cfg *Config) {
 	if cfg.ShowPageNav {
 		features = append(features, "pageNav")
 	}
+	if cfg.ShowPageNav && cfg.PageNavSections {
+		features = append(features, "pageNavSections")
+	}
+	if cfg.Related > 0 {
+		features = append(features, fmt.Sprintf("related(%d)", cfg.Related))
+	}
+	if cfg.Graph {
+		features = append(features, "graph")
+	}
 	if cfg.InstantNav {
 		features = append(features, "instantNav")
 	}
@@ -378,6 +439,15 @@ func printBuildConfig(logger *output.Logger, cfg *Config) {
 	if cfg.Search {
 		features = append(features, "search")
 	}
+	if cfg.Search && cfg.SearchShardSize > 0 {
+		features = append(features, fmt.Sprintf("searchShardSize(%d)", cfg.SearchShardSize))
+	}
+	if cfg.Print {
+		features = append(features, "print")
+	}
+	if cfg.OGImages {
+		features = append(features, "ogImages")
+	}
 	if cfg.AllowBrokenLinks {
 		features = append(features, "allowBrokenLinks")
 	}
@@ -387,6 +457,21 @@ func printBuildConfig(logger *output.Logger, cfg *Config) {
 	}
 }
 
+// validateLayoutsDir checks that a configured layouts directory exists
+func validateLayoutsDir(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("layouts directory not found: %s", path)
+		}
+		return fmt.Errorf("cannot access layouts directory: %v", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("layouts path is not a directory: %s", path)
+	}
+	return nil
+}
+
 // validateInputDir checks if the given path is a valid directory
 func validateInputDir(path string) error {
 	info, err := os.Stat(path)
@@ -423,8 +508,10 @@ func printBuildUsage(w io.Writer) {
 	_, _ = fmt.Fprintln(w, "Appearance:")
 	_, _ = fmt.Fprintln(w, "  --theme <name>       Theme: docs, blog, presentation, readable, vanilla (default: docs)")
 	_, _ = fmt.Fprintln(w, "  --css <path>         Custom CSS file (overrides theme)")
+	_, _ = fmt.Fprintln(w, "  --layouts <dir>      Template overrides (default: <input>/_layouts)")
 	_, _ = fmt.Fprintln(w, "  --accent-color <c>   Accent color: Tailwind name, hex, or gradient (default: sky)")
 	_, _ = fmt.Fprintln(w, "                         Examples: sky | #0ea5e9 | lime-sky | #444444-#555555")
+	_, _ = fmt.Fprintln(w, "  --accent-color-dark  Dark-mode accent color, same syntax (default: derived for contrast)")
 	_, _ = fmt.Fprintln(w, "  --favicon <path>     Favicon file (.ico, .png, .svg)")
 	_, _ = fmt.Fprintln(w, "")
 	_, _ = fmt.Fprintln(w, "Navigation:")
@@ -435,6 +522,7 @@ func printBuildUsage(w io.Writer) {
 	_, _ = fmt.Fprintln(w, "  --inline-assets      Embed CSS/JS inline instead of external files")
 	_, _ = fmt.Fprintln(w, "  --pwa                Enable PWA manifest and service worker for offline support")
 	_, _ = fmt.Fprintln(w, "  --search             Enable site search with Cmd+K command palette")
+	_, _ = fmt.Fprintln(w, "  --print              Generate printable books (print.html and <folder>/print/)")
 	_, _ = fmt.Fprintln(w, "  --allow-broken-links Don't fail build on broken internal links")
 	_, _ = fmt.Fprintln(w, "")
 	_, _ = fmt.Fprintln(w, "SEO:")
@@ -474,7 +562,7 @@ var buildValueFlags = map[string]bool{
 	"o": true, "output": true,
 	"title": true, "url": true, "author": true,
 	"og-image": true, "favicon": true,
-	"theme": true, "css": true, "accent-color": true,
+	"theme": true, "css": true, "accent-color": true, "accent-color-dark": true, "layouts": true,
 	"config": true, "c": true,
 }
 
@@ -597,10 +685,18 @@ func applyFileConfig(cfg *Config, fileCfg *config.FileConfig, tracker *configTra
 		cfg.CSSPath = fileCfg.CSS
 		tracker.set("css", fileCfg.CSS, sourceFile)
 	}
+	if fileCfg.Layouts != "" {
+		cfg.LayoutsDir = fileCfg.Layouts
+		tracker.set("layouts", fileCfg.Layouts, sourceFile)
+	}
 	if fileCfg.AccentColor != "" {
 		cfg.AccentColor = fileCfg.AccentColor
 		tracker.set("accentColor", fileCfg.AccentColor, sourceFile)
 	}
+	if fileCfg.AccentColorDark != "" {
+		cfg.AccentColorDark = fileCfg.AccentColorDark
+		tracker.set("accentColorDark", fileCfg.AccentColorDark, sourceFile)
+	}
 	if fileCfg.Favicon != "" {
 		cfg.FaviconPath = fileCfg.Favicon
 		tracker.set("favicon", fileCfg.Favicon, sourceFile)
@@ -623,6 +719,18 @@ func applyFileConfig(cfg *Config, fileCfg *config.FileConfig, tracker *configTra
 		cfg.ShowPageNav = *fileCfg.PageNav
 		tracker.set("pageNav", *fileCfg.PageNav, sourceFile)
 	}
+	if fileCfg.PageNavSections != nil {
+		cfg.PageNavSections = *fileCfg.PageNavSections
+	}
+	if fileCfg.Related != nil {
+		cfg.Related = *fileCfg.Related
+	}
+	if fileCfg.Graph != nil {
+		cfg.Graph = *fileCfg.Graph
+	}
+	if fileCfg.OGImages != nil {
+		cfg.OGImages = *fileCfg.OGImages
+	}
 	if fileCfg.InstantNav != nil {
 		cfg.InstantNav = *fileCfg.InstantNav
 		tracker.set("instantNav", *fileCfg.InstantNav, sourceFile)
@@ -639,8 +747,39 @@ func applyFileConfig(cfg *Config, fileCfg *config.FileConfig, tracker *configTra
 		cfg.Search = *fileCfg.Search
 		tracker.set("search", *fileCfg.Search, sourceFile)
 	}
+	if fileCfg.SearchShardSize != nil {
+		cfg.SearchShardSize = *fileCfg.SearchShardSize
+	}
+	if fileCfg.Print != nil {
+		cfg.Print = *fileCfg.Print
+		tracker.set("print", *fileCfg.Print, sourceFile)
+	}
 	if fileCfg.AllowBrokenLinks != nil {
 		cfg.AllowBrokenLinks = *fileCfg.AllowBrokenLinks
 		tracker.set("allowBrokenLinks", *fileCfg.AllowBrokenLinks, sourceFile)
 	}
+
+	// Folder rules - config file only
+	if fileCfg.Folders != nil {
+		cfg.Folders = fileCfg.Folders
+	}
+	if fileCfg.Fonts != nil {
+		cfg.Fonts = fileCfg.Fonts
+	}
+	if fileCfg.Menus != nil {
+		cfg.Menus = fileCfg.Menus
+	}
+	if fileCfg.Versions != nil {
+		cfg.Versions = fileCfg.Versions
+	}
+
+	// Include paths - config file only
+	cfg.HeaderPath = fileCfg.Header
+	cfg.FooterPath = fileCfg.Footer
+	cfg.BannerPath = fileCfg.Banner
+
+	// Localization - config file only
+	cfg.Language = fileCfg.Language
+	cfg.Languages = fileCfg.Languages
+	cfg.Translations = fileCfg.Translations
 }
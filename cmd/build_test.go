@@ -457,25 +457,41 @@ func TestApplyFileConfig(t *testing.T) {
 	t.Run("apply all fields", func(t *testing.T) {
 		cfg := DefaultConfig()
 		fileCfg := &config.FileConfig{
-			Output:       "./public",
-			Title:        "Test Site",
-			URL:          "https://example.com",
-			Author:       "Test Author",
-			Theme:        "blog",
-			CSS:          "./custom.css",
-			AccentColor:  "#ff6600",
-			Favicon:      "./favicon.png",
-			OGImage:      "./og.png",
-			TopNav:       config.BoolPtr(true),
-			Breadcrumbs:  config.BoolPtr(false),
-			PageNav:      config.BoolPtr(true),
-			InstantNav:   config.BoolPtr(true),
-			InlineAssets: config.BoolPtr(true),
-			PWA:          config.BoolPtr(true),
+			Output:          "./public",
+			Title:           "Test Site",
+			URL:             "https://example.com",
+			Author:          "Test Author",
+			Theme:           "blog",
+			CSS:             "./custom.css",
+			AccentColor:     "#ff6600",
+			AccentColorDark: "amber",
+			Favicon:         "./favicon.png",
+			OGImage:         "./og.png",
+			TopNav:          config.BoolPtr(true),
+			Breadcrumbs:     config.BoolPtr(false),
+			PageNav:         config.BoolPtr(true),
+			InstantNav:      config.BoolPtr(true),
+			InlineAssets:    config.BoolPtr(true),
+			PWA:             config.BoolPtr(true),
+			Folders:         map[string]config.FolderConfig{"talks": {Layout: "landing"}},
+			Banner:          "notice.md",
+			Print:           config.BoolPtr(true),
 		}
 
 		applyFileConfig(cfg, fileCfg, newConfigTracker())
 
+		if !cfg.Print {
+			t.Error("Print should be true")
+		}
+
+		if cfg.BannerPath != "notice.md" {
+			t.Errorf("BannerPath = %q, want %q", cfg.BannerPath, "notice.md")
+		}
+
+		if cfg.Folders["talks"].Layout != "landing" {
+			t.Errorf("Folders[talks].Layout = %q, want %q", cfg.Folders["talks"].Layout, "landing")
+		}
+
 		if cfg.OutputDir != "./public" {
 			t.Errorf("OutputDir = %q, want %q", cfg.OutputDir, "./public")
 		}
@@ -497,6 +513,9 @@ func TestApplyFileConfig(t *testing.T) {
 		if cfg.AccentColor != "#ff6600" {
 			t.Errorf("AccentColor = %q, want %q", cfg.AccentColor, "#ff6600")
 		}
+		if cfg.AccentColorDark != "amber" {
+			t.Errorf("AccentColorDark = %q, want %q", cfg.AccentColorDark, "amber")
+		}
 		if cfg.FaviconPath != "./favicon.png" {
 			t.Errorf("FaviconPath = %q, want %q", cfg.FaviconPath, "./favicon.png")
 		}
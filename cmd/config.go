@@ -1,6 +1,12 @@
 // Package cmd provides the command implementations for the volcano CLI.
 package cmd
 
+import (
+	"strings"
+
+	"github.com/wusher/volcano/internal/config"
+)
+
 // Config holds all configuration options for the volcano CLI
 type Config struct {
 	InputDir         string // Input directory containing markdown files
@@ -14,21 +20,43 @@ type Config struct {
 	SiteURL          string // Base URL for canonical links and SEO
 	Author           string // Site author
 	OGImage          string // Default Open Graph image
+	OGImages         bool   // Generate an Open Graph image for each page (config file only)
 	FaviconPath      string // Path to favicon file
 	TopNav           bool   // Display root files in top navigation bar
 	ShowPageNav      bool   // Show previous/next page navigation
+	PageNavSections  bool   // Keep prev/next links within top-level folders (config file only)
+	Related          int    // Number of related pages shown below each page (config file only)
+	Graph            bool   // Link graph page and local graph below each page (config file only)
 	ShowBreadcrumbs  bool   // Show breadcrumb navigation
 	Theme            string // Theme name (docs, blog, vanilla)
 	CSSPath          string // Path to custom CSS file
+	LayoutsDir       string // Directory with template overrides (default: <input>/_layouts)
 	AccentColor      string // Accent color: Tailwind name (e.g. "sky") or hex (e.g. "#0ea5e9")
+	AccentColorDark  string // Dark-mode accent color, same syntax as AccentColor (derived if empty)
 	InstantNav       bool   // Enable instant navigation with hover prefetching
 	ViewTransitions  bool   // Enable browser view transitions API
 	InlineAssets     bool   // Embed CSS/JS inline instead of external files
 	PWA              bool   // Enable PWA manifest and service worker generation
 	Search           bool   // Enable search index generation and command palette
+	SearchShardSize  int    // Target size of each search index shard in KB (config file only)
+	Print            bool   // Generate printable books (print.html and <folder>/print/)
 	AllowBrokenLinks bool   // Don't fail build on broken internal links
 	NoVerify         bool   // serve: skip internal-link validation (no console warnings, no inline banner)
 
+	Folders map[string]config.FolderConfig // Per-folder settings (config file only)
+	Fonts   *config.FontsConfig            // Self-hosted fonts (config file only)
+	Menus   map[string][]config.MenuItem   // Navigation menus (config file only)
+
+	Versions *config.VersionsConfig // Versioned documentation (config file only)
+
+	HeaderPath string // Header include (config file only, default: <input>/_header.md)
+	FooterPath string // Footer include (config file only, default: <input>/_footer.md)
+	BannerPath string // Banner include (config file only, default: <input>/_banner.md)
+
+	Language     string                       // Default language (config file only, default: en)
+	Languages    []string                     // Translation languages (config file only)
+	Translations map[string]map[string]string // Interface text overrides by language (config file only)
+
 	// Internal fields (not settable via CLI)
 	configFilePath string // Path to loaded config file (for verbose logging)
 }
@@ -44,3 +72,15 @@ func DefaultConfig() *Config {
 		ViewTransitions: true,  // View transitions enabled by default
 	}
 }
+
+// versionsSummary describes the versions setting for the config printout
+func versionsSummary(vc *config.VersionsConfig) string {
+	if len(vc.List) == 0 {
+		return "version folders of the input"
+	}
+	names := make([]string, len(vc.List))
+	for i, v := range vc.List {
+		names[i] = v.Name
+	}
+	return strings.Join(names, ", ")
+}
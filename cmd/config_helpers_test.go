@@ -160,10 +160,16 @@ func TestApplyServeFileConfig(t *testing.T) {
 		Breadcrumbs: config.BoolPtr(false),
 		PWA:         config.BoolPtr(true),
 		Search:      config.BoolPtr(true),
+		Print:       config.BoolPtr(true),
+		Folders:     map[string]config.FolderConfig{"talks": {Layout: "landing"}},
 	}
 
 	applyServeFileConfig(cfg, fileCfg, tracker)
 
+	if cfg.Folders["talks"].Layout != "landing" {
+		t.Errorf("Folders[talks].Layout = %q, want %q", cfg.Folders["talks"].Layout, "landing")
+	}
+
 	if cfg.Port != 8080 {
 		t.Errorf("Port = %d, want %d", cfg.Port, 8080)
 	}
@@ -185,6 +191,9 @@ func TestApplyServeFileConfig(t *testing.T) {
 	if !cfg.Search {
 		t.Error("Search should be true")
 	}
+	if !cfg.Print {
+		t.Error("Print should be true")
+	}
 	if tracker.getSource("title") != sourceFile {
 		t.Errorf("expected title source file, got %v", tracker.getSource("title"))
 	}
@@ -251,6 +260,7 @@ func TestPrintServeConfig(t *testing.T) {
 	cfg.InstantNav = true
 	cfg.PWA = true
 	cfg.Search = true
+	cfg.Print = true
 
 	var buf bytes.Buffer
 	logger := output.NewLogger(&buf, false, false, false)
@@ -260,7 +270,7 @@ func TestPrintServeConfig(t *testing.T) {
 	if !strings.Contains(output, "port:") || !strings.Contains(output, "features:") {
 		t.Errorf("expected serve config output, got %q", output)
 	}
-	if !strings.Contains(output, "search") {
-		t.Errorf("expected search in features, got %q", output)
+	if !strings.Contains(output, "search") || !strings.Contains(output, "print") {
+		t.Errorf("expected search and print in features, got %q", output)
 	}
 }
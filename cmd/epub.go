@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/epub"
+	"github.com/wusher/volcano/internal/includes"
+	"github.com/wusher/volcano/internal/output"
+)
+
+// epubValueFlags is the set of flags that take values for the epub command
+var epubValueFlags = map[string]bool{
+	"o": true, "output": true,
+	"title": true, "author": true, "url": true,
+	"config": true, "c": true,
+}
+
+// Epub handles the epub subcommand for exporting a site as an ebook
+func Epub(args []string, stdout, stderr io.Writer) error {
+	errLogger := output.NewLogger(stderr, output.IsStderrTTY(), false, false)
+
+	fs := flag.NewFlagSet("epub", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var showHelp, quiet bool
+	var outputPath, title, author, siteURL, configFlag string
+
+	fs.StringVar(&outputPath, "o", "site.epub", "Output path for the ebook")
+	fs.StringVar(&outputPath, "output", "site.epub", "Output path for the ebook")
+	fs.StringVar(&title, "title", "", "Book title (default: site title, then the home page title)")
+	fs.StringVar(&author, "author", "", "Book author (default: site author)")
+	fs.StringVar(&siteURL, "url", "", "Site base URL, used for links to pages outside the book")
+	fs.StringVar(&configFlag, "config", "", "Path to config file (default: volcano.json in input directory)")
+	fs.StringVar(&configFlag, "c", "", "Path to config file (default: volcano.json in input directory)")
+	fs.BoolVar(&quiet, "q", false, "Suppress non-error output")
+	fs.BoolVar(&quiet, "quiet", false, "Suppress non-error output")
+	fs.BoolVar(&showHelp, "h", false, "Show help")
+	fs.BoolVar(&showHelp, "help", false, "Show help")
+
+	fs.Usage = func() {
+		printEpubUsage(stdout)
+	}
+
+	// Reorder args to put flags first (Go's flag package stops at first non-flag)
+	if err := fs.Parse(reorderArgs(args, epubValueFlags)); err != nil {
+		return err
+	}
+
+	if showHelp {
+		printEpubUsage(stdout)
+		return nil
+	}
+
+	if fs.NArg() < 1 {
+		errLogger.Error("input folder is required")
+		_, _ = fmt.Fprintln(stderr, "")
+		printEpubUsage(stderr)
+		return fmt.Errorf("input folder is required")
+	}
+	inputDir := fs.Arg(0)
+	if err := validateInputDir(inputDir); err != nil {
+		errLogger.Error("%v", err)
+		return err
+	}
+
+	stdLogger := output.NewLogger(stdout, output.IsStdoutTTY(), quiet, false)
+
+	// The config file supplies the site's title, author, URL and include
+	// files; flags override it
+	cfg := epub.Config{InputDir: inputDir, Title: title, Author: author, SiteURL: siteURL}
+	fileCfg, cfgPath, err := config.LoadOrDiscover(configFlag, inputDir)
+	if err != nil {
+		errLogger.Error("%v", err)
+		return err
+	}
+	if fileCfg != nil {
+		stdLogger.Println("Using config: %s", cfgPath)
+		if cfg.Title == "" {
+			cfg.Title = fileCfg.Title
+		}
+		if cfg.Author == "" {
+			cfg.Author = fileCfg.Author
+		}
+		if cfg.SiteURL == "" {
+			cfg.SiteURL = fileCfg.URL
+		}
+		cfg.Includes = includes.Paths{Header: fileCfg.Header, Footer: fileCfg.Footer, Banner: fileCfg.Banner}
+		cfg.Language = fileCfg.Language
+		cfg.Languages = fileCfg.Languages
+		cfg.Translations = fileCfg.Translations
+	}
+
+	// Render into memory so a failed export leaves no partial file behind
+	var buf bytes.Buffer
+	result, err := epub.Write(&buf, cfg)
+	if err != nil {
+		errLogger.Error("%v", err)
+		return err
+	}
+	if dir := filepath.Dir(outputPath); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			errLogger.Error("failed to create output directory: %v", err)
+			return err
+		}
+	}
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		errLogger.Error("failed to write ebook: %v", err)
+		return err
+	}
+
+	for _, warning := range result.Warnings {
+		stdLogger.Warning("%s", warning)
+	}
+	stdLogger.Success("Wrote %s: %q, %d pages, %d images", outputPath, result.Title, result.Chapters, result.Images)
+	return nil
+}
+
+func printEpubUsage(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "Export a site as an EPUB ebook")
+	_, _ = fmt.Fprintln(w, "")
+	_, _ = fmt.Fprintln(w, "Usage:")
+	_, _ = fmt.Fprintln(w, "  volcano epub [flags] <input>")
+	_, _ = fmt.Fprintln(w, "")
+	_, _ = fmt.Fprintln(w, "Flags:")
+	_, _ = fmt.Fprintln(w, "  -o, --output <path>   Output path (default: site.epub)")
+	_, _ = fmt.Fprintln(w, "  --title <title>       Book title (default: site title, then the home page title)")
+	_, _ = fmt.Fprintln(w, "  --author <name>       Book author (default: site author)")
+	_, _ = fmt.Fprintln(w, "  --url <url>           Site base URL, used for links to pages outside the book")
+	_, _ = fmt.Fprintln(w, "  -c, --config <path>   Path to config file (default: volcano.json in input directory)")
+	_, _ = fmt.Fprintln(w, "  -q, --quiet           Suppress non-error output")
+	_, _ = fmt.Fprintln(w, "  -h, --help            Show this help message")
+	_, _ = fmt.Fprintln(w, "")
+	_, _ = fmt.Fprintln(w, "Description:")
+	_, _ = fmt.Fprintln(w, "  Renders every page in sidebar order into an EPUB 3 book. The table of")
+	_, _ = fmt.Fprintln(w, "  contents follows the folder tree, links between pages stay inside the")
+	_, _ = fmt.Fprintln(w, "  book and local images are embedded.")
+	_, _ = fmt.Fprintln(w, "")
+	_, _ = fmt.Fprintln(w, "Examples:")
+	_, _ = fmt.Fprintln(w, "  volcano epub ./docs -o handbook.epub")
+	_, _ = fmt.Fprintln(w, "  volcano epub ./docs --title=\"Handbook\" --author=\"Docs Team\"")
+}
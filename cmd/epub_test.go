@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEpub(t *testing.T) {
+	inputDir := t.TempDir()
+	for name, content := range map[string]string{
+		"index.md":     "# Home\n\n[Guide](/guide/)",
+		"guide.md":     "# Guide",
+		"volcano.json": `{"title": "Config Title", "author": "Jo"}`,
+	} {
+		if err := os.WriteFile(filepath.Join(inputDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outPath := filepath.Join(t.TempDir(), "out", "book.epub")
+
+	var stdout, stderr bytes.Buffer
+	if err := Epub([]string{inputDir, "-o", outPath, "--author", "Sam"}, &stdout, &stderr); err != nil {
+		t.Fatalf("Epub() error = %v, stderr = %s", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"Config Title", 2 pages`) {
+		t.Errorf("stdout = %q, want a summary with the config title", stdout.String())
+	}
+
+	zr, err := zip.OpenReader(outPath)
+	if err != nil {
+		t.Fatalf("output is not a zip archive: %v", err)
+	}
+	defer func() { _ = zr.Close() }()
+	for _, f := range zr.File {
+		if f.Name != "OEBPS/content.opf" {
+			continue
+		}
+		rc, _ := f.Open()
+		var buf bytes.Buffer
+		_, _ = buf.ReadFrom(rc)
+		_ = rc.Close()
+		if !strings.Contains(buf.String(), "<dc:creator>Sam</dc:creator>") {
+			t.Error("--author should override the config file")
+		}
+	}
+}
+
+func TestEpubErrors(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := Epub([]string{}, &stdout, &stderr); err == nil {
+		t.Error("Epub() should require an input folder")
+	}
+	if err := Epub([]string{filepath.Join(t.TempDir(), "missing")}, &stdout, &stderr); err == nil {
+		t.Error("Epub() should fail for a missing input folder")
+	}
+
+	// A folder without pages writes nothing
+	empty := t.TempDir()
+	outPath := filepath.Join(t.TempDir(), "empty.epub")
+	if err := Epub([]string{empty, "-o", outPath}, &stdout, &stderr); err == nil {
+		t.Error("Epub() should fail without pages")
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Error("failed export should not leave a file behind")
+	}
+
+	stdout.Reset()
+	if err := Epub([]string{"--help"}, &stdout, &stderr); err != nil {
+		t.Errorf("Epub(--help) error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "volcano epub") {
+		t.Error("help should show usage")
+	}
+}
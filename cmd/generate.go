@@ -4,6 +4,8 @@ import (
 	"io"
 
 	"github.com/wusher/volcano/internal/generator"
+	"github.com/wusher/volcano/internal/includes"
+	"github.com/wusher/volcano/internal/versions"
 )
 
 // Generate handles the static site generation from input folder to output folder
@@ -18,19 +20,51 @@ func Generate(cfg *Config, w io.Writer) error {
 		SiteURL:          cfg.SiteURL,
 		Author:           cfg.Author,
 		OGImage:          cfg.OGImage,
+		OGImages:         cfg.OGImages,
 		FaviconPath:      cfg.FaviconPath,
 		TopNav:           cfg.TopNav,
 		ShowPageNav:      cfg.ShowPageNav,
+		PageNavSections:  cfg.PageNavSections,
+		Related:          cfg.Related,
+		Graph:            cfg.Graph,
 		ShowBreadcrumbs:  cfg.ShowBreadcrumbs,
 		Theme:            cfg.Theme,
 		CSSPath:          cfg.CSSPath,
+		LayoutsDir:       cfg.LayoutsDir,
 		AccentColor:      cfg.AccentColor,
+		AccentColorDark:  cfg.AccentColorDark,
 		InstantNav:       cfg.InstantNav,
 		ViewTransitions:  cfg.ViewTransitions,
 		InlineAssets:     cfg.InlineAssets,
 		PWA:              cfg.PWA,
 		Search:           cfg.Search,
+		SearchShardSize:  cfg.SearchShardSize,
+		Print:            cfg.Print,
 		AllowBrokenLinks: cfg.AllowBrokenLinks,
+		Folders:          cfg.Folders,
+		Fonts:            cfg.Fonts,
+		Menus:            cfg.Menus,
+		Includes: includes.Paths{
+			Header: cfg.HeaderPath,
+			Footer: cfg.FooterPath,
+			Banner: cfg.BannerPath,
+		},
+		Language:     cfg.Language,
+		Languages:    cfg.Languages,
+		Translations: cfg.Translations,
+	}
+
+	if cfg.Versions != nil {
+		list, latest, err := versions.Resolve(cfg.Versions, cfg.InputDir)
+		if err != nil {
+			return err
+		}
+		set, err := versions.NewSet(list, latest, cfg.SiteURL)
+		if err != nil {
+			return err
+		}
+		_, err = generator.GenerateVersions(genConfig, set, w)
+		return err
 	}
 
 	gen, err := generator.New(genConfig, w)
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/wusher/volcano/internal/config"
 )
 
 func TestGenerate(t *testing.T) {
@@ -53,3 +55,40 @@ func TestGenerate(t *testing.T) {
 		t.Error("Generate should create index.html")
 	}
 }
+
+func TestGenerateVersions(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	for _, version := range []string{"v1", "v2"} {
+		dir := filepath.Join(inputDir, version)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "index.md"), []byte("# "+version), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Title:     "Test Site",
+		Versions:  &config.VersionsConfig{},
+	}
+	var buf bytes.Buffer
+	if err := Generate(cfg, &buf); err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+	for _, path := range []string{"v1/index.html", "v2/index.html", "latest/index.html", "index.html"} {
+		if _, err := os.Stat(filepath.Join(outputDir, path)); err != nil {
+			t.Errorf("Generate should create %s", path)
+		}
+	}
+
+	cfg.Versions = &config.VersionsConfig{Latest: "v3"}
+	if err := Generate(cfg, &buf); err == nil {
+		t.Error("Generate should fail for an unknown latest version")
+	}
+}
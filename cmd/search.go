@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/output"
+	"github.com/wusher/volcano/internal/search"
+	"github.com/wusher/volcano/internal/server"
+)
+
+// searchValueFlags is the set of flags that take values for the search command
+var searchValueFlags = map[string]bool{
+	"n": true, "limit": true,
+	"p": true, "port": true,
+	"lang":   true,
+	"config": true, "c": true,
+}
+
+// searchResult is a search result as --json prints it
+type searchResult struct {
+	search.Result
+	Source string `json:"source"` // Markdown file, relative to the input directory
+}
+
+// Search handles the search subcommand, which searches a site from the
+// terminal with the same index and ranking as the site's command palette
+func Search(args []string, stdout, stderr io.Writer) error {
+	errLogger := output.NewLogger(stderr, output.IsStderrTTY(), false, false)
+	cfg := DefaultConfig()
+
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var showHelp, jsonOutput, open bool
+	var limit, port int
+	var lang, configFlag string
+
+	fs.BoolVar(&jsonOutput, "json", false, "Print results as JSON")
+	fs.BoolVar(&open, "open", false, "Print only the dev server URL of the best result")
+	fs.IntVar(&limit, "n", 10, "Maximum number of results")
+	fs.IntVar(&limit, "limit", 10, "Maximum number of results")
+	fs.IntVar(&port, "p", 0, "Dev server port for --open (default: the serve port)")
+	fs.IntVar(&port, "port", 0, "Dev server port for --open (default: the serve port)")
+	fs.StringVar(&lang, "lang", "", "Language of the query (default: the site language)")
+	fs.StringVar(&configFlag, "config", "", "Path to config file (default: volcano.json in input directory)")
+	fs.StringVar(&configFlag, "c", "", "Path to config file (default: volcano.json in input directory)")
+	fs.BoolVar(&showHelp, "h", false, "Show help")
+	fs.BoolVar(&showHelp, "help", false, "Show help")
+
+	fs.Usage = func() {
+		printSearchUsage(stdout)
+	}
+
+	// Reorder args to put flags first (Go's flag package stops at first non-flag)
+	if err := fs.Parse(reorderArgs(args, searchValueFlags)); err != nil {
+		return err
+	}
+
+	if showHelp {
+		printSearchUsage(stdout)
+		return nil
+	}
+
+	if fs.NArg() < 2 {
+		errLogger.Error("input folder and query are required")
+		_, _ = fmt.Fprintln(stderr, "")
+		printSearchUsage(stderr)
+		return fmt.Errorf("input folder and query are required")
+	}
+	cfg.InputDir = fs.Arg(0)
+	query := strings.Join(fs.Args()[1:], " ")
+	if err := validateInputDir(cfg.InputDir); err != nil {
+		errLogger.Error("%v", err)
+		return err
+	}
+
+	// The config file sets what the index holds: languages, includes,
+	// shard size
+	fileCfg, _, err := config.LoadOrDiscover(configFlag, cfg.InputDir)
+	if err != nil {
+		errLogger.Error("%v", err)
+		return err
+	}
+	if fileCfg != nil {
+		applyServeFileConfig(cfg, fileCfg, newConfigTracker())
+	}
+	if port > 0 {
+		cfg.Port = port
+	}
+	if lang == "" {
+		lang = i18n.Normalize(cfg.Language)
+	}
+
+	// Build the index the dev server serves, which matches the built site's
+	sourceDir, err := previewSourceDir(cfg)
+	if err != nil {
+		errLogger.Error("%v", err)
+		return err
+	}
+	srv, err := server.NewDynamicServer(dynamicConfig(cfg, sourceDir), io.Discard)
+	if err != nil {
+		errLogger.Error("%v", err)
+		return err
+	}
+	files, sources, err := srv.SearchFiles()
+	if err != nil {
+		errLogger.Error("failed to build search index: %v", err)
+		return err
+	}
+	searcher, err := search.NewSearcher(files)
+	if err != nil {
+		errLogger.Error("%v", err)
+		return err
+	}
+	results, total, err := searcher.Search(query, lang, limit)
+	if err != nil {
+		errLogger.Error("%v", err)
+		return err
+	}
+
+	if open {
+		if len(results) == 0 {
+			errLogger.Error("no results for %q", query)
+			return fmt.Errorf("no results for %q", query)
+		}
+		_, _ = fmt.Fprintf(stdout, "http://localhost:%d%s\n", cfg.Port, results[0].URL)
+		return nil
+	}
+
+	found := make([]searchResult, len(results))
+	for i, result := range results {
+		found[i] = searchResult{Result: result, Source: resultSource(sources, result.URL, cfg.InputDir)}
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(found, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(stdout, string(data))
+		return nil
+	}
+
+	printSearchResults(stdout, found, total, lang, output.IsStdoutTTY())
+	return nil
+}
+
+// resultSource returns the markdown file of the page a result URL points to,
+// relative to the input directory
+func resultSource(sources map[string]string, url, inputDir string) string {
+	if i := strings.IndexByte(url, '#'); i >= 0 {
+		url = url[:i]
+	}
+	source := sources[url]
+	if rel, err := filepath.Rel(inputDir, source); err == nil && !strings.HasPrefix(rel, "..") {
+		source = rel
+	}
+	return filepath.ToSlash(source)
+}
+
+// printSearchResults prints each result's title, URL, source file and
+// snippet, with matched words highlighted when colored
+func printSearchResults(w io.Writer, results []searchResult, total int, lang string, colored bool) {
+	if len(results) == 0 {
+		_, _ = fmt.Fprintln(w, "No results")
+		return
+	}
+	mark := func(s string) string { return s }
+	gray := func(s string) string { return s }
+	if colored {
+		mark = func(s string) string { return output.ColorYellow + s + output.ColorReset }
+		gray = func(s string) string { return output.ColorGray + s + output.ColorReset }
+	}
+
+	for _, r := range results {
+		title := r.Highlight(r.Title, lang, mark)
+		if r.PageTitle != "" {
+			title += gray(" · " + r.PageTitle)
+		}
+		_, _ = fmt.Fprintln(w, title)
+		_, _ = fmt.Fprintln(w, "  "+r.URL+"  "+gray(r.Source))
+		if r.Snippet != "" {
+			_, _ = fmt.Fprintln(w, "  "+r.Highlight(r.Snippet, lang, mark))
+		}
+		_, _ = fmt.Fprintln(w, "")
+	}
+	if total > len(results) {
+		_, _ = fmt.Fprintln(w, gray(fmt.Sprintf("%d of %d results", len(results), total)))
+	} else {
+		_, _ = fmt.Fprintln(w, gray(fmt.Sprintf("%d results", total)))
+	}
+}
+
+func printSearchUsage(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "Search a site from the terminal")
+	_, _ = fmt.Fprintln(w, "")
+	_, _ = fmt.Fprintln(w, "Usage:")
+	_, _ = fmt.Fprintln(w, "  volcano search [flags] <input> <query>")
+	_, _ = fmt.Fprintln(w, "")
+	_, _ = fmt.Fprintln(w, "Flags:")
+	_, _ = fmt.Fprintln(w, "  --json                Print results as JSON")
+	_, _ = fmt.Fprintln(w, "  --open                Print only the dev server URL of the best result")
+	_, _ = fmt.Fprintln(w, "  -n, --limit <n>       Maximum number of results (default: 10)")
+	_, _ = fmt.Fprintln(w, "  -p, --port <port>     Dev server port for --open (default: the serve port)")
+	_, _ = fmt.Fprintln(w, "  --lang <code>         Language of the query (default: the site language)")
+	_, _ = fmt.Fprintln(w, "  -c, --config <path>   Path to config file (default: volcano.json in input directory)")
+	_, _ = fmt.Fprintln(w, "  -h, --help            Show this help message")
+	_, _ = fmt.Fprintln(w, "")
+	_, _ = fmt.Fprintln(w, "Description:")
+	_, _ = fmt.Fprintln(w, "  Builds the site's search index in memory and ranks pages and sections")
+	_, _ = fmt.Fprintln(w, "  the way the Cmd+K palette does, filters like tag:api and in:guides")
+	_, _ = fmt.Fprintln(w, "  included. Each result shows its URL, markdown file and a snippet.")
+	_, _ = fmt.Fprintln(w, "")
+	_, _ = fmt.Fprintln(w, "Examples:")
+	_, _ = fmt.Fprintln(w, "  volcano search ./docs \"consumer groups\"")
+	_, _ = fmt.Fprintln(w, "  volcano search ./docs \"tag:api in:guides\" --json")
+	_, _ = fmt.Fprintln(w, "  open $(volcano search ./docs kafka --open)")
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func searchSite(t *testing.T) string {
+	t.Helper()
+	inputDir := t.TempDir()
+	for name, content := range map[string]string{
+		"index.md":            "# Home\n\nWelcome.",
+		"guides/kafka.md":     "---\ntags: [streaming]\n---\n# Kafka\n\nBrokers and partitions.\n\n## Consumer Groups\n\nGroups share the deployments of partitions.",
+		"guides/postgres.md":  "# Postgres\n\nTables and indexes.",
+		"guides/kafka.fr.md":  "# Kafka\n\nLes déploiements des brokers.",
+		"volcano.json":        `{"languages": ["fr"], "port": 4000}`,
+		"guides/unrelated.md": "# Unrelated\n\nNothing here.",
+	} {
+		path := filepath.Join(inputDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return inputDir
+}
+
+func TestSearch(t *testing.T) {
+	inputDir := searchSite(t)
+
+	var stdout, stderr bytes.Buffer
+	if err := Search([]string{inputDir, "deployment", "partitions"}, &stdout, &stderr); err != nil {
+		t.Fatalf("Search() error = %v, stderr = %s", err, stderr.String())
+	}
+	want := "Consumer Groups · Kafka\n  /guides/kafka/#consumer-groups  guides/kafka.md\n  Groups share the deployments of partitions.\n\n1 results\n"
+	if stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+
+	// JSON results carry the source file; filters work as in the palette
+	stdout.Reset()
+	if err := Search([]string{"--json", inputDir, "tag:streaming"}, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	var results []map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		t.Fatalf("--json output is not JSON: %v\n%s", err, stdout.String())
+	}
+	if len(results) != 1 || results[0]["url"] != "/guides/kafka/" || results[0]["source"] != "guides/kafka.md" {
+		t.Errorf("results = %v", results)
+	}
+
+	// Translations are searched in their language
+	stdout.Reset()
+	if err := Search([]string{inputDir, "deploiement", "--lang", "fr", "--json"}, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), `"source": "guides/kafka.fr.md"`) {
+		t.Errorf("French search = %s", stdout.String())
+	}
+
+	// --open prints the dev server URL of the best result
+	stdout.Reset()
+	if err := Search([]string{inputDir, "postgres", "--open"}, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "http://localhost:4000/guides/postgres/\n" {
+		t.Errorf("--open = %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := Search([]string{inputDir, "guides", "-n", "1"}, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "1 of ") {
+		t.Errorf("--limit output = %q", stdout.String())
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := Search([]string{}, &stdout, &stderr); err == nil {
+		t.Error("Search() should require an input folder and a query")
+	}
+	if err := Search([]string{filepath.Join(t.TempDir(), "missing"), "x"}, &stdout, &stderr); err == nil {
+		t.Error("Search() should fail for a missing input folder")
+	}
+
+	inputDir := searchSite(t)
+	if err := Search([]string{inputDir, "xyzzy", "--open"}, &stdout, &stderr); err == nil {
+		t.Error("--open should fail without results")
+	}
+	stdout.Reset()
+	if err := Search([]string{inputDir, "xyzzy"}, &stdout, &stderr); err != nil || stdout.String() != "No results\n" {
+		t.Errorf("Search(no results) = %q, %v", stdout.String(), err)
+	}
+
+	stdout.Reset()
+	if err := Search([]string{"--help"}, &stdout, &stderr); err != nil {
+		t.Errorf("Search(--help) error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "volcano search") {
+		t.Error("help should show usage")
+	}
+}
@@ -9,9 +9,12 @@ import (
 	"strings"
 
 	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/includes"
 	"github.com/wusher/volcano/internal/output"
 	"github.com/wusher/volcano/internal/server"
 	"github.com/wusher/volcano/internal/styles"
+	"github.com/wusher/volcano/internal/versions"
 )
 
 // ServeCommand handles the serve subcommand for starting the development server
@@ -62,7 +65,9 @@ func ServeCommand(args []string, stdout, stderr io.Writer) error {
 	fs.StringVar(&cfg.Author, "author", cfg.Author, "Site author")
 	fs.StringVar(&cfg.Theme, "theme", cfg.Theme, "Theme name (docs, blog, presentation, readable, vanilla)")
 	fs.StringVar(&cfg.CSSPath, "css", cfg.CSSPath, "Path to custom CSS file")
+	fs.StringVar(&cfg.LayoutsDir, "layouts", cfg.LayoutsDir, "Directory with template overrides (default: <input>/_layouts)")
 	fs.StringVar(&cfg.AccentColor, "accent-color", cfg.AccentColor, "Accent color: Tailwind name, hex, or two-color gradient ('lime-sky', '#444444-#555555')")
+	fs.StringVar(&cfg.AccentColorDark, "accent-color-dark", cfg.AccentColorDark, "Dark-mode accent color, same syntax as --accent-color (default: derived for contrast)")
 	fs.StringVar(&cfg.FaviconPath, "favicon", cfg.FaviconPath, "Path to favicon file")
 	fs.BoolVar(&cfg.TopNav, "top-nav", cfg.TopNav, "Display root files in top navigation bar")
 	fs.BoolVar(&cfg.ShowPageNav, "page-nav", cfg.ShowPageNav, "Show previous/next page navigation")
@@ -74,6 +79,7 @@ func ServeCommand(args []string, stdout, stderr io.Writer) error {
 	fs.BoolVar(&cfg.Verbose, "verbose", cfg.Verbose, "Enable debug output")
 	fs.BoolVar(&cfg.PWA, "pwa", cfg.PWA, "Enable PWA manifest and service worker for offline support")
 	fs.BoolVar(&cfg.Search, "search", cfg.Search, "Enable site search with Cmd+K command palette")
+	fs.BoolVar(&cfg.Print, "print", cfg.Print, "Generate printable books (print.html and <folder>/print/)")
 	fs.BoolVar(&cfg.NoVerify, "no-verify", cfg.NoVerify, "Skip internal-link validation (no console warnings, no inline banner)")
 	fs.StringVar(&configFlag, "config", "", "Path to config file (default: volcano.json in input directory)")
 	fs.StringVar(&configFlag, "c", "", "Path to config file (default: volcano.json in input directory)")
@@ -142,6 +148,14 @@ func ServeCommand(args []string, stdout, stderr io.Writer) error {
 		cfg.Theme = ""
 	}
 
+	// Validate layouts directory if provided
+	if cfg.LayoutsDir != "" {
+		if err := validateLayoutsDir(cfg.LayoutsDir); err != nil {
+			errLogger.Error("%v", err)
+			return err
+		}
+	}
+
 	// Validate favicon path if provided
 	if cfg.FaviconPath != "" {
 		if _, err := os.Stat(cfg.FaviconPath); err != nil {
@@ -185,7 +199,9 @@ func initServeDefaultTracker(tracker *configTracker, cfg *Config) {
 	tracker.set("author", cfg.Author, sourceDefault)
 	tracker.set("theme", cfg.Theme, sourceDefault)
 	tracker.set("css", cfg.CSSPath, sourceDefault)
+	tracker.set("layouts", cfg.LayoutsDir, sourceDefault)
 	tracker.set("accentColor", cfg.AccentColor, sourceDefault)
+	tracker.set("accentColorDark", cfg.AccentColorDark, sourceDefault)
 	tracker.set("favicon", cfg.FaviconPath, sourceDefault)
 	tracker.set("topNav", cfg.TopNav, sourceDefault)
 	tracker.set("breadcrumbs", cfg.ShowBreadcrumbs, sourceDefault)
@@ -193,25 +209,29 @@ func initServeDefaultTracker(tracker *configTracker, cfg *Config) {
 	tracker.set("instantNav", cfg.InstantNav, sourceDefault)
 	tracker.set("pwa", cfg.PWA, sourceDefault)
 	tracker.set("search", cfg.Search, sourceDefault)
+	tracker.set("print", cfg.Print, sourceDefault)
 }
 
 // copyServeConfigValues creates a copy of config values for override detection
 func copyServeConfigValues(cfg *Config) map[string]interface{} {
 	return map[string]interface{}{
-		"port":        cfg.Port,
-		"title":       cfg.Title,
-		"url":         cfg.SiteURL,
-		"author":      cfg.Author,
-		"theme":       cfg.Theme,
-		"css":         cfg.CSSPath,
-		"accentColor": cfg.AccentColor,
-		"favicon":     cfg.FaviconPath,
-		"topNav":      cfg.TopNav,
-		"breadcrumbs": cfg.ShowBreadcrumbs,
-		"pageNav":     cfg.ShowPageNav,
-		"instantNav":  cfg.InstantNav,
-		"pwa":         cfg.PWA,
-		"search":      cfg.Search,
+		"port":            cfg.Port,
+		"title":           cfg.Title,
+		"url":             cfg.SiteURL,
+		"author":          cfg.Author,
+		"theme":           cfg.Theme,
+		"css":             cfg.CSSPath,
+		"layouts":         cfg.LayoutsDir,
+		"accentColor":     cfg.AccentColor,
+		"accentColorDark": cfg.AccentColorDark,
+		"favicon":         cfg.FaviconPath,
+		"topNav":          cfg.TopNav,
+		"breadcrumbs":     cfg.ShowBreadcrumbs,
+		"pageNav":         cfg.ShowPageNav,
+		"instantNav":      cfg.InstantNav,
+		"pwa":             cfg.PWA,
+		"search":          cfg.Search,
+		"print":           cfg.Print,
 	}
 }
 
@@ -229,7 +249,9 @@ func detectServeCLIOverrides(cfg *Config, preCLI map[string]interface{}, tracker
 	checkOverride("author", preCLI["author"], cfg.Author)
 	checkOverride("theme", preCLI["theme"], cfg.Theme)
 	checkOverride("css", preCLI["css"], cfg.CSSPath)
+	checkOverride("layouts", preCLI["layouts"], cfg.LayoutsDir)
 	checkOverride("accentColor", preCLI["accentColor"], cfg.AccentColor)
+	checkOverride("accentColorDark", preCLI["accentColorDark"], cfg.AccentColorDark)
 	checkOverride("favicon", preCLI["favicon"], cfg.FaviconPath)
 	checkOverride("topNav", preCLI["topNav"], cfg.TopNav)
 	checkOverride("breadcrumbs", preCLI["breadcrumbs"], cfg.ShowBreadcrumbs)
@@ -237,6 +259,7 @@ func detectServeCLIOverrides(cfg *Config, preCLI map[string]interface{}, tracker
 	checkOverride("instantNav", preCLI["instantNav"], cfg.InstantNav)
 	checkOverride("pwa", preCLI["pwa"], cfg.PWA)
 	checkOverride("search", preCLI["search"], cfg.Search)
+	checkOverride("print", preCLI["print"], cfg.Print)
 }
 
 // printServeCLIOverrides prints messages for CLI flags that override config file values
@@ -247,20 +270,23 @@ func printServeCLIOverrides(logger *output.Logger, tracker *configTracker, hasCo
 
 	// Map of option names to their CLI flag names
 	flagNames := map[string]string{
-		"port":        "--port",
-		"title":       "--title",
-		"url":         "--url",
-		"author":      "--author",
-		"theme":       "--theme",
-		"css":         "--css",
-		"accentColor": "--accent-color",
-		"favicon":     "--favicon",
-		"topNav":      "--top-nav",
-		"breadcrumbs": "--breadcrumbs",
-		"pageNav":     "--page-nav",
-		"instantNav":  "--instant-nav",
-		"pwa":         "--pwa",
-		"search":      "--search",
+		"port":            "--port",
+		"title":           "--title",
+		"url":             "--url",
+		"author":          "--author",
+		"theme":           "--theme",
+		"css":             "--css",
+		"layouts":         "--layouts",
+		"accentColor":     "--accent-color",
+		"accentColorDark": "--accent-color-dark",
+		"favicon":         "--favicon",
+		"topNav":          "--top-nav",
+		"breadcrumbs":     "--breadcrumbs",
+		"pageNav":         "--page-nav",
+		"instantNav":      "--instant-nav",
+		"pwa":             "--pwa",
+		"search":          "--search",
+		"print":           "--print",
 	}
 
 	for name, flagName := range flagNames {
@@ -289,9 +315,37 @@ func printServeConfig(logger *output.Logger, cfg *Config) {
 	if cfg.CSSPath != "" {
 		logger.Println("  css:         %s", cfg.CSSPath)
 	}
+	if cfg.LayoutsDir != "" {
+		logger.Println("  layouts:     %s", cfg.LayoutsDir)
+	}
+	if len(cfg.Folders) > 0 {
+		logger.Println("  folders:     %d rule(s)", len(cfg.Folders))
+	}
+	if n := len(cfg.Menus[config.MainMenu]); n > 0 {
+		logger.Println("  menu:        %d item(s)", n)
+	}
+	if cfg.Versions != nil {
+		logger.Println("  versions:    %s", versionsSummary(cfg.Versions))
+	}
+	if n := cfg.Fonts.FileCount(); n > 0 {
+		logger.Println("  fonts:       %d file(s)", n)
+	}
+	for _, inc := range []struct{ name, path string }{
+		{"header", cfg.HeaderPath}, {"footer", cfg.FooterPath}, {"banner", cfg.BannerPath},
+	} {
+		if inc.path != "" {
+			logger.Println("  %s:      %s", inc.name, inc.path)
+		}
+	}
+	if cfg.Language != "" || len(cfg.Languages) > 0 {
+		logger.Println("  language:    %s", strings.Join(append([]string{i18n.Normalize(cfg.Language)}, cfg.Languages...), ", "))
+	}
 	if cfg.AccentColor != "" {
 		logger.Println("  accentColor: %s", cfg.AccentColor)
 	}
+	if cfg.AccentColorDark != "" {
+		logger.Println("  accentColorDark: %s", cfg.AccentColorDark)
+	}
 	if cfg.FaviconPath != "" {
 		logger.Println("  favicon:     %s", cfg.FaviconPath)
 	}
@@ -307,6 +361,15 @@ func printServeConfig(logger *output.Logger, cfg *Config) {
 	if cfg.ShowPageNav {
 		features = append(features, "pageNav")
 	}
+	if cfg.ShowPageNav && cfg.PageNavSections {
+		features = append(features, "pageNavSections")
+	}
+	if cfg.Related > 0 {
+		features = append(features, fmt.Sprintf("related(%d)", cfg.Related))
+	}
+	if cfg.Graph {
+		features = append(features, "graph")
+	}
 	if cfg.InstantNav {
 		features = append(features, "instantNav")
 	}
@@ -316,6 +379,12 @@ func printServeConfig(logger *output.Logger, cfg *Config) {
 	if cfg.Search {
 		features = append(features, "search")
 	}
+	if cfg.Search && cfg.SearchShardSize > 0 {
+		features = append(features, fmt.Sprintf("searchShardSize(%d)", cfg.SearchShardSize))
+	}
+	if cfg.Print {
+		features = append(features, "print")
+	}
 
 	if len(features) > 0 {
 		logger.Println("  features:    %s", strings.Join(features, ", "))
@@ -351,10 +420,18 @@ func applyServeFileConfig(cfg *Config, fileCfg *config.FileConfig, tracker *conf
 		cfg.CSSPath = fileCfg.CSS
 		tracker.set("css", fileCfg.CSS, sourceFile)
 	}
+	if fileCfg.Layouts != "" {
+		cfg.LayoutsDir = fileCfg.Layouts
+		tracker.set("layouts", fileCfg.Layouts, sourceFile)
+	}
 	if fileCfg.AccentColor != "" {
 		cfg.AccentColor = fileCfg.AccentColor
 		tracker.set("accentColor", fileCfg.AccentColor, sourceFile)
 	}
+	if fileCfg.AccentColorDark != "" {
+		cfg.AccentColorDark = fileCfg.AccentColorDark
+		tracker.set("accentColorDark", fileCfg.AccentColorDark, sourceFile)
+	}
 	if fileCfg.Favicon != "" {
 		cfg.FaviconPath = fileCfg.Favicon
 		tracker.set("favicon", fileCfg.Favicon, sourceFile)
@@ -373,6 +450,15 @@ func applyServeFileConfig(cfg *Config, fileCfg *config.FileConfig, tracker *conf
 		cfg.ShowPageNav = *fileCfg.PageNav
 		tracker.set("pageNav", *fileCfg.PageNav, sourceFile)
 	}
+	if fileCfg.PageNavSections != nil {
+		cfg.PageNavSections = *fileCfg.PageNavSections
+	}
+	if fileCfg.Related != nil {
+		cfg.Related = *fileCfg.Related
+	}
+	if fileCfg.Graph != nil {
+		cfg.Graph = *fileCfg.Graph
+	}
 	if fileCfg.InstantNav != nil {
 		cfg.InstantNav = *fileCfg.InstantNav
 		tracker.set("instantNav", *fileCfg.InstantNav, sourceFile)
@@ -385,6 +471,37 @@ func applyServeFileConfig(cfg *Config, fileCfg *config.FileConfig, tracker *conf
 		cfg.Search = *fileCfg.Search
 		tracker.set("search", *fileCfg.Search, sourceFile)
 	}
+	if fileCfg.SearchShardSize != nil {
+		cfg.SearchShardSize = *fileCfg.SearchShardSize
+	}
+	if fileCfg.Print != nil {
+		cfg.Print = *fileCfg.Print
+		tracker.set("print", *fileCfg.Print, sourceFile)
+	}
+
+	// Folder rules - config file only
+	if fileCfg.Folders != nil {
+		cfg.Folders = fileCfg.Folders
+	}
+	if fileCfg.Fonts != nil {
+		cfg.Fonts = fileCfg.Fonts
+	}
+	if fileCfg.Menus != nil {
+		cfg.Menus = fileCfg.Menus
+	}
+	if fileCfg.Versions != nil {
+		cfg.Versions = fileCfg.Versions
+	}
+
+	// Include paths - config file only
+	cfg.HeaderPath = fileCfg.Header
+	cfg.FooterPath = fileCfg.Footer
+	cfg.BannerPath = fileCfg.Banner
+
+	// Localization - config file only
+	cfg.Language = fileCfg.Language
+	cfg.Languages = fileCfg.Languages
+	cfg.Translations = fileCfg.Translations
 }
 
 // prescanServeArgs extracts the input directory and config path from args
@@ -440,30 +557,15 @@ func prescanServeArgs(args []string) (inputDir, configPath string) {
 // dynamic rendering so changes are reflected immediately without restart.
 // Otherwise, it serves static files from the directory.
 func Serve(cfg *Config, w io.Writer) error {
+	sourceDir, err := previewSourceDir(cfg)
+	if err != nil {
+		return err
+	}
+
 	// Check if this is a source directory (contains .md files but no index.html)
-	if isSourceDirectory(cfg.InputDir) {
+	if isSourceDirectory(sourceDir) {
 		// Use dynamic server for live rendering
-		dynamicCfg := server.DynamicConfig{
-			SourceDir:       cfg.InputDir,
-			Title:           cfg.Title,
-			Port:            cfg.Port,
-			Quiet:           cfg.Quiet,
-			Verbose:         cfg.Verbose,
-			TopNav:          cfg.TopNav,
-			ShowPageNav:     cfg.ShowPageNav,
-			ShowBreadcrumbs: cfg.ShowBreadcrumbs,
-			Theme:           cfg.Theme,
-			CSSPath:         cfg.CSSPath,
-			AccentColor:     cfg.AccentColor,
-			InstantNav:      cfg.InstantNav,
-			ViewTransitions: cfg.ViewTransitions,
-			FaviconPath:     cfg.FaviconPath,
-			PWA:             cfg.PWA,
-			Search:          cfg.Search,
-			NoVerify:        cfg.NoVerify,
-		}
-
-		srv, err := server.NewDynamicServer(dynamicCfg, w)
+		srv, err := server.NewDynamicServer(dynamicConfig(cfg, sourceDir), w)
 		if err != nil {
 			return err
 		}
@@ -483,6 +585,66 @@ func Serve(cfg *Config, w io.Writer) error {
 	return srv.Start()
 }
 
+// previewSourceDir returns the directory the dev server renders: the input
+// directory, or the latest version of a versioned site
+func previewSourceDir(cfg *Config) (string, error) {
+	if cfg.Versions == nil {
+		return cfg.InputDir, nil
+	}
+	list, latest, err := versions.Resolve(cfg.Versions, cfg.InputDir)
+	if err != nil {
+		return "", err
+	}
+	sourceDir := cfg.InputDir
+	for _, v := range list {
+		if v.Name == latest {
+			sourceDir = v.Dir
+		}
+	}
+	return sourceDir, nil
+}
+
+// dynamicConfig returns the dev server config for rendering sourceDir
+func dynamicConfig(cfg *Config, sourceDir string) server.DynamicConfig {
+	return server.DynamicConfig{
+		SourceDir:       sourceDir,
+		Title:           cfg.Title,
+		Port:            cfg.Port,
+		Quiet:           cfg.Quiet,
+		Verbose:         cfg.Verbose,
+		TopNav:          cfg.TopNav,
+		ShowPageNav:     cfg.ShowPageNav,
+		PageNavSections: cfg.PageNavSections,
+		Related:         cfg.Related,
+		Graph:           cfg.Graph,
+		ShowBreadcrumbs: cfg.ShowBreadcrumbs,
+		Theme:           cfg.Theme,
+		CSSPath:         cfg.CSSPath,
+		LayoutsDir:      cfg.LayoutsDir,
+		AccentColor:     cfg.AccentColor,
+		AccentColorDark: cfg.AccentColorDark,
+		InstantNav:      cfg.InstantNav,
+		ViewTransitions: cfg.ViewTransitions,
+		FaviconPath:     cfg.FaviconPath,
+		PWA:             cfg.PWA,
+		Search:          cfg.Search,
+		SearchShardSize: cfg.SearchShardSize,
+		Print:           cfg.Print,
+		NoVerify:        cfg.NoVerify,
+		Folders:         cfg.Folders,
+		Fonts:           cfg.Fonts,
+		Menus:           cfg.Menus,
+		Includes: includes.Paths{
+			Header: cfg.HeaderPath,
+			Footer: cfg.FooterPath,
+			Banner: cfg.BannerPath,
+		},
+		Language:     cfg.Language,
+		Languages:    cfg.Languages,
+		Translations: cfg.Translations,
+	}
+}
+
 // isSourceDirectory checks if a directory is a source directory
 // (contains .md files anywhere in its tree but no index.html at the root).
 // The recursion is bounded — we stop on the first .md found, so a deeply
@@ -557,8 +719,10 @@ func printServeUsage(w io.Writer) {
 	_, _ = fmt.Fprintln(w, "Appearance:")
 	_, _ = fmt.Fprintln(w, "  --theme <name>       Theme: docs, blog, presentation, readable, vanilla (default: docs)")
 	_, _ = fmt.Fprintln(w, "  --css <path>         Custom CSS file (overrides theme)")
+	_, _ = fmt.Fprintln(w, "  --layouts <dir>      Template overrides (default: <input>/_layouts)")
 	_, _ = fmt.Fprintln(w, "  --accent-color <c>   Accent color: Tailwind name, hex, or gradient (default: sky)")
 	_, _ = fmt.Fprintln(w, "                         Examples: sky | #0ea5e9 | lime-sky | #444444-#555555")
+	_, _ = fmt.Fprintln(w, "  --accent-color-dark  Dark-mode accent color, same syntax (default: derived for contrast)")
 	_, _ = fmt.Fprintln(w, "  --favicon <path>     Favicon file (ico, png, svg, gif)")
 	_, _ = fmt.Fprintln(w, "")
 	_, _ = fmt.Fprintln(w, "Navigation:")
@@ -576,6 +740,7 @@ func printServeUsage(w io.Writer) {
 	_, _ = fmt.Fprintln(w, "")
 	_, _ = fmt.Fprintln(w, "Search:")
 	_, _ = fmt.Fprintln(w, "  --search             Enable site search with Cmd+K command palette")
+	_, _ = fmt.Fprintln(w, "  --print              Generate printable books (print.html and <folder>/print/)")
 	_, _ = fmt.Fprintln(w, "")
 	_, _ = fmt.Fprintln(w, "Validation:")
 	_, _ = fmt.Fprintln(w, "  --no-verify          Skip internal-link validation (no console warnings, no inline banner)")
@@ -600,6 +765,6 @@ func printServeUsage(w io.Writer) {
 var serveValueFlags = map[string]bool{
 	"p": true, "port": true,
 	"title": true, "url": true, "author": true,
-	"theme": true, "css": true, "accent-color": true, "favicon": true,
+	"theme": true, "css": true, "accent-color": true, "accent-color-dark": true, "layouts": true, "favicon": true,
 	"config": true, "c": true,
 }
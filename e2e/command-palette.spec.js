@@ -343,7 +343,7 @@ ${LOREM}
     expect(resultTypes).toContain("page");
   });
 
-  test("heading results show parent page as snippet", async ({ page }) => {
+  test("heading results show their parent page", async ({ page }) => {
     await page.goto(`http://localhost:${PORT}/`);
 
     // Open command palette
@@ -354,9 +354,9 @@ ${LOREM}
     await page.fill("#command-palette-input", "installation");
     await page.waitForTimeout(300);
 
-    // Should show the parent page title as snippet
-    const snippet = await page.textContent(".result-snippet");
-    expect(snippet).toBe("Getting Started");
+    // Should show the parent page title
+    const parent = await page.textContent(".result-page");
+    expect(parent).toBe("Getting Started");
   });
 
   test("limits results to 10", async ({ page }) => {
@@ -115,6 +115,48 @@ ${LOREM}
     expect(resultText).toContain("API Reference");
   });
 
+  test("search finds body text and highlights it", async ({ page }) => {
+    await page.goto(`http://localhost:${PORT}/`);
+
+    // Open command palette
+    await page.keyboard.press("Meta+k");
+    await page.waitForSelector(".command-palette.open");
+
+    // Search for a word only in a page's body, with a typo
+    await page.fill("#command-palette-input", "qiuckly");
+    await page.waitForTimeout(300);
+
+    const resultText = await page.textContent(".command-palette-results");
+    expect(resultText).toContain("Getting Started");
+    const marked = await page.textContent(".result-snippet mark");
+    expect(marked).toBe("quickly");
+  });
+
+  test("filters narrow results and show as chips", async ({ page }) => {
+    await page.goto(`http://localhost:${PORT}/`);
+
+    // Open command palette
+    await page.keyboard.press("Meta+k");
+    await page.waitForSelector(".command-palette.open");
+
+    // Words that match several pages, then only the guides section
+    await page.fill("#command-palette-input", "guide");
+    await page.waitForTimeout(300);
+    expect(await page.locator(".search-chip").count()).toBeGreaterThan(0);
+
+    await page.fill("#command-palette-input", "guide in:guides");
+    await page.waitForTimeout(300);
+    const resultText = await page.textContent(".command-palette-results");
+    expect(resultText).toContain("Advanced Guide");
+    expect(resultText).not.toContain("Getting Started");
+    expect(await page.textContent(".search-chip.active")).toContain("in:guides");
+
+    // Clicking the filter's chip removes it
+    await page.click(".search-chip.active");
+    await page.waitForTimeout(300);
+    expect(await page.inputValue("#command-palette-input")).toBe("guide");
+  });
+
   test("clicking search result navigates to page", async ({ page }) => {
     await page.goto(`http://localhost:${PORT}/`);
 
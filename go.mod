@@ -17,4 +17,5 @@ require (
 	github.com/dlclark/regexp2 v1.11.5 // indirect
 	github.com/tdewolff/parse/v2 v2.8.5 // indirect
 	golang.org/x/sys v0.39.0 // indirect
+	golang.org/x/text v0.33.0 // indirect
 )
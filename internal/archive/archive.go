@@ -0,0 +1,269 @@
+// Package archive builds date archives of a folder's dated pages: one page
+// with everything grouped by year and month (/archive/), and a page for
+// each year (/2024/) and month (/2024/03/).
+package archive
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/navigation"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// Slug is the path segment of an archive's index page
+const Slug = "archive"
+
+// Entry is a dated page in an archive
+type Entry struct {
+	Title string
+	URL   string
+	Date  time.Time
+}
+
+// Month is a month's entries, newest first
+type Month struct {
+	Month   time.Month
+	URLPath string // URL path without base path (/2024/03/)
+	Entries []Entry
+}
+
+// Year is a year's months, newest first
+type Year struct {
+	Year    int
+	URLPath string // URL path without base path (/2024/)
+	Months  []Month
+}
+
+// Archive is the dated pages under a folder, grouped by year and month
+type Archive struct {
+	Folder  *tree.Node
+	Years   []Year            // Newest first
+	months  map[string]string // Month URL path, by page path
+	baseURL string
+}
+
+// Page is a generated archive page
+type Page struct {
+	URLPath string // URL path without base path
+	Title   string
+	Content template.HTML
+}
+
+// Collect returns the folders that get an archive: the topmost folders for
+// which enabled reports true, starting at root. A folder's archive covers
+// its subfolders, so they don't get one of their own.
+func Collect(root *tree.Node, enabled func(folder *tree.Node) bool) []*tree.Node {
+	if root == nil {
+		return nil
+	}
+	if enabled(root) {
+		return []*tree.Node{root}
+	}
+	var folders []*tree.Node
+	for _, child := range root.Children {
+		if child.IsFolder {
+			folders = append(folders, Collect(child, enabled)...)
+		}
+	}
+	return folders
+}
+
+// Build groups the dated pages under folder by year and month. Dates come
+// from a page's filename prefix or its `date` front matter; undated pages
+// and pages hidden from navigation are left out, as is the folder's own
+// index page. baseURL is the site URL or base path that links are
+// prefixed with.
+func Build(folder *tree.Node, allPages []*tree.Node, baseURL string) *Archive {
+	a := &Archive{Folder: folder, months: make(map[string]string), baseURL: baseURL}
+
+	type dated struct {
+		page *tree.Node
+		date time.Time
+	}
+	var pages []dated
+	for _, page := range navigation.FlattenPagesForPagination(folder, allPages) {
+		if folder.HasIndex && page.Path == folder.IndexPath {
+			continue
+		}
+		if date := tree.NodeDate(page); !date.IsZero() {
+			pages = append(pages, dated{page, date})
+		}
+	}
+	sort.SliceStable(pages, func(i, j int) bool {
+		return pages[i].date.After(pages[j].date)
+	})
+
+	for _, p := range pages {
+		year, month := p.date.Year(), p.date.Month()
+		if len(a.Years) == 0 || a.Years[len(a.Years)-1].Year != year {
+			a.Years = append(a.Years, Year{Year: year, URLPath: a.YearURLPath(year)})
+		}
+		y := &a.Years[len(a.Years)-1]
+		if len(y.Months) == 0 || y.Months[len(y.Months)-1].Month != month {
+			y.Months = append(y.Months, Month{Month: month, URLPath: a.MonthURLPath(year, month)})
+		}
+		m := &y.Months[len(y.Months)-1]
+		m.Entries = append(m.Entries, Entry{
+			Title: p.page.Name,
+			URL:   tree.PrefixURL(baseURL, tree.GetURLPath(p.page)),
+			Date:  p.date,
+		})
+		a.months[p.page.Path] = m.URLPath
+	}
+	return a
+}
+
+// Empty reports whether the archive has no dated pages
+func (a *Archive) Empty() bool {
+	return len(a.Years) == 0
+}
+
+// URLPath returns the URL path of the archive's index page
+func (a *Archive) URLPath() string {
+	return tree.GetURLPath(a.Folder) + Slug + "/"
+}
+
+// YearURLPath returns the URL path of a year's archive page
+func (a *Archive) YearURLPath(year int) string {
+	return tree.GetURLPath(a.Folder) + strconv.Itoa(year) + "/"
+}
+
+// MonthURLPath returns the URL path of a month's archive page
+func (a *Archive) MonthURLPath(year int, month time.Month) string {
+	return a.YearURLPath(year) + fmt.Sprintf("%02d", int(month)) + "/"
+}
+
+// MonthURL returns the link to the month archive a page is listed in, or ""
+// if the page isn't in the archive
+func (a *Archive) MonthURL(page *tree.Node) string {
+	urlPath, ok := a.months[page.Path]
+	if !ok {
+		return ""
+	}
+	return tree.PrefixURL(a.baseURL, urlPath)
+}
+
+// Pages returns every page of the archive: its index, then each year
+// followed by its months
+func (a *Archive) Pages(messages i18n.Messages) []Page {
+	if a.Empty() {
+		return nil
+	}
+	pages := []Page{a.indexPage(messages)}
+	for _, year := range a.Years {
+		pages = append(pages, a.yearPage(year, messages))
+		for _, month := range year.Months {
+			pages = append(pages, a.monthPage(year, month, messages))
+		}
+	}
+	return pages
+}
+
+// Find returns the archive page at urlPath
+func (a *Archive) Find(urlPath string, messages i18n.Messages) (Page, bool) {
+	for _, page := range a.Pages(messages) {
+		if page.URLPath == urlPath {
+			return page, true
+		}
+	}
+	return Page{}, false
+}
+
+// title names an archive page, after the folder unless it's the site root
+func (a *Archive) title(name string) string {
+	if a.Folder.Parent == nil || a.Folder.Name == "" {
+		return name
+	}
+	return a.Folder.Name + " – " + name
+}
+
+// link returns an escaped, base-prefixed href for a URL path
+func (a *Archive) link(urlPath string) string {
+	return template.HTMLEscapeString(tree.PrefixURL(a.baseURL, urlPath))
+}
+
+func (a *Archive) indexPage(messages i18n.Messages) Page {
+	title := a.title(messages.T("archive"))
+	var sb strings.Builder
+	writeHeader(&sb, title)
+	for _, year := range a.Years {
+		sb.WriteString(`<section class="archive-year">`)
+		sb.WriteString("\n")
+		sb.WriteString(`<h2><a href="` + a.link(year.URLPath) + `">` + strconv.Itoa(year.Year) + `</a></h2>`)
+		sb.WriteString("\n")
+		for _, month := range year.Months {
+			sb.WriteString(`<h3><a href="` + a.link(month.URLPath) + `">`)
+			sb.WriteString(template.HTMLEscapeString(messages.Month(month.Month)))
+			sb.WriteString(`</a></h3>`)
+			sb.WriteString("\n")
+			writeEntries(&sb, month.Entries)
+		}
+		sb.WriteString(`</section>`)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(`</article>`)
+	return Page{URLPath: a.URLPath(), Title: title, Content: template.HTML(sb.String())}
+}
+
+func (a *Archive) yearPage(year Year, messages i18n.Messages) Page {
+	title := a.title(strconv.Itoa(year.Year))
+	var sb strings.Builder
+	writeHeader(&sb, title)
+	for _, month := range year.Months {
+		sb.WriteString(`<h2><a href="` + a.link(month.URLPath) + `">`)
+		sb.WriteString(template.HTMLEscapeString(messages.Month(month.Month)))
+		sb.WriteString(`</a></h2>`)
+		sb.WriteString("\n")
+		writeEntries(&sb, month.Entries)
+	}
+	a.writeFooter(&sb, messages)
+	return Page{URLPath: year.URLPath, Title: title, Content: template.HTML(sb.String())}
+}
+
+func (a *Archive) monthPage(year Year, month Month, messages i18n.Messages) Page {
+	title := a.title(messages.MonthYear(year.Year, month.Month))
+	var sb strings.Builder
+	writeHeader(&sb, title)
+	writeEntries(&sb, month.Entries)
+	a.writeFooter(&sb, messages)
+	return Page{URLPath: month.URLPath, Title: title, Content: template.HTML(sb.String())}
+}
+
+// writeHeader opens an archive page
+func writeHeader(sb *strings.Builder, title string) {
+	sb.WriteString(`<article class="auto-index-page archive-page">`)
+	sb.WriteString("\n")
+	sb.WriteString(`<h1>` + template.HTMLEscapeString(title) + `</h1>`)
+	sb.WriteString("\n")
+}
+
+// writeFooter links a year or month page back to the archive and closes it
+func (a *Archive) writeFooter(sb *strings.Builder, messages i18n.Messages) {
+	sb.WriteString(`<p class="archive-all"><a href="` + a.link(a.URLPath()) + `">`)
+	sb.WriteString(template.HTMLEscapeString(a.title(messages.T("archive"))))
+	sb.WriteString(`</a></p>`)
+	sb.WriteString("\n")
+	sb.WriteString(`</article>`)
+}
+
+// writeEntries writes a list of dated pages
+func writeEntries(sb *strings.Builder, entries []Entry) {
+	sb.WriteString(`<ul class="archive-entries">`)
+	sb.WriteString("\n")
+	for _, entry := range entries {
+		date := entry.Date.Format("2006-01-02")
+		sb.WriteString(`<li><time datetime="` + date + `">` + date + `</time> `)
+		sb.WriteString(`<a href="` + template.HTMLEscapeString(entry.URL) + `">`)
+		sb.WriteString(template.HTMLEscapeString(entry.Title))
+		sb.WriteString(`</a></li>`)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(`</ul>`)
+	sb.WriteString("\n")
+}
@@ -0,0 +1,176 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+func scanSite(t *testing.T, files map[string]string) *tree.Site {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	site, err := tree.Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return site
+}
+
+func blogSite(t *testing.T) *tree.Site {
+	return scanSite(t, map[string]string{
+		"index.md":                      "# Home",
+		"about.md":                      "# About",
+		"blog/index.md":                 "# Blog",
+		"blog/2024-03-15-spring.md":     "# Spring",
+		"blog/2024-03-02-march.md":      "# Early March",
+		"blog/2024-01-10-new-year.md":   "# New Year",
+		"blog/2023-12-01-winter.md":     "# Winter",
+		"blog/hidden/2023-12-24-eve.md": "---\nnav: false\n---\n# Eve",
+		"blog/notes/dated.md":           "---\ndate: 2022-06-30\n---\n# Dated",
+		"blog/notes/undated.md":         "# Undated",
+	})
+}
+
+func folderAt(t *testing.T, site *tree.Site, path string) *tree.Node {
+	t.Helper()
+	for _, child := range site.Root.Children {
+		if child.IsFolder && child.Path == path {
+			return child
+		}
+	}
+	t.Fatalf("no folder %s", path)
+	return nil
+}
+
+func TestCollect(t *testing.T) {
+	site := blogSite(t)
+	folders := Collect(site.Root, func(folder *tree.Node) bool {
+		return strings.HasPrefix(folder.Path, "blog")
+	})
+	if len(folders) != 1 || folders[0].Path != "blog" {
+		t.Errorf("Collect() = %v, want only the topmost blog folder", folders)
+	}
+	if folders := Collect(site.Root, func(*tree.Node) bool { return true }); len(folders) != 1 || folders[0] != site.Root {
+		t.Errorf("Collect() = %v, want the root", folders)
+	}
+	if Collect(nil, func(*tree.Node) bool { return true }) != nil {
+		t.Error("Collect(nil) should return nil")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	site := blogSite(t)
+	a := Build(folderAt(t, site, "blog"), site.AllPages, "https://example.com/docs/")
+
+	var got []string
+	for _, year := range a.Years {
+		for _, month := range year.Months {
+			var titles []string
+			for _, entry := range month.Entries {
+				titles = append(titles, entry.Title)
+			}
+			got = append(got, month.URLPath+" "+strings.Join(titles, ", "))
+		}
+	}
+	want := []string{
+		"/blog/2024/03/ Spring, Early March",
+		"/blog/2024/01/ New Year",
+		"/blog/2023/12/ Winter",
+		"/blog/2022/06/ Dated",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Build() months =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if a.Years[0].URLPath != "/blog/2024/" || a.URLPath() != "/blog/archive/" {
+		t.Errorf("URL paths = %q, %q", a.Years[0].URLPath, a.URLPath())
+	}
+	if entry := a.Years[0].Months[0].Entries[0]; entry.URL != "/docs/blog/spring/" || entry.Date.Format("2006-01-02") != "2024-03-15" {
+		t.Errorf("entry = %+v", entry)
+	}
+
+	for path, want := range map[string]string{
+		"blog/2024-03-15-spring.md":     "/docs/blog/2024/03/",
+		"blog/notes/dated.md":           "/docs/blog/2022/06/",
+		"blog/notes/undated.md":         "",
+		"blog/hidden/2023-12-24-eve.md": "",
+		"blog/index.md":                 "",
+	} {
+		if got := a.MonthURL(&tree.Node{Path: path}); got != want {
+			t.Errorf("MonthURL(%s) = %q, want %q", path, got, want)
+		}
+	}
+
+	undated := scanSite(t, map[string]string{"index.md": "# Home", "about.md": "# About"})
+	if empty := Build(undated.Root, undated.AllPages, ""); !empty.Empty() || empty.Pages(i18n.English) != nil {
+		t.Error("an archive without dated pages should be empty")
+	}
+}
+
+func TestPages(t *testing.T) {
+	site := blogSite(t)
+	a := Build(site.Root, site.AllPages, "")
+
+	var urls []string
+	for _, page := range a.Pages(i18n.English) {
+		urls = append(urls, page.URLPath)
+	}
+	want := "/archive/ /2024/ /2024/03/ /2024/01/ /2023/ /2023/12/ /2022/ /2022/06/"
+	if strings.Join(urls, " ") != want {
+		t.Errorf("Pages() = %v, want %s", urls, want)
+	}
+
+	index, ok := a.Find("/archive/", i18n.English)
+	if !ok || index.Title != "Archive" {
+		t.Fatalf("Find(/archive/) = %+v, %v", index, ok)
+	}
+	for _, want := range []string{
+		`<h2><a href="/2024/">2024</a></h2>`,
+		`<h3><a href="/2024/03/">March</a></h3>`,
+		`<li><time datetime="2024-03-15">2024-03-15</time> <a href="/blog/spring/">Spring</a></li>`,
+	} {
+		if !strings.Contains(string(index.Content), want) {
+			t.Errorf("archive index missing %s", want)
+		}
+	}
+
+	year, _ := a.Find("/2024/", i18n.English)
+	if year.Title != "2024" || !strings.Contains(string(year.Content), `<h2><a href="/2024/01/">January</a></h2>`) || strings.Contains(string(year.Content), "Winter") {
+		t.Errorf("year page = %+v", year)
+	}
+	month, _ := a.Find("/2024/03/", i18n.Load("de", nil))
+	if month.Title != "März 2024" || !strings.Contains(string(month.Content), `<p class="archive-all"><a href="/archive/">Archiv</a></p>`) {
+		t.Errorf("month page = %+v", month)
+	}
+	if _, ok := a.Find("/2021/", i18n.English); ok {
+		t.Error("Find() should not match years without pages")
+	}
+}
+
+func TestFolderTitles(t *testing.T) {
+	site := blogSite(t)
+	a := Build(folderAt(t, site, "blog"), site.AllPages, "/docs")
+	page, ok := a.Find("/blog/2023/12/", i18n.English)
+	if !ok || page.Title != "Blog – December 2023" {
+		t.Errorf("Find(/blog/2023/12/) = %q, %v", page.Title, ok)
+	}
+	if !strings.Contains(string(page.Content), `<a href="/docs/blog/archive/">Blog – Archive</a>`) {
+		t.Errorf("month page should link to the folder's archive:\n%s", page.Content)
+	}
+	if got := a.MonthURLPath(2024, time.February); got != "/blog/2024/02/" {
+		t.Errorf("MonthURLPath() = %q", got)
+	}
+}
@@ -0,0 +1,308 @@
+package assets
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/wusher/volcano/internal/config"
+)
+
+// fontRole is one typographic role a self-hosted font can fill
+type fontRole struct {
+	name     string // "body", "headings" or "code"
+	family   string // Default CSS family name
+	fallback string // Default fallback stack
+}
+
+var fontRoles = []fontRole{
+	{"body", "Volcano Body", `system-ui, -apple-system, "Segoe UI", Roboto, sans-serif`},
+	{"headings", "Volcano Headings", `var(--font-body, system-ui, sans-serif)`},
+	{"code", "Volcano Code", `ui-monospace, "SF Mono", Menlo, Consolas, monospace`},
+}
+
+// fontFormats maps supported font extensions to their @font-face format and MIME type
+var fontFormats = map[string]struct{ format, mime string }{
+	".woff2": {"woff2", "font/woff2"},
+	".woff":  {"woff", "font/woff"},
+	".ttf":   {"truetype", "font/ttf"},
+}
+
+// fontNameRegex matches characters not allowed in a hashed font filename
+var fontNameRegex = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// FontFile is a font file copied into the assets directory under a hashed name
+type FontFile struct {
+	FileName string // Hashed filename (e.g., "brand-regular.a1b2c3d4.woff2")
+	URLPath  string // URL path (e.g., "/assets/brand-regular.a1b2c3d4.woff2")
+	MimeType string // MIME type (e.g., "font/woff2")
+	Data     []byte // File content
+	name     string // Filename stem before hashing
+	ext      string // Extension without the dot
+}
+
+// Fonts holds the self-hosted fonts for a site: the files to publish, the
+// CSS that declares and applies them, and the files worth preloading.
+// A nil *Fonts means no fonts are configured.
+type Fonts struct {
+	Files    []FontFile // Every font file, in configuration order
+	CSS      string     // @font-face rules and font variable overrides
+	preloads []FontFile // One file per role, preloaded from <head>
+}
+
+// LoadFonts reads the configured font files and builds their CSS. Relative
+// paths are resolved against the working directory first, then inputDir.
+// Font URLs are prefixed with baseURL. Returns nil if no fonts are configured.
+func LoadFonts(cfg *config.FontsConfig, inputDir, baseURL string) (*Fonts, error) {
+	if cfg.FileCount() == 0 {
+		return nil, nil
+	}
+
+	fonts := &Fonts{}
+	byPath := map[string]FontFile{}
+	var faces, vars strings.Builder
+
+	for _, role := range fontRoles {
+		font := fontForRole(cfg, role.name)
+		if font == nil || len(font.Files) == 0 {
+			continue
+		}
+
+		family := strings.TrimSpace(strings.ReplaceAll(font.Family, `"`, ""))
+		if family == "" {
+			family = role.family
+		}
+		fallback := strings.TrimSpace(font.Fallback)
+		if fallback == "" {
+			fallback = role.fallback
+		}
+
+		// Files covering the same weight and style share one @font-face,
+		// listed in order so browsers pick the first format they support
+		type face struct {
+			weight, style string
+			src           []string
+		}
+		var roleFaces []*face
+		for _, file := range font.Files {
+			asset, format, err := loadFontFile(file.Path, inputDir, baseURL, byPath)
+			if err != nil {
+				return nil, fmt.Errorf("fonts.%s: %w", role.name, err)
+			}
+			if _, seen := byPath[file.Path]; !seen {
+				byPath[file.Path] = asset
+				fonts.Files = append(fonts.Files, asset)
+			}
+
+			weight := strings.TrimSpace(file.Weight)
+			if weight == "" {
+				weight = "400"
+			}
+			style := strings.TrimSpace(file.Style)
+			if style == "" {
+				style = "normal"
+			}
+			src := fmt.Sprintf(`url("%s") format("%s")`, asset.URLPath, format)
+
+			var target *face
+			for _, f := range roleFaces {
+				if f.weight == weight && f.style == style {
+					target = f
+					break
+				}
+			}
+			if target == nil {
+				target = &face{weight: weight, style: style}
+				roleFaces = append(roleFaces, target)
+				// Preload the first file of the role's first face: the one
+				// the page needs before anything else renders in this role
+				if len(roleFaces) == 1 {
+					fonts.addPreload(asset)
+				}
+			}
+			target.src = append(target.src, src)
+		}
+
+		for _, f := range roleFaces {
+			fmt.Fprintf(&faces, "@font-face {\n  font-family: \"%s\";\n  src: %s;\n  font-weight: %s;\n  font-style: %s;\n  font-display: swap;\n}\n\n",
+				family, strings.Join(f.src, ",\n       "), f.weight, f.style)
+		}
+		fmt.Fprintf(&vars, "  --font-%s: \"%s\", %s;\n", role.name, family, fallback)
+	}
+
+	fonts.CSS = faces.String() + fontVariableCSS(cfg, vars.String())
+	return fonts, nil
+}
+
+// fontForRole returns the configured font for a role
+func fontForRole(cfg *config.FontsConfig, role string) *config.FontConfig {
+	switch role {
+	case "body":
+		return cfg.Body
+	case "headings":
+		return cfg.Headings
+	default:
+		return cfg.Code
+	}
+}
+
+// fontVariableCSS declares the --font-body/--font-headings/--font-code
+// variables, points the themes' own font variables at them and applies them
+// directly, since not every theme routes its fonts through variables
+func fontVariableCSS(cfg *config.FontsConfig, vars string) string {
+	var sb strings.Builder
+	hasBody := cfg.Body != nil && len(cfg.Body.Files) > 0
+	hasHeadings := cfg.Headings != nil && len(cfg.Headings.Files) > 0
+	hasCode := cfg.Code != nil && len(cfg.Code.Files) > 0
+
+	sb.WriteString(":root {\n")
+	sb.WriteString(vars)
+	if hasBody {
+		sb.WriteString("  --font-sans: var(--font-body);\n  --font-serif: var(--font-body);\n  --font-dyslexic: var(--font-body);\n")
+		if !hasHeadings {
+			sb.WriteString("  --font-headings: var(--font-body);\n")
+		}
+	}
+	if hasCode {
+		sb.WriteString("  --font-mono: var(--font-code);\n")
+	}
+	sb.WriteString("}\n")
+
+	if hasBody {
+		sb.WriteString("\nbody {\n  font-family: var(--font-body);\n}\n")
+	}
+	if hasBody || hasHeadings {
+		sb.WriteString("\nh1, h2, h3, h4, h5, h6,\n.prose h1, .prose h2, .prose h3, .prose h4, .prose h5, .prose h6 {\n  font-family: var(--font-headings);\n}\n")
+	}
+	if hasCode {
+		sb.WriteString("\ncode, kbd, samp, pre,\n.prose code, .prose pre {\n  font-family: var(--font-code);\n}\n")
+	}
+	return sb.String()
+}
+
+// loadFontFile reads a font file and computes its hashed name and URL.
+// Files already loaded (shared between roles) are returned from byPath.
+func loadFontFile(path, inputDir, baseURL string, byPath map[string]FontFile) (FontFile, string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	kind, ok := fontFormats[ext]
+	if !ok {
+		return FontFile{}, "", fmt.Errorf("unsupported font format %q in %s (use woff2, woff or ttf)", ext, path)
+	}
+	if asset, ok := byPath[path]; ok {
+		return asset, kind.format, nil
+	}
+
+	data, err := os.ReadFile(resolveFontPath(path, inputDir))
+	if err != nil {
+		return FontFile{}, "", fmt.Errorf("failed to read font: %w", err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	name = strings.Trim(fontNameRegex.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if name == "" {
+		name = "font"
+	}
+	fileName := HashedFileName(name, ext[1:], string(data))
+
+	return FontFile{
+		FileName: fileName,
+		URLPath:  baseURL + "/assets/" + fileName,
+		MimeType: kind.mime,
+		Data:     data,
+		name:     name,
+		ext:      ext[1:],
+	}, kind.format, nil
+}
+
+// resolveFontPath returns path as given if it exists, otherwise relative to inputDir
+func resolveFontPath(path, inputDir string) string {
+	if filepath.IsAbs(path) || inputDir == "" {
+		return path
+	}
+	if fileExists(path) {
+		return path
+	}
+	if candidate := filepath.Join(inputDir, path); fileExists(candidate) {
+		return candidate
+	}
+	return path
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// addPreload records a file to preload, skipping duplicates
+func (f *Fonts) addPreload(file FontFile) {
+	for _, p := range f.preloads {
+		if p.URLPath == file.URLPath {
+			return
+		}
+	}
+	f.preloads = append(f.preloads, file)
+}
+
+// Write copies the font files into outputDir/assets under their hashed names
+func (f *Fonts) Write(outputDir, baseURL string) error {
+	if f == nil {
+		return nil
+	}
+	for _, file := range f.Files {
+		if _, err := WriteHashedAsset(outputDir, file.name, file.ext, string(file.Data), baseURL); err != nil {
+			return fmt.Errorf("failed to write font: %w", err)
+		}
+	}
+	return nil
+}
+
+// URLs returns the URL path of every font file
+func (f *Fonts) URLs() []string {
+	if f == nil {
+		return nil
+	}
+	urls := make([]string, 0, len(f.Files))
+	for _, file := range f.Files {
+		urls = append(urls, file.URLPath)
+	}
+	return urls
+}
+
+// Find returns the font file served at urlPath
+func (f *Fonts) Find(urlPath string) (FontFile, bool) {
+	if f == nil {
+		return FontFile{}, false
+	}
+	for _, file := range f.Files {
+		if file.URLPath == urlPath {
+			return file, true
+		}
+	}
+	return FontFile{}, false
+}
+
+// StyleSheet returns the fonts CSS, or "" if no fonts are configured
+func (f *Fonts) StyleSheet() string {
+	if f == nil {
+		return ""
+	}
+	return f.CSS
+}
+
+// RenderPreloadLinks generates <link rel="preload"> tags for the fonts each
+// role needs first. Font preloads must be CORS requests, hence crossorigin.
+func (f *Fonts) RenderPreloadLinks() template.HTML {
+	if f == nil || len(f.preloads) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for _, file := range f.preloads {
+		fmt.Fprintf(&sb, "<link rel=\"preload\" href=\"%s\" as=\"font\" type=\"%s\" crossorigin>\n",
+			template.HTMLEscapeString(file.URLPath), file.MimeType)
+	}
+	return template.HTML(sb.String())
+}
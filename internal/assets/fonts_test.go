@@ -0,0 +1,186 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wusher/volcano/internal/config"
+)
+
+func writeFontFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("font:"+name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadFonts_None(t *testing.T) {
+	fonts, err := LoadFonts(nil, "", "")
+	if err != nil || fonts != nil {
+		t.Fatalf("LoadFonts(nil) = %v, %v; want nil, nil", fonts, err)
+	}
+	// A nil *Fonts is safe to use
+	if fonts.StyleSheet() != "" || fonts.RenderPreloadLinks() != "" || fonts.URLs() != nil {
+		t.Error("nil Fonts should produce no CSS, preloads or URLs")
+	}
+	if _, ok := fonts.Find("/assets/x.woff2"); ok {
+		t.Error("nil Fonts should find nothing")
+	}
+	if err := fonts.Write(t.TempDir(), ""); err != nil {
+		t.Errorf("nil Fonts Write() error = %v", err)
+	}
+}
+
+func TestLoadFonts(t *testing.T) {
+	dir := t.TempDir()
+	writeFontFiles(t, dir, "Brand Sans-Regular.woff2", "Brand Sans-Regular.woff", "BrandSans-Bold.woff2", "Mono.ttf")
+
+	cfg := &config.FontsConfig{
+		Body: &config.FontConfig{
+			Family: "Brand Sans",
+			Files: []config.FontFile{
+				{Path: filepath.Join(dir, "Brand Sans-Regular.woff2")},
+				{Path: filepath.Join(dir, "Brand Sans-Regular.woff")},
+				{Path: filepath.Join(dir, "BrandSans-Bold.woff2"), Weight: "700"},
+			},
+		},
+		Code: &config.FontConfig{
+			Files: []config.FontFile{{Path: filepath.Join(dir, "Mono.ttf")}},
+		},
+	}
+
+	fonts, err := LoadFonts(cfg, "", "/docs")
+	if err != nil {
+		t.Fatalf("LoadFonts() error = %v", err)
+	}
+
+	if len(fonts.Files) != 4 {
+		t.Fatalf("expected 4 font files, got %d", len(fonts.Files))
+	}
+	regular := fonts.Files[0]
+	if !strings.HasPrefix(regular.FileName, "brand-sans-regular.") || !strings.HasSuffix(regular.FileName, ".woff2") {
+		t.Errorf("unexpected hashed name %q", regular.FileName)
+	}
+	if regular.URLPath != "/docs/assets/"+regular.FileName {
+		t.Errorf("URLPath = %q, want base URL prefix", regular.URLPath)
+	}
+	if regular.MimeType != "font/woff2" {
+		t.Errorf("MimeType = %q", regular.MimeType)
+	}
+
+	css := fonts.CSS
+	// Regular woff2 + woff share one @font-face; bold gets its own
+	if got := strings.Count(css, "@font-face"); got != 3 {
+		t.Errorf("expected 3 @font-face rules, got %d:\n%s", got, css)
+	}
+	for _, want := range []string{
+		`font-family: "Brand Sans";`,
+		`format("woff2"),`,
+		`format("woff")`,
+		`format("truetype")`,
+		"font-weight: 700;",
+		"font-display: swap;",
+		`--font-body: "Brand Sans", system-ui`,
+		`--font-code: "Volcano Code", ui-monospace`,
+		"--font-headings: var(--font-body);",
+		"--font-mono: var(--font-code);",
+		"font-family: var(--font-headings);",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("CSS missing %q:\n%s", want, css)
+		}
+	}
+
+	// One preload per role: the first file of the first face
+	links := string(fonts.RenderPreloadLinks())
+	if strings.Count(links, `rel="preload"`) != 2 {
+		t.Errorf("expected 2 preload links, got:\n%s", links)
+	}
+	if !strings.Contains(links, `href="`+regular.URLPath+`" as="font" type="font/woff2" crossorigin`) {
+		t.Errorf("preload links missing regular body font:\n%s", links)
+	}
+
+	if got := fonts.URLs(); len(got) != 4 || got[0] != regular.URLPath {
+		t.Errorf("URLs() = %v", got)
+	}
+	if found, ok := fonts.Find(regular.URLPath); !ok || string(found.Data) != "font:Brand Sans-Regular.woff2" {
+		t.Error("Find() should return the regular font")
+	}
+
+	out := t.TempDir()
+	if err := fonts.Write(out, "/docs"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	for _, file := range fonts.Files {
+		if _, err := os.Stat(filepath.Join(out, "assets", file.FileName)); err != nil {
+			t.Errorf("font not written: %v", err)
+		}
+	}
+}
+
+func TestLoadFonts_ResolvesAgainstInputDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFontFiles(t, dir, "fonts/heading.woff2")
+
+	cfg := &config.FontsConfig{
+		Headings: &config.FontConfig{Files: []config.FontFile{{Path: "fonts/heading.woff2"}}},
+	}
+	fonts, err := LoadFonts(cfg, dir, "")
+	if err != nil {
+		t.Fatalf("LoadFonts() error = %v", err)
+	}
+	if !strings.Contains(fonts.CSS, `--font-headings: "Volcano Headings"`) {
+		t.Errorf("CSS missing headings variable:\n%s", fonts.CSS)
+	}
+	if strings.Contains(fonts.CSS, "body {") {
+		t.Error("headings-only config should not restyle body text")
+	}
+}
+
+func TestLoadFonts_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writeFontFiles(t, dir, "font.otf")
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"unsupported format", filepath.Join(dir, "font.otf"), "unsupported font format"},
+		{"missing file", filepath.Join(dir, "missing.woff2"), "failed to read font"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.FontsConfig{Code: &config.FontConfig{Files: []config.FontFile{{Path: tt.path}}}}
+			_, err := LoadFonts(cfg, "", "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.Contains(err.Error(), "fonts.code") {
+				t.Errorf("LoadFonts() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashedFileName(t *testing.T) {
+	a := HashedFileName("styles", "css", "body{}")
+	if a != HashedFileName("styles", "css", "body{}") {
+		t.Error("HashedFileName should be deterministic")
+	}
+	if a == HashedFileName("styles", "css", "p{}") {
+		t.Error("HashedFileName should change with content")
+	}
+	asset, err := WriteHashedAsset(t.TempDir(), "styles", "css", "body{}", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asset.FileName != a {
+		t.Errorf("WriteHashedAsset name %q != HashedFileName %q", asset.FileName, a)
+	}
+}
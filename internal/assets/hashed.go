@@ -16,16 +16,22 @@ type HashedAsset struct {
 	Content  string // The raw content
 }
 
-// WriteHashedAsset writes content to a file with a content-based hash in the filename.
-// Returns the hashed filename and URL path.
+// HashedFileName returns the content-hashed filename WriteHashedAsset uses
+// for content, so callers can reference an asset before it is written.
 // Pattern: {name}.{hash}.{ext} (e.g., "styles.a1b2c3d4.css")
-func WriteHashedAsset(outputDir, name, ext, content, baseURL string) (*HashedAsset, error) {
+func HashedFileName(name, ext, content string) string {
 	// Compute SHA256 hash and take first 8 characters
 	hash := sha256.Sum256([]byte(content))
 	hashStr := hex.EncodeToString(hash[:])[:8]
+	return fmt.Sprintf("%s.%s.%s", name, hashStr, ext)
+}
 
+// WriteHashedAsset writes content to a file with a content-based hash in the filename.
+// Returns the hashed filename and URL path.
+// Pattern: {name}.{hash}.{ext} (e.g., "styles.a1b2c3d4.css")
+func WriteHashedAsset(outputDir, name, ext, content, baseURL string) (*HashedAsset, error) {
 	// Build filename and paths
-	fileName := fmt.Sprintf("%s.%s.%s", name, hashStr, ext)
+	fileName := HashedFileName(name, ext, content)
 	assetsDir := filepath.Join(outputDir, "assets")
 	filePath := filepath.Join(assetsDir, fileName)
 
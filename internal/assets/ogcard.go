@@ -0,0 +1,249 @@
+package assets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	accent "github.com/wusher/volcano/internal/color"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// OG card size, the 1.91:1 image Open Graph and Twitter cards show largest
+const (
+	OGCardWidth  = 1200
+	OGCardHeight = 630
+)
+
+// ogCardVersion is hashed into card file names, so changing how cards are
+// drawn redraws cached ones
+const ogCardVersion = "1"
+
+// Card colors and layout. The accent is lightened to read on the background.
+const (
+	ogCardBackground = "#111827"
+	ogCardText       = "#f9fafb"
+	ogCardMuted      = "#9ca3af"
+	ogCardAccent     = "#0ea5e9" // Used when no accent is set (Tailwind sky-500)
+	ogCardPadding    = 80
+	ogCardBar        = 16 // Height of the accent bar along the top
+	ogCardTitleLines = 3
+)
+
+// ogCardTitleSizes are the title font sizes tried in turn until the title
+// fits in ogCardTitleLines lines
+var ogCardTitleSizes = []float64{76, 64, 54}
+
+// OGCard holds what a generated Open Graph image shows
+type OGCard struct {
+	Title     string // Page title
+	Section   string // Display name of the top-level folder the page is in
+	SiteTitle string // Site title
+	Accent    string // Accent color or gradient, as color.ResolveAccentSpec takes it
+}
+
+// FileName returns the card's path in the output directory,
+// og/<hash>.png, with a hash of everything the card shows
+func (c OGCard) FileName() string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{ogCardVersion, c.Title, c.Section, c.SiteTitle, c.Accent}, "\x00")))
+	return "og/" + hex.EncodeToString(hash[:])[:16] + ".png"
+}
+
+// WriteOGCard writes the card's PNG to the output directory, unless a
+// previous build already did, and returns its URL
+func WriteOGCard(card OGCard, outputDir, baseURL string) (string, error) {
+	name := card.FileName()
+	path := filepath.Join(outputDir, filepath.FromSlash(name))
+	if _, err := os.Stat(path); err != nil {
+		data, err := RenderOGCard(card)
+		if err != nil {
+			return "", err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", fmt.Errorf("failed to create og directory: %w", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return "", fmt.Errorf("failed to write og image: %w", err)
+		}
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/" + name, nil
+}
+
+// ogFonts parses the bold and regular Go fonts once
+var ogFonts = sync.OnceValues(func() ([2]*opentype.Font, error) {
+	var fonts [2]*opentype.Font
+	for i, ttf := range [][]byte{gobold.TTF, goregular.TTF} {
+		f, err := opentype.Parse(ttf)
+		if err != nil {
+			return fonts, err
+		}
+		fonts[i] = f
+	}
+	return fonts, nil
+})
+
+// RenderOGCard draws the card as a PNG: the accent bar along the top, the
+// section in the accent color, the title in large bold type below it and
+// the site title at the bottom
+func RenderOGCard(card OGCard) ([]byte, error) {
+	start, end, err := accent.ResolveAccentSpec(card.Accent)
+	if err != nil {
+		return nil, err
+	}
+	if start == "" {
+		start = ogCardAccent
+	}
+	if end == "" {
+		end = start
+	}
+	fonts, err := ogFonts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load og image fonts: %w", err)
+	}
+	bold, regular := fonts[0], fonts[1]
+
+	img := image.NewRGBA(image.Rect(0, 0, OGCardWidth, OGCardHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(hexColor(ogCardBackground)), image.Point{}, draw.Src)
+
+	// Accent bar, blending from the start color to the end color
+	from, to := hexColor(start), hexColor(end)
+	for x := 0; x < OGCardWidth; x++ {
+		c := blend(from, to, float64(x)/float64(OGCardWidth-1))
+		draw.Draw(img, image.Rect(x, 0, x+1, ogCardBar), image.NewUniform(c), image.Point{}, draw.Src)
+	}
+
+	width := OGCardWidth - 2*ogCardPadding
+	y := ogCardPadding + ogCardBar // Top of the text, moved down as lines are drawn
+	if card.Section != "" {
+		y += 40
+		label, err := accent.EnsureContrast(start, ogCardBackground, accent.MinDarkContrast)
+		if err != nil {
+			return nil, err
+		}
+		face, err := opentype.NewFace(bold, &opentype.FaceOptions{Size: 32, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, err
+		}
+		drawLine(img, face, hexColor(label), fitLine(face, card.Section, width), y)
+		y += 28
+	}
+
+	// The title takes the largest size it fits at, and is cut short at the
+	// smallest
+	var face font.Face
+	var lines []string
+	var size float64
+	for _, size = range ogCardTitleSizes {
+		if face, err = opentype.NewFace(bold, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull}); err != nil {
+			return nil, err
+		}
+		if lines = wrapText(face, card.Title, width); len(lines) <= ogCardTitleLines {
+			break
+		}
+	}
+	if len(lines) > ogCardTitleLines {
+		lines = lines[:ogCardTitleLines]
+		lines[ogCardTitleLines-1] = fitLine(face, lines[ogCardTitleLines-1]+" …", width)
+	}
+	y += int(size)
+	for _, line := range lines {
+		drawLine(img, face, hexColor(ogCardText), line, y)
+		y += int(size * 1.2)
+	}
+
+	if card.SiteTitle != "" {
+		face, err := opentype.NewFace(regular, &opentype.FaceOptions{Size: 32, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, err
+		}
+		drawLine(img, face, hexColor(ogCardMuted), fitLine(face, card.SiteTitle, width), OGCardHeight-ogCardPadding)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode og image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// wrapText splits text into lines no wider than width, breaking between
+// words. A word wider than a line is cut to fit.
+func wrapText(face font.Face, text string, width int) []string {
+	limit := fixed.I(width)
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if font.MeasureString(face, candidate) <= limit {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = fitLine(face, word, width)
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fitLine returns text, cut and ended with an ellipsis if it is wider
+// than width
+func fitLine(face font.Face, text string, width int) string {
+	limit := fixed.I(width)
+	if font.MeasureString(face, text) <= limit {
+		return text
+	}
+	runes := []rune(strings.TrimSuffix(text, " …"))
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		cut := strings.TrimRight(string(runes), " ") + "…"
+		if font.MeasureString(face, cut) <= limit {
+			return cut
+		}
+	}
+	return "…"
+}
+
+// drawLine draws a line of text with its baseline at y
+func drawLine(img *image.RGBA, face font.Face, c color.Color, text string, y int) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(ogCardPadding, y),
+	}
+	d.DrawString(text)
+}
+
+// hexColor converts a validated hex color to a color.Color
+func hexColor(hex string) color.RGBA {
+	r, g, b, _ := accent.ParseHex(hex)
+	return color.RGBA{R: r, G: g, B: b, A: 255}
+}
+
+// blend mixes two colors, t of the way from a to b
+func blend(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5)
+	}
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 255}
+}
@@ -0,0 +1,131 @@
+package assets
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestRenderOGCard(t *testing.T) {
+	cards := []OGCard{
+		{Title: "Consumer Groups", Section: "Guides", SiteTitle: "Docs", Accent: "lime-sky"},
+		{Title: "Home", Accent: "#1e3a8a"},
+		{Title: strings.Repeat("A very long title that will not fit ", 10)},
+	}
+	for _, card := range cards {
+		data, err := RenderOGCard(card)
+		if err != nil {
+			t.Fatalf("RenderOGCard(%q) error = %v", card.Title, err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("RenderOGCard(%q) is not a PNG: %v", card.Title, err)
+		}
+		if b := img.Bounds(); b.Dx() != OGCardWidth || b.Dy() != OGCardHeight {
+			t.Errorf("RenderOGCard(%q) size = %dx%d, want %dx%d", card.Title, b.Dx(), b.Dy(), OGCardWidth, OGCardHeight)
+		}
+	}
+
+	if _, err := RenderOGCard(OGCard{Title: "Bad", Accent: "not-a-color"}); err == nil {
+		t.Error("RenderOGCard() with an invalid accent should fail")
+	}
+}
+
+func TestOGCardFileName(t *testing.T) {
+	card := OGCard{Title: "Kafka", Section: "Guides", SiteTitle: "Docs", Accent: "sky"}
+	name := card.FileName()
+	if !strings.HasPrefix(name, "og/") || !strings.HasSuffix(name, ".png") {
+		t.Errorf("FileName() = %q, want og/<hash>.png", name)
+	}
+	if card.FileName() != name {
+		t.Error("FileName() should be stable")
+	}
+
+	changed := []OGCard{
+		{Title: "Kafka 2", Section: "Guides", SiteTitle: "Docs", Accent: "sky"},
+		{Title: "Kafka", Section: "Books", SiteTitle: "Docs", Accent: "sky"},
+		{Title: "Kafka", Section: "Guides", SiteTitle: "Site", Accent: "sky"},
+		{Title: "Kafka", Section: "Guides", SiteTitle: "Docs", Accent: "lime"},
+	}
+	for _, c := range changed {
+		if c.FileName() == name {
+			t.Errorf("FileName() of %+v should differ from %+v", c, card)
+		}
+	}
+}
+
+func TestWriteOGCard(t *testing.T) {
+	outputDir := t.TempDir()
+	card := OGCard{Title: "Kafka", SiteTitle: "Docs"}
+
+	url, err := WriteOGCard(card, outputDir, "https://example.com/docs/")
+	if err != nil {
+		t.Fatalf("WriteOGCard() error = %v", err)
+	}
+	if want := "https://example.com/docs/" + card.FileName(); url != want {
+		t.Errorf("WriteOGCard() = %q, want %q", url, want)
+	}
+	path := filepath.Join(outputDir, filepath.FromSlash(card.FileName()))
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("card not written: %v", err)
+	}
+
+	// A card already written is reused, not drawn again
+	if err := os.WriteFile(path, []byte("cached"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WriteOGCard(card, outputDir, ""); err != nil {
+		t.Fatalf("WriteOGCard() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "cached" {
+		t.Error("WriteOGCard() should reuse a cached card")
+	}
+
+	if url, _ := WriteOGCard(card, outputDir, ""); url != "/"+card.FileName() {
+		t.Errorf("WriteOGCard() without a base URL = %q, want %q", url, "/"+card.FileName())
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	fonts, err := ogFonts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	face, err := opentype.NewFace(fonts[0], &opentype.FaceOptions{Size: 40, DPI: 72})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := wrapText(face, "one two three four five six seven eight nine ten", 300)
+	if len(lines) < 2 {
+		t.Fatalf("wrapText() = %q, want several lines", lines)
+	}
+	if strings.Join(lines, " ") != "one two three four five six seven eight nine ten" {
+		t.Errorf("wrapText() = %q, lost or reordered words", lines)
+	}
+	for _, line := range lines {
+		if font.MeasureString(face, line) > fixed.I(300) {
+			t.Errorf("line %q is wider than 300", line)
+		}
+	}
+
+	// Words wider than a line are cut
+	lines = wrapText(face, strings.Repeat("x", 100), 300)
+	if len(lines) != 1 || !strings.HasSuffix(lines[0], "…") {
+		t.Errorf("wrapText() of a long word = %q, want one line ending in …", lines)
+	}
+
+	if got := fitLine(face, "short", 300); got != "short" {
+		t.Errorf("fitLine() = %q, want %q", got, "short")
+	}
+	if got := fitLine(face, "wide", 1); got != "…" {
+		t.Errorf("fitLine() with no room = %q, want %q", got, "…")
+	}
+}
@@ -57,11 +57,14 @@ func Build(node *tree.Node) Index {
 	return BuildWithBaseURL(node, "")
 }
 
-// BuildWithBaseURL creates an Index for a folder with base URL prefixing
+// BuildWithBaseURL creates an Index for a folder with base URL prefixing.
+// Items leave out pages hidden from navigation. They follow the sidebar
+// order when the folder sets one (`_folder.yml`, index front matter or page
+// weights); otherwise files come first, then folders, alphabetically.
 func BuildWithBaseURL(node *tree.Node, baseURL string) Index {
 	var items []Item
 
-	for _, child := range node.Children {
+	for _, child := range node.NavChildren() {
 		url := tree.GetURLPath(child)
 		// For folders, construct the URL from the path
 		if child.IsFolder {
@@ -76,14 +79,14 @@ func BuildWithBaseURL(node *tree.Node, baseURL string) Index {
 		})
 	}
 
-	// Sort: files first, then folders, then alphabetically
-	// (matches the tree navigation sort order)
-	sort.Slice(items, func(i, j int) bool {
-		if items[i].IsFolder != items[j].IsFolder {
-			return !items[i].IsFolder // files first
-		}
-		return strings.ToLower(items[i].Title) < strings.ToLower(items[j].Title)
-	})
+	if !hasNavOrder(node) {
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].IsFolder != items[j].IsFolder {
+				return !items[i].IsFolder // files first
+			}
+			return strings.ToLower(items[i].Title) < strings.ToLower(items[j].Title)
+		})
+	}
 
 	slugPath := tree.SlugifyPath(node.Path)
 	urlPath := "/" + slugPath + "/"
@@ -162,3 +165,17 @@ func CollectFoldersNeedingAutoIndex(node *tree.Node) []*tree.Node {
 
 	return folders
 }
+
+// hasNavOrder reports whether a folder orders its children itself, with
+// folder settings or page weights
+func hasNavOrder(node *tree.Node) bool {
+	if node.NavOrder != nil {
+		return true
+	}
+	for _, child := range node.Children {
+		if child.Weight != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -181,3 +181,57 @@ func TestCollectFoldersNeedingAutoIndex(t *testing.T) {
 		t.Errorf("expected WithoutIndex folder, got %s", folders[0].Name)
 	}
 }
+
+func TestBuildFollowsNavigation(t *testing.T) {
+	folder := &tree.Node{
+		Name:     "Guides",
+		Path:     "guides",
+		IsFolder: true,
+		NavOrder: &tree.NavOrder{Sort: tree.SortManual, Order: []string{"zebra"}},
+		Children: []*tree.Node{
+			{Name: "Zebra", Path: "guides/zebra.md"},
+			{Name: "Secret", Path: "guides/secret.md", Hidden: true},
+			{Name: "Apple", Path: "guides/apple.md"},
+		},
+	}
+
+	index := Build(folder)
+	if len(index.Children) != 2 {
+		t.Fatalf("expected hidden pages left out, got %d children", len(index.Children))
+	}
+	if index.Children[0].Title != "Zebra" || index.Children[1].Title != "Apple" {
+		t.Errorf("children = %v, want the folder's navigation order", index.Children)
+	}
+}
+
+func TestBuildDefaultOrder(t *testing.T) {
+	weight := 1
+	children := func() []*tree.Node {
+		return []*tree.Node{
+			{Name: "Zoo", Path: "guides/zoo", IsFolder: true},
+			{Name: "zebra", Path: "guides/zebra.md"},
+			{Name: "Apple", Path: "guides/apple.md"},
+		}
+	}
+
+	// Without ordering settings: files first, then folders, alphabetically
+	folder := &tree.Node{Name: "Guides", Path: "guides", IsFolder: true, Children: children()}
+	var got []string
+	for _, item := range Build(folder).Children {
+		got = append(got, item.Title)
+	}
+	if strings.Join(got, ",") != "Apple,zebra,Zoo" {
+		t.Errorf("children = %v, want files first, then folders, alphabetically", got)
+	}
+
+	// A weighted page means the folder's own order is kept
+	folder.Children = children()
+	folder.Children[0].Weight = &weight
+	got = nil
+	for _, item := range Build(folder).Children {
+		got = append(got, item.Title)
+	}
+	if strings.Join(got, ",") != "Zoo,zebra,Apple" {
+		t.Errorf("children = %v, want the folder's navigation order", got)
+	}
+}
@@ -0,0 +1,339 @@
+// Package book assembles a folder's pages into one printable document.
+package book
+
+import (
+	"fmt"
+	"html/template"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// SiteURLPath is where the whole-site book is published
+const SiteURLPath = "/print.html"
+
+// FolderSlug is the path segment of a folder's book (e.g. /guides/print/)
+const FolderSlug = "print"
+
+// RenderFunc renders a page to HTML, returning its title and content
+type RenderFunc func(node *tree.Node) (title, content string, err error)
+
+// Book is a folder's pages concatenated in sidebar order
+type Book struct {
+	Title    string        // Folder title (empty for the site root)
+	Content  template.HTML // Every chapter, with ids and links rewritten
+	TOC      template.HTML // Table of contents linking to each chapter
+	Chapters int           // Number of pages included
+}
+
+// Entry is one stop in a folder's reading order: a page, or a folder
+// without an index page, which opens a part of its own
+type Entry struct {
+	Page   *tree.Node // The page; nil for a folder without an index
+	Folder *tree.Node // The folder a part stands for; nil for pages
+	Depth  int        // Nesting depth below the outline's root
+	Slug   string     // URL path with slashes as dashes ("" for the home page)
+}
+
+// chapter is one page (or one folder without an index) in the book
+type chapter struct {
+	title   string
+	urlPath string // Page URL without base path; "" for folders without an index
+	content string // Rendered HTML; "" for folders without an index
+	depth   int    // Nesting depth below the book's root
+	slug    string // Base for the chapter's anchor
+	anchor  string // In-document id of the chapter
+	ids     map[string]string
+}
+
+var (
+	idAttrRegex   = regexp.MustCompile(`\sid="([^"]*)"`)
+	linkAttrRegex = regexp.MustCompile(`\s(href|src)="([^"]*)"`)
+	h1Regex       = regexp.MustCompile(`(?i)<h1[\s>]`)
+)
+
+// URLPath returns the URL path of the book for folder, or SiteURLPath for the
+// root. The root of a translation's tree (Path "fr") gets /fr/print.html.
+func URLPath(folder *tree.Node) string {
+	if folder == nil || folder.Parent == nil {
+		if folder != nil && folder.Path != "" {
+			return "/" + tree.SlugifyPath(folder.Path) + SiteURLPath
+		}
+		return SiteURLPath
+	}
+	return "/" + tree.SlugifyPath(folder.Path) + "/" + FolderSlug + "/"
+}
+
+// Folders returns every folder below root that gets its own book
+func Folders(root *tree.Node) []*tree.Node {
+	var folders []*tree.Node
+	var walk func(node *tree.Node)
+	walk = func(node *tree.Node) {
+		for _, child := range node.Children {
+			if child.IsFolder {
+				folders = append(folders, child)
+				walk(child)
+			}
+		}
+	}
+	walk(root)
+	return folders
+}
+
+// Outline lists the pages under folder (the site root or any folder) in
+// sidebar order. The folder's own index page comes first; in a folder's
+// outline the other pages nest below it.
+func Outline(folder *tree.Node, allPages []*tree.Node) []Entry {
+	byPath := make(map[string]*tree.Node, len(allPages))
+	for _, page := range allPages {
+		byPath[page.Path] = page
+	}
+
+	var entries []Entry
+	addPage := func(node *tree.Node, depth int) {
+		entries = append(entries, Entry{
+			Page:  node,
+			Depth: depth,
+			Slug:  strings.ReplaceAll(strings.Trim(tree.GetURLPath(node), "/"), "/", "-"),
+		})
+	}
+
+	var walk func(node *tree.Node, depth int)
+	walk = func(node *tree.Node, depth int) {
+		for _, child := range node.Children {
+			if !child.IsFolder {
+				addPage(child, depth)
+				continue
+			}
+			if index := byPath[child.IndexPath]; child.HasIndex && index != nil {
+				addPage(index, depth)
+			} else {
+				entries = append(entries, Entry{
+					Folder: child,
+					Depth:  depth,
+					Slug:   strings.ReplaceAll(tree.SlugifyPath(child.Path), "/", "-"),
+				})
+			}
+			walk(child, depth+1)
+		}
+	}
+
+	depth := 0
+	if index := byPath[folder.IndexPath]; folder.HasIndex && index != nil {
+		addPage(index, 0)
+		if folder.Parent != nil {
+			depth = 1
+		}
+	}
+	walk(folder, depth)
+	return entries
+}
+
+// Build renders the pages under folder (the site root or any folder) in
+// sidebar order and joins them into one document. Heading and footnote ids
+// are made unique across pages, and links between included pages become
+// in-document anchors. basePath is the site's URL path prefix (e.g. "/docs");
+// messages translate the table of contents.
+func Build(folder *tree.Node, allPages []*tree.Node, basePath string, messages i18n.Messages, render RenderFunc) (*Book, error) {
+	var chapters []*chapter
+	for _, entry := range Outline(folder, allPages) {
+		if entry.Page == nil {
+			chapters = append(chapters, &chapter{title: entry.Folder.Name, depth: entry.Depth, slug: entry.Slug})
+			continue
+		}
+		title, content, err := render(entry.Page)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Page.Path, err)
+		}
+		chapters = append(chapters, &chapter{
+			title:   title,
+			urlPath: tree.GetURLPath(entry.Page),
+			content: content,
+			depth:   entry.Depth,
+			slug:    entry.Slug,
+		})
+	}
+
+	// A folder's book takes its title from the folder's index page
+	b := &Book{}
+	if folder.Parent != nil {
+		b.Title = folder.Name
+		if len(chapters) > 0 && chapters[0].urlPath != "" && chapters[0].depth == 0 && folder.HasIndex {
+			b.Title = chapters[0].title
+		}
+	}
+
+	assignIDs(chapters)
+
+	byURL := make(map[string]*chapter, len(chapters))
+	for _, ch := range chapters {
+		if ch.urlPath != "" {
+			byURL[ch.urlPath] = ch
+		}
+	}
+
+	var sb strings.Builder
+	for _, ch := range chapters {
+		class := "print-chapter"
+		if ch.urlPath == "" {
+			class = "print-part"
+		} else {
+			b.Chapters++
+		}
+		fmt.Fprintf(&sb, "<section class=\"%s prose\" id=\"%s\" data-depth=\"%d\">\n", class, ch.anchor, ch.depth)
+		if ch.urlPath == "" || !h1Regex.MatchString(ch.content) {
+			fmt.Fprintf(&sb, "<h1>%s</h1>\n", template.HTMLEscapeString(ch.title))
+		}
+		sb.WriteString(rewriteLinks(ch, byURL, basePath))
+		sb.WriteString("\n</section>\n")
+	}
+	b.Content = template.HTML(sb.String())
+	b.TOC = renderTOC(chapters, messages)
+
+	return b, nil
+}
+
+// assignIDs gives each chapter an anchor and renames every id in its
+// content so no two elements in the book share one. The first page keeps
+// its ids; later duplicates get a numeric suffix (installation-2).
+func assignIDs(chapters []*chapter) {
+	used := make(map[string]bool)
+	unique := func(id string) string {
+		candidate := id
+		for n := 2; used[candidate]; n++ {
+			candidate = id + "-" + strconv.Itoa(n)
+		}
+		used[candidate] = true
+		return candidate
+	}
+
+	for _, ch := range chapters {
+		// Prefixed so a page's anchor doesn't take the id of its own headings
+		slug := ch.slug
+		if slug == "" {
+			slug = "home"
+		}
+		ch.anchor = unique("page-" + slug)
+
+		ch.ids = make(map[string]string)
+		ch.content = idAttrRegex.ReplaceAllStringFunc(ch.content, func(match string) string {
+			id := idAttrRegex.FindStringSubmatch(match)[1]
+			renamed, seen := ch.ids[id]
+			if !seen {
+				renamed = unique(id)
+				ch.ids[id] = renamed
+			}
+			return ` id="` + renamed + `"`
+		})
+	}
+}
+
+// rewriteLinks points links to pages in the book at their chapter anchors
+// and makes relative links absolute, since the book lives at a different URL
+// than the pages it includes
+func rewriteLinks(ch *chapter, byURL map[string]*chapter, basePath string) string {
+	return linkAttrRegex.ReplaceAllStringFunc(ch.content, func(match string) string {
+		parts := linkAttrRegex.FindStringSubmatch(match)
+		attr, value := parts[1], parts[2]
+
+		if strings.HasPrefix(value, "#") {
+			if attr == "href" {
+				if id, ok := ch.ids[value[1:]]; ok {
+					return ` href="#` + id + `"`
+				}
+			}
+			return match
+		}
+		if value == "" || strings.HasPrefix(value, "//") || strings.Contains(strings.SplitN(value, "/", 2)[0], ":") {
+			return match // External, mailto:, data: and the like
+		}
+
+		target, fragment := value, ""
+		if i := strings.Index(target, "#"); i >= 0 {
+			target, fragment = target[:i], target[i+1:]
+		}
+
+		var sitePath string
+		if strings.HasPrefix(target, "/") {
+			sitePath = target
+			if basePath != "" && (target == basePath || strings.HasPrefix(target, basePath+"/")) {
+				sitePath = strings.TrimPrefix(target, basePath)
+			}
+		} else {
+			sitePath = path.Join(ch.urlPath, target)
+			if strings.HasSuffix(target, "/") || target == "." || target == ".." {
+				sitePath += "/"
+			}
+		}
+		if sitePath == "" {
+			sitePath = "/"
+		}
+
+		if attr == "href" {
+			lookup := sitePath
+			if !strings.HasSuffix(lookup, "/") && path.Ext(lookup) == "" {
+				lookup += "/"
+			}
+			if dest, ok := byURL[lookup]; ok {
+				anchor := dest.anchor
+				if id, ok := dest.ids[fragment]; ok && fragment != "" {
+					anchor = id
+				}
+				return ` href="#` + anchor + `"`
+			}
+		}
+
+		if strings.HasPrefix(target, "/") {
+			return match
+		}
+		if fragment != "" {
+			sitePath += "#" + fragment
+		}
+		return " " + attr + `="` + basePath + sitePath + `"`
+	})
+}
+
+// renderTOC lists the chapters as nested lists following their depth
+func renderTOC(chapters []*chapter, messages i18n.Messages) template.HTML {
+	if len(chapters) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	label := template.HTMLEscapeString(messages.T("contents"))
+	fmt.Fprintf(&sb, "<nav class=\"print-toc\" aria-label=\"%s\">\n<h2>%s</h2>\n<ol>\n", label, label)
+	depth := 0
+	for i, ch := range chapters {
+		if i > 0 {
+			switch {
+			case ch.depth > depth:
+				// Open one level at a time so a jump of several levels stays valid
+				for ; depth < ch.depth; depth++ {
+					sb.WriteString("\n<ol>\n")
+				}
+			case ch.depth < depth:
+				for ; depth > ch.depth; depth-- {
+					sb.WriteString("</li>\n</ol>\n")
+				}
+				sb.WriteString("</li>\n")
+			default:
+				sb.WriteString("</li>\n")
+			}
+		} else {
+			for ; depth < ch.depth; depth++ {
+				sb.WriteString("<li>\n<ol>\n")
+			}
+		}
+		fmt.Fprintf(&sb, "<li><a href=\"#%s\">%s</a>", ch.anchor, template.HTMLEscapeString(ch.title))
+	}
+	for ; depth > 0; depth-- {
+		sb.WriteString("</li>\n</ol>\n")
+	}
+	sb.WriteString("</li>\n</ol>\n</nav>\n")
+
+	return template.HTML(sb.String())
+}
@@ -0,0 +1,219 @@
+package book
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// scanSite writes files into a temp dir and scans it
+func scanSite(t *testing.T, files map[string]string) *tree.Site {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	site, err := tree.Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return site
+}
+
+// stubRender returns canned HTML keyed by URL path
+func stubRender(content map[string]string) RenderFunc {
+	return func(node *tree.Node) (string, string, error) {
+		urlPath := tree.GetURLPath(node)
+		html, ok := content[urlPath]
+		if !ok {
+			return "", "", fmt.Errorf("no content for %s", urlPath)
+		}
+		return node.Name, html, nil
+	}
+}
+
+func TestURLPath(t *testing.T) {
+	site := scanSite(t, map[string]string{"guides/setup/intro.md": "# Intro"})
+	if got := URLPath(site.Root); got != SiteURLPath {
+		t.Errorf("URLPath(root) = %q, want %q", got, SiteURLPath)
+	}
+
+	folders := Folders(site.Root)
+	if len(folders) != 2 {
+		t.Fatalf("Folders() = %d folders, want 2", len(folders))
+	}
+	if got := URLPath(folders[1]); got != "/guides/setup/print/" {
+		t.Errorf("URLPath(guides/setup) = %q, want /guides/setup/print/", got)
+	}
+}
+
+func TestOutline(t *testing.T) {
+	site := scanSite(t, map[string]string{
+		"index.md":        "# Home",
+		"guides/index.md": "# Guides",
+		"guides/intro.md": "# Intro",
+		"notes/todo.md":   "# Todo",
+	})
+
+	var got []string
+	for _, entry := range Outline(site.Root, site.AllPages) {
+		var name string
+		if entry.Page != nil {
+			name = entry.Page.Path
+		} else {
+			name = "part:" + entry.Folder.Name
+		}
+		got = append(got, fmt.Sprintf("%s@%d=%s", name, entry.Depth, entry.Slug))
+	}
+	want := []string{
+		"index.md@0=",
+		filepath.Join("guides", "index.md") + "@0=guides",
+		filepath.Join("guides", "intro.md") + "@1=guides-intro",
+		"part:Notes@0=notes",
+		filepath.Join("notes", "todo.md") + "@1=notes-todo",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Outline() = %v, want %v", got, want)
+	}
+}
+
+func TestBuildSite(t *testing.T) {
+	site := scanSite(t, map[string]string{
+		"index.md":        "# Home",
+		"guides/index.md": "# Guides",
+		"guides/intro.md": "# Intro",
+		"notes/todo.md":   "# Todo",
+	})
+	content := map[string]string{
+		"/":              `<h1 id="home">Home</h1><h2 id="install">Install</h2><p><a href="/guides/intro/#install">intro</a> <a href="#install">here</a></p>`,
+		"/guides/":       `<h1 id="guides">Guides</h1><p><a href="intro/">relative</a> <img src="diagram.png"></p>`,
+		"/guides/intro/": `<h1 id="intro">Intro</h1><h2 id="install">Install</h2><p><a href="/">home</a> <a href="https://example.com/">ext</a></p>`,
+		"/notes/todo/":   `<p>No heading</p><a href="/missing/">missing</a>`,
+	}
+
+	b, err := Build(site.Root, site.AllPages, "", i18n.Load("en", nil), stubRender(content))
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if b.Chapters != 4 {
+		t.Errorf("Chapters = %d, want 4", b.Chapters)
+	}
+	if b.Title != "" {
+		t.Errorf("Title = %q, want empty for the site root", b.Title)
+	}
+
+	html := string(b.Content)
+	for _, want := range []string{
+		// Sidebar order: root files, then folders with their index first
+		`<section class="print-chapter prose" id="page-home" data-depth="0">`,
+		`<section class="print-chapter prose" id="page-guides" data-depth="0">`,
+		`<section class="print-chapter prose" id="page-guides-intro" data-depth="1">`,
+		// Folder without index becomes a part heading
+		`<section class="print-part prose" id="page-notes" data-depth="0">`,
+		"<h1>Notes</h1>",
+		// Pages without an H1 get their title
+		"<h1>Todo</h1>",
+		// Duplicate ids across pages get a suffix; links follow them
+		`<h2 id="install">Install</h2>`,
+		`<h2 id="install-2">Install</h2>`,
+		`<a href="#install-2">intro</a>`,
+		`<a href="#install">here</a>`,
+		// Links to included pages become anchors
+		`<a href="#page-guides-intro">relative</a>`,
+		`<a href="#page-home">home</a>`,
+		// Relative resources become absolute; other links are untouched
+		`<img src="/guides/diagram.png">`,
+		`<a href="https://example.com/">ext</a>`,
+		`<a href="/missing/">missing</a>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("book should contain %q", want)
+		}
+	}
+	if strings.Index(html, `id="page-guides"`) > strings.Index(html, `id="page-guides-intro"`) {
+		t.Error("folder index should come before the folder's pages")
+	}
+
+	toc := string(b.TOC)
+	for _, want := range []string{
+		`<nav class="print-toc" aria-label="Contents">`,
+		`<li><a href="#page-guides">Guides</a>`,
+		"<ol>\n<li><a href=\"#page-guides-intro\">Intro</a></li>\n</ol>\n</li>",
+		`<li><a href="#page-notes">Notes</a>`,
+	} {
+		if !strings.Contains(toc, want) {
+			t.Errorf("TOC should contain %q, got:\n%s", want, toc)
+		}
+	}
+	if strings.Count(toc, "<ol>") != strings.Count(toc, "</ol>") || strings.Count(toc, "<li>") != strings.Count(toc, "</li>") {
+		t.Errorf("TOC lists are not balanced:\n%s", toc)
+	}
+}
+
+func TestBuildFolder(t *testing.T) {
+	site := scanSite(t, map[string]string{
+		"index.md":        "# Home",
+		"guides/index.md": "# Guides",
+		"guides/intro.md": "# Intro",
+	})
+	content := map[string]string{
+		"/guides/":       `<h1 id="guides">Guides</h1><a href="/docs/guides/intro/">intro</a><a href="/docs/">home</a>`,
+		"/guides/intro/": `<h1 id="intro">Intro</h1>`,
+	}
+
+	b, err := Build(Folders(site.Root)[0], site.AllPages, "/docs", i18n.Load("fr", nil), stubRender(content))
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if b.Title != "Guides" {
+		t.Errorf("Title = %q, want the folder index title", b.Title)
+	}
+	if b.Chapters != 2 {
+		t.Errorf("Chapters = %d, want 2", b.Chapters)
+	}
+	html := string(b.Content)
+	if !strings.Contains(html, `<a href="#page-guides-intro">intro</a>`) {
+		t.Error("base-path links to pages in the book should become anchors")
+	}
+	if !strings.Contains(html, `<a href="/docs/">home</a>`) {
+		t.Error("links to pages outside the book should be kept")
+	}
+	if strings.Contains(html, "page-home") {
+		t.Error("folder book should not include pages outside the folder")
+	}
+	if toc := string(b.TOC); !strings.Contains(toc, `<nav class="print-toc" aria-label="Sommaire">`) || !strings.Contains(toc, "<h2>Sommaire</h2>") {
+		t.Errorf("TOC should be translated, got:\n%s", toc)
+	}
+}
+
+func TestBuildRenderError(t *testing.T) {
+	site := scanSite(t, map[string]string{"index.md": "# Home"})
+	_, err := Build(site.Root, site.AllPages, "", i18n.Load("en", nil), stubRender(nil))
+	if err == nil || !strings.Contains(err.Error(), "index.md") {
+		t.Errorf("Build() error = %v, want error naming the page", err)
+	}
+}
+
+func TestRenderTOCDeepFirstChapter(t *testing.T) {
+	toc := string(renderTOC([]*chapter{
+		{title: "Deep", anchor: "a", depth: 2},
+		{title: "Top", anchor: "b", depth: 0},
+	}, i18n.Load("en", nil)))
+	if strings.Count(toc, "<ol>") != 3 || strings.Count(toc, "</ol>") != 3 {
+		t.Errorf("TOC lists are not balanced:\n%s", toc)
+	}
+	if renderTOC(nil, i18n.Load("en", nil)) != "" {
+		t.Error("empty book should have no TOC")
+	}
+}
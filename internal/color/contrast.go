@@ -0,0 +1,76 @@
+package color
+
+import "math"
+
+// MinDarkContrast is the contrast ratio a derived dark-mode accent must reach
+// against the theme's dark background (WCAG AA for normal text)
+const MinDarkContrast = 4.5
+
+// RelativeLuminance returns the WCAG relative luminance (0-1) of a hex color
+func RelativeLuminance(hex string) (float64, error) {
+	r, g, b, err := ParseHex(hex)
+	if err != nil {
+		return 0, err
+	}
+	return 0.2126*linearChannel(r) + 0.7152*linearChannel(g) + 0.0722*linearChannel(b), nil
+}
+
+// linearChannel converts an sRGB channel (0-255) to linear light
+func linearChannel(c uint8) float64 {
+	v := float64(c) / 255.0
+	if v <= 0.03928 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// ContrastRatio returns the WCAG contrast ratio (1-21) between two hex colors
+func ContrastRatio(a, b string) (float64, error) {
+	la, err := RelativeLuminance(a)
+	if err != nil {
+		return 0, err
+	}
+	lb, err := RelativeLuminance(b)
+	if err != nil {
+		return 0, err
+	}
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05), nil
+}
+
+// EnsureContrast returns fg, adjusted if necessary so that it reaches at least
+// minRatio against bg. Hue and saturation are kept; lightness moves away from
+// the background (lighter on dark backgrounds, darker on light ones) until the
+// ratio is met or lightness runs out.
+func EnsureContrast(fg, bg string, minRatio float64) (string, error) {
+	ratio, err := ContrastRatio(fg, bg)
+	if err != nil {
+		return "", err
+	}
+	if ratio >= minRatio {
+		return fg, nil
+	}
+
+	bgLum, _ := RelativeLuminance(bg)
+	step := 1.0
+	if bgLum > 0.5 {
+		step = -1.0
+	}
+
+	hsl := RGBToHSL(mustParseHex(fg))
+	for l := hsl.L + step; ; l += step {
+		l = math.Max(0, math.Min(100, l))
+		candidate := RGBToHex(HSLToRGB(HSL{H: hsl.H, S: hsl.S, L: l}))
+		if r, _ := ContrastRatio(candidate, bg); r >= minRatio || l == 0 || l == 100 {
+			return candidate, nil
+		}
+	}
+}
+
+// mustParseHex parses a hex color already validated by the caller
+func mustParseHex(hex string) (r, g, b uint8) {
+	r, g, b, _ = ParseHex(hex)
+	return r, g, b
+}
@@ -0,0 +1,93 @@
+package color
+
+import "testing"
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{"black on white", "#000000", "#ffffff", 21},
+		{"white on black", "#ffffff", "#000000", 21},
+		{"same color", "#0ea5e9", "#0ea5e9", 1},
+		{"gray on white", "#767676", "#ffffff", 4.54},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ContrastRatio(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if abs(got-tt.want) > 0.01 {
+				t.Errorf("ContrastRatio(%s, %s) = %.3f, want %.2f", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ContrastRatio("nope", "#000000"); err == nil {
+		t.Error("expected error for invalid foreground")
+	}
+	if _, err := ContrastRatio("#000000", "nope"); err == nil {
+		t.Error("expected error for invalid background")
+	}
+}
+
+func TestEnsureContrast(t *testing.T) {
+	t.Run("already readable is unchanged", func(t *testing.T) {
+		got, err := EnsureContrast("#0ea5e9", "#000000", MinDarkContrast)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "#0ea5e9" {
+			t.Errorf("got %s, want #0ea5e9", got)
+		}
+	})
+
+	t.Run("dark color is lightened on dark background", func(t *testing.T) {
+		in := "#0f172a"
+		got, err := EnsureContrast(in, "#000000", MinDarkContrast)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ratio, _ := ContrastRatio(got, "#000000")
+		if ratio < MinDarkContrast {
+			t.Errorf("EnsureContrast(%s) = %s with ratio %.2f, want >= %.1f", in, got, ratio, MinDarkContrast)
+		}
+		before := RGBToHSL(mustParseHex(in))
+		after := RGBToHSL(mustParseHex(got))
+		if after.L <= before.L {
+			t.Errorf("expected lightness to increase, got %.1f -> %.1f", before.L, after.L)
+		}
+		if abs(after.H-before.H) > 2 {
+			t.Errorf("expected hue to be kept, got %.1f -> %.1f", before.H, after.H)
+		}
+	})
+
+	t.Run("light color is darkened on light background", func(t *testing.T) {
+		got, err := EnsureContrast("#fde047", "#ffffff", MinDarkContrast)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ratio, _ := ContrastRatio(got, "#ffffff"); ratio < MinDarkContrast {
+			t.Errorf("EnsureContrast = %s with ratio %.2f, want >= %.1f", got, ratio, MinDarkContrast)
+		}
+	})
+
+	t.Run("unreachable ratio returns the extreme", func(t *testing.T) {
+		got, err := EnsureContrast("#808080", "#777777", 21)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "#ffffff" {
+			t.Errorf("got %s, want #ffffff", got)
+		}
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		if _, err := EnsureContrast("bad", "#000000", MinDarkContrast); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
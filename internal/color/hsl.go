@@ -159,29 +159,80 @@ func GenerateAccentVariants(hexColor string) (*AccentVariants, error) {
 
 // GenerateAccentCSS generates CSS custom properties for accent colors.
 // Accepts a single color (Tailwind name or hex) or a two-color gradient spec
-// like "lime-sky" / "#444444-#555555". Returns empty string if accentColor is empty.
+// like "lime-sky" / "#444444-#555555". Returns empty string if both
+// accentColor and accentColorDark are empty.
+//
+// accentColorDark takes the same syntax and sets the accent for
+// [data-theme="dark"]. When it is empty and darkBackground is set, the dark
+// accent is derived from accentColor: each color is lightened until it reaches
+// MinDarkContrast against darkBackground. With neither, light and dark mode
+// share one accent.
 //
 // For gradients the rule emits three variables (--accent, --accent-end,
 // --accent-gradient) and applies the gradient to the scroll progress bar and
 // the prose H1 so the user-visible effect is immediate. Themes can also opt
 // in to --accent-gradient elsewhere.
-func GenerateAccentCSS(accentColor string) (string, error) {
+func GenerateAccentCSS(accentColor, accentColorDark, darkBackground string) (string, error) {
 	start, end, err := ResolveAccentSpec(accentColor)
 	if err != nil {
 		return "", err
 	}
-	if start == "" {
+	darkStart, darkEnd, err := ResolveAccentSpec(accentColorDark)
+	if err != nil {
+		return "", fmt.Errorf("dark mode: %w", err)
+	}
+	if darkStart == "" && darkBackground != "" {
+		if darkStart, err = deriveDarkAccent(start, darkBackground); err != nil {
+			return "", err
+		}
+		if darkEnd, err = deriveDarkAccent(end, darkBackground); err != nil {
+			return "", err
+		}
+	}
+	if start == "" && darkStart == "" {
 		return "", nil
 	}
 
-	if end == "" {
-		// Single color — preserve existing behavior.
-		return fmt.Sprintf(`:root, [data-theme="dark"] {
-  --accent: %s;
-}`, start), nil
+	gradient := end != "" || darkEnd != ""
+	var css string
+	switch {
+	case darkStart == "" || (darkStart == start && darkEnd == end):
+		css = fmt.Sprintf(":root, [data-theme=\"dark\"] {\n%s}", accentVars(start, end, gradient))
+	case start == "":
+		css = fmt.Sprintf("[data-theme=\"dark\"] {\n%s}", accentVars(darkStart, darkEnd, gradient))
+	default:
+		css = fmt.Sprintf(":root {\n%s}\n\n[data-theme=\"dark\"] {\n%s}",
+			accentVars(start, end, gradient), accentVars(darkStart, darkEnd, gradient))
+	}
+	if !gradient {
+		return css, nil
 	}
+	return css + "\n\n" + accentGradientRules, nil
+}
 
-	// Two-color gradient. Two gradient variables ship:
+// deriveDarkAccent lightens an accent color until it reads on the dark background
+func deriveDarkAccent(hex, darkBackground string) (string, error) {
+	if hex == "" {
+		return "", nil
+	}
+	derived, err := EnsureContrast(hex, darkBackground, MinDarkContrast)
+	if err != nil {
+		return "", fmt.Errorf("dark background: %w", err)
+	}
+	return derived, nil
+}
+
+// accentVars returns the accent custom property declarations for one color
+// scheme. When the site uses gradients anywhere, a single color is emitted as
+// a flat gradient (start == end) so the gradient rules still resolve.
+func accentVars(start, end string, gradient bool) string {
+	if !gradient {
+		return fmt.Sprintf("  --accent: %s;\n", start)
+	}
+	if end == "" {
+		end = start
+	}
+	// Two gradient variables ship:
 	//   --accent-gradient            left-to-right — used for big backgrounds + text fills,
 	//                                where reading direction dominates the perceived blend
 	//   --accent-gradient-vertical   top-to-bottom — used for narrow vertical accents
@@ -191,14 +242,15 @@ func GenerateAccentCSS(accentColor string) (string, error) {
 	// headings because the gradient axis runs diagonally — most of the text
 	// bounding box sat in the first half of the gradient. Horizontal direction
 	// gives an even, predictable A→B sweep across the line.
-	return fmt.Sprintf(`:root, [data-theme="dark"] {
-  --accent: %s;
+	return fmt.Sprintf(`  --accent: %s;
   --accent-end: %s;
   --accent-gradient: linear-gradient(to right, %s, %s);
   --accent-gradient-vertical: linear-gradient(to bottom, %s, %s);
+`, start, end, start, end, start, end)
 }
 
-.scroll-progress-bar {
+// accentGradientRules applies the accent gradient to the page chrome
+const accentGradientRules = `.scroll-progress-bar {
   background: var(--accent-gradient);
 }
 
@@ -224,7 +276,7 @@ func GenerateAccentCSS(accentColor string) (string, error) {
 .prose h1 {
   width: -moz-fit-content;
   width: fit-content;
-  max-width: 100%%;
+  max-width: 100%;
 }
 
 /* Vertical gradient on single-edge left-border accents (admonitions,
@@ -243,7 +295,7 @@ func GenerateAccentCSS(accentColor string) (string, error) {
 .toc-sidebar {
   border-left-color: transparent;
   background:
-    var(--accent-gradient-vertical) left center / 3px 100%% no-repeat,
+    var(--accent-gradient-vertical) left center / 3px 100% no-repeat,
     var(--bg-primary);
 }
 
@@ -252,5 +304,4 @@ func GenerateAccentCSS(accentColor string) (string, error) {
 .prose h2 {
   border-bottom-color: transparent;
   border-image: var(--accent-gradient) 1;
-}`, start, end, start, end, start, end), nil
-}
+}`
@@ -269,7 +269,7 @@ func TestGenerateAccentVariants(t *testing.T) {
 
 func TestGenerateAccentCSS(t *testing.T) {
 	t.Run("empty color", func(t *testing.T) {
-		css, err := GenerateAccentCSS("")
+		css, err := GenerateAccentCSS("", "", "")
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
@@ -279,7 +279,7 @@ func TestGenerateAccentCSS(t *testing.T) {
 	})
 
 	t.Run("valid color", func(t *testing.T) {
-		css, err := GenerateAccentCSS("#ff6600")
+		css, err := GenerateAccentCSS("#ff6600", "", "")
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
@@ -296,7 +296,7 @@ func TestGenerateAccentCSS(t *testing.T) {
 	})
 
 	t.Run("invalid color", func(t *testing.T) {
-		_, err := GenerateAccentCSS("invalid")
+		_, err := GenerateAccentCSS("invalid", "", "")
 		if err == nil {
 			t.Error("expected error for invalid color")
 		}
@@ -344,7 +344,7 @@ func TestResolveAccentColor(t *testing.T) {
 }
 
 func TestGenerateAccentCSS_TailwindName(t *testing.T) {
-	css, err := GenerateAccentCSS("sky")
+	css, err := GenerateAccentCSS("sky", "", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -397,7 +397,7 @@ func TestResolveAccentSpec(t *testing.T) {
 }
 
 func TestGenerateAccentCSS_Gradient(t *testing.T) {
-	css, err := GenerateAccentCSS("lime-sky")
+	css, err := GenerateAccentCSS("lime-sky", "", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -443,7 +443,7 @@ func TestGenerateAccentCSS_Gradient(t *testing.T) {
 }
 
 func TestGenerateAccentCSS_SingleColorNoGradientRules(t *testing.T) {
-	css, err := GenerateAccentCSS("sky")
+	css, err := GenerateAccentCSS("sky", "", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -457,6 +457,85 @@ func TestGenerateAccentCSS_SingleColorNoGradientRules(t *testing.T) {
 	}
 }
 
+func TestGenerateAccentCSS_DarkMode(t *testing.T) {
+	t.Run("explicit dark accent", func(t *testing.T) {
+		css, err := GenerateAccentCSS("sky", "amber", "#000000")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(css, ":root {\n  --accent: #0ea5e9;") {
+			t.Errorf("light accent should be scoped to :root, got: %q", css)
+		}
+		if !strings.Contains(css, "[data-theme=\"dark\"] {\n  --accent: #f59e0b;") {
+			t.Errorf("dark accent should be scoped to [data-theme=dark], got: %q", css)
+		}
+	})
+
+	t.Run("derived dark accent meets contrast", func(t *testing.T) {
+		css, err := GenerateAccentCSS("#1e3a8a", "", "#000000")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		idx := strings.Index(css, "[data-theme=\"dark\"] {\n  --accent: ")
+		if idx < 0 || strings.Contains(css, ":root, [data-theme") {
+			t.Fatalf("expected a separate dark rule, got: %q", css)
+		}
+		dark := css[idx+len("[data-theme=\"dark\"] {\n  --accent: ") : idx+len("[data-theme=\"dark\"] {\n  --accent: ")+7]
+		if ratio, _ := ContrastRatio(dark, "#000000"); ratio < MinDarkContrast {
+			t.Errorf("derived dark accent %s has contrast %.2f, want >= %.1f", dark, ratio, MinDarkContrast)
+		}
+	})
+
+	t.Run("readable accent shares one rule", func(t *testing.T) {
+		css, err := GenerateAccentCSS("sky", "", "#000000")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(css, ":root, [data-theme=\"dark\"]") {
+			t.Errorf("expected a shared rule, got: %q", css)
+		}
+	})
+
+	t.Run("dark gradient with single light color", func(t *testing.T) {
+		css, err := GenerateAccentCSS("sky", "lime-sky", "#000000")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(css, "linear-gradient(to right, #0ea5e9, #0ea5e9)") {
+			t.Errorf("light mode should get a flat gradient, got: %q", css)
+		}
+		if !strings.Contains(css, "linear-gradient(to right, #84cc16, #0ea5e9)") {
+			t.Errorf("dark mode should get the gradient, got: %q", css)
+		}
+		if !strings.Contains(css, ".scroll-progress-bar") {
+			t.Errorf("gradient rules should be emitted, got: %q", css)
+		}
+	})
+
+	t.Run("dark accent only", func(t *testing.T) {
+		css, err := GenerateAccentCSS("", "amber", "#000000")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if css != "[data-theme=\"dark\"] {\n  --accent: #f59e0b;\n}" {
+			t.Errorf("unexpected CSS: %q", css)
+		}
+	})
+
+	t.Run("invalid dark accent", func(t *testing.T) {
+		_, err := GenerateAccentCSS("sky", "nope", "#000000")
+		if err == nil || !strings.Contains(err.Error(), "dark mode") {
+			t.Errorf("expected dark mode error, got %v", err)
+		}
+	})
+
+	t.Run("invalid dark background", func(t *testing.T) {
+		if _, err := GenerateAccentCSS("sky", "", "black"); err == nil {
+			t.Error("expected error for invalid dark background")
+		}
+	})
+}
+
 func TestTailwindColorsHaveAllExpectedNames(t *testing.T) {
 	required := []string{"sky", "rose", "emerald", "teal", "slate", "indigo", "amber"}
 	for _, name := range required {
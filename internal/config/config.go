@@ -25,25 +25,55 @@ type FileConfig struct {
 	Author string `json:"author"` // Site author
 
 	// Appearance
-	Theme       string `json:"theme"`       // Theme name (docs, blog, vanilla)
-	CSS         string `json:"css"`         // Path to custom CSS file
-	AccentColor string `json:"accentColor"` // Accent color: Tailwind name (e.g. "sky") or hex (e.g. "#0ea5e9")
-	Favicon     string `json:"favicon"`     // Path to favicon file
+	Theme           string `json:"theme"`           // Theme name (docs, blog, vanilla)
+	CSS             string `json:"css"`             // Path to custom CSS file
+	Layouts         string `json:"layouts"`         // Directory with template overrides (default: _layouts in input dir)
+	AccentColor     string `json:"accentColor"`     // Accent color: Tailwind name (e.g. "sky") or hex (e.g. "#0ea5e9")
+	AccentColorDark string `json:"accentColorDark"` // Dark-mode accent color, same syntax as accentColor (derived if empty)
+	Favicon         string `json:"favicon"`         // Path to favicon file
+
+	// Site-wide includes (default: _header.md, _footer.md, _banner.md in input dir)
+	Header string `json:"header"` // Markdown shown above every page's content
+	Footer string `json:"footer"` // Markdown shown below every page's content
+	Banner string `json:"banner"` // Dismissible announcement shown at the top of every page
+
+	// Localization
+	Language     string                       `json:"language"`               // Default language code (default: en)
+	Languages    []string                     `json:"languages,omitempty"`    // Translation languages, from page.<lang>.md files
+	Translations map[string]map[string]string `json:"translations,omitempty"` // Interface text overrides, keyed by language then message
 
 	// Navigation
-	TopNav       *bool `json:"topNav,omitempty"`       // Show top navigation bar
-	Breadcrumbs  *bool `json:"breadcrumbs,omitempty"`  // Show breadcrumbs
-	PageNav      *bool `json:"pageNav,omitempty"`      // Show prev/next navigation
-	InstantNav   *bool `json:"instantNav,omitempty"`   // Enable instant navigation
-	InlineAssets *bool `json:"inlineAssets,omitempty"` // Embed CSS/JS inline
-	PWA          *bool `json:"pwa,omitempty"`          // Enable PWA support
-	Search       *bool `json:"search,omitempty"`       // Enable search
+	TopNav          *bool `json:"topNav,omitempty"`          // Show top navigation bar
+	Breadcrumbs     *bool `json:"breadcrumbs,omitempty"`     // Show breadcrumbs
+	PageNav         *bool `json:"pageNav,omitempty"`         // Show prev/next navigation
+	PageNavSections *bool `json:"pageNavSections,omitempty"` // Keep prev/next links within top-level folders
+	Related         *int  `json:"related,omitempty"`         // Number of related pages shown below each page (0: none)
+	Graph           *bool `json:"graph,omitempty"`           // Link graph page and local graph below each page
+	InstantNav      *bool `json:"instantNav,omitempty"`      // Enable instant navigation
+	InlineAssets    *bool `json:"inlineAssets,omitempty"`    // Embed CSS/JS inline
+	PWA             *bool `json:"pwa,omitempty"`             // Enable PWA support
+	Search          *bool `json:"search,omitempty"`          // Enable search
+	SearchShardSize *int  `json:"searchShardSize,omitempty"` // Target size of each search index shard in KB
+	Print           *bool `json:"print,omitempty"`           // Generate printable books
 
 	// SEO
-	OGImage string `json:"ogImage"` // Default Open Graph image URL
+	OGImage  string `json:"ogImage"`            // Default Open Graph image URL
+	OGImages *bool  `json:"ogImages,omitempty"` // Generate an Open Graph image for each page
 
 	// Build options
 	AllowBrokenLinks *bool `json:"allowBrokenLinks,omitempty"` // Don't fail build on broken links
+
+	// Self-hosted fonts for body text, headings and code
+	Fonts *FontsConfig `json:"fonts,omitempty"`
+
+	// Per-folder settings, keyed by folder path relative to the input directory
+	Folders map[string]FolderConfig `json:"folders,omitempty"`
+
+	// Navigation menus by name; "main" replaces the automatic top nav
+	Menus map[string][]MenuItem `json:"menus,omitempty"`
+
+	// Versions of the documentation, each built under its own URL prefix
+	Versions *VersionsConfig `json:"versions,omitempty"`
 }
 
 // Load reads a config file from the given path and returns the parsed configuration.
@@ -139,8 +169,13 @@ func DefaultFileConfig() *FileConfig {
 		Author:           "",
 		Theme:            "docs",
 		CSS:              "",
+		Layouts:          "",
 		AccentColor:      "sky",
 		Favicon:          "",
+		Header:           "",
+		Footer:           "",
+		Banner:           "",
+		Language:         "en",
 		TopNav:           BoolPtr(false),
 		Breadcrumbs:      BoolPtr(false),
 		PageNav:          BoolPtr(false),
@@ -148,6 +183,7 @@ func DefaultFileConfig() *FileConfig {
 		InlineAssets:     BoolPtr(false),
 		PWA:              BoolPtr(false),
 		Search:           BoolPtr(false),
+		Print:            BoolPtr(false),
 		OGImage:          "",
 		AllowBrokenLinks: BoolPtr(false),
 	}
@@ -177,15 +213,36 @@ func MergeConfigs(defaults, existing *FileConfig) *FileConfig {
 	if existing.CSS != "" {
 		result.CSS = existing.CSS
 	}
+	if existing.Layouts != "" {
+		result.Layouts = existing.Layouts
+	}
 	if existing.AccentColor != "" {
 		result.AccentColor = existing.AccentColor
 	}
+	if existing.AccentColorDark != "" {
+		result.AccentColorDark = existing.AccentColorDark
+	}
 	if existing.Favicon != "" {
 		result.Favicon = existing.Favicon
 	}
 	if existing.OGImage != "" {
 		result.OGImage = existing.OGImage
 	}
+	if existing.OGImages != nil {
+		result.OGImages = existing.OGImages
+	}
+	if existing.Header != "" {
+		result.Header = existing.Header
+	}
+	if existing.Footer != "" {
+		result.Footer = existing.Footer
+	}
+	if existing.Banner != "" {
+		result.Banner = existing.Banner
+	}
+	if existing.Language != "" {
+		result.Language = existing.Language
+	}
 
 	// Pointer values - only override if explicitly set in existing
 	if existing.Port != nil {
@@ -200,6 +257,15 @@ func MergeConfigs(defaults, existing *FileConfig) *FileConfig {
 	if existing.PageNav != nil {
 		result.PageNav = existing.PageNav
 	}
+	if existing.PageNavSections != nil {
+		result.PageNavSections = existing.PageNavSections
+	}
+	if existing.Related != nil {
+		result.Related = existing.Related
+	}
+	if existing.Graph != nil {
+		result.Graph = existing.Graph
+	}
 	if existing.InstantNav != nil {
 		result.InstantNav = existing.InstantNav
 	}
@@ -212,9 +278,35 @@ func MergeConfigs(defaults, existing *FileConfig) *FileConfig {
 	if existing.Search != nil {
 		result.Search = existing.Search
 	}
+	if existing.SearchShardSize != nil {
+		result.SearchShardSize = existing.SearchShardSize
+	}
+	if existing.Print != nil {
+		result.Print = existing.Print
+	}
 	if existing.AllowBrokenLinks != nil {
 		result.AllowBrokenLinks = existing.AllowBrokenLinks
 	}
+	if existing.Fonts != nil {
+		result.Fonts = existing.Fonts
+	}
+	if existing.Versions != nil {
+		result.Versions = existing.Versions
+	}
+
+	// Map values - keep the existing folder rules as a whole
+	if existing.Folders != nil {
+		result.Folders = existing.Folders
+	}
+	if existing.Menus != nil {
+		result.Menus = existing.Menus
+	}
+	if existing.Languages != nil {
+		result.Languages = existing.Languages
+	}
+	if existing.Translations != nil {
+		result.Translations = existing.Translations
+	}
 
 	return &result
 }
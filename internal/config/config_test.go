@@ -357,6 +357,13 @@ func TestMergeConfigs(t *testing.T) {
 		URL:              "https://example.com",
 		Theme:            "blog",
 		AccentColor:      "#ff6600",
+		AccentColorDark:  "amber",
+		Header:           "_partials/header.md",
+		Footer:           "_partials/footer.md",
+		Banner:           "_partials/banner.md",
+		Language:         "fr",
+		Languages:        []string{"de"},
+		Translations:     map[string]map[string]string{"fr": {"next": "Suite"}},
 		Port:             IntPtr(8080),
 		TopNav:           BoolPtr(true),
 		Breadcrumbs:      nil,
@@ -384,6 +391,15 @@ func TestMergeConfigs(t *testing.T) {
 	if merged.AccentColor != "#ff6600" {
 		t.Errorf("AccentColor = %q, want %q", merged.AccentColor, "#ff6600")
 	}
+	if merged.AccentColorDark != "amber" {
+		t.Errorf("AccentColorDark = %q, want %q", merged.AccentColorDark, "amber")
+	}
+	if merged.Header != "_partials/header.md" || merged.Footer != "_partials/footer.md" || merged.Banner != "_partials/banner.md" {
+		t.Errorf("includes = %q, %q, %q", merged.Header, merged.Footer, merged.Banner)
+	}
+	if merged.Language != "fr" || len(merged.Languages) != 1 || merged.Translations["fr"]["next"] != "Suite" {
+		t.Errorf("localization = %q, %v, %v", merged.Language, merged.Languages, merged.Translations)
+	}
 	if merged.Port == nil || *merged.Port != 8080 {
 		t.Errorf("Port = %v, want 8080", merged.Port)
 	}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// FolderConfig holds settings that apply to every page under a content folder.
+// Folders are keyed by their path relative to the input directory, either as
+// written on disk ("02-guides/api") or as it appears in URLs ("guides/api").
+// The key "" or "/" applies to the whole site.
+type FolderConfig struct {
+	Layout  string         `json:"layout,omitempty"`  // Default layout for pages in the folder
+	Listing *ListingConfig `json:"listing,omitempty"` // List the folder's pages with dates and excerpts, paginated
+	Archive bool           `json:"archive,omitempty"` // Generate date archives of the folder's dated pages
+	Schema  string         `json:"schema,omitempty"`  // Schema.org type of the folder's pages, for JSON-LD ("none" for none)
+
+	Robots    string `json:"robots,omitempty"`    // Robots directive of the folder's pages, such as "noindex, nofollow"
+	Canonical string `json:"canonical,omitempty"` // Site URL the folder's pages are canonical at, for syndicated copies
+	Sitemap   *bool  `json:"sitemap,omitempty"`   // List the folder's pages in sitemap.xml (default: true)
+	Crawl     *bool  `json:"crawl,omitempty"`     // Let crawlers fetch the folder (default: true); false disallows it in robots.txt
+}
+
+// ListingConfig turns a folder's index page into a paginated listing of its
+// pages, newest first, for blogs and changelogs
+type ListingConfig struct {
+	Style    string `json:"style,omitempty"`    // list, card or timeline (default: list)
+	PageSize int    `json:"pageSize,omitempty"` // Entries per page (default: 10)
+}
+
+// ResolveFolderConfig returns the folder settings for a page, given its source
+// path relative to the input directory. Settings are merged from the site root
+// down to the page's own folder, so the nearest folder that sets a value wins.
+func ResolveFolderConfig(folders map[string]FolderConfig, pagePath string) FolderConfig {
+	var result FolderConfig
+	if len(folders) == 0 {
+		return result
+	}
+
+	byKey := make(map[string]FolderConfig, len(folders))
+	for key, fc := range folders {
+		byKey[normalizeFolderKey(key)] = fc
+	}
+
+	dir := path.Dir(filepath.ToSlash(pagePath))
+	if dir == "." {
+		dir = ""
+	}
+
+	// Walk "", "a", "a/b", ... so deeper folders override shallower ones
+	var rawParts, slugParts []string
+	if fc, ok := byKey[""]; ok {
+		result = mergeFolderConfig(result, fc)
+	}
+	if dir == "" {
+		return result
+	}
+	for _, part := range strings.Split(dir, "/") {
+		rawParts = append(rawParts, part)
+		slugParts = append(slugParts, tree.Slugify(part))
+
+		raw := strings.Join(rawParts, "/")
+		slug := strings.Join(slugParts, "/")
+		if fc, ok := byKey[raw]; ok {
+			result = mergeFolderConfig(result, fc)
+		} else if fc, ok := byKey[slug]; ok {
+			result = mergeFolderConfig(result, fc)
+		}
+	}
+
+	return result
+}
+
+// mergeFolderConfig overlays the values set in override onto base
+func mergeFolderConfig(base, override FolderConfig) FolderConfig {
+	if override.Layout != "" {
+		base.Layout = override.Layout
+	}
+	if override.Listing != nil {
+		base.Listing = override.Listing
+	}
+	if override.Archive {
+		base.Archive = true
+	}
+	if override.Schema != "" {
+		base.Schema = override.Schema
+	}
+	if override.Robots != "" {
+		base.Robots = override.Robots
+	}
+	if override.Canonical != "" {
+		base.Canonical = override.Canonical
+	}
+	if override.Sitemap != nil {
+		base.Sitemap = override.Sitemap
+	}
+	if override.Crawl != nil {
+		base.Crawl = override.Crawl
+	}
+	return base
+}
+
+// normalizeFolderKey trims "./" and surrounding slashes from a folder key
+func normalizeFolderKey(key string) string {
+	key = filepath.ToSlash(strings.TrimSpace(key))
+	key = strings.TrimPrefix(key, "./")
+	return strings.Trim(key, "/")
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestResolveFolderConfig(t *testing.T) {
+	folders := map[string]FolderConfig{
+		"/":              {Layout: "wide"},
+		"./talks/":       {Layout: "landing"},
+		"guides/api":     {Layout: "default"},
+		"03-tutorials":   {Layout: "landing"},
+		"tutorials/deep": {},
+	}
+
+	tests := []struct {
+		name     string
+		pagePath string
+		want     string
+	}{
+		{"root page uses site default", "index.md", "wide"},
+		{"unmatched folder uses site default", "notes/a.md", "wide"},
+		{"raw folder key", "talks/intro.md", "landing"},
+		{"nested folder inherits", "talks/2024/intro.md", "landing"},
+		{"deeper folder wins", "guides/api/auth.md", "default"},
+		{"slug key matches numbered folder", "02-guides/01-api/auth.md", "default"},
+		{"raw numbered key", "03-tutorials/a.md", "landing"},
+		{"empty override keeps parent", "03-tutorials/deep/a.md", "landing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveFolderConfig(folders, tt.pagePath)
+			if got.Layout != tt.want {
+				t.Errorf("ResolveFolderConfig(%q).Layout = %q, want %q", tt.pagePath, got.Layout, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveFolderConfigEmpty(t *testing.T) {
+	if got := ResolveFolderConfig(nil, "guides/a.md"); got != (FolderConfig{}) {
+		t.Errorf("ResolveFolderConfig(nil) = %+v, want zero value", got)
+	}
+	if got := ResolveFolderConfig(map[string]FolderConfig{"blog": {Layout: "wide"}}, "index.md"); got.Layout != "" {
+		t.Errorf("root page Layout = %q, want empty", got.Layout)
+	}
+}
+
+func TestResolveFolderConfigListing(t *testing.T) {
+	var cfg FileConfig
+	if err := json.Unmarshal([]byte(`{"folders": {"blog": {"listing": {"style": "card", "pageSize": 5}}, "blog/drafts": {"layout": "wide"}}}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	got := ResolveFolderConfig(cfg.Folders, "02-blog/index.md")
+	if got.Listing == nil || *got.Listing != (ListingConfig{Style: "card", PageSize: 5}) {
+		t.Errorf("blog Listing = %+v", got.Listing)
+	}
+	if got := ResolveFolderConfig(cfg.Folders, "blog/drafts/index.md"); got.Listing == nil || got.Layout != "wide" {
+		t.Errorf("nested folder = %+v, want the listing inherited", got)
+	}
+	if got := ResolveFolderConfig(cfg.Folders, "guides/index.md"); got.Listing != nil {
+		t.Errorf("guides Listing = %+v, want nil", got.Listing)
+	}
+}
+
+func TestResolveFolderConfigArchive(t *testing.T) {
+	folders := map[string]FolderConfig{"/": {Layout: "wide"}, "blog": {Archive: true}}
+	if got := ResolveFolderConfig(folders, "blog/2024/index.md"); !got.Archive || got.Layout != "wide" {
+		t.Errorf("blog subfolder = %+v, want the archive setting inherited", got)
+	}
+	if got := ResolveFolderConfig(folders, "index.md"); got.Archive {
+		t.Error("the root should not have an archive")
+	}
+}
+
+func TestResolveFolderConfigIndexing(t *testing.T) {
+	var cfg FileConfig
+	data := `{"folders": {"internal": {"robots": "noindex, nofollow", "sitemap": false}, "internal/public": {"robots": "index, follow"}, "private": {"crawl": false}, "syndicated": {"canonical": "https://example.org"}}}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	got := ResolveFolderConfig(cfg.Folders, "internal/runbooks/deploy.md")
+	if got.Robots != "noindex, nofollow" || got.Sitemap == nil || *got.Sitemap {
+		t.Errorf("internal page = %+v, want noindex and no sitemap", got)
+	}
+	got = ResolveFolderConfig(cfg.Folders, "internal/public/faq.md")
+	if got.Robots != "index, follow" || got.Sitemap == nil || *got.Sitemap {
+		t.Errorf("internal/public page = %+v, want its own robots and the inherited sitemap setting", got)
+	}
+	if got := ResolveFolderConfig(cfg.Folders, "private/keys.md"); got.Crawl == nil || *got.Crawl || got.Robots != "" {
+		t.Errorf("private page = %+v, want crawling disallowed", got)
+	}
+	if got := ResolveFolderConfig(cfg.Folders, "syndicated/post.md"); got.Canonical != "https://example.org" || got.Sitemap != nil {
+		t.Errorf("syndicated page = %+v, want the canonical site", got)
+	}
+}
+
+func TestFoldersJSON(t *testing.T) {
+	var cfg FileConfig
+	if err := json.Unmarshal([]byte(`{"folders": {"talks": {"layout": "landing"}}}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Folders["talks"].Layout != "landing" {
+		t.Errorf("Folders[talks].Layout = %q, want %q", cfg.Folders["talks"].Layout, "landing")
+	}
+
+	merged := MergeConfigs(DefaultFileConfig(), &cfg)
+	if merged.Folders["talks"].Layout != "landing" {
+		t.Error("MergeConfigs should keep folder rules")
+	}
+
+	data, err := json.Marshal(DefaultFileConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw["folders"]; ok {
+		t.Error("default config should not include folders")
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"encoding/json"
+)
+
+// FontsConfig holds self-hosted font files for each typographic role.
+// Any role left unset keeps the theme's font.
+type FontsConfig struct {
+	Body     *FontConfig `json:"body,omitempty"`     // Body text
+	Headings *FontConfig `json:"headings,omitempty"` // H1-H6 (default: the body font)
+	Code     *FontConfig `json:"code,omitempty"`     // Inline code and code blocks
+}
+
+// FontConfig describes one font family and the files that make it up
+type FontConfig struct {
+	Family   string     `json:"family,omitempty"`   // CSS family name (default: derived from the role)
+	Fallback string     `json:"fallback,omitempty"` // Fallback stack used while the font loads
+	Files    []FontFile `json:"files"`              // Font files (woff2, woff or ttf)
+}
+
+// FontFile is a single font file. In volcano.json it is either a path string
+// or an object with the weight and style the file covers.
+type FontFile struct {
+	Path   string `json:"path"`             // Path to the font file
+	Weight string `json:"weight,omitempty"` // CSS font-weight, e.g. "700" or "100 900" (default: 400)
+	Style  string `json:"style,omitempty"`  // CSS font-style, e.g. "italic" (default: normal)
+}
+
+// UnmarshalJSON accepts either "path/to/font.woff2" or {"path": ..., "weight": ...}
+func (f *FontFile) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*f = FontFile{Path: path}
+		return nil
+	}
+	type fontFile FontFile // avoid recursing into this method
+	var file fontFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	*f = FontFile(file)
+	return nil
+}
+
+// FileCount returns the number of font files configured across all roles
+func (c *FontsConfig) FileCount() int {
+	if c == nil {
+		return 0
+	}
+	count := 0
+	for _, font := range []*FontConfig{c.Body, c.Headings, c.Code} {
+		if font != nil {
+			count += len(font.Files)
+		}
+	}
+	return count
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFontsConfigUnmarshal(t *testing.T) {
+	data := `{
+		"body": {"family": "Brand", "files": ["fonts/brand.woff2", {"path": "fonts/brand-bold.woff2", "weight": "700", "style": "italic"}]},
+		"code": {"files": ["fonts/mono.woff2"]}
+	}`
+	var cfg FontsConfig
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if cfg.Body == nil || cfg.Body.Family != "Brand" || len(cfg.Body.Files) != 2 {
+		t.Fatalf("unexpected body font: %+v", cfg.Body)
+	}
+	if cfg.Body.Files[0] != (FontFile{Path: "fonts/brand.woff2"}) {
+		t.Errorf("string form: got %+v", cfg.Body.Files[0])
+	}
+	if cfg.Body.Files[1] != (FontFile{Path: "fonts/brand-bold.woff2", Weight: "700", Style: "italic"}) {
+		t.Errorf("object form: got %+v", cfg.Body.Files[1])
+	}
+	if cfg.Headings != nil {
+		t.Error("headings should be unset")
+	}
+	if got := cfg.FileCount(); got != 3 {
+		t.Errorf("FileCount() = %d, want 3", got)
+	}
+
+	var nilCfg *FontsConfig
+	if nilCfg.FileCount() != 0 {
+		t.Error("nil FontsConfig should have no files")
+	}
+
+	var file FontFile
+	if err := json.Unmarshal([]byte(`42`), &file); err == nil {
+		t.Error("expected error for a non-string, non-object font file")
+	}
+}
@@ -0,0 +1,15 @@
+package config
+
+// MainMenu is the menu shown in the top navigation bar
+const MainMenu = "main"
+
+// MenuItem is an entry of a navigation menu. It links to a page of the site
+// (Page) or to any URL (URL); an item with Children is a dropdown group.
+type MenuItem struct {
+	Name     string     `json:"name,omitempty"`     // Label (default: the linked page's title)
+	Page     string     `json:"page,omitempty"`     // Page path ("guides/intro.md") or wikilink target ("intro", "[[guides/intro]]")
+	URL      string     `json:"url,omitempty"`      // External URL, or a path on the site
+	Icon     string     `json:"icon,omitempty"`     // Built-in icon name, image path, or text such as an emoji
+	Match    string     `json:"match,omitempty"`    // URL prefix that marks the item active (default: the item's URL)
+	Children []MenuItem `json:"children,omitempty"` // Dropdown items
+}
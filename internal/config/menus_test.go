@@ -0,0 +1,41 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMenusJSON(t *testing.T) {
+	var cfg FileConfig
+	data := `{"menus": {"main": [
+		{"page": "guides", "icon": "book"},
+		{"name": "More", "children": [{"name": "GitHub", "url": "https://github.com/example"}]}
+	]}}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	main := cfg.Menus[MainMenu]
+	if len(main) != 2 || main[0].Page != "guides" || main[0].Icon != "book" {
+		t.Fatalf("main menu = %+v", main)
+	}
+	if len(main[1].Children) != 1 || main[1].Children[0].URL != "https://github.com/example" {
+		t.Errorf("dropdown = %+v", main[1])
+	}
+
+	merged := MergeConfigs(DefaultFileConfig(), &cfg)
+	if len(merged.Menus[MainMenu]) != 2 {
+		t.Error("MergeConfigs should keep menus")
+	}
+
+	out, err := json.Marshal(DefaultFileConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(out, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw["menus"]; ok {
+		t.Error("default config should not include menus")
+	}
+}
@@ -0,0 +1,15 @@
+package config
+
+// VersionsConfig builds several versions of the documentation in one run,
+// each under its own URL prefix (/v1/, /v2/) plus the latest at /latest/
+type VersionsConfig struct {
+	Latest string          `json:"latest,omitempty"` // Version also built at /latest/ (default: the last one)
+	List   []VersionConfig `json:"list,omitempty"`   // Versions, oldest first (default: the input's version folders)
+}
+
+// VersionConfig is one version of the documentation
+type VersionConfig struct {
+	Name  string `json:"name"`            // URL prefix, such as "v2"
+	Label string `json:"label,omitempty"` // Text in the version menu (default: the name)
+	Dir   string `json:"dir,omitempty"`   // Content directory, as given or relative to the input (default: <input>/<name>)
+}
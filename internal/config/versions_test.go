@@ -0,0 +1,34 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestVersionsJSON(t *testing.T) {
+	var cfg FileConfig
+	data := `{"versions": {"latest": "v2", "list": [{"name": "v1", "dir": "../v1-docs"}, {"name": "v2", "label": "2.x"}]}}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Versions == nil || cfg.Versions.Latest != "v2" || len(cfg.Versions.List) != 2 {
+		t.Fatalf("Versions = %+v", cfg.Versions)
+	}
+	if cfg.Versions.List[0].Dir != "../v1-docs" || cfg.Versions.List[1].Label != "2.x" {
+		t.Errorf("List = %+v", cfg.Versions.List)
+	}
+
+	merged := MergeConfigs(DefaultFileConfig(), &cfg)
+	if merged.Versions != cfg.Versions {
+		t.Error("MergeConfigs should keep versions")
+	}
+
+	// An empty object turns versions on, found from the input's folders
+	var auto FileConfig
+	if err := json.Unmarshal([]byte(`{"versions": {}}`), &auto); err != nil {
+		t.Fatal(err)
+	}
+	if auto.Versions == nil || len(auto.Versions.List) != 0 {
+		t.Errorf("Versions = %+v, want an empty config", auto.Versions)
+	}
+}
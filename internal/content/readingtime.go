@@ -3,9 +3,9 @@ package content
 
 import (
 	"regexp"
-	"strconv"
-	"strings"
 	"unicode"
+
+	"github.com/wusher/volcano/internal/i18n"
 )
 
 // ReadingTime holds reading time calculation results
@@ -84,10 +84,12 @@ func countWords(text string) int {
 	return words
 }
 
-// FormatReadingTime returns a human-readable reading time string
+// FormatReadingTime returns a human-readable reading time string in English
 func FormatReadingTime(rt ReadingTime) string {
-	if rt.Minutes == 1 {
-		return "1 min read"
-	}
-	return strings.TrimSpace(strings.Join([]string{strconv.Itoa(rt.Minutes), "min read"}, " "))
+	return FormatReadingTimeIn(rt, i18n.English)
+}
+
+// FormatReadingTimeIn returns a reading time string in the language of messages
+func FormatReadingTimeIn(rt ReadingTime, messages i18n.Messages) string {
+	return messages.ReadingTime(rt.Minutes)
 }
@@ -0,0 +1,537 @@
+// Package epub packages a site's pages into an EPUB 3 ebook.
+package epub
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"hash/crc32"
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+
+	"github.com/wusher/volcano/internal/book"
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/includes"
+	"github.com/wusher/volcano/internal/markdown"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// mimeType identifies the archive as an EPUB; it must be its first entry
+const mimeType = "application/epub+zip"
+
+// stylesheetPath is the book's stylesheet inside the archive
+const stylesheetPath = "OEBPS/styles/book.css"
+
+// imageTypes maps image extensions to the media types EPUB readers support
+var imageTypes = map[string]string{
+	".gif":  "image/gif",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".svg":  "image/svg+xml",
+	".webp": "image/webp",
+}
+
+var h1Regex = regexp.MustCompile(`(?i)<h1[\s>]`)
+
+// Config holds the options for an ebook
+type Config struct {
+	InputDir     string
+	Title        string                       // Book title (default: the home page's title, then the folder name)
+	Author       string                       // Listed as the book's creator
+	Language     string                       // BCP 47 language tag (default: "en")
+	Languages    []string                     // Translation languages; their page.<lang>.md files are left out
+	Translations map[string]map[string]string // Interface text overrides, keyed by language then message
+	SiteURL      string                       // Identifies the book; links to pages outside it point here
+	Includes     includes.Paths               // Site header, footer and banner sources, left out of the book
+	Modified     time.Time                    // Last-modified date (default: now)
+}
+
+// Result summarizes a written ebook
+type Result struct {
+	Title    string
+	Chapters int      // Pages included
+	Images   int      // Images embedded
+	Warnings []string // Links and images that couldn't be kept inside the book
+}
+
+// chapter is one content document: a page, or a folder without an index
+type chapter struct {
+	id      string // Manifest id, also the file name
+	title   string
+	urlPath string // Page (or folder) URL the chapter replaces
+	source  string // Page path relative to the input folder; "" for folders
+	depth   int
+	content string // XHTML body
+	svg     bool   // Has inline SVG, which the manifest must declare
+}
+
+// image is a file embedded in the book
+type image struct {
+	id, href, mediaType string
+	data                []byte
+}
+
+// builder collects the chapters and images of one ebook
+type builder struct {
+	cfg         Config
+	messages    i18n.Messages // Interface text in the book's language
+	transformer *markdown.ContentTransformer
+	chapters    []*chapter
+	byURL       map[string]*chapter
+	images      map[string]*image // By site path
+	imageOrder  []*image
+	result      *Result
+}
+
+// Write renders every page under cfg.InputDir in sidebar order and writes
+// them to w as an EPUB 3 book, with a table of contents that follows the
+// folder tree. Links between pages point at their chapters and local images
+// are embedded.
+func Write(w io.Writer, cfg Config) (*Result, error) {
+	if cfg.Language == "" {
+		cfg.Language = "en"
+	}
+	if cfg.Modified.IsZero() {
+		cfg.Modified = time.Now()
+	}
+
+	site, err := cfg.Includes.ScanLanguage(cfg.InputDir, "", cfg.Languages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan input directory: %w", err)
+	}
+	entries := book.Outline(site.Root, site.AllPages)
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no markdown files found in %s", cfg.InputDir)
+	}
+
+	b := &builder{
+		cfg:         cfg,
+		messages:    i18n.Load(cfg.Language, cfg.Translations[cfg.Language]),
+		transformer: markdown.NewContentTransformer(""),
+		byURL:       make(map[string]*chapter, len(entries)),
+		images:      make(map[string]*image),
+		result:      &Result{},
+	}
+
+	// Every chapter needs a file before any page is rendered, so links
+	// can point forward
+	for i, entry := range entries {
+		ch := &chapter{id: fmt.Sprintf("ch%03d", i+1), depth: entry.Depth}
+		if entry.Page != nil {
+			ch.urlPath = tree.GetURLPath(entry.Page)
+			ch.source = entry.Page.Path
+		} else {
+			ch.title = entry.Folder.Name
+			ch.urlPath = "/" + tree.SlugifyPath(entry.Folder.Path) + "/"
+		}
+		b.chapters = append(b.chapters, ch)
+		b.byURL[ch.urlPath] = ch
+	}
+
+	for i, entry := range entries {
+		if err := b.renderChapter(b.chapters[i], entry.Page); err != nil {
+			return nil, err
+		}
+		if entry.Page != nil {
+			b.result.Chapters++
+		}
+	}
+
+	b.result.Title = cfg.Title
+	if b.result.Title == "" {
+		if home := b.byURL["/"]; home != nil {
+			b.result.Title = home.title
+		}
+	}
+	if b.result.Title == "" {
+		if abs, err := filepath.Abs(cfg.InputDir); err == nil {
+			b.result.Title = filepath.Base(abs)
+		}
+	}
+	b.result.Images = len(b.imageOrder)
+
+	if err := b.writeArchive(w); err != nil {
+		return nil, err
+	}
+	return b.result, nil
+}
+
+// renderChapter runs a page through the markdown pipeline and converts the
+// result to XHTML. Folders without an index get a title page.
+func (b *builder) renderChapter(ch *chapter, page *tree.Node) error {
+	html := ""
+	if page != nil {
+		mdContent, err := os.ReadFile(page.SourcePath)
+		if err != nil {
+			return err
+		}
+
+		// Compute source directory for wikilink resolution
+		relDir := filepath.Dir(page.Path)
+		sourceDir := "/"
+		if relDir != "." && relDir != "" {
+			sourceDir = "/" + tree.SlugifyPath(relDir) + "/"
+		}
+
+		p, err := b.transformer.TransformMarkdown(mdContent, sourceDir, page.SourcePath, tree.GetOutputPath(page), ch.urlPath, page.Name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", page.Path, err)
+		}
+		ch.title = p.Title
+		html = p.Content
+	}
+	if !h1Regex.MatchString(html) {
+		html = "<h1>" + template.HTMLEscapeString(ch.title) + "</h1>\n" + html
+	}
+
+	content, svg, err := toXHTML(html, func(el *xml.StartElement) (bool, string) {
+		return b.rewrite(ch, el)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: cannot convert to XHTML: %w", ch.source, err)
+	}
+	ch.content, ch.svg = content, svg
+	return nil
+}
+
+// rewrite points links at chapters and images at embedded copies
+func (b *builder) rewrite(ch *chapter, el *xml.StartElement) (bool, string) {
+	switch el.Name.Local {
+	case "a":
+		if href := attrValue(*el, "href"); href != "" {
+			setAttr(el, "href", b.resolveLink(ch, href))
+		}
+	case "img":
+		return b.embedImage(ch, el)
+	}
+	return true, ""
+}
+
+// resolveLink returns the in-book target of a link, an absolute site URL
+// for pages outside the book, or "" to drop the link
+func (b *builder) resolveLink(ch *chapter, href string) string {
+	sitePath, fragment, ok := resolveSitePath(ch.urlPath, href)
+	if !ok {
+		return href
+	}
+
+	lookup := sitePath
+	if !strings.HasSuffix(lookup, "/") && path.Ext(lookup) == "" {
+		lookup += "/"
+	}
+	if dest, ok := b.byURL[lookup]; ok {
+		target := dest.id + ".xhtml"
+		if fragment != "" {
+			target += "#" + fragment
+		}
+		return target
+	}
+
+	if b.cfg.SiteURL == "" {
+		b.warn("%s: link to %s is not in the book and was removed", ch.source, href)
+		return ""
+	}
+	if fragment != "" {
+		sitePath += "#" + fragment
+	}
+	return strings.TrimSuffix(b.cfg.SiteURL, "/") + sitePath
+}
+
+// embedImage adds a local image to the book. Remote and missing images are
+// replaced by a link or their alt text, since EPUB books must be self-contained.
+func (b *builder) embedImage(ch *chapter, el *xml.StartElement) (bool, string) {
+	src := attrValue(*el, "src")
+	alt := attrValue(*el, "alt")
+	if src == "" || strings.HasPrefix(src, "data:") {
+		return true, ""
+	}
+
+	sitePath, _, ok := resolveSitePath(ch.urlPath, src)
+	if !ok {
+		text := alt
+		if text == "" {
+			text = src
+		}
+		return false, `<a href="` + attrEscaper.Replace(src) + `">` + textEscaper.Replace(text) + "</a>"
+	}
+
+	img, ok := b.images[sitePath]
+	if !ok {
+		mediaType := imageTypes[strings.ToLower(path.Ext(sitePath))]
+		data, err := os.ReadFile(filepath.Join(b.cfg.InputDir, filepath.FromSlash(sitePath)))
+		if mediaType == "" || err != nil {
+			b.warn("%s: image %s could not be embedded", ch.source, src)
+			if alt == "" {
+				return false, ""
+			}
+			return false, "<span>" + textEscaper.Replace(alt) + "</span>"
+		}
+		img = &image{
+			id:        fmt.Sprintf("img%03d", len(b.imageOrder)+1),
+			href:      "media" + (&url.URL{Path: sitePath}).EscapedPath(),
+			mediaType: mediaType,
+			data:      data,
+		}
+		b.images[sitePath] = img
+		b.imageOrder = append(b.imageOrder, img)
+	}
+	setAttr(el, "src", "../"+img.href)
+	return true, ""
+}
+
+// resolveSitePath resolves a link on the page at base to a path on the
+// site. Fragment-only, external, mailto: and data: links aren't local.
+func resolveSitePath(base, value string) (sitePath, fragment string, ok bool) {
+	if value == "" || strings.HasPrefix(value, "#") || strings.HasPrefix(value, "//") || strings.Contains(strings.SplitN(value, "/", 2)[0], ":") {
+		return "", "", false
+	}
+
+	target := value
+	if i := strings.Index(target, "#"); i >= 0 {
+		target, fragment = target[:i], target[i+1:]
+	}
+	if i := strings.Index(target, "?"); i >= 0 {
+		target = target[:i]
+	}
+
+	if strings.HasPrefix(target, "/") {
+		sitePath = target
+	} else {
+		sitePath = path.Join(base, target)
+		if strings.HasSuffix(target, "/") || target == "." || target == ".." || target == "" {
+			sitePath += "/"
+		}
+	}
+	if unescaped, err := url.PathUnescape(sitePath); err == nil {
+		sitePath = unescaped
+	}
+	return strings.ReplaceAll(sitePath, "//", "/"), fragment, true
+}
+
+// warn records a problem that doesn't stop the book from being written
+func (b *builder) warn(format string, args ...interface{}) {
+	b.result.Warnings = append(b.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// writeArchive writes the OCF container: the mimetype, container.xml
+// pointing at the package document, then the book's files
+func (b *builder) writeArchive(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	// Readers sniff the mimetype, so it's stored uncompressed and without
+	// the extra fields a modification time would add
+	data := []byte(mimeType)
+	fw, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               "mimetype",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(data),
+		CompressedSize64:   uint64(len(data)),
+		UncompressedSize64: uint64(len(data)),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := fw.Write(data); err != nil {
+		return err
+	}
+
+	css, err := b.stylesheet()
+	if err != nil {
+		return err
+	}
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"META-INF/container.xml", []byte(containerXML)},
+		{"OEBPS/content.opf", b.packageDocument()},
+		{"OEBPS/nav.xhtml", b.navDocument()},
+		{stylesheetPath, css},
+	}
+	for _, ch := range b.chapters {
+		files = append(files, struct {
+			name string
+			data []byte
+		}{"OEBPS/text/" + ch.id + ".xhtml", b.contentDocument(ch)})
+	}
+	for _, img := range b.imageOrder {
+		name, _ := url.PathUnescape(img.href)
+		files = append(files, struct {
+			name string
+			data []byte
+		}{"OEBPS/" + name, img.data})
+	}
+
+	for _, file := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: b.cfg.Modified,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(file.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// containerXML points readers at the package document
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// packageDocument lists the book's metadata, every file in it and the
+// reading order
+func (b *builder) packageDocument() []byte {
+	esc := attrEscaper.Replace
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&sb, "<package xmlns=\"http://www.idpf.org/2007/opf\" version=\"3.0\" unique-identifier=\"book-id\" xml:lang=\"%s\">\n", esc(b.cfg.Language))
+	sb.WriteString("  <metadata xmlns:dc=\"http://purl.org/dc/elements/1.1/\">\n")
+	fmt.Fprintf(&sb, "    <dc:identifier id=\"book-id\">%s</dc:identifier>\n", b.identifier())
+	fmt.Fprintf(&sb, "    <dc:title>%s</dc:title>\n", esc(b.result.Title))
+	fmt.Fprintf(&sb, "    <dc:language>%s</dc:language>\n", esc(b.cfg.Language))
+	if b.cfg.Author != "" {
+		fmt.Fprintf(&sb, "    <dc:creator>%s</dc:creator>\n", esc(b.cfg.Author))
+	}
+	fmt.Fprintf(&sb, "    <meta property=\"dcterms:modified\">%s</meta>\n", b.cfg.Modified.UTC().Format("2006-01-02T15:04:05Z"))
+	sb.WriteString("  </metadata>\n  <manifest>\n")
+	sb.WriteString("    <item id=\"nav\" href=\"nav.xhtml\" media-type=\"application/xhtml+xml\" properties=\"nav\"/>\n")
+	sb.WriteString("    <item id=\"css\" href=\"styles/book.css\" media-type=\"text/css\"/>\n")
+	for _, ch := range b.chapters {
+		properties := ""
+		if ch.svg {
+			properties = ` properties="svg"`
+		}
+		fmt.Fprintf(&sb, "    <item id=\"%s\" href=\"text/%s.xhtml\" media-type=\"application/xhtml+xml\"%s/>\n", ch.id, ch.id, properties)
+	}
+	for _, img := range b.imageOrder {
+		fmt.Fprintf(&sb, "    <item id=\"%s\" href=\"%s\" media-type=\"%s\"/>\n", img.id, esc(img.href), img.mediaType)
+	}
+	sb.WriteString("  </manifest>\n  <spine>\n")
+	for _, ch := range b.chapters {
+		fmt.Fprintf(&sb, "    <itemref idref=\"%s\"/>\n", ch.id)
+	}
+	sb.WriteString("  </spine>\n</package>\n")
+	return []byte(sb.String())
+}
+
+// identifier derives a stable UUID from the site URL (or the title), so
+// rebuilding the book doesn't make readers treat it as a new one
+func (b *builder) identifier() string {
+	seed := b.cfg.SiteURL
+	if seed == "" {
+		seed = b.result.Title
+	}
+	sum := sha1.Sum([]byte(seed))
+	sum[6] = sum[6]&0x0f | 0x50 // Version 5 (name-based, SHA-1)
+	sum[8] = sum[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// navDocument is the book's table of contents, nested like the folder tree
+func (b *builder) navDocument() []byte {
+	var sb strings.Builder
+	title := b.messages.T("contents")
+	sb.WriteString(b.documentHead(title, ""))
+	fmt.Fprintf(&sb, "<nav epub:type=\"toc\" id=\"toc\">\n<h1>%s</h1>\n<ol>\n", textEscaper.Replace(title))
+	depth := 0
+	for i, ch := range b.chapters {
+		// Outlines only nest one level at a time
+		chDepth := ch.depth
+		if chDepth > depth+1 {
+			chDepth = depth + 1
+		}
+		if i > 0 {
+			switch {
+			case chDepth > depth:
+				sb.WriteString("\n<ol>\n")
+			case chDepth < depth:
+				for ; depth > chDepth; depth-- {
+					sb.WriteString("</li>\n</ol>\n")
+				}
+				sb.WriteString("</li>\n")
+			default:
+				sb.WriteString("</li>\n")
+			}
+		}
+		depth = chDepth
+		fmt.Fprintf(&sb, "<li><a href=\"text/%s.xhtml\">%s</a>", ch.id, textEscaper.Replace(ch.title))
+	}
+	for ; depth > 0; depth-- {
+		sb.WriteString("</li>\n</ol>\n")
+	}
+	sb.WriteString("</li>\n</ol>\n</nav>\n</body>\n</html>\n")
+	return []byte(sb.String())
+}
+
+// contentDocument wraps a chapter's XHTML in its own document
+func (b *builder) contentDocument(ch *chapter) []byte {
+	kind := "chapter"
+	if ch.source == "" {
+		kind = "part"
+	}
+	return []byte(b.documentHead(ch.title, "../") +
+		"<section epub:type=\"" + kind + "\">\n" + ch.content + "\n</section>\n</body>\n</html>\n")
+}
+
+// documentHead opens an XHTML document linking the book's stylesheet;
+// root is the path from the document back to OEBPS/
+func (b *builder) documentHead(title, root string) string {
+	lang := attrEscaper.Replace(b.cfg.Language)
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="` + lang + `" xml:lang="` + lang + `">
+<head>
+<meta charset="utf-8"/>
+<title>` + textEscaper.Replace(title) + `</title>
+<link rel="stylesheet" type="text/css" href="` + root + `styles/book.css"/>
+</head>
+<body>
+`
+}
+
+// stylesheet is a plain reading style plus the syntax highlighting classes
+// the markdown parser emits
+func (b *builder) stylesheet() ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString(bookCSS)
+	formatter := chromahtml.New(chromahtml.WithClasses(true))
+	if err := formatter.WriteCSS(&sb, styles.Get("github")); err != nil {
+		return nil, err
+	}
+	return []byte(sb.String()), nil
+}
+
+// bookCSS leaves fonts and margins to the reader and only styles what
+// plain HTML can't express: code, tables, admonitions and figures
+const bookCSS = `pre { white-space: pre-wrap; word-wrap: break-word; font-size: 0.85em; padding: 0.75em; border: 1px solid #ddd; border-radius: 4px; }
+code { font-family: monospace; }
+img { max-width: 100%; height: auto; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+blockquote { margin: 1em 0; padding-left: 1em; border-left: 3px solid #ccc; }
+.admonition { margin: 1em 0; padding: 0.5em 1em; border-left: 4px solid #888; }
+.admonition-heading { font-weight: bold; }
+.code-block { margin: 1em 0; }
+h1, h2, h3, h4 { page-break-after: avoid; break-after: avoid; }
+`
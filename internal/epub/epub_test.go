@@ -0,0 +1,285 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeSite writes files into a temp dir and returns its path
+func writeSite(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// readBook writes the book for cfg and returns its files by name
+func readBook(t *testing.T, cfg Config) (*zip.Reader, map[string]string, *Result) {
+	t.Helper()
+	var buf bytes.Buffer
+	result, err := Write(&buf, cfg)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("not a zip archive: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		files[f.Name] = string(data)
+	}
+	return zr, files, result
+}
+
+func TestWrite(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"index.md":             "# Handbook\n\n![Diagram](/images/diagram.png)\n\nRead [[guides/Intro]] first.",
+		"images/diagram.png":   "\x89PNG fake",
+		"guides/index.md":      "# Guides\n\nSee [the intro](intro/#install).",
+		"guides/intro.md":      "# Intro\n\n## Install\n\n```go\nfunc main() {}\n```\n\n[Home](/) and [Go](https://go.dev/) & more.\n\n![Remote](https://example.com/logo.png)",
+		"notes/todo.md":        "Nothing <em>yet</em><br>\n\n[gone](/missing/)",
+		"_header.md":           "Site header",
+		"notes/missing-img.md": "# Missing\n\n![Lost](lost.png)",
+	})
+
+	zr, files, result := readBook(t, Config{
+		InputDir: dir,
+		Author:   "Docs Team",
+		Modified: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+
+	// The mimetype comes first, stored and without extra fields
+	first := zr.File[0]
+	if first.Name != "mimetype" || first.Method != zip.Store || len(first.Extra) != 0 {
+		t.Errorf("first entry = %q (method %d, %d extra bytes), want stored mimetype", first.Name, first.Method, len(first.Extra))
+	}
+	if files["mimetype"] != "application/epub+zip" {
+		t.Errorf("mimetype = %q", files["mimetype"])
+	}
+
+	// Every XML file is well-formed
+	for name, content := range files {
+		if !strings.HasSuffix(name, ".xhtml") && !strings.HasSuffix(name, ".opf") && !strings.HasSuffix(name, ".xml") {
+			continue
+		}
+		d := xml.NewDecoder(strings.NewReader(content))
+		for {
+			_, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s is not well-formed: %v\n%s", name, err, content)
+			}
+		}
+	}
+
+	if result.Title != "Handbook" {
+		t.Errorf("Title = %q, want the home page title", result.Title)
+	}
+	if result.Chapters != 5 || result.Images != 1 {
+		t.Errorf("Chapters = %d, Images = %d, want 5 and 1", result.Chapters, result.Images)
+	}
+
+	opf := files["OEBPS/content.opf"]
+	for _, want := range []string{
+		`<dc:title>Handbook</dc:title>`,
+		`<dc:creator>Docs Team</dc:creator>`,
+		`<dc:language>en</dc:language>`,
+		`<meta property="dcterms:modified">2026-01-02T03:04:05Z</meta>`,
+		`properties="nav"`,
+		`<item id="img001" href="media/images/diagram.png" media-type="image/png"/>`,
+		// Sidebar order: home, guides with its pages, then the notes part
+		"<itemref idref=\"ch001\"/>\n    <itemref idref=\"ch002\"/>\n    <itemref idref=\"ch003\"/>",
+	} {
+		if !strings.Contains(opf, want) {
+			t.Errorf("content.opf should contain %q\n%s", want, opf)
+		}
+	}
+	if !strings.Contains(opf, "urn:uuid:") {
+		t.Error("content.opf should have a UUID identifier")
+	}
+
+	nav := files["OEBPS/nav.xhtml"]
+	for _, want := range []string{
+		`<nav epub:type="toc" id="toc">`,
+		"<li><a href=\"text/ch002.xhtml\">Guides</a>\n<ol>\n<li><a href=\"text/ch003.xhtml\">Intro</a></li>\n</ol>\n</li>",
+		`<li><a href="text/ch004.xhtml">Notes</a>`,
+	} {
+		if !strings.Contains(nav, want) {
+			t.Errorf("nav.xhtml should contain %q\n%s", want, nav)
+		}
+	}
+
+	home := files["OEBPS/text/ch001.xhtml"]
+	for _, want := range []string{
+		`<img src="../media/images/diagram.png" alt="Diagram"/>`,
+		`<a href="ch003.xhtml">Intro</a>`,
+		`<link rel="stylesheet" type="text/css" href="../styles/book.css"/>`,
+	} {
+		if !strings.Contains(home, want) {
+			t.Errorf("home chapter should contain %q\n%s", want, home)
+		}
+	}
+	if !strings.Contains(files["OEBPS/text/ch002.xhtml"], `<a href="ch003.xhtml#install">the intro</a>`) {
+		t.Error("relative links with fragments should point at the chapter")
+	}
+
+	intro := files["OEBPS/text/ch003.xhtml"]
+	for _, want := range []string{
+		`<h2 id="install">Install</h2>`,
+		`class="chroma"`,
+		`<a href="ch001.xhtml">Home</a>`,
+		`<a href="https://go.dev/" rel="noopener noreferrer">Go</a> &amp; more`,
+		`<a href="https://example.com/logo.png">Remote</a>`,
+	} {
+		if !strings.Contains(intro, want) {
+			t.Errorf("intro chapter should contain %q\n%s", want, intro)
+		}
+	}
+	for _, unwanted := range []string{"heading-anchor", "copy-button", "external-icon", "target=", "loading=", "opens in new tab"} {
+		if strings.Contains(intro, unwanted) {
+			t.Errorf("intro chapter should not contain %q", unwanted)
+		}
+	}
+
+	// A folder without an index gets a title page; pages without an H1 get their title
+	if !strings.Contains(files["OEBPS/text/ch004.xhtml"], `<section epub:type="part">`) {
+		t.Error("folder without index should become a part")
+	}
+	todo := files["OEBPS/text/ch006.xhtml"]
+	if !strings.Contains(todo, "<h1>Todo</h1>") || !strings.Contains(todo, "<br/>") || !strings.Contains(todo, "<a>gone</a>") {
+		t.Errorf("todo chapter = %s", todo)
+	}
+
+	if files["OEBPS/media/images/diagram.png"] != "\x89PNG fake" {
+		t.Error("local image should be embedded")
+	}
+	if !strings.Contains(files["OEBPS/styles/book.css"], ".chroma") {
+		t.Error("stylesheet should include syntax highlighting")
+	}
+	for name, content := range files {
+		if strings.Contains(content, "Site header") {
+			t.Errorf("%s should not include the site header", name)
+		}
+	}
+
+	if len(result.Warnings) != 2 {
+		t.Errorf("Warnings = %v, want the missing link and image", result.Warnings)
+	}
+}
+
+func TestWriteSiteURLAndTitle(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"guide.md": "# Guide\n\n[API](/api/reference/#auth)",
+	})
+
+	_, files, result := readBook(t, Config{InputDir: dir, Title: "My Book", SiteURL: "https://docs.example.com/", Language: "fr"})
+	if result.Title != "My Book" || len(result.Warnings) != 0 {
+		t.Errorf("Title = %q, Warnings = %v", result.Title, result.Warnings)
+	}
+	if !strings.Contains(files["OEBPS/text/ch001.xhtml"], `href="https://docs.example.com/api/reference/#auth"`) {
+		t.Error("links outside the book should point at the site")
+	}
+	if !strings.Contains(files["OEBPS/content.opf"], `xml:lang="fr"`) {
+		t.Error("language should be set on the package")
+	}
+	if nav := files["OEBPS/nav.xhtml"]; !strings.Contains(nav, "<title>Sommaire</title>") || !strings.Contains(nav, "<h1>Sommaire</h1>") {
+		t.Errorf("table of contents should be in the book's language, got:\n%s", nav)
+	}
+
+	// The identifier is stable across builds
+	_, again, _ := readBook(t, Config{InputDir: dir, Title: "Other", SiteURL: "https://docs.example.com/"})
+	id := func(opf string) string {
+		start := strings.Index(opf, "urn:uuid:")
+		return opf[start : start+45]
+	}
+	if id(files["OEBPS/content.opf"]) != id(again["OEBPS/content.opf"]) {
+		t.Error("identifier should depend only on the site URL")
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := Write(&buf, Config{InputDir: t.TempDir()}); err == nil {
+		t.Error("Write() should fail without pages")
+	}
+	if _, err := Write(&buf, Config{InputDir: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("Write() should fail for a missing folder")
+	}
+}
+
+func TestToXHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+		svg  bool
+	}{
+		{"void elements", `<p>a<br>b<hr></p><img src="x.png">`, `<p>a<br/>b<hr/></p><img src="x.png"/>`, false},
+		{"entities", `<p>&nbsp;&copy; &amp; &lt;tag&gt; AT&T</p>`, "<p> © &amp; &lt;tag&gt; AT&amp;T</p>", false},
+		{"unquoted and bare attributes", `<input type=checkbox checked disabled>`, `<input type="checkbox" checked="checked" disabled="disabled"/>`, false},
+		{"dropped elements", `<p>x</p><script>alert(1)</script><button class="copy-button"><svg><path/></svg></button>`, `<p>x</p>`, false},
+		{"inline svg gets its namespace", `<svg viewBox="0 0 1 1"><rect/></svg>`, `<svg viewBox="0 0 1 1" xmlns="http://www.w3.org/2000/svg"><rect></rect></svg>`, true},
+		{"unclosed elements", `<ul><li>one<li>two</ul>`, `<ul><li>one<li>two</li></li></ul>`, false},
+		{"comments", `<!-- note --><p tabindex="0">x</p>`, `<p>x</p>`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, svg, err := toXHTML(tt.html, nil)
+			if err != nil {
+				t.Fatalf("toXHTML() error = %v", err)
+			}
+			if got != tt.want || svg != tt.svg {
+				t.Errorf("toXHTML() = %q (svg %v), want %q (svg %v)", got, svg, tt.want, tt.svg)
+			}
+		})
+	}
+
+	if _, _, err := toXHTML("<p>x</p></div></div>", nil); err == nil {
+		t.Error("toXHTML() should fail on stray end tags")
+	}
+}
+
+func TestResolveSitePath(t *testing.T) {
+	tests := []struct {
+		base, value    string
+		path, fragment string
+		ok             bool
+	}{
+		{"/guides/intro/", "../setup/", "/guides/setup/", "", true},
+		{"/guides/intro/", "diagram%20one.png", "/guides/intro/diagram one.png", "", true},
+		{"/", "/api/?v=2#auth", "/api/", "auth", true},
+		{"/", "#top", "", "", false},
+		{"/", "mailto:me@example.com", "", "", false},
+		{"/", "//cdn.example.com/x.png", "", "", false},
+	}
+	for _, tt := range tests {
+		path, fragment, ok := resolveSitePath(tt.base, tt.value)
+		if path != tt.path || fragment != tt.fragment || ok != tt.ok {
+			t.Errorf("resolveSitePath(%q, %q) = %q, %q, %v", tt.base, tt.value, path, fragment, ok)
+		}
+	}
+}
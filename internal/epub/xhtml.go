@@ -0,0 +1,226 @@
+package epub
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// voidElements never have content, so they are written self-closed
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "param": true,
+	"source": true, "track": true, "wbr": true,
+}
+
+// autoClose lets the decoder accept void elements written without a slash
+var autoClose = func() []string {
+	names := make([]string, 0, len(voidElements))
+	for name := range voidElements {
+		names = append(names, name)
+	}
+	return names
+}()
+
+// droppedClasses are site controls and decorations that mean nothing in an
+// ebook: heading permalinks, copy buttons and icons
+var droppedClasses = []string{"heading-anchor", "copy-button", "external-icon", "admonition-icon"}
+
+// droppedElements can't run or don't belong in a content document
+var droppedElements = map[string]bool{"script": true, "noscript": true, "iframe": true}
+
+// droppedAttrs are browser hints that EPUB content documents don't allow
+var droppedAttrs = map[string]bool{"target": true, "loading": true, "srcset": true, "tabindex": true}
+
+// foreignNamespaces are the namespaces inline SVG and MathML must declare in XHTML
+var foreignNamespaces = map[string]string{
+	"svg":  "http://www.w3.org/2000/svg",
+	"math": "http://www.w3.org/1998/Math/MathML",
+}
+
+// Escapers for XML text and attribute values
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+)
+
+// newTabNote is the screen-reader hint added to external links
+const newTabNote = `<span class="sr-only">(opens in new tab)</span>`
+
+// rewriteFunc may change an element's attributes in place. It reports
+// whether to keep the element; a dropped void element is replaced by
+// replacement, a dropped element with content is removed with it.
+type rewriteFunc func(el *xml.StartElement) (keep bool, replacement string)
+
+// toXHTML converts rendered page HTML into well-formed XHTML. Site-only
+// controls are removed, void elements are self-closed, entities are
+// resolved and rewrite gets a chance to change every element.
+func toXHTML(html string, rewrite rewriteFunc) (string, bool, error) {
+	html = strings.ReplaceAll(html, newTabNote, "")
+
+	d := xml.NewDecoder(strings.NewReader("<body>" + html + "</body>"))
+	d.Strict = false
+	d.AutoClose = autoClose
+	d.Entity = xml.HTMLEntity
+
+	var sb strings.Builder
+	var open []string // Names of elements written so far, "" for self-closed ones
+	hasSVG := false
+	skipDepth := 0 // Depth inside a dropped element
+
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", false, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			if skipDepth > 0 {
+				skipDepth++
+				continue
+			}
+			if len(open) == 0 && name == "body" {
+				open = append(open, "")
+				continue
+			}
+			if droppedElements[name] || hasDroppedClass(t) {
+				skipDepth = 1
+				continue
+			}
+			keep, replacement := true, ""
+			if rewrite != nil {
+				keep, replacement = rewrite(&t)
+			}
+			if !keep {
+				if voidElements[name] {
+					sb.WriteString(replacement)
+					open = append(open, "")
+				} else {
+					skipDepth = 1
+				}
+				continue
+			}
+			if name == "svg" {
+				hasSVG = true
+			}
+			writeStart(&sb, t)
+			if voidElements[name] {
+				sb.WriteString("/>")
+				open = append(open, "")
+			} else {
+				sb.WriteString(">")
+				open = append(open, name)
+			}
+
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+			if len(open) == 0 {
+				continue
+			}
+			name := open[len(open)-1]
+			open = open[:len(open)-1]
+			if name != "" {
+				sb.WriteString("</" + name + ">")
+			}
+
+		case xml.CharData:
+			if skipDepth == 0 {
+				sb.WriteString(textEscaper.Replace(string(t)))
+			}
+		}
+		// Comments, directives and processing instructions are dropped
+	}
+
+	return sb.String(), hasSVG, nil
+}
+
+// writeStart writes an element's start tag without its closing bracket
+func writeStart(sb *strings.Builder, el xml.StartElement) {
+	name := el.Name.Local
+	sb.WriteString("<" + name)
+
+	hasXMLNS := false
+	for _, attr := range el.Attr {
+		attrName := attrQName(attr.Name)
+		if attrName == "" || droppedAttrs[attrName] {
+			continue
+		}
+		if attrName == "xmlns" {
+			hasXMLNS = true
+		}
+		sb.WriteString(" " + attrName + `="` + attrEscaper.Replace(attr.Value) + `"`)
+	}
+	if ns, ok := foreignNamespaces[name]; ok && !hasXMLNS {
+		sb.WriteString(` xmlns="` + ns + `"`)
+	}
+}
+
+// attrQName restores the prefix the decoder resolved into a namespace.
+// Attributes in namespaces XHTML doesn't know are dropped.
+func attrQName(name xml.Name) string {
+	switch name.Space {
+	case "":
+		return name.Local
+	case "xmlns":
+		return "xmlns:" + name.Local
+	case "xlink", "http://www.w3.org/1999/xlink":
+		return "xlink:" + name.Local
+	case "xml", "http://www.w3.org/XML/1998/namespace":
+		return "xml:" + name.Local
+	case "epub", "http://www.idpf.org/2007/ops":
+		return "epub:" + name.Local
+	}
+	return ""
+}
+
+// hasDroppedClass reports whether el carries one of droppedClasses
+func hasDroppedClass(el xml.StartElement) bool {
+	class := attrValue(el, "class")
+	if class == "" {
+		return false
+	}
+	for _, c := range strings.Fields(class) {
+		for _, dropped := range droppedClasses {
+			if c == dropped {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// attrValue returns the value of an un-namespaced attribute
+func attrValue(el xml.StartElement, name string) string {
+	for _, attr := range el.Attr {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// setAttr changes or removes (when value is "") an un-namespaced attribute
+func setAttr(el *xml.StartElement, name, value string) {
+	for i, attr := range el.Attr {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			if value == "" {
+				el.Attr = append(el.Attr[:i], el.Attr[i+1:]...)
+			} else {
+				el.Attr[i].Value = value
+			}
+			return
+		}
+	}
+	if value != "" {
+		el.Attr = append(el.Attr, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+	}
+}
@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"path/filepath"
+
+	"github.com/wusher/volcano/internal/archive"
+	"github.com/wusher/volcano/internal/book"
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// buildArchives builds the date archives of the site's folders that have
+// archives turned on, leaving out those without dated pages
+func (g *Generator) buildArchives(site *tree.Site) []*archive.Archive {
+	folders := archive.Collect(site.Root, func(folder *tree.Node) bool {
+		return config.ResolveFolderConfig(g.config.Folders, filepath.Join(folder.Path, "index.md")).Archive
+	})
+	var archives []*archive.Archive
+	for _, folder := range folders {
+		if a := archive.Build(folder, site.AllPages, g.config.SiteURL); !a.Empty() {
+			archives = append(archives, a)
+		}
+	}
+	return archives
+}
+
+// archiveDate returns a page's date and the link to its month archive, or
+// empty strings if the page isn't in an archive
+func (g *Generator) archiveDate(node *tree.Node) (string, string) {
+	for _, a := range g.archives {
+		if url := a.MonthURL(node); url != "" {
+			return tree.NodeDate(node).Format("2006-01-02"), url
+		}
+	}
+	return "", ""
+}
+
+// generateArchives writes the archive pages of the language being generated.
+// Pages and folders keep their URLs: an archive page that would replace one
+// is skipped with a warning. Returns the URL paths written.
+func (g *Generator) generateArchives(site *tree.Site) ([]string, error) {
+	taken := make(map[string]bool)
+	for _, page := range site.AllPages {
+		taken[tree.GetURLPath(page)] = true
+	}
+	for _, folder := range append([]*tree.Node{site.Root}, book.Folders(site.Root)...) {
+		taken[tree.GetURLPath(folder)] = true
+	}
+	for _, l := range g.listings {
+		for _, urlPath := range l.URLs() {
+			taken[urlPath] = true
+		}
+	}
+
+	var urls []string
+	for _, a := range g.archives {
+		for _, page := range a.Pages(g.lang.messages) {
+			if taken[page.URLPath] {
+				g.logger.Warning("Skipping archive page %s: a page already uses that URL", page.URLPath)
+				continue
+			}
+			if err := g.writeFolderPage(a.Folder, site.Root, page.Title, page.URLPath, page.Content); err != nil {
+				return nil, err
+			}
+			taken[page.URLPath] = true
+			urls = append(urls, page.URLPath)
+		}
+	}
+	return urls, nil
+}
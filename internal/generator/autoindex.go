@@ -1,10 +1,15 @@
 package generator
 
 import (
+	"fmt"
+	"html/template"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/wusher/volcano/internal/autoindex"
+	"github.com/wusher/volcano/internal/config"
 	"github.com/wusher/volcano/internal/navigation"
 	"github.com/wusher/volcano/internal/seo"
 	"github.com/wusher/volcano/internal/templates"
@@ -14,46 +19,65 @@ import (
 // generateAutoIndex generates an auto-index page for a folder without an index.md
 func (g *Generator) generateAutoIndex(node *tree.Node, root *tree.Node) error {
 	index := autoindex.BuildWithBaseURL(node, g.config.SiteURL)
-	fullOutputPath := filepath.Join(g.config.OutputDir, index.OutputPath)
+	return g.writeFolderPage(node, root, index.Title, index.URLPath, autoindex.RenderContent(index))
+}
+
+// writeFolderPage writes a generated page for a folder, such as its
+// auto-index or a listing page, at urlPath
+func (g *Generator) writeFolderPage(node *tree.Node, root *tree.Node, title, urlPath string, htmlContent template.HTML) error {
+	fullOutputPath := filepath.Join(g.config.OutputDir, strings.TrimPrefix(urlPath, "/"), "index.html")
 
-	// Build content
-	htmlContent := autoindex.RenderContent(index)
+	// Generated folder pages have no front matter, so they use the folder
+	// default layout. Unlike pages, they skip the theme's default: they list
+	// pages to navigate to, so the presentation theme doesn't turn them into decks.
+	layout := templates.ResolveLayout(nil, config.ResolveFolderConfig(g.config.Folders, filepath.Join(node.Path, "index.md")).Layout)
+	if err := g.renderer.ValidateLayout(layout.Name); err != nil {
+		return fmt.Errorf("%s: %w", node.Path, err)
+	}
 
 	// Build breadcrumbs (with base URL prefixing)
 	breadcrumbs := navigation.BuildBreadcrumbsWithBaseURL(node, g.config.Title, g.config.SiteURL)
 	breadcrumbsHTML := navigation.RenderBreadcrumbs(breadcrumbs)
 
 	// Generate SEO meta tags
-	seoConfig := seo.Config{
-		SiteURL:   g.config.SiteURL,
-		SiteTitle: g.config.Title,
-		Author:    g.config.Author,
-		OGImage:   g.ogImageURL, // Use processed URL, not raw path
+	ix, err := g.indexingOf(node, true, urlPath, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", node.Path, err)
+	}
+	pageMeta := seo.GeneratePageMeta(title, string(htmlContent), urlPath, g.seoConfig(node, title, nil, ix))
+	if pageMeta.StructuredData, err = g.structuredData(node, true, nil, pageMeta); err != nil {
+		return fmt.Errorf("%s: %w", node.Path, err)
 	}
-	pageMeta := seo.GeneratePageMeta(index.Title, string(htmlContent), index.URLPath, seoConfig)
+	g.addToSitemap(ix, time.Time{})
 	metaTagsHTML := seo.RenderMetaTags(pageMeta)
 
 	// Render navigation (with base URL prefixing)
-	nav := templates.RenderNavigationWithBaseURL(root, index.URLPath, g.config.SiteURL)
+	nav := templates.RenderNavigationWithBaseURL(root, tree.GetURLPath(node), g.config.SiteURL)
 
 	// Prepare template data
 	data := templates.PageData{
 		SiteTitle:       g.config.Title,
-		PageTitle:       index.Title,
+		PageTitle:       title,
 		Content:         htmlContent,
 		Navigation:      nav,
-		CurrentPath:     index.URLPath,
+		CurrentPath:     urlPath,
 		Breadcrumbs:     breadcrumbsHTML,
 		MetaTags:        metaTagsHTML,
 		ShowSearch:      true,
 		BaseURL:         g.baseURL,
 		CSSURL:          g.cssURL,
+		FontPreloads:    g.fonts.RenderPreloadLinks(),
 		JSURL:           g.jsURL,
 		CSS:             g.inlineCSS(),
 		InstantNavJS:    g.instantNavJS,
 		ViewTransitions: g.viewTransitions,
 		PWAEnabled:      g.pwaEnabled,
+		GraphEnabled:    g.config.Graph,
 	}
+	g.lang.includes.Apply(&data)
+	g.applyLanguage(&data, urlPath)
+	g.applyVersion(&data, urlPath)
+	layout.Apply(&data)
 
 	// Create output directory
 	outputDir := filepath.Dir(fullOutputPath)
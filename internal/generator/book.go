@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wusher/volcano/internal/book"
+	"github.com/wusher/volcano/internal/templates"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// bookMetaTags keeps books, which repeat every page's content, out of search engines
+const bookMetaTags = template.HTML(`<meta name="robots" content="noindex">`)
+
+// generateBooks writes print.html for the whole site and print/ for each
+// folder, built from the pages already rendered. Returns the book URLs.
+func (g *Generator) generateBooks(site *tree.Site) ([]string, error) {
+	pages := make(map[string]generatedPage, len(g.generatedPages))
+	for _, page := range g.generatedPages {
+		pages[page.urlPath] = page
+	}
+	render := func(node *tree.Node) (string, string, error) {
+		page, ok := pages[tree.GetURLPath(node)]
+		if !ok {
+			return "", "", fmt.Errorf("page was not generated")
+		}
+		return page.title, page.htmlContent, nil
+	}
+
+	// Pages and folders (whose auto-index may live there) keep their URLs
+	taken := make(map[string]bool, len(pages))
+	for urlPath := range pages {
+		taken[urlPath] = true
+	}
+	folders := book.Folders(site.Root)
+	for _, folder := range folders {
+		taken["/"+tree.SlugifyPath(folder.Path)+"/"] = true
+	}
+
+	var urls []string
+	for _, folder := range append([]*tree.Node{site.Root}, folders...) {
+		urlPath := book.URLPath(folder)
+		if taken[urlPath] {
+			g.logger.Warning("Skipping printable book %s: a page already uses that URL", urlPath)
+			continue
+		}
+
+		b, err := book.Build(folder, site.AllPages, g.baseURL, g.lang.messages, render)
+		if err != nil {
+			return nil, err
+		}
+		if b.Chapters == 0 {
+			continue
+		}
+		title := b.Title
+		if title == "" {
+			title = g.config.Title
+		}
+
+		data := templates.PageData{
+			SiteTitle:    g.config.Title,
+			PageTitle:    title,
+			Content:      b.Content,
+			TOC:          b.TOC,
+			CurrentPath:  urlPath,
+			MetaTags:     bookMetaTags,
+			FaviconLinks: g.faviconLinks,
+			BaseURL:      g.baseURL,
+			CSSURL:       g.cssURL,
+			FontPreloads: g.fonts.RenderPreloadLinks(),
+			CSS:          g.inlineCSS(),
+		}
+		g.applyLanguage(&data, "")
+		templates.ResolveLayout(nil, templates.LayoutPrint).Apply(&data)
+
+		outputPath := strings.TrimPrefix(urlPath, "/")
+		if strings.HasSuffix(outputPath, "/") {
+			outputPath += "index.html"
+		}
+		fullPath := filepath.Join(g.config.OutputDir, filepath.FromSlash(outputPath))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return nil, err
+		}
+		f, err := os.Create(fullPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", fullPath, err)
+		}
+		err = g.renderer.Render(f, data)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", urlPath, err)
+		}
+
+		g.logger.Verbose("  Book: %s (%d pages)", urlPath, b.Chapters)
+		urls = append(urls, urlPath)
+	}
+	return urls, nil
+}
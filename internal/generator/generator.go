@@ -8,21 +8,31 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/wusher/volcano/internal/archive"
 	"github.com/wusher/volcano/internal/assets"
 	"github.com/wusher/volcano/internal/autoindex"
+	"github.com/wusher/volcano/internal/config"
 	"github.com/wusher/volcano/internal/content"
+	"github.com/wusher/volcano/internal/graph"
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/includes"
 	"github.com/wusher/volcano/internal/instant"
+	"github.com/wusher/volcano/internal/listing"
 	"github.com/wusher/volcano/internal/markdown"
+	"github.com/wusher/volcano/internal/menu"
 	"github.com/wusher/volcano/internal/navigation"
 	"github.com/wusher/volcano/internal/output"
 	"github.com/wusher/volcano/internal/pwa"
+	"github.com/wusher/volcano/internal/related"
 	"github.com/wusher/volcano/internal/search"
 	"github.com/wusher/volcano/internal/seo"
 	"github.com/wusher/volcano/internal/styles"
 	"github.com/wusher/volcano/internal/templates"
 	"github.com/wusher/volcano/internal/toc"
 	"github.com/wusher/volcano/internal/tree"
+	"github.com/wusher/volcano/internal/versions"
 )
 
 // Config holds configuration for the generator
@@ -37,19 +47,40 @@ type Config struct {
 	SiteURL          string // Base URL for canonical links
 	Author           string // Site author
 	OGImage          string // Path to local OG image file (copied to output)
+	OGImages         bool   // Generate an Open Graph image for each page
 	FaviconPath      string // Path to favicon file
 	TopNav           bool   // Display root files in top navigation bar
 	ShowPageNav      bool   // Show previous/next page navigation
+	PageNavSections  bool   // Keep prev/next links within top-level folders
+	Related          int    // Number of related pages shown below each page (0: none)
+	Graph            bool   // Write graph.json and the /graph/ page, and show a local graph below each page
 	ShowBreadcrumbs  bool   // Show breadcrumb navigation
 	Theme            string // Theme name (docs, blog, vanilla)
 	CSSPath          string // Path to custom CSS file
+	LayoutsDir       string // Directory with template overrides (default: <input>/_layouts)
 	AccentColor      string // Custom accent color in hex format (e.g., "#ff6600")
+	AccentColorDark  string // Dark-mode accent color (derived from AccentColor if empty)
 	InstantNav       bool   // Enable instant navigation with hover prefetching
 	ViewTransitions  bool   // Enable browser view transitions API
 	InlineAssets     bool   // Embed CSS/JS inline instead of external files
 	PWA              bool   // Enable PWA manifest and service worker generation
 	Search           bool   // Enable search index generation
+	SearchShardSize  int    // Target size of each search index shard in KB (0: search.DefaultShardSize)
+	Print            bool   // Generate printable books (print.html and <folder>/print/)
 	AllowBrokenLinks bool   // Don't fail build on broken internal links
+
+	Folders map[string]config.FolderConfig // Per-folder settings, keyed by folder path
+	Fonts   *config.FontsConfig            // Self-hosted fonts for body, headings and code
+	Menus   map[string][]config.MenuItem   // Navigation menus; "main" replaces the automatic top nav
+
+	Includes includes.Paths // Header, footer and banner overrides (defaults: _header.md etc.)
+
+	Language     string                       // Default language code (default: en)
+	Languages    []string                     // Translation languages, built from page.<lang>.md files into /<lang>/
+	Translations map[string]map[string]string // Interface text overrides, keyed by language then message
+
+	Versions *versions.Set // Every version of a versioned site (nil if the site isn't versioned)
+	Version  string        // URL prefix of the version being built ("v2", or versions.LatestPrefix)
 }
 
 // Result holds the result of generation
@@ -60,10 +91,21 @@ type Result struct {
 
 // generatedPage tracks a page and its content for link validation
 type generatedPage struct {
+	node        *tree.Node
 	urlPath     string
+	title       string
 	sourceFile  string
+	sourceDir   string // Directory wiki links resolve from
 	mdContent   string
 	htmlContent string
+	tags        []string
+}
+
+// pendingPage is a rendered page waiting to be written
+type pendingPage struct {
+	outputPath string
+	data       templates.PageData
+	page       related.Page
 }
 
 // Generator handles static site generation
@@ -75,25 +117,54 @@ type Generator struct {
 	faviconLinks    template.HTML
 	ogImageURL      string // Processed OG image URL (absolute if BaseURL provided)
 	topNavItems     []templates.TopNavItem
-	generatedPages  []generatedPage // Track pages for link validation
-	baseURL         string          // Base URL path prefix extracted from SiteURL
-	instantNavJS    template.JS     // Instant navigation JavaScript (if enabled)
-	viewTransitions bool            // Enable browser view transitions API
-	cssURL          string          // External CSS file URL (hashed)
-	jsURL           string          // External JS file URL (hashed)
-	css             string          // CSS content (for writing to file)
-	pwaEnabled      bool            // Whether PWA support is enabled
-	searchEnabled   bool            // Whether search is enabled
-	searchIndex     *search.Index   // Search index data
+	pager           *navigation.Pager           // Prev/next links of the language being generated
+	listings        map[string]*listing.Listing // Listings of the language being generated, by folder path
+	archives        []*archive.Archive          // Date archives of the language being generated
+	pending         []pendingPage               // Pages of the language being generated, not yet written
+	generatedPages  []generatedPage             // Track pages for link validation
+	baseURL         string                      // Base URL path prefix extracted from SiteURL
+	instantNavJS    template.JS                 // Instant navigation JavaScript (if enabled)
+	viewTransitions bool                        // Enable browser view transitions API
+	cssURL          string                      // External CSS file URL (hashed)
+	jsURL           string                      // External JS file URL (hashed)
+	css             string                      // CSS content (for writing to file)
+	pwaEnabled      bool                        // Whether PWA support is enabled
+	searchEnabled   bool                        // Whether search is enabled
+	searchIndex     *search.Index               // Search index data
+	searchFiles     []string                    // Search index manifest and shards written, for the PWA precache
+	sitemap         []seo.SitemapEntry          // Pages listed in sitemap.xml
+	disallow        []string                    // Paths robots.txt disallows
+	allow           []string                    // Paths within disallowed ones robots.txt allows
+	fonts           *assets.Fonts               // Self-hosted fonts (nil if none configured)
+	languages       []language                  // Default language first, then translations
+	lang            language                    // Language of the pages being generated
+	translations    *i18n.Translations
 }
 
 // New creates a new Generator
 func New(config Config, writer io.Writer) (*Generator, error) {
+	// Extract base URL path from SiteURL for prefixing all links
+	baseURL := tree.PrefixURL(config.SiteURL, "/")
+	if baseURL == "/" {
+		baseURL = ""
+	} else {
+		// Remove trailing slash from base URL path
+		baseURL = baseURL[:len(baseURL)-1]
+	}
+
+	// Load self-hosted fonts first: their @font-face rules go into the CSS
+	fonts, err := assets.LoadFonts(config.Fonts, config.InputDir, baseURL)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get CSS content using the shared CSSLoader
 	cssConfig := styles.CSSConfig{
-		Theme:       config.Theme,
-		CSSPath:     config.CSSPath,
-		AccentColor: config.AccentColor,
+		Theme:           config.Theme,
+		CSSPath:         config.CSSPath,
+		AccentColor:     config.AccentColor,
+		AccentColorDark: config.AccentColorDark,
+		FontCSS:         fonts.StyleSheet(),
 	}
 	cssLoader := styles.NewCSSLoader(cssConfig, os.ReadFile)
 	css, err := cssLoader.LoadCSS()
@@ -102,20 +173,15 @@ func New(config Config, writer io.Writer) (*Generator, error) {
 	}
 
 	// Create renderer (CSS will be passed per-page or via external file)
-	renderer, err := templates.NewRenderer("")
+	layoutsDir := config.LayoutsDir
+	if layoutsDir == "" {
+		layoutsDir = templates.DiscoverLayoutsDir(config.InputDir)
+	}
+	renderer, err := templates.NewRendererWithLayouts("", layoutsDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create renderer: %w", err)
 	}
 
-	// Extract base URL path from SiteURL for prefixing all links
-	baseURL := tree.PrefixURL(config.SiteURL, "/")
-	if baseURL == "/" {
-		baseURL = ""
-	} else {
-		// Remove trailing slash from base URL path
-		baseURL = baseURL[:len(baseURL)-1]
-	}
-
 	gen := &Generator{
 		config:          config,
 		renderer:        renderer,
@@ -126,8 +192,17 @@ func New(config Config, writer io.Writer) (*Generator, error) {
 		css:             css,
 		pwaEnabled:      config.PWA,
 		searchEnabled:   config.Search,
+		fonts:           fonts,
 	}
 
+	// Render the site-wide header, footer and banner once per language;
+	// they're the same on every page
+	gen.languages, err = gen.loadLanguages()
+	if err != nil {
+		return nil, err
+	}
+	gen.lang = gen.languages[0]
+
 	// Initialize search index if enabled
 	if config.Search {
 		gen.searchIndex = &search.Index{Pages: []search.PageEntry{}}
@@ -182,12 +257,13 @@ func (g *Generator) Generate() (*Result, error) {
 		}
 	}
 
-	// Step 2: Scan input directory
+	// Step 2: Scan input directory, once per language
 	g.logger.Println("Scanning input directory...")
-	site, err := tree.Scan(g.config.InputDir)
+	sites, err := g.scanLanguages()
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan input directory: %w", err)
 	}
+	site := sites[0].site
 
 	if len(site.AllPages) == 0 {
 		g.logger.Warning("No markdown files found in %s", g.config.InputDir)
@@ -203,47 +279,128 @@ func (g *Generator) Generate() (*Result, error) {
 		}
 	}
 
+	// Font files are always separate files, even with inline CSS
+	if g.fonts != nil {
+		if err := g.fonts.Write(g.config.OutputDir, g.baseURL); err != nil {
+			return nil, err
+		}
+		for _, font := range g.fonts.Files {
+			g.logger.Verbose("  Font: %s", font.FileName)
+		}
+	}
+
 	// Count folders
 	folderCount := countFolders(site.Root)
 	g.logger.Println("Found %d markdown files in %d folders", len(site.AllPages), folderCount)
+	if len(sites) > 1 {
+		g.logger.Println("Languages: %s", languageSummary(sites))
+	}
 	g.logger.Println("")
 
-	// Build top nav items if enabled (with base URL prefixing)
-	g.topNavItems = templates.BuildTopNavItemsWithBaseURL(site.Root, g.config.TopNav, g.config.SiteURL)
-	if len(g.topNavItems) > 0 {
-		g.logger.Verbose("Using top navigation bar with %d items", len(g.topNavItems))
+	// Steps 3-4b run once per language; each translation is a separate
+	// tree under /<lang>/ with its own navigation and interface text
+	mainMenu, err := g.buildMainMenu(site)
+	if err != nil {
+		return nil, err
 	}
 
-	// Step 3: Generate pages
+	var allPages, foldersNeedingIndex []*tree.Node
+	var bookURLs, listingURLs, archiveURLs []string
 	g.logger.Println("Generating pages...")
-	for _, node := range site.AllPages {
-		if err := g.generatePage(node, site.Root, site.AllPages); err != nil {
-			return nil, fmt.Errorf("failed to generate %s: %w", node.Path, err)
+	for _, s := range sites {
+		g.lang = s.language
+		allPages = append(allPages, s.site.AllPages...)
+
+		// Build top nav items from the main menu, or from the root items
+		// if enabled (with base URL prefixing)
+		if mainMenu != nil {
+			g.topNavItems = menu.Localize(mainMenu, s.site, g.translations.Prefix(s.code), g.config.SiteURL)
+		} else {
+			g.topNavItems = templates.BuildTopNavItemsWithBaseURL(s.site.Root, g.config.TopNav, g.config.SiteURL)
+		}
+		if len(g.topNavItems) > 0 {
+			g.logger.Verbose("Using top navigation bar with %d items", len(g.topNavItems))
 		}
-		result.PagesGenerated++
-		g.logger.FileSuccess(node.Path)
-	}
 
-	// Step 4: Generate auto-index pages for folders without index.md
-	foldersNeedingIndex := autoindex.CollectFoldersNeedingAutoIndex(site.Root)
-	if len(foldersNeedingIndex) > 0 {
-		g.logger.Verbose("Generating auto-index pages for %d folders...", len(foldersNeedingIndex))
-		for _, folder := range foldersNeedingIndex {
-			if err := g.generateAutoIndex(folder, site.Root); err != nil {
-				return nil, fmt.Errorf("failed to generate auto-index for %s: %w", folder.Path, err)
+		// Step 3: Generate pages
+		g.pager = navigation.NewPager(s.site, g.config.SiteURL, g.config.PageNavSections)
+		listings, err := g.buildListings(s.site)
+		if err != nil {
+			return nil, err
+		}
+		g.listings = listings
+		g.archives = g.buildArchives(s.site)
+		g.collectDisallowed(s.site.Root, true)
+		for _, node := range s.site.AllPages {
+			if err := g.generatePage(node, s.site.Root); err != nil {
+				return nil, fmt.Errorf("failed to generate %s: %w", node.Path, err)
+			}
+		}
+		written, err := g.writePages(s.site)
+		if err != nil {
+			return nil, err
+		}
+		result.PagesGenerated += written
+
+		// Step 4: Generate auto-index pages for folders without index.md
+		folders := autoindex.CollectFoldersNeedingAutoIndex(s.site.Root)
+		if len(folders) > 0 {
+			g.logger.Verbose("Generating auto-index pages for %d folders...", len(folders))
+			for _, folder := range folders {
+				if g.listings[folder.Path] != nil {
+					continue // Its listing takes the auto-index's place
+				}
+				if err := g.generateAutoIndex(folder, s.site.Root); err != nil {
+					return nil, fmt.Errorf("failed to generate auto-index for %s: %w", folder.Path, err)
+				}
+				g.logger.Verbose("  Auto-indexed: %s", folder.Path)
 			}
-			g.logger.Verbose("  Auto-indexed: %s", folder.Path)
+		}
+		foldersNeedingIndex = append(foldersNeedingIndex, folders...)
+
+		// Step 4a: Generate listing pages for blog-style folders
+		urls, err := g.generateListingPages(s.site.Root)
+		if err != nil {
+			return nil, err
+		}
+		listingURLs = append(listingURLs, urls...)
+
+		// Step 4b: Generate date archives
+		urls, err = g.generateArchives(s.site)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate archives: %w", err)
+		}
+		archiveURLs = append(archiveURLs, urls...)
+
+		// Step 4c: Generate printable books for the site and each folder
+		if g.config.Print {
+			g.logger.Verbose("Generating printable books...")
+			urls, err := g.generateBooks(s.site)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate printable books: %w", err)
+			}
+			bookURLs = append(bookURLs, urls...)
 		}
 	}
 
-	// Step 5: Generate 404 page
+	// Step 5: Generate 404 page (in the default language)
+	g.lang = sites[0].language
 	if err := g.generate404(site.Root); err != nil {
 		return nil, fmt.Errorf("failed to generate 404 page: %w", err)
 	}
 
+	// Step 5a: Generate the link graph
+	var graphURLs []string
+	if g.config.Graph {
+		graphURLs, err = g.generateGraph(site)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate graph: %w", err)
+		}
+	}
+
 	// Step 6: Verify all navigation links resolve
 	g.logger.Verbose("Verifying navigation links...")
-	brokenLinks := g.verifyLinks(site.AllPages)
+	brokenLinks := g.verifyLinks(allPages)
 	if len(brokenLinks) > 0 {
 		g.logger.Println("")
 		if g.config.AllowBrokenLinks {
@@ -265,7 +422,11 @@ func (g *Generator) Generate() (*Result, error) {
 
 	// Step 7: Verify all internal links in content resolve
 	g.logger.Verbose("Verifying internal links in content...")
-	validURLs := tree.BuildValidURLMapWithAutoIndex(site.AllPages, foldersNeedingIndex, g.config.SiteURL)
+	validURLs := tree.BuildValidURLMapWithAutoIndex(allPages, foldersNeedingIndex, g.config.SiteURL)
+	for _, url := range append(append(append(bookURLs, listingURLs...), archiveURLs...), graphURLs...) {
+		validURLs[url] = true
+		validURLs[g.baseURL+url] = true
+	}
 	brokenContentLinks := g.verifyContentLinks(validURLs)
 	if len(brokenContentLinks) > 0 {
 		g.logger.Println("")
@@ -335,19 +496,14 @@ func (g *Generator) Generate() (*Result, error) {
 		}
 	}
 
-	// Step 8: Generate PWA assets if enabled
-	if g.pwaEnabled {
-		if err := g.generatePWA(site.AllPages, foldersNeedingIndex); err != nil {
-			return nil, fmt.Errorf("failed to generate PWA assets: %w", err)
-		}
-	}
-
-	// Step 9: Generate search assets if enabled
+	// Step 8: Generate search assets if enabled
 	if g.searchEnabled && g.searchIndex != nil {
-		if err := search.GenerateSearchIndex(g.config.OutputDir, g.searchIndex); err != nil {
+		files, err := search.GenerateSearchIndex(g.config.OutputDir, g.searchIndex, g.config.SearchShardSize)
+		if err != nil {
 			return nil, fmt.Errorf("failed to generate search index: %w", err)
 		}
-		g.logger.Verbose("  search-index.json")
+		g.searchFiles = files
+		g.logger.Verbose("  %s", search.ManifestFile)
 
 		// Write search.js
 		searchJS := search.GenerateSearchJS(g.baseURL)
@@ -358,6 +514,19 @@ func (g *Generator) Generate() (*Result, error) {
 		g.logger.Verbose("  search.js")
 	}
 
+	// Step 9: Generate PWA assets if enabled, after the search index so
+	// that its shards are precached
+	if g.pwaEnabled {
+		if err := g.generatePWA(allPages, foldersNeedingIndex); err != nil {
+			return nil, fmt.Errorf("failed to generate PWA assets: %w", err)
+		}
+	}
+
+	// Step 10: Write the sitemap and robots.txt
+	if err := g.writeSitemap(); err != nil {
+		return nil, err
+	}
+
 	// Print summary
 	g.logger.Println("")
 	g.logger.Success("Generated %d pages in %s", result.PagesGenerated, g.config.OutputDir)
@@ -365,6 +534,20 @@ func (g *Generator) Generate() (*Result, error) {
 	return result, nil
 }
 
+// buildMainMenu resolves the configured main menu against the
+// default-language site. It returns nil when no menu is configured.
+func (g *Generator) buildMainMenu(site *tree.Site) ([]templates.TopNavItem, error) {
+	items, err := menu.Main(g.config.Menus)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	mainMenu, err := menu.Build(items, site, g.config.SiteURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid menu:\n%w", err)
+	}
+	return mainMenu, nil
+}
+
 // countFolders counts the number of folders in the tree
 func countFolders(node *tree.Node) int {
 	if node == nil {
@@ -426,7 +609,7 @@ func (g *Generator) prepareOutputDir() error {
 }
 
 // generatePage generates a single page
-func (g *Generator) generatePage(node *tree.Node, root *tree.Node, allPages []*tree.Node) error {
+func (g *Generator) generatePage(node *tree.Node, root *tree.Node) error {
 	// Get paths
 	outputPath := tree.GetOutputPath(node)
 	urlPath := tree.GetURLPath(node)
@@ -461,9 +644,20 @@ func (g *Generator) generatePage(node *tree.Node, root *tree.Node, allPages []*t
 
 	htmlContent := page.Content
 
+	// Pick the layout from front matter, falling back to the folder default
+	// and then to the theme's default (slides for the presentation theme)
+	defaultLayout := config.ResolveFolderConfig(g.config.Folders, node.Path).Layout
+	if defaultLayout == "" {
+		defaultLayout = templates.ThemeLayout(g.config.Theme)
+	}
+	layout := templates.ResolveLayout(page.FrontMatter, defaultLayout)
+	if err := g.renderer.ValidateLayout(layout.Name); err != nil {
+		return fmt.Errorf("%s: %w", node.SourcePath, err)
+	}
+
 	// Calculate reading time
 	rt := content.CalculateReadingTime(htmlContent)
-	readingTime := content.FormatReadingTime(rt)
+	readingTime := content.FormatReadingTimeIn(rt, g.lang.messages)
 
 	// Build breadcrumbs (with base URL prefixing) - only if enabled
 	var breadcrumbsHTML template.HTML
@@ -475,8 +669,11 @@ func (g *Generator) generatePage(node *tree.Node, root *tree.Node, allPages []*t
 	// Build page navigation (only if enabled, with base URL prefixing)
 	var pageNavHTML template.HTML
 	if g.config.ShowPageNav {
-		pageNav := navigation.BuildPageNavigationWithBaseURL(node, allPages, g.config.SiteURL)
-		pageNavHTML = navigation.RenderPageNavigation(pageNav)
+		pageNav, err := g.pager.Build(node, page.FrontMatter)
+		if err != nil {
+			return fmt.Errorf("%s: %w", node.SourcePath, err)
+		}
+		pageNavHTML = navigation.RenderPageNavigationWithLabels(pageNav, g.lang.messages.T("previous"), g.lang.messages.T("next"))
 	}
 
 	// Extract TOC
@@ -484,19 +681,33 @@ func (g *Generator) generatePage(node *tree.Node, root *tree.Node, allPages []*t
 	tocHTML := toc.RenderTOC(pageTOC)
 	hasTOC := pageTOC != nil && len(pageTOC.Items) > 0
 
+	// A listing folder's index page lists the folder's pages below its content
+	if l := g.listingOf(node); l != nil {
+		htmlContent += string(l.Render(1, g.lang.messages))
+	}
+
 	// Generate SEO meta tags
-	seoConfig := seo.Config{
-		SiteURL:   g.config.SiteURL,
-		SiteTitle: g.config.Title,
-		Author:    g.config.Author,
-		OGImage:   g.ogImageURL, // Use processed URL, not raw path
+	ix, err := g.indexingOf(node, false, urlPath, page.FrontMatter)
+	if err != nil {
+		return fmt.Errorf("%s: %w", node.SourcePath, err)
+	}
+	pageMeta := seo.GeneratePageMeta(page.Title, htmlContent, urlPath, g.seoConfig(node, page.Title, page.FrontMatter, ix))
+	if pageMeta.StructuredData, err = g.structuredData(node, false, page.FrontMatter, pageMeta); err != nil {
+		return fmt.Errorf("%s: %w", node.SourcePath, err)
+	}
+	lastMod := tree.NodeDate(node)
+	if updated, err := time.Parse("2006-01-02", page.FrontMatter.String("updated")); err == nil {
+		lastMod = updated
 	}
-	pageMeta := seo.GeneratePageMeta(page.Title, htmlContent, urlPath, seoConfig)
+	g.addToSitemap(ix, lastMod)
 	metaTagsHTML := seo.RenderMetaTags(pageMeta)
 
 	// Render navigation (filtered when top nav is enabled, with base URL prefixing)
 	nav := templates.RenderNavigationWithTopNavAndBaseURL(root, urlPath, g.topNavItems, g.config.SiteURL)
 
+	// Dated pages in an archive show their date, linked to its month
+	date, dateURL := g.archiveDate(node)
+
 	// Prepare template data
 	data := templates.PageData{
 		SiteTitle:       g.config.Title,
@@ -510,52 +721,53 @@ func (g *Generator) generatePage(node *tree.Node, root *tree.Node, allPages []*t
 		MetaTags:        metaTagsHTML,
 		FaviconLinks:    g.faviconLinks,
 		ReadingTime:     readingTime,
+		Date:            date,
+		DateURL:         dateURL,
 		HasTOC:          hasTOC,
 		ShowSearch:      true,
 		TopNavItems:     g.topNavItems,
 		BaseURL:         g.baseURL,
 		CSSURL:          g.cssURL,
+		FontPreloads:    g.fonts.RenderPreloadLinks(),
 		JSURL:           g.jsURL,
 		CSS:             g.inlineCSS(),
 		InstantNavJS:    g.instantNavJS,
 		ViewTransitions: g.viewTransitions,
 		PWAEnabled:      g.pwaEnabled,
 		SearchEnabled:   g.searchEnabled,
-	}
-
-	// Create output directory
-	outputDir := filepath.Dir(fullOutputPath)
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", outputDir, err)
-	}
-
-	// Write file
-	f, err := os.Create(fullOutputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", fullOutputPath, err)
-	}
-	defer func() { _ = f.Close() }()
-
-	if err := g.renderer.Render(f, data); err != nil {
-		return fmt.Errorf("failed to render page: %w", err)
-	}
+		GraphEnabled:    g.config.Graph,
+	}
+	if g.config.Graph {
+		data.Graph = graph.RenderLocal(urlPath, g.config.SiteURL, g.lang.messages)
+	}
+	g.lang.includes.Apply(&data)
+	g.applyLanguage(&data, urlPath)
+	g.applyVersion(&data, urlPath)
+	layout.Apply(&data)
+
+	// The page is written once every page of the language is rendered, so
+	// that its related pages are known
+	g.pending = append(g.pending, pendingPage{
+		outputPath: fullOutputPath,
+		data:       data,
+		page:       related.Page{Node: node, Title: page.Title, Content: page.Content, FrontMatter: page.FrontMatter},
+	})
 
 	// Track page for link validation
 	g.generatedPages = append(g.generatedPages, generatedPage{
+		node:        node,
 		urlPath:     urlPath,
+		title:       page.Title,
 		sourceFile:  node.SourcePath,
+		sourceDir:   sourceDir,
 		mdContent:   string(mdContent),
 		htmlContent: htmlContent,
+		tags:        page.FrontMatter.List("tags"),
 	})
 
-	// Collect search index data if enabled
-	if g.searchEnabled && g.searchIndex != nil {
-		entry := search.PageEntry{
-			Title:    page.Title,
-			URL:      urlPath,
-			Headings: search.ExtractHeadings(htmlContent),
-		}
-		g.searchIndex.Pages = append(g.searchIndex.Pages, entry)
+	// Collect search index data if enabled, leaving out noindex pages
+	if g.searchEnabled && g.searchIndex != nil && seo.Indexable(ix.robots) {
+		g.searchIndex.Pages = append(g.searchIndex.Pages, search.NewPageEntry(node, g.lang.code, page.Title, page.Content, page.FrontMatter))
 	}
 
 	return nil
@@ -563,31 +775,26 @@ func (g *Generator) generatePage(node *tree.Node, root *tree.Node, allPages []*t
 
 // generate404 generates the 404 error page
 func (g *Generator) generate404(root *tree.Node) error {
-	// Build home URL with base URL prefix
-	homeURL := "/"
-	if g.baseURL != "" {
-		homeURL = g.baseURL + "/"
-	}
-	content := fmt.Sprintf(`<h1>404 - Page Not Found</h1>
-<p>The page you're looking for doesn't exist.</p>
-<p><a href="%s">Return to home</a></p>`, homeURL)
-
 	nav := templates.RenderNavigationWithBaseURL(root, "", g.config.SiteURL)
 
 	data := templates.PageData{
 		SiteTitle:       g.config.Title,
-		PageTitle:       "Page Not Found",
-		Content:         template.HTML(content),
+		PageTitle:       g.lang.messages.T("pageNotFound"),
+		NotFound:        true,
 		Navigation:      nav,
 		CurrentPath:     "",
 		BaseURL:         g.baseURL,
 		CSSURL:          g.cssURL,
+		FontPreloads:    g.fonts.RenderPreloadLinks(),
 		JSURL:           g.jsURL,
 		CSS:             g.inlineCSS(),
 		InstantNavJS:    g.instantNavJS,
 		ViewTransitions: g.viewTransitions,
 		PWAEnabled:      g.pwaEnabled,
 	}
+	g.lang.includes.Apply(&data)
+	g.applyLanguage(&data, "")
+	g.applyVersion(&data, "")
 
 	fullPath := filepath.Join(g.config.OutputDir, "404.html")
 	f, err := os.Create(fullPath)
@@ -667,6 +874,7 @@ func (g *Generator) generatePWA(allPages []*tree.Node, autoIndexFolders []*tree.
 	if g.jsURL != "" {
 		assetURLs = append(assetURLs, g.jsURL)
 	}
+	assetURLs = append(assetURLs, g.fonts.URLs()...)
 	if iconResult.Generated {
 		assetURLs = append(assetURLs, pwa.GetIconURLs(g.baseURL)...)
 	}
@@ -679,7 +887,15 @@ func (g *Generator) generatePWA(allPages []*tree.Node, autoIndexFolders []*tree.
 		if searchBase == "" {
 			searchBase = "/"
 		}
-		assetURLs = append(assetURLs, searchBase+"search.js", searchBase+"search-index.json")
+		// The hashed shards are listed too, so the service worker's cache
+		// name changes with them and a stale manifest is never served
+		assetURLs = append(assetURLs, searchBase+"search.js")
+		for _, name := range g.searchFiles {
+			assetURLs = append(assetURLs, searchBase+name)
+		}
+	}
+	if g.config.Graph {
+		assetURLs = append(assetURLs, g.baseURL+"/"+graph.ScriptName, g.baseURL+"/"+graph.FileName)
 	}
 	// Add favicon if configured
 	if g.config.FaviconPath != "" {
@@ -9,7 +9,12 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/wusher/volcano/internal/assets"
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/includes"
 	"github.com/wusher/volcano/internal/tree"
+	"github.com/wusher/volcano/internal/versions"
 )
 
 func TestNew(t *testing.T) {
@@ -845,6 +850,158 @@ func TestGenerateWithTopNav(t *testing.T) {
 	}
 }
 
+func TestGenerateWithMenu(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.md":           "# Home",
+		"about.md":           "# About",
+		"guides/index.md":    "# Guides",
+		"guides/01-intro.md": "# Intro",
+	}
+	for name, content := range files {
+		path := filepath.Join(inputDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	menus := map[string][]config.MenuItem{
+		"main": {
+			{Page: "[[intro]]", Name: "Start"},
+			{Name: "More", Children: []config.MenuItem{
+				{Page: "about"},
+				{Name: "Source", URL: "https://example.com/repo", Icon: "github"},
+			}},
+		},
+	}
+
+	var buf bytes.Buffer
+	g, err := New(Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Title:     "Test",
+		SiteURL:   "/docs",
+		Menus:     menus,
+	}, &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "guides", "intro", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(content)
+	for _, want := range []string{
+		`<a href="/docs/guides/intro/" aria-current="page" class="active">Start</a>`,
+		`<li class="top-nav-dropdown">`,
+		`<a href="/docs/about/">About</a>`,
+		`<a href="https://example.com/repo" rel="noopener">`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("intro page missing %q", want)
+		}
+	}
+
+	// References to missing pages fail the build
+	menus["main"] = append(menus["main"], config.MenuItem{Page: "missing"})
+	g, err = New(Config{InputDir: inputDir, OutputDir: outputDir, Title: "Test", Menus: menus}, &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err == nil || !strings.Contains(err.Error(), `page "missing" not found`) {
+		t.Errorf("Generate() error = %v, want a missing page error", err)
+	}
+}
+
+func TestGeneratePageNav(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.md":           "# Home",
+		"zebra.md":           "# Zebra",
+		"guides/index.md":    "# Guides",
+		"guides/02-setup.md": "# Setup",
+		"guides/01-intro.md": "---\nprev: false\n---\n# Intro",
+		"api/index.md":       "# API",
+	}
+	for name, content := range files {
+		path := filepath.Join(inputDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	generate := func(sections bool) error {
+		g, err := New(Config{
+			InputDir:        inputDir,
+			OutputDir:       outputDir,
+			Title:           "Test",
+			SiteURL:         "/docs",
+			ShowPageNav:     true,
+			PageNavSections: sections,
+		}, &bytes.Buffer{})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		_, err = g.Generate()
+		return err
+	}
+	read := func(path string) string {
+		content, err := os.ReadFile(filepath.Join(outputDir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	// Links follow the sidebar: root pages, then each folder's index page
+	// followed by its pages
+	if err := generate(false); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	zebra := read("zebra/index.html")
+	if !strings.Contains(zebra, `<a href="/docs/" class="page-nav-prev">`) || !strings.Contains(zebra, `<a href="/docs/api/" class="page-nav-next">`) {
+		t.Error("zebra should link back to the home page and on to the API folder")
+	}
+	if guides := read("guides/index.html"); !strings.Contains(guides, `<a href="/docs/api/" class="page-nav-prev">`) {
+		t.Error("the guides index page should follow the API folder")
+	}
+	intro := read("guides/intro/index.html")
+	if strings.Contains(intro, `class="page-nav-prev"`) || !strings.Contains(intro, `<a href="/docs/guides/setup/" class="page-nav-next">`) {
+		t.Error("intro should hide its previous link and link on to setup")
+	}
+
+	if err := generate(true); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if guides := read("guides/index.html"); strings.Contains(guides, `class="page-nav-prev"`) {
+		t.Error("with sections, the guides index page should have no previous link")
+	}
+
+	// References to missing pages fail the build
+	if err := os.WriteFile(filepath.Join(inputDir, "zebra.md"), []byte("---\nnext: missing\n---\n# Zebra"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := generate(false); err == nil || !strings.Contains(err.Error(), `next: page "missing" not found`) {
+		t.Errorf("Generate() error = %v, want a missing page error", err)
+	}
+}
+
 func TestGenerateWithFaviconAndOGImage(t *testing.T) {
 	tmpDir := t.TempDir()
 	inputDir := filepath.Join(tmpDir, "input")
@@ -922,6 +1079,296 @@ func TestGenerateWithFaviconAndOGImage(t *testing.T) {
 	}
 }
 
+func TestGenerateWithOGImages(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.md":        "# Home",
+		"custom.md":       "---\nogImage: images/custom.png\n---\n# Custom",
+		"guides/kafka.md": "# Kafka",
+	}
+	for name, content := range files {
+		path := filepath.Join(inputDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	config := Config{
+		InputDir:    inputDir,
+		OutputDir:   outputDir,
+		Title:       "Test",
+		SiteURL:     "https://example.com",
+		AccentColor: "lime-sky",
+		OGImages:    true,
+	}
+	g, err := New(config, &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	// Pages get their own card, named by what it shows
+	card := assets.OGCard{Title: "Kafka", Section: "Guides", SiteTitle: "Test", Accent: "lime-sky"}
+	if _, err := os.Stat(filepath.Join(outputDir, card.FileName())); err != nil {
+		t.Errorf("card of guides/kafka.md not written: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "guides", "kafka", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<meta property="og:image" content="https://example.com/` + card.FileName() + `">`,
+		`<meta property="og:image:width" content="1200">`,
+		`<meta name="twitter:image" content="https://example.com/` + card.FileName() + `">`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("guides/kafka/index.html should contain %s", want)
+		}
+	}
+
+	// A folder's own page doesn't repeat its name as the section
+	folderCard := assets.OGCard{Title: "Guides", SiteTitle: "Test", Accent: "lime-sky"}
+	if _, err := os.Stat(filepath.Join(outputDir, folderCard.FileName())); err != nil {
+		t.Errorf("card of the guides folder not written: %v", err)
+	}
+
+	// Front matter overrides the card
+	content, err = os.ReadFile(filepath.Join(outputDir, "custom", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `<meta property="og:image" content="https://example.com/images/custom.png">`) {
+		t.Error("custom/index.html should use its ogImage front matter")
+	}
+	if strings.Contains(string(content), "og:image:width") {
+		t.Error("custom/index.html should not give a size for its own image")
+	}
+}
+
+func TestGenerateStructuredData(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.md":                  "# Home",
+		"blog/2024-03-15-launch.md": "---\nauthor: Ada\n---\n# Launch",
+		"news/2024-04-01-update.md": "# Update",
+		"guides/kafka.md":           "# Kafka",
+		"guides/faq.md":             "---\nschema: none\n---\n# FAQ",
+		"about/team.md":             "# Team",
+	}
+	for name, content := range files {
+		path := filepath.Join(inputDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Title:     "Test",
+		SiteURL:   "https://example.com",
+		Search:    true,
+		Folders: map[string]config.FolderConfig{
+			"blog":  {Listing: &config.ListingConfig{}},
+			"about": {Schema: "WebPage"},
+		},
+	}
+	var buf bytes.Buffer
+	g, err := New(cfg, &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	tests := []struct {
+		page string
+		want []string
+	}{
+		{"index.html", []string{`"@type":"WebSite"`, `"urlTemplate":"https://example.com/?q={search_term_string}"`, `"@type":"WebPage"`}},
+		{"blog/launch/index.html", []string{`"@type":"BlogPosting"`, `"datePublished":"2024-03-15"`, `"author":{"@type":"Person","name":"Ada"}`}},
+		{"news/update/index.html", []string{`"@type":"Article"`, `"datePublished":"2024-04-01"`}},
+		{"guides/kafka/index.html", []string{`"@type":"TechArticle"`, `"@type":"BreadcrumbList"`, `"item":"https://example.com/guides/"`}},
+		{"about/team/index.html", []string{`"@type":"WebPage"`}},
+		{"guides/index.html", []string{`"@type":"CollectionPage"`}},
+	}
+	for _, tc := range tests {
+		content, err := os.ReadFile(filepath.Join(outputDir, tc.page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s should contain %s", tc.page, want)
+			}
+		}
+		if tc.page != "index.html" && strings.Contains(string(content), `"potentialAction"`) {
+			t.Errorf("%s should not describe the WebSite", tc.page)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "guides", "faq", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "application/ld+json") {
+		t.Error("guides/faq/index.html has `schema: none` and should have no JSON-LD")
+	}
+
+	// Settings that leave out required properties fail the build
+	cfg.OutputDir = filepath.Join(tmpDir, "invalid")
+	cfg.Folders = map[string]config.FolderConfig{"guides": {Schema: "BlogPosting"}}
+	g, err = New(cfg, &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err == nil || !strings.Contains(err.Error(), "BlogPosting needs a date") {
+		t.Errorf("Generate() error = %v, want a missing date", err)
+	}
+}
+
+func TestGenerateIndexing(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.md":                      "# Home",
+		"blog/2024-03-15-launch.md":     "---\nupdated: 2024-05-01\n---\n# Launch",
+		"blog/2024-04-01-syndicated.md": "---\ncanonical: https://medium.com/@ada/launch\n---\n# Syndicated",
+		"guides/kafka.md":               "# Kafka",
+		"guides/draft.md":               "---\nsitemap: false\n---\n# Draft",
+		"guides/secret.md":              "---\nrobots: noindex\n---\n# Secret",
+		"internal/runbook.md":           "# Runbook",
+		"internal/public/faq.md":        "# FAQ",
+		"mirror/post.md":                "# Mirror",
+		"private/keys.md":               "# Keys",
+		"private/open/notes.md":         "# Notes",
+	}
+	for name, content := range files {
+		path := filepath.Join(inputDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	yes, no := true, false
+	cfg := Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Title:     "Test",
+		SiteURL:   "https://example.com",
+		Search:    true,
+		Folders: map[string]config.FolderConfig{
+			"internal":        {Robots: "noindex, nofollow"},
+			"internal/public": {Robots: "index, follow"},
+			"mirror":          {Canonical: "https://example.org"},
+			"private":         {Crawl: &no},
+			"private/open":    {Crawl: &yes},
+		},
+	}
+	var buf bytes.Buffer
+	g, err := New(cfg, &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	tests := []struct {
+		page string
+		want string
+	}{
+		{"guides/kafka/index.html", `<meta name="robots" content="index, follow">`},
+		{"guides/secret/index.html", `<meta name="robots" content="noindex">`},
+		{"internal/runbook/index.html", `<meta name="robots" content="noindex, nofollow">`},
+		{"internal/index.html", `<meta name="robots" content="noindex, nofollow">`},
+		{"internal/public/faq/index.html", `<meta name="robots" content="index, follow">`},
+		{"blog/syndicated/index.html", `<link rel="canonical" href="https://medium.com/@ada/launch">`},
+		{"mirror/post/index.html", `<link rel="canonical" href="https://example.org/mirror/post/">`},
+	}
+	for _, tc := range tests {
+		content, err := os.ReadFile(filepath.Join(outputDir, tc.page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), tc.want) {
+			t.Errorf("%s should contain %s", tc.page, tc.want)
+		}
+	}
+
+	sitemap, err := os.ReadFile(filepath.Join(outputDir, "sitemap.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, url := range []string{"/", "/guides/", "/guides/kafka/", "/blog/launch/", "/internal/public/faq/"} {
+		if !strings.Contains(string(sitemap), "<loc>https://example.com"+url+"</loc>") {
+			t.Errorf("sitemap should list %s", url)
+		}
+	}
+	for _, url := range []string{"/guides/draft/", "/guides/secret/", "/internal/", "/internal/runbook/", "/blog/syndicated/", "/mirror/post/"} {
+		if strings.Contains(string(sitemap), "<loc>https://example.com"+url+"</loc>") {
+			t.Errorf("sitemap should not list %s", url)
+		}
+	}
+	if !strings.Contains(string(sitemap), "<lastmod>2024-05-01</lastmod>") {
+		t.Error("sitemap should date pages by their `updated` front matter")
+	}
+
+	robots, err := os.ReadFile(filepath.Join(outputDir, "robots.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Noindex folders stay crawlable so that crawlers see their noindex tags
+	want := "User-agent: *\nDisallow: /private/\nAllow: /private/open/\n\nSitemap: https://example.com/sitemap.xml\n"
+	if string(robots) != want {
+		t.Errorf("robots.txt = %q, want %q", robots, want)
+	}
+
+	// Search leaves out noindex pages, but not pages only left out of the sitemap
+	searched := make(map[string]bool)
+	for _, page := range g.searchIndex.Pages {
+		searched[page.URL] = true
+	}
+	if searched["/guides/secret/"] || searched["/internal/runbook/"] {
+		t.Errorf("search index should leave out noindex pages, got %v", searched)
+	}
+	if !searched["/guides/draft/"] || !searched["/internal/public/faq/"] {
+		t.Errorf("search index should include indexable pages, got %v", searched)
+	}
+
+	// Unknown directives fail the build
+	cfg.OutputDir = filepath.Join(tmpDir, "invalid")
+	cfg.Folders = map[string]config.FolderConfig{"guides": {Robots: "noindx"}}
+	g, err = New(cfg, &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err == nil || !strings.Contains(err.Error(), `unknown robots directive "noindx"`) {
+		t.Errorf("Generate() error = %v, want an unknown directive", err)
+	}
+}
+
 func TestGenerateWithPWA(t *testing.T) {
 	tmpDir := t.TempDir()
 	inputDir := filepath.Join(tmpDir, "input")
@@ -1351,17 +1798,35 @@ func TestGenerateWithSearch(t *testing.T) {
 	}
 
 	// Check search index contains page data
-	indexContent, err := os.ReadFile(searchIndexPath)
-	if err != nil {
-		t.Fatal(err)
-	}
-	indexStr := string(indexContent)
+	indexStr := readSearchIndex(t, outputDir)
 	if !strings.Contains(indexStr, "Home") {
 		t.Error("Search index should contain page title 'Home'")
 	}
 	if !strings.Contains(indexStr, "Welcome") {
 		t.Error("Search index should contain heading 'Welcome'")
 	}
+	if !strings.Contains(indexStr, `["h2","Welcome","/#welcome","Home","This is the home page."]`) {
+		t.Errorf("Search index should contain section text:\n%s", indexStr)
+	}
+}
+
+// readSearchIndex returns the search index manifest and shards written to
+// outputDir, concatenated
+func readSearchIndex(t *testing.T, outputDir string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(outputDir, "search-index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shards, _ := filepath.Glob(filepath.Join(outputDir, "search-index", "*.json"))
+	for _, shard := range shards {
+		data, err := os.ReadFile(shard)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content = append(content, data...)
+	}
+	return string(content)
 }
 
 func TestGenerateWithAutoIndex(t *testing.T) {
@@ -1607,6 +2072,25 @@ func TestGenerateWithPWAAndSearch(t *testing.T) {
 		t.Error("search-index.json should be created with Search=true")
 	}
 
+	// The service worker precaches the manifest and its hashed shards
+	sw, err := os.ReadFile(swPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shards, _ := filepath.Glob(filepath.Join(outputDir, "search-index", "*.json"))
+	if len(shards) == 0 {
+		t.Fatal("search index shards should be written")
+	}
+	for _, shard := range shards {
+		url := "/docs/search-index/" + filepath.Base(shard)
+		if !strings.Contains(string(sw), url) {
+			t.Errorf("sw.js should precache %s", url)
+		}
+	}
+	if !strings.Contains(string(sw), "/docs/search-index.json") {
+		t.Error("sw.js should precache the search index manifest")
+	}
+
 	// Check 404.html
 	notFoundPath := filepath.Join(outputDir, "404.html")
 	if _, err := os.Stat(notFoundPath); os.IsNotExist(err) {
@@ -2211,3 +2695,1056 @@ func TestGenerateWithInlineAssets_NoHashedFiles(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateWithLayoutsDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.md":             "# Home\n\nWelcome.",
+		"_layouts/footer.html": `<footer class="custom-footer">{{.SiteTitle}} footer</footer>`,
+		"_layouts/404.html":    `<h1>Lost</h1>`,
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(inputDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	g, err := New(Config{InputDir: inputDir, OutputDir: outputDir, Title: "Layouts Test"}, &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), `<footer class="custom-footer">Layouts Test footer</footer>`) {
+		t.Error("index.html should use the footer partial from _layouts")
+	}
+
+	notFound, err := os.ReadFile(filepath.Join(outputDir, "404.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(notFound), "<h1>Lost</h1>") {
+		t.Error("404.html should use the 404 partial from _layouts")
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "_layouts")); !os.IsNotExist(err) {
+		t.Error("_layouts should not be copied to the output")
+	}
+}
+
+func TestGenerateWithInvalidLayout(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	layoutsDir := filepath.Join(tmpDir, "layouts")
+	if err := os.MkdirAll(inputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(layoutsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(layoutsDir, "footer.html"), []byte("{{.Broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	_, err := New(Config{InputDir: inputDir, OutputDir: filepath.Join(tmpDir, "output"), LayoutsDir: layoutsDir}, &buf)
+	if err == nil {
+		t.Fatal("New() should fail for an invalid layout")
+	}
+	if !strings.Contains(err.Error(), "footer.html:1") {
+		t.Errorf("error should point at footer.html:1, got %v", err)
+	}
+}
+
+func TestGenerateWithPageLayouts(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.md":                "---\nlayout: landing\n---\n# Home\n\n## A\n\n## B\n\n## C\n",
+		"guide.md":                "# Guide\n\n## A\n\n## B\n\n## C\n",
+		"talks/intro.md":          "# Intro\n\n## A\n\n## B\n\n## C\n",
+		"talks/keynote.md":        "---\nlayout: default\ntoc: false\n---\n# Keynote\n\n## A\n\n## B\n\n## C\n",
+		"_layouts/dashboard.html": `<html><body class="dash">{{.Content}}</body></html>`,
+		"stats.md":                "---\nlayout: dashboard\n---\n# Stats",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(inputDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	g, err := New(Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Title:     "Layouts",
+		Folders:   map[string]config.FolderConfig{"talks": {Layout: "wide"}},
+	}, &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	read := func(path string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(outputDir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	tests := []struct {
+		path    string
+		want    []string
+		notWant []string
+	}{
+		{"index.html", []string{`class="layout-landing no-sidebar"`, `data-layout="landing"`}, []string{`<aside class="sidebar"`, `class="toc-sidebar"`}},
+		{"guide/index.html", []string{`<aside class="sidebar"`, `class="toc-sidebar"`, `data-layout=""`}, []string{`class="layout-`}},
+		{"talks/intro/index.html", []string{`class="layout-wide wide"`, `data-layout="wide"`, `<aside class="sidebar"`}, []string{`class="toc-sidebar"`}},
+		{"talks/keynote/index.html", []string{`<aside class="sidebar"`, `data-layout="default"`}, []string{`class="layout-wide`, `class="toc-sidebar"`}},
+		{"talks/index.html", []string{`class="layout-wide wide"`}, nil},
+		{"stats/index.html", []string{`<html><body class="dash">`}, []string{`<aside class="sidebar"`}},
+	}
+	for _, tt := range tests {
+		html := read(tt.path)
+		for _, want := range tt.want {
+			if !strings.Contains(html, want) {
+				t.Errorf("%s should contain %q", tt.path, want)
+			}
+		}
+		for _, notWant := range tt.notWant {
+			if strings.Contains(html, notWant) {
+				t.Errorf("%s should not contain %q", tt.path, notWant)
+			}
+		}
+	}
+}
+
+func TestGenerateWithUnknownLayout(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	if err := os.MkdirAll(inputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(inputDir, "index.md"), []byte("---\nlayout: nope\n---\n# Home"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	g, err := New(Config{InputDir: inputDir, OutputDir: filepath.Join(tmpDir, "output")}, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = g.Generate()
+	if err == nil {
+		t.Fatal("Generate() should fail for an unknown layout")
+	}
+	if !strings.Contains(err.Error(), "index.md") || !strings.Contains(err.Error(), `unknown layout "nope"`) {
+		t.Errorf("error should name the page and layout, got %v", err)
+	}
+}
+
+func TestGenerateSlides(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+	if err := os.MkdirAll(inputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"deck.md":        "# Deck\n\n---\n\n## First\n\nNote: speak slowly\n\n## Second\n\nBye",
+		"about.md":       "---\nlayout: default\n---\n# About\n\n## Team\n\nText",
+		"talks/intro.md": "# Intro\n\n## Hello",
+	}
+	for name, content := range files {
+		path := filepath.Join(inputDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	g, err := New(Config{InputDir: inputDir, OutputDir: outputDir, Theme: "presentation"}, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	deck, err := os.ReadFile(filepath.Join(outputDir, "deck", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(deck)
+	for _, want := range []string{`class="slide-deck"`, `id="slide-3"`, "<p>speak slowly</p>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("presentation theme page should contain %q", want)
+		}
+	}
+	if strings.Contains(html, `id="slide-4"`) {
+		t.Error("deck should have 3 slides")
+	}
+
+	about, err := os.ReadFile(filepath.Join(outputDir, "about", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(about), `class="slide-deck"`) {
+		t.Error("layout: default should opt out of slides")
+	}
+
+	// Folder auto-indexes are navigation, not decks
+	index, err := os.ReadFile(filepath.Join(outputDir, "talks", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(index), `class="slide-deck"`) || !strings.Contains(string(index), `<aside class="sidebar"`) {
+		t.Error("auto-index pages should keep the standard layout under the presentation theme")
+	}
+}
+
+func TestGenerateWithFonts(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.md":          "# Home",
+		"fonts/brand.woff2": "brand-font-bytes",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(inputDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	g, err := New(Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Title:     "Fonts",
+		PWA:       true,
+		Fonts: &config.FontsConfig{
+			Body: &config.FontConfig{Family: "Brand", Files: []config.FontFile{{Path: "fonts/brand.woff2"}}},
+		},
+	}, &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	fontFiles, _ := filepath.Glob(filepath.Join(outputDir, "assets", "brand.*.woff2"))
+	if len(fontFiles) != 1 {
+		t.Fatalf("expected one hashed font next to the stylesheet, got %v", fontFiles)
+	}
+	fontURL := "/assets/" + filepath.Base(fontFiles[0])
+
+	cssFiles, _ := filepath.Glob(filepath.Join(outputDir, "assets", "styles.*.css"))
+	if len(cssFiles) != 1 {
+		t.Fatalf("expected one stylesheet, got %v", cssFiles)
+	}
+	css, _ := os.ReadFile(cssFiles[0])
+	if !strings.Contains(string(css), "@font-face") || !strings.Contains(string(css), fontURL) {
+		t.Error("stylesheet should declare the font with its hashed URL")
+	}
+
+	html, _ := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if !strings.Contains(string(html), `<link rel="preload" href="`+fontURL+`" as="font"`) {
+		t.Error("pages should preload the font")
+	}
+
+	sw, _ := os.ReadFile(filepath.Join(outputDir, "sw.js"))
+	if !strings.Contains(string(sw), fontURL) {
+		t.Error("service worker should precache the font")
+	}
+}
+
+func TestGenerateWithMissingFont(t *testing.T) {
+	_, err := New(Config{
+		InputDir:  t.TempDir(),
+		OutputDir: t.TempDir(),
+		Fonts: &config.FontsConfig{
+			Code: &config.FontConfig{Files: []config.FontFile{{Path: "missing.woff2"}}},
+		},
+	}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "fonts.code") {
+		t.Errorf("New() error = %v, want fonts.code error", err)
+	}
+}
+
+func TestGenerateWithIncludes(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.md":        "# Home",
+		"guides/intro.md": "# Intro",
+		"_header.md":      "Header **notice**",
+		"_banner.md":      "Version 2 is out",
+		"shared/foot.md":  "Footer text",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(inputDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g, err := New(Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Title:     "Includes",
+		Search:    true,
+		Includes:  includes.Paths{Footer: filepath.Join(inputDir, "shared", "foot.md")},
+	}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	result, err := g.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	for _, page := range []string{"index.html", filepath.Join("guides", "intro", "index.html"), "404.html"} {
+		html, err := os.ReadFile(filepath.Join(outputDir, page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := string(html)
+		if !strings.Contains(s, "<strong>notice</strong>") {
+			t.Errorf("%s: missing header include", page)
+		}
+		if !strings.Contains(s, "Footer text") {
+			t.Errorf("%s: missing footer include", page)
+		}
+		if !strings.Contains(s, `class="site-banner"`) || !strings.Contains(s, `data-banner-id="`) {
+			t.Errorf("%s: missing dismissible banner", page)
+		}
+		if strings.Contains(s, `href="/header/"`) || strings.Contains(s, `href="/shared/foot/"`) {
+			t.Errorf("%s: includes should not appear in navigation", page)
+		}
+	}
+
+	// Includes are not pages: not written, not counted, not searchable
+	for _, page := range []string{"header", "_header", "banner", "_banner", filepath.Join("shared", "foot")} {
+		if _, err := os.Stat(filepath.Join(outputDir, page, "index.html")); err == nil {
+			t.Errorf("include %s should not be published as a page", page)
+		}
+	}
+	if result.PagesGenerated != 2 {
+		t.Errorf("PagesGenerated = %d, want 2", result.PagesGenerated)
+	}
+	index := readSearchIndex(t, outputDir)
+	if strings.Contains(index, "notice") || strings.Contains(index, "Version 2") {
+		t.Error("includes should not be in the search index")
+	}
+}
+
+func TestGeneratePrintBooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.md":               "# Home\n\nSee [[runbooks/restart#steps|restart steps]].",
+		"runbooks/index.md":      "# Runbooks\n\n## Steps\n\nOverview.",
+		"runbooks/restart.md":    "# Restart\n\n## Steps\n\n1. Stop\n2. [Back](../)",
+		"runbooks/print/note.md": "# Note",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(inputDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g, err := New(Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Title:     "Ops",
+		SiteURL:   "https://example.com/ops/",
+		Print:     true,
+	}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	site, err := os.ReadFile(filepath.Join(outputDir, "print.html"))
+	if err != nil {
+		t.Fatalf("print.html not written: %v", err)
+	}
+	s := string(site)
+	for _, want := range []string{
+		`<body class="layout-print no-sidebar"`,
+		`<meta name="robots" content="noindex">`,
+		`<nav class="print-toc"`,
+		`id="page-home"`,
+		`id="page-runbooks"`,
+		`id="page-runbooks-restart"`,
+		`id="steps-2"`,                         // second "Steps" heading de-duplicated
+		`<a href="#steps-2">restart steps</a>`, // wikilink to a heading on another page
+		`<a href="#page-runbooks">Back</a>`,    // relative link to an included page
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("print.html should contain %q", want)
+		}
+	}
+	if strings.Contains(s, `class="tree-nav"`) {
+		t.Error("print.html should not render the sidebar")
+	}
+
+	// A real folder at <folder>/print/ keeps its URL and auto-index
+	if _, err := os.Stat(filepath.Join(outputDir, "runbooks", "print", "note", "index.html")); err != nil {
+		t.Error("pages under a print/ folder should still be generated")
+	}
+	autoIndex, _ := os.ReadFile(filepath.Join(outputDir, "runbooks", "print", "index.html"))
+	if strings.Contains(string(autoIndex), "layout-print") {
+		t.Error("the runbooks book should not overwrite the print/ folder's index")
+	}
+	folder, err := os.ReadFile(filepath.Join(outputDir, "runbooks", "print", "print", "index.html"))
+	if err != nil {
+		t.Fatalf("a print/ folder should get its own book: %v", err)
+	}
+	if strings.Contains(string(folder), `id="page-home"`) || !strings.Contains(string(folder), `id="page-runbooks-print-note"`) {
+		t.Error("folder book should hold only the folder's pages")
+	}
+}
+
+func TestGenerateWithoutPrint(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	if err := os.MkdirAll(inputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(inputDir, "index.md"), []byte("# Home"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outputDir := filepath.Join(tmpDir, "output")
+	g, err := New(Config{InputDir: inputDir, OutputDir: outputDir}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "print.html")); err == nil {
+		t.Error("print.html should only be generated with Print enabled")
+	}
+}
+
+func TestGenerateMultilingual(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.md":           "# Home",
+		"index.fr.md":        "# Accueil",
+		"guides/intro.md":    "# Intro\n\nSee [setup](../setup/).",
+		"guides/intro.fr.md": "# Introduction",
+		"guides/setup.md":    "# Setup",
+		"guides/intro.ar.md": "# مقدمة",
+		"_footer.md":         "Footer",
+		"_footer.fr.md":      "Pied de page",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(inputDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var logs bytes.Buffer
+	g, err := New(Config{
+		InputDir:     inputDir,
+		OutputDir:    outputDir,
+		Title:        "Docs",
+		SiteURL:      "https://example.com/",
+		ShowPageNav:  true,
+		Languages:    []string{"fr", "ar", "de"},
+		Translations: map[string]map[string]string{"fr": {"previous": "Avant !"}},
+	}, &logs)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(logs.String(), `No pages found for language "de"`) {
+		t.Error("languages without pages should be reported")
+	}
+
+	read := func(path string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(outputDir, path))
+		if err != nil {
+			t.Fatalf("%s not written: %v", path, err)
+		}
+		return string(data)
+	}
+
+	fr := read("fr/guides/intro/index.html")
+	for _, want := range []string{
+		`<html lang="fr" dir="ltr">`,
+		`class="language-switcher"`,
+		`<link rel="alternate" hreflang="en" href="https://example.com/guides/intro/">`,
+		`<link rel="alternate" hreflang="x-default" href="https://example.com/guides/intro/">`,
+		`<link rel="alternate" hreflang="ar" href="https://example.com/ar/guides/intro/">`,
+		"Pied de page",
+		"Introduction",
+	} {
+		if !strings.Contains(fr, want) {
+			t.Errorf("fr page missing %q", want)
+		}
+	}
+	if !strings.Contains(fr, "Avant !") {
+		t.Error("fr page navigation should use the translation override")
+	}
+
+	if ar := read("ar/guides/intro/index.html"); !strings.Contains(ar, `<html lang="ar" dir="rtl">`) || !strings.Contains(ar, ">Footer<") {
+		t.Error("ar page should be right-to-left with the default footer")
+	}
+
+	en := read("guides/setup/index.html")
+	if !strings.Contains(en, `<html lang="en" dir="ltr">`) || strings.Contains(en, `rel="alternate" hreflang="fr"`) {
+		t.Error("untranslated pages should only link to existing translations")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "guides", "intro.fr", "index.html")); err == nil {
+		t.Error("translations should not be generated as default-language pages")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "de")); err == nil {
+		t.Error("languages without pages should not be generated")
+	}
+}
+
+func TestGenerateVersions(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"v1/index.md":        "# Home v1",
+		"v1/guides/intro.md": "# Intro v1\n\nSee [home](/).",
+		"v1/old.md":          "---\nlayout: landing\n---\n# Old",
+		"v2/index.md":        "# Home v2",
+		"v2/guides/intro.md": "# Intro v2",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(inputDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, latest, err := versions.Resolve(&config.VersionsConfig{}, inputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := versions.NewSet(list, latest, "https://example.com/docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	result, err := GenerateVersions(Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Title:     "Docs",
+		SiteURL:   "https://example.com/docs",
+		Search:    true,
+		Clean:     true,
+		Quiet:     true,
+	}, set, &buf)
+	if err != nil {
+		t.Fatalf("GenerateVersions() error = %v", err)
+	}
+	if result.PagesGenerated != 7 {
+		t.Errorf("PagesGenerated = %d, want 7 (v1, v2 and latest)", result.PagesGenerated)
+	}
+
+	// Each version has its own pages and search index
+	for _, path := range []string{
+		"v1/old/index.html", "v1/search-index.json",
+		"v2/guides/intro/index.html", "v2/search-index.json",
+		"latest/guides/intro/index.html", "latest/search-index.json",
+		"index.html", "404.html",
+	} {
+		if _, err := os.Stat(filepath.Join(outputDir, path)); err != nil {
+			t.Errorf("missing %s", path)
+		}
+	}
+	index := readSearchIndex(t, filepath.Join(outputDir, "v1"))
+	if !strings.Contains(index, `"/old/"`) || strings.Contains(index, "Intro v2") {
+		t.Error("the v1 search index should hold v1 pages only")
+	}
+
+	v1, err := os.ReadFile(filepath.Join(outputDir, "v1", "guides", "intro", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<summary aria-label="Version">v1</summary>`,
+		`<li><a href="/docs/latest/guides/intro/">v2 <span class="version-latest">(latest)</span></a></li>`,
+		`<li><a href="/docs/v1/guides/intro/" aria-current="true" class="active">v1</a></li>`,
+		`<div class="version-banner" role="note">`,
+		`<a href="/docs/v1/">home</a>`,
+		`<link rel="canonical" href="https://example.com/docs/v1/guides/intro/">`,
+	} {
+		if !strings.Contains(string(v1), want) {
+			t.Errorf("v1 intro page missing %q", want)
+		}
+	}
+
+	// Layouts without the sidebar keep the version switcher, in the header
+	old, err := os.ReadFile(filepath.Join(outputDir, "v1", "old", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(old), `class="tree-nav"`) || !strings.Contains(string(old), `<summary aria-label="Version">v1</summary>`) {
+		t.Error("the v1 landing page should have the version switcher without the sidebar")
+	}
+
+	// The latest version has no banner, and its pages name /latest/ as canonical
+	v2, err := os.ReadFile(filepath.Join(outputDir, "v2", "guides", "intro", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(v2), `class="version-banner"`) {
+		t.Error("the latest version should not show the outdated banner")
+	}
+	if !strings.Contains(string(v2), `<link rel="canonical" href="https://example.com/docs/latest/guides/intro/">`) {
+		t.Error("v2 pages should name their /latest/ copy as canonical")
+	}
+
+	redirect, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(redirect), `url=/docs/latest/`) || !strings.Contains(string(redirect), `<html lang="en" dir="ltr">`) {
+		t.Errorf("root index.html should redirect to /docs/latest/:\n%s", redirect)
+	}
+
+	// The redirect page is in the site's default language
+	frDir := t.TempDir()
+	if err := writeVersionsRoot(frDir, set, "fr", i18n.Load("fr", nil)); err != nil {
+		t.Fatal(err)
+	}
+	frRedirect, err := os.ReadFile(filepath.Join(frDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<html lang="fr" dir="ltr">`, "<title>Redirection…</title>", ">Voir la dernière version</a>"} {
+		if !strings.Contains(string(frRedirect), want) {
+			t.Errorf("French redirect page missing %q:\n%s", want, frRedirect)
+		}
+	}
+
+	// v2's pages are canonical under /latest/, so only v1 and latest have
+	// sitemaps, named by the robots.txt at the root
+	if _, err := os.Stat(filepath.Join(outputDir, "v2", "sitemap.xml")); err == nil {
+		t.Error("v2 should have no sitemap")
+	}
+	robots, err := os.ReadFile(filepath.Join(outputDir, "robots.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "User-agent: *\nAllow: /\n\nSitemap: https://example.com/docs/v1/sitemap.xml\nSitemap: https://example.com/docs/latest/sitemap.xml\n"
+	if string(robots) != want {
+		t.Errorf("robots.txt = %q, want %q", robots, want)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "v1", "robots.txt")); err == nil {
+		t.Error("versions should not have their own robots.txt")
+	}
+}
+
+func TestGenerateListing(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.md":                     "# Home",
+		"blog/index.md":                "# Blog\n\nNews from the team.",
+		"blog/2024-01-10-launch.md":    "---\ncover: images/launch.png\n---\n# Launch\n\nWe launched today.",
+		"blog/2024-02-20-update.md":    "# Update\n\nA small update.",
+		"blog/2024-03-05-roadmap.md":   "---\nexcerpt: What comes next.\n---\n# Roadmap",
+		"changelog/2024-04-01-v1.md":   "# v1.0",
+		"changelog/2023-11-12-beta.md": "# Beta",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(inputDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Title:     "News",
+		SiteURL:   "https://example.com/docs/",
+		Folders: map[string]config.FolderConfig{
+			"blog":      {Listing: &config.ListingConfig{PageSize: 2}},
+			"changelog": {Listing: &config.ListingConfig{Style: "timeline"}},
+		},
+	}
+	g, err := New(cfg, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	read := func(path string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(outputDir, path))
+		if err != nil {
+			t.Fatalf("%s not written: %v", path, err)
+		}
+		return string(content)
+	}
+
+	// The index page keeps its content and lists the newest posts below it
+	blog := read("blog/index.html")
+	for _, want := range []string{
+		"News from the team.",
+		`<section class="listing listing-list">`,
+		`<a href="/docs/blog/roadmap/">Roadmap</a>`,
+		`<p class="listing-excerpt">What comes next.</p>`,
+		`<a href="/docs/blog/update/">Update</a>`,
+		`<a href="/docs/blog/page/2/" class="listing-older" rel="next">`,
+	} {
+		if !strings.Contains(blog, want) {
+			t.Errorf("blog/index.html should contain %q", want)
+		}
+	}
+	if strings.Contains(blog, `<a href="/docs/blog/launch/">Launch</a>`) {
+		t.Error("the oldest post should be on page 2")
+	}
+
+	page2 := read("blog/page/2/index.html")
+	for _, want := range []string{
+		`<title>Blog – Page 2 of 2`,
+		`<a href="/docs/blog/launch/">Launch</a>`,
+		`<img src="/docs/images/launch.png" alt="" loading="lazy">`,
+		`<a href="/docs/blog/" class="listing-newer" rel="prev">`,
+	} {
+		if !strings.Contains(page2, want) {
+			t.Errorf("blog/page/2/index.html should contain %q", want)
+		}
+	}
+
+	// A folder without an index page gets its listing instead of an auto-index
+	changelog := read("changelog/index.html")
+	if !strings.Contains(changelog, `<section class="listing listing-timeline">`) || !strings.Contains(changelog, `<li class="listing-year">2023</li>`) {
+		t.Error("changelog/index.html should hold the timeline listing")
+	}
+	if strings.Contains(changelog, `class="folder-index"`) {
+		t.Error("changelog/index.html should not hold the auto-index")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "changelog", "page")); err == nil {
+		t.Error("a single-page listing should not write later pages")
+	}
+
+	// Invalid listing settings fail the build
+	cfg.OutputDir = filepath.Join(tmpDir, "bad")
+	cfg.Folders = map[string]config.FolderConfig{"blog": {Listing: &config.ListingConfig{Style: "grid"}}}
+	g, err = New(cfg, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Generate(); err == nil || !strings.Contains(err.Error(), `listing for blog: unknown listing style "grid"`) {
+		t.Errorf("Generate() error = %v, want the listing style reported", err)
+	}
+}
+
+func TestGenerateArchives(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.md":                        "# Home",
+		"blog/index.md":                   "# Blog\n\nSee the [archive](/blog/archive/) and [March](/blog/2024/03/).",
+		"blog/2024-03-15-spring.md":       "# Spring",
+		"blog/2024-01-10-launch.md":       "# Launch",
+		"blog/2023/index.md":              "# 2023 Recap",
+		"blog/2023/2023-05-01-kickoff.md": "# Kickoff",
+		"guides/2024-02-01-setup.md":      "# Setup",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(inputDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var logs bytes.Buffer
+	g, err := New(Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Title:     "News",
+		SiteURL:   "https://example.com/",
+		Folders:   map[string]config.FolderConfig{"blog": {Archive: true}},
+	}, &logs)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	read := func(path string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(outputDir, path))
+		if err != nil {
+			t.Fatalf("%s not written: %v", path, err)
+		}
+		return string(content)
+	}
+
+	archive := read("blog/archive/index.html")
+	for _, want := range []string{
+		`<title>Blog – Archive`,
+		`<h2><a href="/blog/2024/">2024</a></h2>`,
+		`<a href="/blog/2023/kickoff/">Kickoff</a>`,
+	} {
+		if !strings.Contains(archive, want) {
+			t.Errorf("blog/archive/index.html should contain %q", want)
+		}
+	}
+	if strings.Contains(archive, `<time datetime="2024-02-01">`) {
+		t.Error("the blog archive should not list pages outside the blog")
+	}
+	if month := read("blog/2024/03/index.html"); !strings.Contains(month, `<h1>Blog – March 2024</h1>`) {
+		t.Error("blog/2024/03/index.html should hold the month's pages")
+	}
+	read("blog/2023/05/index.html")
+
+	// A real folder keeps its URL
+	if recap := read("blog/2023/index.html"); !strings.Contains(recap, "2023 Recap") || strings.Contains(recap, "archive-page") {
+		t.Error("blog/2023/ should keep its own index page")
+	}
+	if !strings.Contains(logs.String(), "Skipping archive page /blog/2023/") {
+		t.Errorf("expected a warning for the skipped year page:\n%s", logs.String())
+	}
+
+	// Dated pages link their date to the month archive
+	if spring := read("blog/spring/index.html"); !strings.Contains(spring, `<time datetime="2024-03-15"><a href="/blog/2024/03/">2024-03-15</a></time>`) {
+		t.Error("blog/spring/ should link its date to the month archive")
+	}
+	if setup := read("guides/setup/index.html"); strings.Contains(setup, `class="page-date"`) {
+		t.Error("pages outside an archive should not show the date")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "archive")); err == nil {
+		t.Error("no site-wide archive should be written without the root setting")
+	}
+}
+
+func TestGenerateRelated(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.md":    "# Home",
+		"kafka.md":    "---\ntags: [streaming]\n---\n# Kafka\n\nBrokers and partitions.",
+		"pulsar.md":   "---\ntags: [streaming]\n---\n# Pulsar\n\nBrokers and topics.",
+		"postgres.md": "---\nrelated: [kafka]\n---\n# Postgres\n\nTables and indexes.",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(inputDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g, err := New(Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Title:     "Docs",
+		SiteURL:   "https://example.com/docs/",
+		Related:   2,
+	}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	kafka, err := os.ReadFile(filepath.Join(outputDir, "kafka", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(kafka), `<h2 class="related-pages-title">Related pages</h2>`) ||
+		!strings.Contains(string(kafka), `<li><a href="/docs/pulsar/">Pulsar</a></li>`) {
+		t.Error("kafka/index.html should suggest Pulsar")
+	}
+	postgres, err := os.ReadFile(filepath.Join(outputDir, "postgres", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(postgres), `<li><a href="/docs/kafka/">Kafka</a></li>`) ||
+		strings.Contains(string(postgres), `<li><a href="/docs/pulsar/">`) {
+		t.Error("postgres/index.html should list only its related front matter")
+	}
+
+	// An unknown related page fails the build
+	if err := os.WriteFile(filepath.Join(inputDir, "postgres.md"), []byte("---\nrelated: [missing]\n---\n# Postgres"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g, err = New(Config{InputDir: inputDir, OutputDir: outputDir, Title: "Docs"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err == nil || !strings.Contains(err.Error(), `invalid related page: page "missing" not found`) {
+		t.Errorf("Generate() error = %v, want an invalid related page", err)
+	}
+}
+
+func TestGenerateGraph(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	outputDir := filepath.Join(tmpDir, "output")
+
+	files := map[string]string{
+		"index.md":           "# Home\n\nSee [[guides/setup]] and the [graph](/graph/).",
+		"guides/setup.md":    "---\ntags: [ops]\n---\n# Setup\n\n![[Install]]",
+		"guides/install.md":  "# Install\n\nBack to [setup](/guides/setup/).",
+		"reference/index.md": "# Reference",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(inputDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g, err := New(Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Title:     "Docs",
+		SiteURL:   "https://example.com/docs/",
+		Graph:     true,
+	}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	read := func(path string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(outputDir, path))
+		if err != nil {
+			t.Fatalf("%s not written: %v", path, err)
+		}
+		return string(content)
+	}
+
+	data := read("graph.json")
+	for _, want := range []string{
+		`{"source":"/","target":"/guides/setup/","type":"wikilink"}`,
+		`{"source":"/guides/setup/","target":"/guides/install/","type":"embed"}`,
+		`{"source":"/guides/install/","target":"/guides/setup/","type":"link"}`,
+		`{"source":"/guides/setup/","target":"tag:ops","type":"tag"}`,
+		`{"id":"/guides/setup/","type":"page","title":"Setup","url":"/docs/guides/setup/","folder":"/guides/"}`,
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("graph.json should contain %s:\n%s", want, data)
+		}
+	}
+	if js := read("graph.js"); !strings.Contains(js, "const baseURL = '/docs'") {
+		t.Error("graph.js should load graph.json from the base path")
+	}
+	if page := read("graph/index.html"); !strings.Contains(page, `<article class="graph-page">`) || !strings.Contains(page, `<script defer src="/docs/graph.js"></script>`) {
+		t.Error("graph/index.html should hold the full graph")
+	}
+	setup := read("guides/setup/index.html")
+	if !strings.Contains(setup, `<div class="graph-view" data-graph-center="/guides/setup/">`) || !strings.Contains(setup, `<a href="/docs/graph/?from=/guides/setup/">`) {
+		t.Error("guides/setup/index.html should show its local graph")
+	}
+
+	// A page at /graph/ keeps its URL
+	if err := os.WriteFile(filepath.Join(inputDir, "graph.md"), []byte("# My Graph"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var logs bytes.Buffer
+	g, err = New(Config{InputDir: inputDir, OutputDir: outputDir, Title: "Docs", Graph: true}, &logs)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if page := read("graph/index.html"); !strings.Contains(page, "My Graph") || strings.Contains(page, `class="graph-page"`) {
+		t.Error("graph.md should keep its URL")
+	}
+	if !strings.Contains(logs.String(), "Skipping graph page /graph/") {
+		t.Errorf("expected a warning for the skipped graph page:\n%s", logs.String())
+	}
+}
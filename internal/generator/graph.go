@@ -0,0 +1,51 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/wusher/volcano/internal/book"
+	"github.com/wusher/volcano/internal/graph"
+	"github.com/wusher/volcano/internal/markdown"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// generateGraph writes graph.json, built from the links of every page checked
+// for link validation, and graph.js. The full graph page is written in the
+// default language unless a page or folder already uses its URL. Returns the
+// URL paths of the pages written.
+func (g *Generator) generateGraph(site *tree.Site) ([]string, error) {
+	pages := make([]graph.Page, 0, len(g.generatedPages))
+	for _, p := range g.generatedPages {
+		pages = append(pages, graph.Page{
+			Node:  p.node,
+			Title: p.title,
+			Links: markdown.ExtractPageLinks(p.htmlContent, p.mdContent, p.sourceDir, g.config.SiteURL),
+			Tags:  p.tags,
+		})
+	}
+	data, err := graph.Build(pages, g.config.SiteURL).JSON()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(g.config.OutputDir, graph.FileName), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", graph.FileName, err)
+	}
+	g.logger.Verbose("  %s", graph.FileName)
+	if err := os.WriteFile(filepath.Join(g.config.OutputDir, graph.ScriptName), []byte(graph.GenerateJS(g.baseURL)), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", graph.ScriptName, err)
+	}
+	g.logger.Verbose("  %s", graph.ScriptName)
+
+	for _, node := range append(book.Folders(site.Root), site.AllPages...) {
+		if tree.GetURLPath(node) == graph.URLPath {
+			g.logger.Warning("Skipping graph page %s: a page already uses that URL", graph.URLPath)
+			return nil, nil
+		}
+	}
+	if err := g.writeFolderPage(site.Root, site.Root, g.lang.messages.T("graph"), graph.URLPath, graph.RenderPage(g.lang.messages)); err != nil {
+		return nil, err
+	}
+	return []string{graph.URLPath}, nil
+}
@@ -0,0 +1,129 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/includes"
+	"github.com/wusher/volcano/internal/templates"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// language is one language of the site: its interface text and includes
+type language struct {
+	code     string // Language code ("en", "fr")
+	scan     string // Language passed to tree scanning ("" for the default language)
+	messages i18n.Messages
+	includes *includes.Includes
+}
+
+// siteLanguage is a scanned language of the site
+type siteLanguage struct {
+	language
+	site *tree.Site
+}
+
+// loadLanguages prepares the default language and each translation language
+// from the config, rendering their includes
+func (g *Generator) loadLanguages() ([]language, error) {
+	defaultLang := i18n.Normalize(g.config.Language)
+	codes := []string{defaultLang}
+	for _, code := range g.config.Languages {
+		code = i18n.Normalize(code)
+		if !containsString(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+
+	languages := make([]language, 0, len(codes))
+	for i, code := range codes {
+		lang := language{code: code, messages: i18n.Load(code, g.config.Translations[code])}
+		if i > 0 {
+			lang.scan = code
+		}
+		inc, err := includes.LoadLanguage(g.config.InputDir, g.config.Includes, lang.scan, g.transformer)
+		if err != nil {
+			return nil, err
+		}
+		lang.includes = inc
+		languages = append(languages, lang)
+	}
+	return languages, nil
+}
+
+// scanLanguages scans every language of the site. Translation languages
+// without pages are skipped with a warning.
+func (g *Generator) scanLanguages() ([]siteLanguage, error) {
+	translationCodes := make([]string, 0, len(g.languages)-1)
+	for _, lang := range g.languages[1:] {
+		translationCodes = append(translationCodes, lang.code)
+	}
+
+	var sites []siteLanguage
+	for _, lang := range g.languages {
+		site, err := g.config.Includes.ScanLanguage(g.config.InputDir, lang.scan, translationCodes)
+		if err != nil {
+			return nil, err
+		}
+		if lang.scan != "" && len(site.AllPages) == 0 {
+			g.logger.Warning("No pages found for language %q (expected files like page.%s.md)", lang.code, lang.code)
+			continue
+		}
+		sites = append(sites, siteLanguage{language: lang, site: site})
+	}
+
+	// Match pages across the languages that have them
+	names := make(map[string]string, len(sites))
+	var codes []string
+	for i, s := range sites {
+		names[s.code] = s.messages.T("languageName")
+		if i > 0 {
+			codes = append(codes, s.code)
+		}
+	}
+	g.translations = i18n.NewTranslations(sites[0].code, codes, names)
+	for _, s := range sites {
+		g.translations.AddSite(s.code, s.site)
+	}
+	return sites, nil
+}
+
+// applyLanguage sets the current language's text and direction on page data,
+// with the language switcher and hreflang links for urlPath (empty for
+// pages that have no translations, such as the 404 page)
+func (g *Generator) applyLanguage(data *templates.PageData, urlPath string) {
+	data.Lang = g.lang.code
+	data.Dir = i18n.Dir(g.lang.code)
+	data.I18n = g.lang.messages
+	if urlPath == "" {
+		return
+	}
+	data.Translations = g.translations.Alternates(urlPath, g.lang.code, g.config.SiteURL)
+	data.MetaTags += i18n.HreflangLinks(data.Translations, g.config.SiteURL)
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// languageSummary describes the site's languages for the build log
+func languageSummary(sites []siteLanguage) string {
+	summary := ""
+	for i, s := range sites {
+		if i > 0 {
+			summary += ", "
+		}
+		pages := "pages"
+		if len(s.site.AllPages) == 1 {
+			pages = "page"
+		}
+		summary += fmt.Sprintf("%s (%d %s)", s.code, len(s.site.AllPages), pages)
+	}
+	return summary
+}
@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/wusher/volcano/internal/book"
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/listing"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// buildListings builds the listings of the site's folders that have listing
+// settings, keyed by folder path
+func (g *Generator) buildListings(site *tree.Site) (map[string]*listing.Listing, error) {
+	listings := make(map[string]*listing.Listing)
+	for _, folder := range append([]*tree.Node{site.Root}, book.Folders(site.Root)...) {
+		cfg := config.ResolveFolderConfig(g.config.Folders, filepath.Join(folder.Path, "index.md")).Listing
+		if cfg == nil {
+			continue
+		}
+		l, err := listing.Build(folder, site.AllPages, *cfg, g.config.SiteURL, g.lang.messages, g.renderNodeContent)
+		if err != nil {
+			return nil, fmt.Errorf("listing for %s: %w", folder.Path, err)
+		}
+		listings[folder.Path] = l
+	}
+	return listings, nil
+}
+
+// listingOf returns the listing of the folder whose index page is node
+func (g *Generator) listingOf(node *tree.Node) *listing.Listing {
+	for _, l := range g.listings {
+		if l.Folder.HasIndex && l.Folder.IndexPath == node.Path {
+			return l
+		}
+	}
+	return nil
+}
+
+// generateListingPages writes the listing pages of every listing folder:
+// the first page for folders without an index page, where it takes the
+// auto-index's place, and the later pages of all of them. Returns the URL
+// paths of the later pages.
+func (g *Generator) generateListingPages(root *tree.Node) ([]string, error) {
+	var urls []string
+	for _, l := range g.listings {
+		for _, page := range l.Pages {
+			if page.Number == 1 && l.Folder.HasIndex {
+				continue
+			}
+			title := l.Title
+			if page.Number > 1 {
+				title += " – " + g.lang.messages.PageOf(page.Number, len(l.Pages))
+				urls = append(urls, page.URLPath)
+			}
+			if err := g.writeFolderPage(l.Folder, root, title, page.URLPath, l.RenderPage(page.Number, g.lang.messages)); err != nil {
+				return nil, fmt.Errorf("failed to generate listing page %s: %w", page.URLPath, err)
+			}
+		}
+	}
+	return urls, nil
+}
+
+// renderNodeContent renders a page's markdown for a listing entry
+func (g *Generator) renderNodeContent(node *tree.Node) (string, string, tree.FrontMatter, error) {
+	mdContent, err := os.ReadFile(node.SourcePath)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	relDir := filepath.Dir(node.Path)
+	sourceDir := "/"
+	if relDir != "." && relDir != "" {
+		sourceDir = "/" + tree.SlugifyPath(relDir) + "/"
+	}
+
+	page, err := g.transformer.TransformMarkdown(
+		mdContent,
+		sourceDir,
+		node.SourcePath,
+		tree.GetOutputPath(node),
+		tree.GetURLPath(node),
+		node.Name,
+	)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return page.Title, page.Content, page.FrontMatter, nil
+}
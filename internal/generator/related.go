@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/wusher/volcano/internal/related"
+	"github.com/wusher/volcano/internal/templates"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// writePages writes the pages rendered for the language being generated,
+// adding their related pages. Returns the number of pages written.
+func (g *Generator) writePages(site *tree.Site) (int, error) {
+	pending := g.pending
+	g.pending = nil
+
+	// Related pages need every page's content; `related` front matter lists
+	// pages even when suggestions are off
+	var finder *related.Finder
+	wanted := g.config.Related > 0
+	pages := make([]related.Page, len(pending))
+	for i, p := range pending {
+		pages[i] = p.page
+		if _, ok := p.page.FrontMatter["related"]; ok {
+			wanted = true
+		}
+	}
+	if wanted {
+		finder = related.New(site, pages, g.config.SiteURL, g.config.Related)
+	}
+
+	for _, p := range pending {
+		node := p.page.Node
+		if finder != nil {
+			links, err := finder.Related(node)
+			if err != nil {
+				return 0, fmt.Errorf("failed to generate %s: %s: %w", node.Path, node.SourcePath, err)
+			}
+			p.data.Related = related.Render(links, g.lang.messages)
+		}
+		if err := g.writePage(p.outputPath, p.data); err != nil {
+			return 0, fmt.Errorf("failed to generate %s: %w", node.Path, err)
+		}
+		g.logger.FileSuccess(node.Path)
+	}
+	return len(pending), nil
+}
+
+// writePage renders a page to its output file
+func (g *Generator) writePage(fullOutputPath string, data templates.PageData) error {
+	// Create output directory
+	outputDir := filepath.Dir(fullOutputPath)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", outputDir, err)
+	}
+
+	// Write file
+	f, err := os.Create(fullOutputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", fullOutputPath, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := g.renderer.Render(f, data); err != nil {
+		return fmt.Errorf("failed to render page: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wusher/volcano/internal/assets"
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/navigation"
+	"github.com/wusher/volcano/internal/seo"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// seoConfig returns the SEO settings of a page, with its indexing, its
+// `author` front matter and its Open Graph image: the page's `ogImage` front
+// matter, else a card generated for it when ogImages is on, else the site's
+// --og-image
+func (g *Generator) seoConfig(node *tree.Node, title string, fm tree.FrontMatter, ix indexing) seo.Config {
+	cfg := seo.Config{
+		SiteURL:   g.canonicalSiteURL(),
+		SiteTitle: g.config.Title,
+		Author:    g.config.Author,
+		OGImage:   g.ogImageURL, // Use processed URL, not raw path
+		Robots:    ix.robots,
+		Canonical: ix.canonical,
+	}
+	if author := strings.TrimSpace(fm.String("author")); author != "" {
+		cfg.Author = author
+	}
+
+	// Front matter keys are lowercased, so this is `ogImage`
+	if image := strings.TrimSpace(fm.String("ogimage")); image != "" {
+		if !strings.Contains(image, "://") && !strings.HasPrefix(image, "//") {
+			image = strings.TrimSuffix(g.config.SiteURL, "/") + "/" + strings.TrimPrefix(image, "/")
+		}
+		cfg.OGImage = image
+		return cfg
+	}
+	if !g.config.OGImages {
+		return cfg
+	}
+
+	card := assets.OGCard{
+		Title:     title,
+		Section:   sectionName(node),
+		SiteTitle: g.config.Title,
+		Accent:    g.config.AccentColor,
+	}
+	if card.Section == title {
+		card.Section = ""
+	}
+	image, err := assets.WriteOGCard(card, g.config.OutputDir, g.config.SiteURL)
+	if err != nil {
+		g.logger.Warning("Failed to generate og image for %s: %v", tree.GetURLPath(node), err)
+		return cfg
+	}
+	cfg.OGImage = image
+	cfg.OGImageWidth = assets.OGCardWidth
+	cfg.OGImageHeight = assets.OGCardHeight
+	return cfg
+}
+
+// sectionName returns the display name of the top-level folder a node is,
+// or is in ("" at the top level)
+func sectionName(node *tree.Node) string {
+	for ; node != nil && node.Parent != nil; node = node.Parent {
+		if node.IsFolder && node.Parent.Parent == nil {
+			return node.Name
+		}
+	}
+	return ""
+}
+
+// structuredData describes a page as schema.org JSON-LD, from its meta tags.
+// The type is the page's `schema` front matter, else its folder's `schema`
+// setting, else follows from the page: CollectionPage for generated folder
+// pages, WebPage for the home page, BlogPosting for dated pages in a listing,
+// Article for other dated pages and TechArticle for the rest. Returns nil
+// unless --url is a full URL, since JSON-LD needs absolute URLs.
+func (g *Generator) structuredData(node *tree.Node, folderPage bool, fm tree.FrontMatter, meta seo.PageMeta) (*seo.StructuredData, error) {
+	siteURL := strings.TrimSuffix(g.canonicalSiteURL(), "/")
+	if !strings.Contains(siteURL, "://") {
+		return nil, nil
+	}
+
+	pagePath := node.Path
+	if folderPage {
+		pagePath = filepath.Join(node.Path, "index.md")
+	}
+	folder := config.ResolveFolderConfig(g.config.Folders, pagePath)
+	schema := strings.TrimSpace(fm.String("schema"))
+	if schema == "" {
+		schema = folder.Schema
+	}
+	if err := seo.ValidateSchema(schema); err != nil {
+		return nil, err
+	}
+
+	crumbs := navigation.BuildBreadcrumbsWithBaseURL(node, g.config.Title, "")
+	data := &seo.StructuredData{
+		Type:        schema,
+		SiteName:    g.config.Title,
+		Home:        len(crumbs) == 1,
+		Title:       meta.OG.Title,
+		Description: meta.SEO.Description,
+		URL:         meta.SEO.Canonical,
+		Image:       meta.OG.Image,
+		Author:      meta.SEO.Author,
+		Language:    g.lang.code,
+	}
+	if data.Title == "" {
+		// A language's home page, generated without an index page
+		data.Title = g.config.Title
+	}
+	if !folderPage {
+		data.DatePublished = tree.NodeDate(node)
+		if updated, err := time.Parse("2006-01-02", fm.String("updated")); err == nil {
+			data.DateModified = updated
+		}
+	}
+	if data.Type == "" {
+		switch {
+		case folderPage:
+			data.Type = seo.SchemaCollection
+		case data.Home:
+			data.Type = seo.SchemaWebPage
+		case !data.DatePublished.IsZero() && folder.Listing != nil:
+			data.Type = seo.SchemaBlogPosting
+		case !data.DatePublished.IsZero():
+			data.Type = seo.SchemaArticle
+		default:
+			data.Type = seo.SchemaTechArticle
+		}
+	}
+
+	// The home page of a translation is its language folder
+	data.SiteURL = siteURL + crumbs[0].URL
+	if data.Home {
+		data.SiteURL = data.URL
+	}
+	if g.searchEnabled {
+		data.SearchURL = data.SiteURL + "?q={search_term_string}"
+	}
+	if !data.Home {
+		for _, crumb := range crumbs {
+			url := meta.SEO.Canonical
+			if crumb.URL != "" {
+				url = siteURL + crumb.URL
+			}
+			data.Breadcrumbs = append(data.Breadcrumbs, seo.Breadcrumb{Name: crumb.Label, URL: url})
+		}
+	}
+
+	if err := data.Validate(); err != nil {
+		return nil, fmt.Errorf("structured data: %w", err)
+	}
+	return data, nil
+}
+
+// indexing is how search engines may treat a page
+type indexing struct {
+	robots    string // Robots directive ("" for the default)
+	canonical string // Canonical URL ("" without --url)
+	sitemap   bool   // List the page in sitemap.xml
+}
+
+// indexingOf returns a page's robots directive, canonical URL and whether
+// it's in the sitemap, from its `robots`, `canonical` and `sitemap` front
+// matter, else its folder's settings. Pages that aren't indexable or are
+// canonical at another URL are never in the sitemap.
+func (g *Generator) indexingOf(node *tree.Node, folderPage bool, urlPath string, fm tree.FrontMatter) (indexing, error) {
+	pagePath := node.Path
+	if folderPage {
+		pagePath = filepath.Join(node.Path, "index.md")
+	}
+	folder := config.ResolveFolderConfig(g.config.Folders, pagePath)
+
+	ix := indexing{sitemap: true}
+	var err error
+	if ix.robots, err = seo.PageRobots(fm, folder); err != nil {
+		return ix, err
+	}
+
+	// A page's canonical URL is its own, or its /latest/ copy's in the
+	// latest version
+	if ix.canonical, err = seo.PageCanonical(g.canonicalSiteURL(), urlPath, fm, folder); err != nil {
+		return ix, err
+	}
+
+	if sitemap, ok := fm.Bool("sitemap"); ok {
+		ix.sitemap = sitemap
+	} else if folder.Sitemap != nil {
+		ix.sitemap = *folder.Sitemap
+	}
+	own := strings.TrimSuffix(g.config.SiteURL, "/") + urlPath
+	if !seo.Indexable(ix.robots) || ix.canonical != own {
+		ix.sitemap = false
+	}
+	return ix, nil
+}
+
+// addToSitemap lists a page in sitemap.xml if its indexing allows it
+func (g *Generator) addToSitemap(ix indexing, lastMod time.Time) {
+	if ix.sitemap {
+		g.sitemap = append(g.sitemap, seo.SitemapEntry{URL: ix.canonical, LastMod: lastMod})
+	}
+}
+
+// collectDisallowed adds node, and the folders below it, to the paths
+// robots.txt disallows when their folder settings set `crawl` to false and
+// their parent folder's don't, or to the paths it allows again when it's the
+// other way round. Noindex folders stay crawlable, so that crawlers see
+// their noindex tags.
+func (g *Generator) collectDisallowed(node *tree.Node, parentCrawled bool) {
+	crawl := config.ResolveFolderConfig(g.config.Folders, filepath.Join(node.Path, "index.md")).Crawl
+	crawled := crawl == nil || *crawl
+	if parentCrawled && !crawled {
+		g.disallow = append(g.disallow, g.baseURL+tree.GetURLPath(node))
+	} else if !parentCrawled && crawled {
+		g.allow = append(g.allow, g.baseURL+tree.GetURLPath(node))
+	}
+	for _, child := range node.Children {
+		if child.IsFolder {
+			g.collectDisallowed(child, crawled)
+		}
+	}
+}
+
+// writeSitemap writes sitemap.xml, listing the pages search engines may
+// index, and robots.txt pointing at it. Both need --url to be a full URL.
+// A versioned site's robots.txt is written at its root, by
+// writeVersionsRoot.
+func (g *Generator) writeSitemap() error {
+	if !strings.Contains(g.config.SiteURL, "://") {
+		return nil
+	}
+	var sitemaps []string
+	if len(g.sitemap) > 0 {
+		data, err := seo.RenderSitemap(g.sitemap)
+		if err != nil {
+			return fmt.Errorf("failed to render sitemap: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(g.config.OutputDir, seo.SitemapFile), data, 0644); err != nil {
+			return fmt.Errorf("failed to write sitemap: %w", err)
+		}
+		g.logger.Verbose("  %s (%d pages)", seo.SitemapFile, len(g.sitemap))
+		sitemaps = append(sitemaps, strings.TrimSuffix(g.config.SiteURL, "/")+"/"+seo.SitemapFile)
+	}
+	if g.config.Versions != nil {
+		return nil
+	}
+	robots := seo.RenderRobotsTxt(g.disallow, g.allow, sitemaps)
+	if err := os.WriteFile(filepath.Join(g.config.OutputDir, "robots.txt"), []byte(robots), 0644); err != nil {
+		return fmt.Errorf("failed to write robots.txt: %w", err)
+	}
+	g.logger.Verbose("  robots.txt")
+	return nil
+}
@@ -0,0 +1,140 @@
+package generator
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/seo"
+	"github.com/wusher/volcano/internal/templates"
+	"github.com/wusher/volcano/internal/versions"
+)
+
+// GenerateVersions builds every version of a versioned site, each into its
+// own folder of the output directory (/v1/, /v2/), then the latest version
+// again into /latest/. The site root redirects to /latest/.
+func GenerateVersions(config Config, set *versions.Set, writer io.Writer) (*Result, error) {
+	if config.Clean {
+		if err := os.RemoveAll(config.OutputDir); err != nil {
+			return nil, fmt.Errorf("failed to clean output directory: %w", err)
+		}
+	}
+
+	result := &Result{}
+	var disallow, allow []string
+	for _, prefix := range set.Prefixes() {
+		version := set.Version(prefix)
+		vc := config
+		vc.InputDir = version.Dir
+		vc.OutputDir = filepath.Join(config.OutputDir, prefix)
+		vc.SiteURL = set.SiteURL(prefix)
+		vc.Clean = false
+		vc.Versions = set
+		vc.Version = prefix
+
+		gen, err := New(vc, writer)
+		if err != nil {
+			return nil, fmt.Errorf("version %s: %w", prefix, err)
+		}
+		vr, err := gen.Generate()
+		if err != nil {
+			return nil, fmt.Errorf("version %s: %w", prefix, err)
+		}
+		result.PagesGenerated += vr.PagesGenerated
+		result.Warnings = append(result.Warnings, vr.Warnings...)
+		disallow = append(disallow, gen.disallow...)
+		allow = append(allow, gen.allow...)
+	}
+
+	lang := i18n.Normalize(config.Language)
+	if err := writeVersionsRoot(config.OutputDir, set, lang, i18n.Load(lang, config.Translations[lang])); err != nil {
+		return nil, err
+	}
+	if err := writeVersionsRobots(config.OutputDir, config.SiteURL, set, disallow, allow); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// writeVersionsRoot writes the site root of a versioned site: an index
+// page redirecting to the latest version, in the site's default language,
+// and the latest version's 404 page
+func writeVersionsRoot(outputDir string, set *versions.Set, lang string, messages i18n.Messages) error {
+	latest, _ := set.URL(versions.LatestPrefix, "/")
+	escaped := template.HTMLEscapeString(latest)
+	redirect := `<!DOCTYPE html>
+<html lang="` + template.HTMLEscapeString(lang) + `" dir="` + i18n.Dir(lang) + `">
+<head>
+<meta charset="utf-8">
+<title>` + template.HTMLEscapeString(messages.T("redirecting")) + `</title>
+<link rel="canonical" href="` + escaped + `">
+<meta http-equiv="refresh" content="0; url=` + escaped + `">
+</head>
+<body>
+<p><a href="` + escaped + `">` + template.HTMLEscapeString(messages.T("viewLatestVersion")) + `</a></p>
+</body>
+</html>
+`
+	if err := os.WriteFile(filepath.Join(outputDir, "index.html"), []byte(redirect), 0644); err != nil {
+		return fmt.Errorf("failed to write index.html: %w", err)
+	}
+
+	notFound, err := os.ReadFile(filepath.Join(outputDir, versions.LatestPrefix, "404.html"))
+	if err != nil {
+		return nil // Empty sites have no 404 page
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "404.html"), notFound, 0644); err != nil {
+		return fmt.Errorf("failed to write 404.html: %w", err)
+	}
+	return nil
+}
+
+// writeVersionsRobots writes the robots.txt of a versioned site at its root,
+// with the disallowed and allowed paths of every version and the sitemaps of
+// the versions that have one. It needs --url to be a full URL.
+func writeVersionsRobots(outputDir, siteURL string, set *versions.Set, disallow, allow []string) error {
+	if !strings.Contains(siteURL, "://") {
+		return nil
+	}
+	var sitemaps []string
+	for _, prefix := range set.Prefixes() {
+		if _, err := os.Stat(filepath.Join(outputDir, prefix, seo.SitemapFile)); err == nil {
+			sitemaps = append(sitemaps, set.SiteURL(prefix)+"/"+seo.SitemapFile)
+		}
+	}
+	robots := seo.RenderRobotsTxt(disallow, allow, sitemaps)
+	if err := os.WriteFile(filepath.Join(outputDir, "robots.txt"), []byte(robots), 0644); err != nil {
+		return fmt.Errorf("failed to write robots.txt: %w", err)
+	}
+	return nil
+}
+
+// applyVersion sets the version menu and outdated-version banner on page
+// data for urlPath (empty for pages without counterparts, such as the 404
+// page, which only get the banner)
+func (g *Generator) applyVersion(data *templates.PageData, urlPath string) {
+	set := g.config.Versions
+	if set == nil {
+		return
+	}
+	data.Version = set.Version(g.config.Version).Label
+	if set.Outdated(g.config.Version) {
+		data.LatestVersion, _ = set.URL(versions.LatestPrefix, urlPath)
+	}
+	if urlPath != "" {
+		data.Versions = set.Links(g.config.Version, urlPath)
+	}
+}
+
+// canonicalSiteURL returns the site URL for canonical links. The latest
+// version's pages name their /latest/ copies, so search engines index one.
+func (g *Generator) canonicalSiteURL() string {
+	if g.config.Versions == nil {
+		return g.config.SiteURL
+	}
+	return g.config.Versions.CanonicalSiteURL(g.config.Version)
+}
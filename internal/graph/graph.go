@@ -0,0 +1,247 @@
+// Package graph builds the link graph of a site: its pages, tags and folders,
+// and the wiki links, markdown links and embeds between pages. The graph is
+// written to graph.json and drawn by a canvas script on the /graph/ page and
+// in a small local graph below each page.
+package graph
+
+import (
+	"encoding/json"
+	"html/template"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/markdown"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// URL path of the full graph page, and names of the files it loads
+const (
+	URLPath    = "/graph/"
+	FileName   = "graph.json"
+	ScriptName = "graph.js"
+)
+
+// Node types
+const (
+	TypePage   = "page"
+	TypeTag    = "tag"
+	TypeFolder = "folder"
+)
+
+// Edge types besides the link kinds of markdown.PageLink: a page's tags and
+// the folder it's in
+const (
+	EdgeTag    = "tag"
+	EdgeFolder = "folder"
+)
+
+// localDepth is how many links away from a page its local graph reaches
+const localDepth = 1
+
+// Graph is the nodes and edges of a site
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Node is a page, tag or folder
+type Node struct {
+	ID     string `json:"id"`               // Page URL path, "tag:<name>" or "folder:<URL path>"
+	Type   string `json:"type"`             // TypePage, TypeTag or TypeFolder
+	Title  string `json:"title"`            // Page title, tag name or folder name
+	URL    string `json:"url,omitempty"`    // Link to the page or folder, with base URL
+	Folder string `json:"folder,omitempty"` // URL path of the folder a page is in, or of the folder itself
+}
+
+// Edge connects two nodes
+type Edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"` // A markdown link kind, EdgeTag or EdgeFolder
+}
+
+// Page is a rendered page of the site
+type Page struct {
+	Node  *tree.Node
+	Title string
+	Links []markdown.PageLink // Internal links of its content
+	Tags  []string
+}
+
+// Build builds the graph of a site's pages. baseURL is the site URL or base
+// path that links in the content, and the node URLs, carry. Nodes and edges
+// are sorted, so the same pages always give the same graph.
+func Build(pages []Page, baseURL string) *Graph {
+	g := &Graph{Nodes: []Node{}, Edges: []Edge{}}
+	nodes := make(map[string]bool)
+	edges := make(map[Edge]bool)
+	addNode := func(n Node) {
+		if !nodes[n.ID] {
+			nodes[n.ID] = true
+			g.Nodes = append(g.Nodes, n)
+		}
+	}
+	addEdge := func(e Edge) {
+		if e.Source != e.Target && !edges[e] {
+			edges[e] = true
+			g.Edges = append(g.Edges, e)
+		}
+	}
+
+	for _, page := range pages {
+		urlPath := tree.GetURLPath(page.Node)
+		addNode(Node{
+			ID:     urlPath,
+			Type:   TypePage,
+			Title:  page.Title,
+			URL:    tree.PrefixURL(baseURL, urlPath),
+			Folder: folderPath(page.Node.Parent),
+		})
+	}
+
+	base := tree.ExtractBasePath(baseURL)
+	for _, page := range pages {
+		source := tree.GetURLPath(page.Node)
+		for _, link := range page.Links {
+			if target := linkTarget(link.URL, base); nodes[target] {
+				addEdge(Edge{Source: source, Target: target, Type: link.Kind})
+			}
+		}
+
+		for _, tag := range page.Tags {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" {
+				continue
+			}
+			addNode(Node{ID: "tag:" + tag, Type: TypeTag, Title: tag})
+			addEdge(Edge{Source: source, Target: "tag:" + tag, Type: EdgeTag})
+		}
+
+		// Each folder links to the one it's in, up to the top-level folders
+		from := source
+		for folder := page.Node.Parent; folder != nil && folder.Parent != nil; folder = folder.Parent {
+			urlPath := tree.GetURLPath(folder)
+			addNode(Node{
+				ID:     "folder:" + urlPath,
+				Type:   TypeFolder,
+				Title:  folder.Name,
+				URL:    tree.PrefixURL(baseURL, urlPath),
+				Folder: urlPath,
+			})
+			addEdge(Edge{Source: from, Target: "folder:" + urlPath, Type: EdgeFolder})
+			from = "folder:" + urlPath
+		}
+	}
+
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Type < b.Type
+	})
+	return g
+}
+
+// JSON returns the graph as written to graph.json
+func (g *Graph) JSON() ([]byte, error) {
+	return json.Marshal(g)
+}
+
+// RenderPage renders the content of the full graph page
+func RenderPage(messages i18n.Messages) template.HTML {
+	var sb strings.Builder
+	sb.WriteString(`<article class="graph-page">`)
+	sb.WriteString("\n")
+	sb.WriteString(`<h1>` + template.HTMLEscapeString(messages.T("graph")) + `</h1>`)
+	sb.WriteString("\n")
+	writeView(&sb, "", 0, messages)
+	sb.WriteString(`</article>`)
+	return template.HTML(sb.String())
+}
+
+// RenderLocal renders the local graph shown below the page at urlPath, with
+// a link to the full graph centred on the page
+func RenderLocal(urlPath, baseURL string, messages i18n.Messages) template.HTML {
+	title := template.HTMLEscapeString(messages.T("graph"))
+	var sb strings.Builder
+	sb.WriteString(`<section class="local-graph" aria-label="` + title + `">`)
+	sb.WriteString("\n")
+	sb.WriteString(`<h2 class="local-graph-title">` + title + `</h2>`)
+	sb.WriteString("\n")
+	writeView(&sb, urlPath, localDepth, messages)
+	href := tree.PrefixURL(baseURL, URLPath) + "?from=" + urlPath
+	sb.WriteString(`<p class="local-graph-open"><a href="` + template.HTMLEscapeString(href) + `">`)
+	sb.WriteString(template.HTMLEscapeString(messages.T("openGraph")))
+	sb.WriteString(`</a></p>`)
+	sb.WriteString("\n")
+	sb.WriteString(`</section>`)
+	return template.HTML(sb.String())
+}
+
+// writeView writes a graph canvas with its folder and depth filters. center
+// is the URL path of the page the view is centred on, "" for the whole site,
+// and depth how many links away from it nodes are shown (0: all).
+func writeView(sb *strings.Builder, center string, depth int, messages i18n.Messages) {
+	sb.WriteString(`<div class="graph-view" data-graph-center="` + template.HTMLEscapeString(center) + `">`)
+	sb.WriteString("\n")
+	sb.WriteString(`<div class="graph-controls">`)
+	sb.WriteString("\n")
+	sb.WriteString(`<label>` + template.HTMLEscapeString(messages.T("graphFolder")) + ` <select class="graph-folder">`)
+	sb.WriteString(`<option value="">` + template.HTMLEscapeString(messages.T("graphAllFolders")) + `</option>`)
+	sb.WriteString(`</select></label>`)
+	sb.WriteString("\n")
+	sb.WriteString(`<label>` + template.HTMLEscapeString(messages.T("graphDepth")) + ` <select class="graph-depth">`)
+	for _, d := range []int{1, 2, 3, 0} {
+		label := strconv.Itoa(d)
+		if d == 0 {
+			label = template.HTMLEscapeString(messages.T("graphAll"))
+		}
+		selected := ""
+		if d == depth {
+			selected = " selected"
+		}
+		sb.WriteString(`<option value="` + strconv.Itoa(d) + `"` + selected + `>` + label + `</option>`)
+	}
+	sb.WriteString(`</select></label>`)
+	sb.WriteString("\n")
+	sb.WriteString(`</div>`)
+	sb.WriteString("\n")
+	sb.WriteString(`<canvas class="graph-canvas"></canvas>`)
+	sb.WriteString("\n")
+	sb.WriteString(`</div>`)
+	sb.WriteString("\n")
+}
+
+// folderPath returns the URL path of the folder a page is in
+func folderPath(folder *tree.Node) string {
+	if folder == nil {
+		return "/"
+	}
+	return tree.GetURLPath(folder)
+}
+
+// linkTarget returns the page URL path an internal link points to, without
+// the base path, fragment or query; "" for links outside the base path
+func linkTarget(href, base string) string {
+	if i := strings.IndexAny(href, "#?"); i >= 0 {
+		href = href[:i]
+	}
+	if base != "" {
+		rest, ok := strings.CutPrefix(href, base)
+		if !ok {
+			return ""
+		}
+		href = rest
+	}
+	if !strings.HasSuffix(href, "/") {
+		href += "/"
+	}
+	return href
+}
@@ -0,0 +1,176 @@
+package graph
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/markdown"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+func scanSite(t *testing.T, files map[string]string) *tree.Site {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	site, err := tree.Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return site
+}
+
+func testPages(t *testing.T) []Page {
+	site := scanSite(t, map[string]string{
+		"index.md":              "# Home",
+		"faq.md":                "# FAQ",
+		"guides/setup.md":       "# Setup",
+		"guides/deep/config.md": "# Config",
+	})
+	links := map[string][]markdown.PageLink{
+		"index.md": {{URL: "/docs/guides/setup/#install", Kind: markdown.LinkWiki}},
+		"guides/setup.md": {
+			{URL: "/docs/faq/", Kind: markdown.LinkMarkdown},
+			{URL: "/docs/guides/deep/config", Kind: markdown.LinkEmbed},
+			{URL: "/docs/guides/setup/#top", Kind: markdown.LinkMarkdown}, // Itself
+			{URL: "/docs/missing/", Kind: markdown.LinkMarkdown},
+			{URL: "/docs/guides/diagram.png", Kind: markdown.LinkEmbed},
+			{URL: "/other/faq/", Kind: markdown.LinkMarkdown},
+		},
+	}
+	tags := map[string][]string{
+		"faq.md":          {"API"},
+		"guides/setup.md": {"api", " ops ", ""},
+	}
+	var pages []Page
+	for _, node := range site.AllPages {
+		pages = append(pages, Page{Node: node, Title: node.Name, Links: links[node.Path], Tags: tags[node.Path]})
+	}
+	return pages
+}
+
+func TestBuild(t *testing.T) {
+	g := Build(testPages(t), "https://example.com/docs/")
+
+	var nodes []string
+	for _, n := range g.Nodes {
+		nodes = append(nodes, n.Type+" "+n.ID+" "+n.Title+" "+n.URL+" "+n.Folder)
+	}
+	wantNodes := []string{
+		"page / Home /docs/ /",
+		"page /faq/ FAQ /docs/faq/ /",
+		"page /guides/deep/config/ Config /docs/guides/deep/config/ /guides/deep/",
+		"page /guides/setup/ Setup /docs/guides/setup/ /guides/",
+		"folder folder:/guides/ Guides /docs/guides/ /guides/",
+		"folder folder:/guides/deep/ Deep /docs/guides/deep/ /guides/deep/",
+		"tag tag:api api  ",
+		"tag tag:ops ops  ",
+	}
+	if strings.Join(nodes, "\n") != strings.Join(wantNodes, "\n") {
+		t.Errorf("nodes =\n%s\nwant\n%s", strings.Join(nodes, "\n"), strings.Join(wantNodes, "\n"))
+	}
+
+	var edges []string
+	for _, e := range g.Edges {
+		edges = append(edges, e.Source+" -"+e.Type+"-> "+e.Target)
+	}
+	wantEdges := []string{
+		"/ -wikilink-> /guides/setup/",
+		"/faq/ -tag-> tag:api",
+		"/guides/deep/config/ -folder-> folder:/guides/deep/",
+		"/guides/setup/ -link-> /faq/",
+		"/guides/setup/ -embed-> /guides/deep/config/",
+		"/guides/setup/ -folder-> folder:/guides/",
+		"/guides/setup/ -tag-> tag:api",
+		"/guides/setup/ -tag-> tag:ops",
+		"folder:/guides/deep/ -folder-> folder:/guides/",
+	}
+	if strings.Join(edges, "\n") != strings.Join(wantEdges, "\n") {
+		t.Errorf("edges =\n%s\nwant\n%s", strings.Join(edges, "\n"), strings.Join(wantEdges, "\n"))
+	}
+}
+
+func TestJSON(t *testing.T) {
+	first, err := Build(testPages(t), "/docs").JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := Build(testPages(t), "/docs").JSON()
+	if string(first) != string(second) {
+		t.Error("the same pages should give the same graph.json")
+	}
+
+	var decoded struct {
+		Nodes []map[string]string `json:"nodes"`
+		Edges []map[string]string `json:"edges"`
+	}
+	if err := json.Unmarshal(first, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Nodes[0]["id"] != "/" || decoded.Edges[0]["type"] != "wikilink" {
+		t.Errorf("graph.json = %s", first)
+	}
+	if _, ok := decoded.Nodes[len(decoded.Nodes)-1]["url"]; ok {
+		t.Error("tag nodes should have no url")
+	}
+
+	empty, _ := Build(nil, "").JSON()
+	if string(empty) != `{"nodes":[],"edges":[]}` {
+		t.Errorf("empty graph = %s", empty)
+	}
+}
+
+func TestRender(t *testing.T) {
+	page := string(RenderPage(i18n.English))
+	for _, want := range []string{
+		`<article class="graph-page">`,
+		`<h1>Graph</h1>`,
+		`<div class="graph-view" data-graph-center="">`,
+		`<option value="0" selected>All</option>`,
+		`<canvas class="graph-canvas"></canvas>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("RenderPage() missing %s:\n%s", want, page)
+		}
+	}
+
+	local := string(RenderLocal("/guides/setup/", "https://example.com/docs/", i18n.Load("de", nil)))
+	for _, want := range []string{
+		`<section class="local-graph" aria-label="Graph">`,
+		`<div class="graph-view" data-graph-center="/guides/setup/">`,
+		`<label>Ordner <select class="graph-folder"><option value="">Alle Ordner</option></select></label>`,
+		`<option value="1" selected>1</option>`,
+		`<a href="/docs/graph/?from=/guides/setup/">Ganzen Graphen öffnen</a>`,
+	} {
+		if !strings.Contains(local, want) {
+			t.Errorf("RenderLocal() missing %s:\n%s", want, local)
+		}
+	}
+}
+
+func TestLinkTarget(t *testing.T) {
+	tests := []struct {
+		href, base, want string
+	}{
+		{"/guides/setup/", "", "/guides/setup/"},
+		{"/guides/setup#install", "", "/guides/setup/"},
+		{"/docs/guides/?q=1", "/docs", "/guides/"},
+		{"/other/guides/", "/docs", ""},
+	}
+	for _, tt := range tests {
+		if got := linkTarget(tt.href, tt.base); got != tt.want {
+			t.Errorf("linkTarget(%q, %q) = %q, want %q", tt.href, tt.base, got, tt.want)
+		}
+	}
+}
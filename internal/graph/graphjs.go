@@ -0,0 +1,288 @@
+package graph
+
+// GenerateJS returns the JavaScript that draws every graph view on a page
+// (elements with the graph-view class) from graph.json. It lays the graph
+// out with a small force simulation on a canvas: no libraries, no CDN.
+// Clicking a page or folder opens it; the filters show one folder's nodes,
+// or only the nodes a few links away from the view's centre.
+func GenerateJS(baseURL string) string {
+	return `(function() {
+    const baseURL = '` + baseURL + `';
+    let graphData = null;
+
+    function loadGraph() {
+        if (!graphData) {
+            graphData = fetch(baseURL + '/` + FileName + `').then(function(res) { return res.json(); });
+        }
+        return graphData;
+    }
+
+    function initAll() {
+        document.querySelectorAll('.graph-view').forEach(function(view) {
+            if (view.dataset.graphReady) return;
+            view.dataset.graphReady = 'true';
+            loadGraph().then(function(data) {
+                setup(view, data);
+            }).catch(function(e) {
+                console.error('Failed to load graph:', e);
+            });
+        });
+    }
+
+    function setup(view, data) {
+        const canvas = view.querySelector('.graph-canvas');
+        const folderSelect = view.querySelector('.graph-folder');
+        const depthSelect = view.querySelector('.graph-depth');
+        const ctx = canvas.getContext('2d');
+        const byId = new Map();
+        const neighbors = new Map();
+        data.nodes.forEach(function(n) {
+            byId.set(n.id, n);
+            neighbors.set(n.id, new Set());
+        });
+        data.edges.forEach(function(e) {
+            neighbors.get(e.source).add(e.target);
+            neighbors.get(e.target).add(e.source);
+        });
+
+        // The full graph page can be centred on a page with ?from=
+        const local = view.dataset.graphCenter !== '';
+        let center = view.dataset.graphCenter || new URLSearchParams(window.location.search).get('from') || '';
+        if (!byId.has(center)) center = '';
+        if (local && (!center || neighbors.get(center).size === 0)) {
+            view.closest('.local-graph').hidden = true;
+            return;
+        }
+        if (!center) {
+            depthSelect.parentNode.hidden = true;
+        } else if (!local) {
+            depthSelect.value = '2';
+        }
+
+        data.nodes.filter(function(n) { return n.type === 'folder'; }).forEach(function(n) {
+            const option = document.createElement('option');
+            const level = n.folder.split('/').length - 3;
+            option.value = n.folder;
+            option.textContent = '\u00a0\u00a0'.repeat(level) + n.title;
+            folderSelect.appendChild(option);
+        });
+
+        // Positions start on a spiral in ID order, so layouts are the same on every visit
+        const positions = new Map();
+        data.nodes.forEach(function(n, i) {
+            const angle = i * 2.39996;
+            const radius = 12 * Math.sqrt(i + 1);
+            positions.set(n.id, { x: Math.cos(angle) * radius, y: Math.sin(angle) * radius, vx: 0, vy: 0 });
+        });
+
+        let nodes = [];
+        let edges = [];
+        let alpha = 1;
+        let hovered = null;
+        let frame = null;
+        let transform = { scale: 1, x: 0, y: 0 };
+
+        function filter() {
+            const folder = folderSelect.value;
+            const depth = center ? parseInt(depthSelect.value, 10) : 0;
+            const shown = new Set();
+            data.nodes.forEach(function(n) {
+                if (n.type !== 'tag' && (!folder || (n.folder || '').indexOf(folder) === 0)) shown.add(n.id);
+            });
+            data.edges.forEach(function(e) {
+                if (byId.get(e.target).type === 'tag' && shown.has(e.source)) shown.add(e.target);
+            });
+            if (center) shown.add(center);
+
+            let ids = shown;
+            if (center && depth > 0) {
+                ids = new Set([center]);
+                let layer = [center];
+                for (let d = 0; d < depth; d++) {
+                    const next = [];
+                    layer.forEach(function(id) {
+                        neighbors.get(id).forEach(function(other) {
+                            if (shown.has(other) && !ids.has(other)) {
+                                ids.add(other);
+                                next.push(other);
+                            }
+                        });
+                    });
+                    layer = next;
+                }
+            }
+
+            nodes = data.nodes.filter(function(n) { return ids.has(n.id); });
+            edges = data.edges.filter(function(e) { return ids.has(e.source) && ids.has(e.target); });
+            alpha = 1;
+            start();
+        }
+
+        function tick() {
+            for (let i = 0; i < nodes.length; i++) {
+                const a = positions.get(nodes[i].id);
+                for (let j = i + 1; j < nodes.length; j++) {
+                    const b = positions.get(nodes[j].id);
+                    const dx = a.x - b.x;
+                    const dy = a.y - b.y;
+                    const d2 = Math.max(dx * dx + dy * dy, 1);
+                    const force = 600 / d2 * alpha;
+                    a.vx += dx * force / Math.sqrt(d2);
+                    a.vy += dy * force / Math.sqrt(d2);
+                    b.vx -= dx * force / Math.sqrt(d2);
+                    b.vy -= dy * force / Math.sqrt(d2);
+                }
+            }
+            edges.forEach(function(e) {
+                const a = positions.get(e.source);
+                const b = positions.get(e.target);
+                const dx = b.x - a.x;
+                const dy = b.y - a.y;
+                const d = Math.max(Math.sqrt(dx * dx + dy * dy), 1);
+                const force = (d - 50) * 0.05 * alpha;
+                a.vx += dx / d * force;
+                a.vy += dy / d * force;
+                b.vx -= dx / d * force;
+                b.vy -= dy / d * force;
+            });
+            nodes.forEach(function(n) {
+                const p = positions.get(n.id);
+                p.vx -= p.x * 0.01 * alpha;
+                p.vy -= p.y * 0.01 * alpha;
+                p.x += p.vx;
+                p.y += p.vy;
+                p.vx *= 0.6;
+                p.vy *= 0.6;
+            });
+            alpha *= 0.98;
+        }
+
+        function radius(n) {
+            if (n.type === 'tag') return 3;
+            if (n.type === 'folder') return 5;
+            return Math.min(4 + neighbors.get(n.id).size * 0.5, 9);
+        }
+
+        function color(name, fallback) {
+            return getComputedStyle(view).getPropertyValue(name).trim() || fallback;
+        }
+
+        function draw() {
+            const ratio = window.devicePixelRatio || 1;
+            const width = canvas.clientWidth;
+            const height = canvas.clientHeight;
+            if (canvas.width !== width * ratio || canvas.height !== height * ratio) {
+                canvas.width = width * ratio;
+                canvas.height = height * ratio;
+            }
+
+            // Fit the nodes in the canvas
+            let minX = Infinity, minY = Infinity, maxX = -Infinity, maxY = -Infinity;
+            nodes.forEach(function(n) {
+                const p = positions.get(n.id);
+                minX = Math.min(minX, p.x);
+                minY = Math.min(minY, p.y);
+                maxX = Math.max(maxX, p.x);
+                maxY = Math.max(maxY, p.y);
+            });
+            const scale = Math.min((width - 40) / Math.max(maxX - minX, 1), (height - 40) / Math.max(maxY - minY, 1), 2);
+            transform = { scale: scale, x: width / 2 - (minX + maxX) / 2 * scale, y: height / 2 - (minY + maxY) / 2 * scale };
+
+            const accent = color('--accent', '#0ea5e9');
+            const text = color('--text-primary', '#333');
+            const muted = color('--text-muted', '#888');
+            const border = color('--border-color', '#ddd');
+
+            ctx.setTransform(ratio, 0, 0, ratio, 0, 0);
+            ctx.clearRect(0, 0, width, height);
+            ctx.lineWidth = 1;
+            edges.forEach(function(e) {
+                const a = screen(e.source);
+                const b = screen(e.target);
+                const active = hovered && (e.source === hovered.id || e.target === hovered.id);
+                ctx.strokeStyle = active ? accent : border;
+                ctx.setLineDash(e.type === 'tag' || e.type === 'folder' ? [2, 3] : []);
+                ctx.beginPath();
+                ctx.moveTo(a.x, a.y);
+                ctx.lineTo(b.x, b.y);
+                ctx.stroke();
+            });
+            ctx.setLineDash([]);
+
+            ctx.font = '11px system-ui, sans-serif';
+            ctx.textAlign = 'center';
+            nodes.forEach(function(n) {
+                const p = screen(n.id);
+                const r = radius(n);
+                ctx.fillStyle = n.type === 'page' ? accent : (n.type === 'folder' ? text : muted);
+                ctx.beginPath();
+                ctx.arc(p.x, p.y, r, 0, Math.PI * 2);
+                ctx.fill();
+                if (n.id === center) {
+                    ctx.strokeStyle = text;
+                    ctx.beginPath();
+                    ctx.arc(p.x, p.y, r + 3, 0, Math.PI * 2);
+                    ctx.stroke();
+                }
+                if (nodes.length <= 40 || n === hovered || n.id === center) {
+                    ctx.fillStyle = n === hovered ? text : muted;
+                    ctx.fillText(n.type === 'tag' ? '#' + n.title : n.title, p.x, p.y + r + 12);
+                }
+            });
+        }
+
+        function screen(id) {
+            const p = positions.get(id);
+            return { x: p.x * transform.scale + transform.x, y: p.y * transform.scale + transform.y };
+        }
+
+        function start() {
+            if (frame) return;
+            frame = requestAnimationFrame(function step() {
+                tick();
+                draw();
+                frame = alpha > 0.02 ? requestAnimationFrame(step) : null;
+            });
+        }
+
+        function nodeAt(event) {
+            const rect = canvas.getBoundingClientRect();
+            const x = event.clientX - rect.left;
+            const y = event.clientY - rect.top;
+            let found = null;
+            nodes.forEach(function(n) {
+                const p = screen(n.id);
+                const r = radius(n) + 4;
+                if ((p.x - x) * (p.x - x) + (p.y - y) * (p.y - y) <= r * r) found = n;
+            });
+            return found;
+        }
+
+        canvas.addEventListener('mousemove', function(event) {
+            const n = nodeAt(event);
+            if (n !== hovered) {
+                hovered = n;
+                canvas.style.cursor = n && n.url ? 'pointer' : '';
+                canvas.title = n ? n.title : '';
+                draw();
+            }
+        });
+        canvas.addEventListener('mouseleave', function() {
+            hovered = null;
+            draw();
+        });
+        canvas.addEventListener('click', function(event) {
+            const n = nodeAt(event);
+            if (n && n.url) window.location.href = n.url;
+        });
+        folderSelect.addEventListener('change', filter);
+        depthSelect.addEventListener('change', filter);
+        window.addEventListener('resize', draw);
+        filter();
+    }
+
+    initAll();
+    document.addEventListener('instant:navigated', initAll);
+})();
+`
+}
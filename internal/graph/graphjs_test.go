@@ -0,0 +1,24 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateJS(t *testing.T) {
+	js := GenerateJS("/docs")
+	for _, want := range []string{
+		"const baseURL = '/docs'",
+		"baseURL + '/graph.json'",
+		"querySelectorAll('.graph-view')",
+		"getContext('2d')",
+		"instant:navigated",
+	} {
+		if !strings.Contains(js, want) {
+			t.Errorf("GenerateJS() missing %q", want)
+		}
+	}
+	if strings.Contains(js, "http://") || strings.Contains(js, "https://") {
+		t.Error("graph.js should not load anything from other sites")
+	}
+}
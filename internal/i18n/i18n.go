@@ -0,0 +1,167 @@
+// Package i18n provides translated interface text and language helpers.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+// DefaultLanguage is the language used when none is configured
+const DefaultLanguage = "en"
+
+// clientKeys are the messages the browser scripts need (search palette)
+var clientKeys = []string{"search", "typeToSearch", "noResults"}
+
+// rtlLanguages are the base languages written right to left
+var rtlLanguages = map[string]bool{
+	"ar": true, "dv": true, "fa": true, "he": true, "ku": true, "ps": true, "ur": true, "yi": true,
+}
+
+// bundles holds the built-in messages, keyed by language code
+var bundles = loadBundles()
+
+// English is the built-in English text, used for any missing message
+var English = Messages(bundles[DefaultLanguage])
+
+// Messages is the interface text for one language, keyed by message ID.
+// A nil Messages falls back to English.
+type Messages map[string]string
+
+// loadBundles reads the embedded locale files
+func loadBundles() map[string]map[string]string {
+	entries, err := localesFS.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	result := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := localesFS.ReadFile("locales/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic("i18n: invalid locale " + entry.Name() + ": " + err.Error())
+		}
+		result[strings.TrimSuffix(entry.Name(), ".json")] = messages
+	}
+	return result
+}
+
+// Builtin returns the codes of the languages with built-in text, sorted
+func Builtin() []string {
+	codes := make([]string, 0, len(bundles))
+	for code := range bundles {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Normalize lowercases a language code and uses "-" as the region separator
+// ("pt_BR" -> "pt-br"). An empty code becomes DefaultLanguage.
+func Normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(lang, "_", "-")))
+	if lang == "" {
+		return DefaultLanguage
+	}
+	return lang
+}
+
+// base returns the language without its region ("pt-br" -> "pt")
+func base(lang string) string {
+	if i := strings.IndexByte(lang, '-'); i > 0 {
+		return lang[:i]
+	}
+	return lang
+}
+
+// Load returns the interface text for lang. Messages come from overrides,
+// then the built-in bundle for the language (or its base language, so
+// "pt-br" uses "pt"), then English.
+func Load(lang string, overrides map[string]string) Messages {
+	lang = Normalize(lang)
+	result := make(Messages, len(English)+len(overrides))
+	for key, value := range English {
+		result[key] = value
+	}
+	if lang != DefaultLanguage {
+		// Unknown languages are named by their code until translated
+		result["languageName"] = lang
+	}
+	for _, code := range []string{base(lang), lang} {
+		for key, value := range bundles[code] {
+			result[key] = value
+		}
+	}
+	for key, value := range overrides {
+		result[key] = value
+	}
+	return result
+}
+
+// T returns the message for key. Keys missing from the bundle use the
+// English text, and unknown keys are returned as-is.
+func (m Messages) T(key string) string {
+	if value, ok := m[key]; ok {
+		return value
+	}
+	if value, ok := English[key]; ok {
+		return value
+	}
+	return key
+}
+
+// ReadingTime formats an estimated reading time ("5 min read")
+func (m Messages) ReadingTime(minutes int) string {
+	return strings.ReplaceAll(m.T("readingTime"), "{n}", strconv.Itoa(minutes))
+}
+
+// OutdatedVersion returns the outdated-version banner text for a version
+func (m Messages) OutdatedVersion(version string) string {
+	return strings.ReplaceAll(m.T("outdatedVersion"), "{version}", version)
+}
+
+// PageOf returns the position of a page in a paginated listing ("Page 2 of 5")
+func (m Messages) PageOf(n, total int) string {
+	return strings.NewReplacer("{n}", strconv.Itoa(n), "{total}", strconv.Itoa(total)).Replace(m.T("pageOf"))
+}
+
+// Month returns the name of a month ("March"), from the comma-separated
+// `months` message
+func (m Messages) Month(month time.Month) string {
+	names := strings.Split(m.T("months"), ",")
+	if len(names) != 12 {
+		names = strings.Split(English["months"], ",")
+	}
+	return strings.TrimSpace(names[month-1])
+}
+
+// MonthYear returns a month and year ("March 2024")
+func (m Messages) MonthYear(year int, month time.Month) string {
+	return strings.NewReplacer("{month}", m.Month(month), "{year}", strconv.Itoa(year)).Replace(m.T("monthYear"))
+}
+
+// Client returns the messages used by the browser scripts
+func (m Messages) Client() map[string]string {
+	result := make(map[string]string, len(clientKeys))
+	for _, key := range clientKeys {
+		result[key] = m.T(key)
+	}
+	return result
+}
+
+// Dir returns the text direction of a language: "rtl" or "ltr"
+func Dir(lang string) string {
+	if rtlLanguages[base(Normalize(lang))] {
+		return "rtl"
+	}
+	return "ltr"
+}
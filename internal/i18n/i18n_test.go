@@ -0,0 +1,139 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuiltinBundlesComplete(t *testing.T) {
+	for _, code := range Builtin() {
+		for key := range English {
+			if _, ok := bundles[code][key]; !ok {
+				t.Errorf("%s bundle is missing %q", code, key)
+			}
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"":        "en",
+		"FR":      "fr",
+		"pt_BR":   "pt-br",
+		" de ":    "de",
+		"zh-Hans": "zh-hans",
+	}
+	for input, want := range tests {
+		if got := Normalize(input); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fr := Load("fr", nil)
+	if got := fr.T("pageNotFound"); got != "Page introuvable" {
+		t.Errorf("fr pageNotFound = %q", got)
+	}
+
+	// Regional variants use their base language
+	if got := Load("pt-BR", nil).T("languageName"); got != Load("pt", nil).T("languageName") {
+		t.Errorf("pt-br languageName = %q, want the pt bundle's", got)
+	}
+
+	// Unknown languages fall back to English text, named by their code
+	nl := Load("nl", nil)
+	if got := nl.T("pageNotFound"); got != "Page Not Found" {
+		t.Errorf("nl pageNotFound = %q, want English fallback", got)
+	}
+	if got := nl.T("languageName"); got != "nl" {
+		t.Errorf("nl languageName = %q, want the code", got)
+	}
+
+	// Overrides win over the built-in text
+	custom := Load("fr", map[string]string{"next": "Suivante !"})
+	if got := custom.T("next"); got != "Suivante !" {
+		t.Errorf("override next = %q", got)
+	}
+	if got := custom.T("previous"); got != fr.T("previous") {
+		t.Errorf("previous = %q, want the built-in text", got)
+	}
+}
+
+func TestMessagesT(t *testing.T) {
+	var nilMessages Messages
+	if got := nilMessages.T("returnHome"); got != English["returnHome"] {
+		t.Errorf("nil Messages T() = %q, want English", got)
+	}
+	if got := (Messages{}).T("noSuchKey"); got != "noSuchKey" {
+		t.Errorf("unknown key = %q, want the key", got)
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	if got := English.ReadingTime(5); got != "5 min read" {
+		t.Errorf("English.ReadingTime(5) = %q", got)
+	}
+	custom := Load("en", map[string]string{"readingTime": "{n} minutes"})
+	if got := custom.ReadingTime(3); got != "3 minutes" {
+		t.Errorf("ReadingTime(3) = %q", got)
+	}
+}
+
+func TestOutdatedVersion(t *testing.T) {
+	want := "You're viewing the documentation for v1, which is not the latest version."
+	if got := English.OutdatedVersion("v1"); got != want {
+		t.Errorf("English.OutdatedVersion(v1) = %q", got)
+	}
+	if got := Load("fr", nil).OutdatedVersion("v1"); !strings.Contains(got, "v1") || got == want {
+		t.Errorf("fr OutdatedVersion(v1) = %q, want French text naming v1", got)
+	}
+}
+
+func TestPageOf(t *testing.T) {
+	if got := English.PageOf(2, 5); got != "Page 2 of 5" {
+		t.Errorf("English.PageOf(2, 5) = %q", got)
+	}
+	if got := Load("de", nil).PageOf(2, 5); got != "Seite 2 von 5" {
+		t.Errorf("de PageOf(2, 5) = %q", got)
+	}
+}
+
+func TestMonthYear(t *testing.T) {
+	if got := English.MonthYear(2024, time.March); got != "March 2024" {
+		t.Errorf("English.MonthYear() = %q", got)
+	}
+	if got := Load("ja", nil).MonthYear(2024, time.December); got != "2024年12月" {
+		t.Errorf("ja MonthYear() = %q", got)
+	}
+	if got := Load("fr", map[string]string{"months": "jan"}).Month(time.January); got != "January" {
+		t.Errorf("a malformed months message should fall back to English, got %q", got)
+	}
+}
+
+func TestClient(t *testing.T) {
+	client := Load("de", nil).Client()
+	if len(client) != len(clientKeys) {
+		t.Errorf("Client() has %d messages, want %d", len(client), len(clientKeys))
+	}
+	if client["typeToSearch"] == "" || client["typeToSearch"] == English["typeToSearch"] {
+		t.Errorf("de typeToSearch = %q, want German text", client["typeToSearch"])
+	}
+}
+
+func TestDir(t *testing.T) {
+	tests := map[string]string{
+		"ar":    "rtl",
+		"he":    "rtl",
+		"fa-IR": "rtl",
+		"en":    "ltr",
+		"fr":    "ltr",
+		"":      "ltr",
+	}
+	for lang, want := range tests {
+		if got := Dir(lang); got != want {
+			t.Errorf("Dir(%q) = %q, want %q", lang, got, want)
+		}
+	}
+}
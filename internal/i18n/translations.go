@@ -0,0 +1,149 @@
+package i18n
+
+import (
+	"html/template"
+	"strings"
+
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// Alternate is the current page in one of the site's languages, used for
+// the language switcher and hreflang links
+type Alternate struct {
+	Lang       string // Language code ("fr")
+	Name       string // Language name in that language ("Français")
+	URL        string // Page URL (base-prefixed), or the language's home page if untranslated
+	Translated bool   // Whether the page exists in this language
+	Current    bool   // Whether this is the language being viewed
+}
+
+// Translations matches pages across the languages of a multilingual site.
+// The default language is served from the site root and each translation
+// language from /<lang>/; a page and its translations share the URL path
+// below that prefix.
+type Translations struct {
+	Default   string              // Default language code
+	Languages []string            // Translation language codes, in display order
+	names     map[string]string   // Language code -> language name
+	pages     map[string][]string // URL path without language prefix -> languages with the page
+}
+
+// NewTranslations creates an empty set of translations. names gives the
+// display name of each language (missing names use the code).
+func NewTranslations(defaultLang string, languages []string, names map[string]string) *Translations {
+	return &Translations{
+		Default:   defaultLang,
+		Languages: languages,
+		names:     names,
+		pages:     make(map[string][]string),
+	}
+}
+
+// Prefix returns the URL prefix of a language: "" for the default language
+// and "/<lang>" for translations
+func (t *Translations) Prefix(lang string) string {
+	if t == nil || lang == t.Default {
+		return ""
+	}
+	return "/" + lang
+}
+
+// Key returns a URL path without its language prefix
+func (t *Translations) Key(urlPath, lang string) string {
+	prefix := t.Prefix(lang)
+	if prefix == "" {
+		return urlPath
+	}
+	if urlPath == prefix+"/" || urlPath == prefix {
+		return "/"
+	}
+	return strings.TrimPrefix(urlPath, prefix)
+}
+
+// Add records that a URL path (with its language prefix) exists in lang
+func (t *Translations) Add(lang, urlPath string) {
+	key := t.Key(urlPath, lang)
+	for _, existing := range t.pages[key] {
+		if existing == lang {
+			return
+		}
+	}
+	t.pages[key] = append(t.pages[key], lang)
+}
+
+// AddSite records every page and folder of a language's site
+func (t *Translations) AddSite(lang string, site *tree.Site) {
+	for _, page := range site.AllPages {
+		t.Add(lang, tree.GetURLPath(page))
+	}
+	var addFolders func(node *tree.Node)
+	addFolders = func(node *tree.Node) {
+		t.Add(lang, tree.GetURLPath(node))
+		for _, child := range node.Children {
+			if child.IsFolder {
+				addFolders(child)
+			}
+		}
+	}
+	addFolders(site.Root)
+}
+
+// has reports whether the page at key exists in lang
+func (t *Translations) has(key, lang string) bool {
+	for _, existing := range t.pages[key] {
+		if existing == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// Alternates returns the page at urlPath (in lang) in every language of the
+// site, default language first. It returns nil for a single-language site.
+func (t *Translations) Alternates(urlPath, lang, baseURL string) []Alternate {
+	if t == nil || len(t.Languages) == 0 {
+		return nil
+	}
+	key := t.Key(urlPath, lang)
+	all := append([]string{t.Default}, t.Languages...)
+	alternates := make([]Alternate, 0, len(all))
+	for _, code := range all {
+		alt := Alternate{Lang: code, Name: code, Current: code == lang, Translated: t.has(key, code)}
+		if name := t.names[code]; name != "" {
+			alt.Name = name
+		}
+		path := t.Prefix(code) + key
+		if !alt.Translated {
+			path = t.Prefix(code) + "/"
+		}
+		alt.URL = tree.PrefixURL(baseURL, path)
+		alternates = append(alternates, alt)
+	}
+	return alternates
+}
+
+// HreflangLinks renders <link rel="alternate" hreflang> tags for the
+// languages a page is translated into, plus x-default for the default
+// language. URLs are absolute when siteURL is set.
+func HreflangLinks(alternates []Alternate, siteURL string) template.HTML {
+	origin := ""
+	if i := strings.Index(siteURL, "://"); i >= 0 {
+		origin = siteURL
+		if j := strings.Index(siteURL[i+3:], "/"); j >= 0 {
+			origin = siteURL[:i+3+j]
+		}
+	}
+
+	var b strings.Builder
+	for i, alt := range alternates {
+		if !alt.Translated {
+			continue
+		}
+		href := template.HTMLEscapeString(origin + alt.URL)
+		b.WriteString(`  <link rel="alternate" hreflang="` + template.HTMLEscapeString(alt.Lang) + `" href="` + href + `">` + "\n")
+		if i == 0 {
+			b.WriteString(`  <link rel="alternate" hreflang="x-default" href="` + href + `">` + "\n")
+		}
+	}
+	return template.HTML(b.String())
+}
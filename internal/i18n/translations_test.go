@@ -0,0 +1,115 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// newSite builds a site with a guides folder holding the given pages
+func newSite(prefix string, pages ...string) *tree.Site {
+	root := tree.NewNode("", prefix, true)
+	guides := tree.NewNode("Guides", strings.TrimPrefix(prefix+"/guides", "/"), true)
+	root.Children = append(root.Children, guides)
+	site := &tree.Site{Root: root}
+	for _, name := range pages {
+		page := tree.NewNode(name, guides.Path+"/"+name+".md", false)
+		guides.Children = append(guides.Children, page)
+		site.AllPages = append(site.AllPages, page)
+	}
+	return site
+}
+
+func newTestTranslations() *Translations {
+	tr := NewTranslations("en", []string{"fr", "de"}, map[string]string{"en": "English", "fr": "Français"})
+	tr.AddSite("en", newSite("", "intro", "setup"))
+	tr.AddSite("fr", newSite("fr", "intro"))
+	return tr
+}
+
+func TestTranslationsKey(t *testing.T) {
+	tr := newTestTranslations()
+	tests := []struct {
+		urlPath, lang, want string
+	}{
+		{"/guides/intro/", "en", "/guides/intro/"},
+		{"/fr/guides/intro/", "fr", "/guides/intro/"},
+		{"/fr/", "fr", "/"},
+		{"/fr", "fr", "/"},
+	}
+	for _, tt := range tests {
+		if got := tr.Key(tt.urlPath, tt.lang); got != tt.want {
+			t.Errorf("Key(%q, %q) = %q, want %q", tt.urlPath, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestTranslationsAlternates(t *testing.T) {
+	tr := newTestTranslations()
+
+	alts := tr.Alternates("/fr/guides/intro/", "fr", "/docs/")
+	if len(alts) != 3 {
+		t.Fatalf("got %d alternates, want 3", len(alts))
+	}
+	want := []Alternate{
+		{Lang: "en", Name: "English", URL: "/docs/guides/intro/", Translated: true},
+		{Lang: "fr", Name: "Français", URL: "/docs/fr/guides/intro/", Translated: true, Current: true},
+		{Lang: "de", Name: "de", URL: "/docs/de/", Translated: false},
+	}
+	for i := range want {
+		if alts[i] != want[i] {
+			t.Errorf("alternate %d = %+v, want %+v", i, alts[i], want[i])
+		}
+	}
+
+	// Untranslated pages link to the language's home page
+	alts = tr.Alternates("/guides/setup/", "en", "")
+	if alts[1].Translated || alts[1].URL != "/fr/" {
+		t.Errorf("fr alternate of an untranslated page = %+v", alts[1])
+	}
+	if alts[0].URL != "/guides/setup/" || !alts[0].Current {
+		t.Errorf("en alternate = %+v", alts[0])
+	}
+
+	// Folders are matched too
+	if alts := tr.Alternates("/fr/guides/", "fr", ""); !alts[0].Translated {
+		t.Error("the guides folder should exist in both languages")
+	}
+
+	single := NewTranslations("en", nil, nil)
+	if alts := single.Alternates("/", "en", ""); alts != nil {
+		t.Errorf("single-language Alternates() = %v, want nil", alts)
+	}
+	var missing *Translations
+	if alts := missing.Alternates("/", "en", ""); alts != nil {
+		t.Errorf("nil Translations Alternates() = %v, want nil", alts)
+	}
+}
+
+func TestHreflangLinks(t *testing.T) {
+	tr := newTestTranslations()
+	links := string(HreflangLinks(tr.Alternates("/guides/intro/", "en", "/docs/"), "https://example.com/docs/"))
+	for _, want := range []string{
+		`<link rel="alternate" hreflang="en" href="https://example.com/docs/guides/intro/">`,
+		`<link rel="alternate" hreflang="x-default" href="https://example.com/docs/guides/intro/">`,
+		`<link rel="alternate" hreflang="fr" href="https://example.com/docs/fr/guides/intro/">`,
+	} {
+		if !strings.Contains(links, want) {
+			t.Errorf("HreflangLinks() missing %q in:\n%s", want, links)
+		}
+	}
+	if strings.Contains(links, `hreflang="de"`) {
+		t.Error("untranslated languages should not get hreflang links")
+	}
+
+	// Without a site URL the links are root-relative
+	links = string(HreflangLinks(tr.Alternates("/guides/intro/", "en", ""), ""))
+	if !strings.Contains(links, `hreflang="fr" href="/fr/guides/intro/"`) {
+		t.Errorf("relative HreflangLinks() = %s", links)
+	}
+
+	if links := HreflangLinks(nil, ""); links != "" {
+		t.Errorf("HreflangLinks(nil) = %q, want empty", links)
+	}
+}
@@ -0,0 +1,161 @@
+// Package includes renders the site-wide header, footer and banner snippets
+// that are injected into every page.
+package includes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wusher/volcano/internal/markdown"
+	"github.com/wusher/volcano/internal/templates"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// Default include files, looked up in the root of the input directory.
+// These names are also listed in tree.IncludeFiles so they are never
+// published as pages.
+const (
+	HeaderFile = "_header.md"
+	FooterFile = "_footer.md"
+	BannerFile = "_banner.md"
+)
+
+// Paths overrides where each include is read from. Empty paths fall back to
+// the default file in the input directory.
+type Paths struct {
+	Header string // Markdown shown above every page's content
+	Footer string // Markdown shown below every page's content
+	Banner string // Dismissible announcement shown at the top of every page
+}
+
+// Includes holds the rendered snippets for a site
+type Includes struct {
+	Header   template.HTML // Rendered header
+	Footer   template.HTML // Rendered footer
+	Banner   template.HTML // Rendered banner
+	BannerID string        // Hash of the banner source; a dismissal lasts until it changes
+}
+
+// Load reads and renders the includes through the markdown pipeline. A missing
+// default file is skipped; a configured path that doesn't exist is an error.
+// Relative configured paths are resolved against the working directory first,
+// then inputDir.
+func Load(inputDir string, paths Paths, transformer *markdown.ContentTransformer) (*Includes, error) {
+	return LoadLanguage(inputDir, paths, "", transformer)
+}
+
+// LoadLanguage is like Load for one language of a multilingual site: each
+// include uses its translation (_header.fr.md for _header.md) when there is
+// one, and the default-language file otherwise
+func LoadLanguage(inputDir string, paths Paths, lang string, transformer *markdown.ContentTransformer) (*Includes, error) {
+	inc := &Includes{}
+	var bannerSource []byte
+
+	for _, item := range []struct {
+		configured, fallback string
+		target               *template.HTML
+		source               *[]byte
+	}{
+		{paths.Header, HeaderFile, &inc.Header, nil},
+		{paths.Footer, FooterFile, &inc.Footer, nil},
+		{paths.Banner, BannerFile, &inc.Banner, &bannerSource},
+	} {
+		path := filepath.Join(inputDir, item.fallback)
+		if item.configured != "" {
+			path = resolvePath(item.configured, inputDir)
+		}
+		if lang != "" {
+			ext := filepath.Ext(path)
+			translated := strings.TrimSuffix(path, ext) + "." + lang + ext
+			if _, err := os.Stat(translated); err == nil {
+				path = translated
+			}
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if item.configured == "" && os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read include: %w", err)
+		}
+
+		page, err := transformer.TransformMarkdown(content, "/", path, "", "/", "")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		*item.target = template.HTML(strings.TrimSpace(page.Content))
+		if item.source != nil {
+			*item.source = content
+		}
+	}
+
+	if inc.Banner != "" {
+		sum := sha256.Sum256(bannerSource)
+		inc.BannerID = hex.EncodeToString(sum[:])[:12]
+	}
+	return inc, nil
+}
+
+// Excluded returns the configured include files that live inside inputDir,
+// relative to it, so scanning can leave them out of the page tree
+func (p Paths) Excluded(inputDir string) []string {
+	absInput, err := filepath.Abs(inputDir)
+	if err != nil {
+		return nil
+	}
+	var excluded []string
+	for _, configured := range []string{p.Header, p.Footer, p.Banner} {
+		if configured == "" {
+			continue
+		}
+		abs, err := filepath.Abs(resolvePath(configured, inputDir))
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(absInput, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		excluded = append(excluded, rel)
+	}
+	return excluded
+}
+
+// Scan scans inputDir, leaving out the include files
+func (p Paths) Scan(inputDir string) (*tree.Site, error) {
+	return tree.ScanExcluding(inputDir, p.Excluded(inputDir))
+}
+
+// ScanLanguage scans one language of inputDir (see tree.ScanLanguage),
+// leaving out the include files and their translations
+func (p Paths) ScanLanguage(inputDir, lang string, languages []string) (*tree.Site, error) {
+	return tree.ScanLanguage(inputDir, p.Excluded(inputDir), lang, languages)
+}
+
+// Apply copies the includes onto page data
+func (inc *Includes) Apply(data *templates.PageData) {
+	if inc == nil {
+		return
+	}
+	data.SiteHeader = inc.Header
+	data.SiteFooter = inc.Footer
+	data.Banner = inc.Banner
+	data.BannerID = inc.BannerID
+}
+
+// resolvePath returns path as given if it exists, otherwise relative to inputDir
+func resolvePath(path, inputDir string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	return filepath.Join(inputDir, path)
+}
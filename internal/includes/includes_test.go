@@ -0,0 +1,155 @@
+package includes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wusher/volcano/internal/markdown"
+	"github.com/wusher/volcano/internal/templates"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, HeaderFile), "Welcome to **the docs**")
+	writeFile(t, filepath.Join(dir, BannerFile), "v2 is out!")
+
+	inc, err := Load(dir, Paths{}, markdown.NewContentTransformer(""))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !strings.Contains(string(inc.Header), "<strong>the docs</strong>") {
+		t.Errorf("Header = %q, want rendered markdown", inc.Header)
+	}
+	if inc.Footer != "" {
+		t.Errorf("Footer = %q, want empty when _footer.md is missing", inc.Footer)
+	}
+	if !strings.Contains(string(inc.Banner), "v2 is out!") {
+		t.Errorf("Banner = %q", inc.Banner)
+	}
+	if len(inc.BannerID) != 12 {
+		t.Errorf("BannerID = %q, want 12 hex characters", inc.BannerID)
+	}
+
+	// Changing the banner changes its ID, so a dismissal doesn't hide the new one
+	writeFile(t, filepath.Join(dir, BannerFile), "v3 is out!")
+	next, err := Load(dir, Paths{}, markdown.NewContentTransformer(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.BannerID == inc.BannerID {
+		t.Error("BannerID should change with the banner content")
+	}
+}
+
+func TestLoadConfiguredPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "partials", "foot.md"), "Copyright 2026")
+	writeFile(t, filepath.Join(dir, FooterFile), "ignored")
+
+	inc, err := Load(dir, Paths{Footer: "partials/foot.md"}, markdown.NewContentTransformer(""))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !strings.Contains(string(inc.Footer), "Copyright 2026") {
+		t.Errorf("Footer = %q, want the configured file", inc.Footer)
+	}
+	if inc.BannerID != "" {
+		t.Errorf("BannerID = %q, want empty without a banner", inc.BannerID)
+	}
+
+	if _, err := Load(dir, Paths{Banner: "missing.md"}, markdown.NewContentTransformer("")); err == nil {
+		t.Error("Load() should fail when a configured include is missing")
+	}
+}
+
+func TestPathsExcluded(t *testing.T) {
+	dir := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "header.md")
+	paths := Paths{Header: outside, Footer: "partials/foot.md"}
+
+	excluded := paths.Excluded(dir)
+	if len(excluded) != 1 || excluded[0] != filepath.Join("partials", "foot.md") {
+		t.Errorf("Excluded() = %v, want only the file inside the input directory", excluded)
+	}
+}
+
+func TestPathsScan(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "index.md"), "# Home")
+	writeFile(t, filepath.Join(dir, "_footer.md"), "Footer")
+	writeFile(t, filepath.Join(dir, "partials", "note.md"), "Note")
+
+	site, err := Paths{Banner: "partials/note.md"}.Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(site.AllPages) != 1 || filepath.Base(site.AllPages[0].SourcePath) != "index.md" {
+		t.Errorf("Scan() pages = %d, want only index.md", len(site.AllPages))
+	}
+}
+
+func TestApply(t *testing.T) {
+	var data templates.PageData
+	var none *Includes
+	none.Apply(&data)
+	if data.SiteHeader != "" || data.Banner != "" {
+		t.Error("nil includes should leave page data untouched")
+	}
+
+	inc := &Includes{Header: "h", Footer: "f", Banner: "b", BannerID: "abc"}
+	inc.Apply(&data)
+	if data.SiteHeader != "h" || data.SiteFooter != "f" || data.Banner != "b" || data.BannerID != "abc" {
+		t.Errorf("Apply() = %+v", data)
+	}
+}
+
+func TestLoadLanguage(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, FooterFile), "Footer")
+	writeFile(t, filepath.Join(dir, "_footer.fr.md"), "Pied de page")
+	writeFile(t, filepath.Join(dir, BannerFile), "News")
+	writeFile(t, filepath.Join(dir, "index.md"), "# Home")
+	writeFile(t, filepath.Join(dir, "index.fr.md"), "# Accueil")
+
+	fr, err := LoadLanguage(dir, Paths{}, "fr", markdown.NewContentTransformer(""))
+	if err != nil {
+		t.Fatalf("LoadLanguage() error = %v", err)
+	}
+	if !strings.Contains(string(fr.Footer), "Pied de page") {
+		t.Errorf("fr Footer = %q, want the translation", fr.Footer)
+	}
+	if !strings.Contains(string(fr.Banner), "News") {
+		t.Errorf("fr Banner = %q, want the untranslated banner", fr.Banner)
+	}
+
+	en, err := LoadLanguage(dir, Paths{}, "", markdown.NewContentTransformer(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(en.Footer), "Footer") {
+		t.Errorf("default Footer = %q", en.Footer)
+	}
+
+	// Translated includes are not pages in either language
+	for _, lang := range []string{"", "fr"} {
+		site, err := Paths{}.ScanLanguage(dir, lang, []string{"fr"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(site.AllPages) != 1 {
+			t.Errorf("ScanLanguage(%q) found %d pages, want 1", lang, len(site.AllPages))
+		}
+	}
+}
@@ -76,6 +76,16 @@ const instantNavJSRaw = `
         return true;
     }
 
+    // Describe the layout of a page and the chrome it hides, so that pages
+    // framed differently are told apart ("" and "default" are the same layout)
+    function pageChrome(body) {
+        return [
+            body.getAttribute('data-layout') || 'default',
+            body.classList.contains('no-sidebar'),
+            body.classList.contains('wide')
+        ].join(' ');
+    }
+
     // Prefetch a page using link prefetch
     function prefetchPage(href) {
         if (prefetched.has(href)) return;
@@ -120,6 +130,12 @@ const instantNavJSRaw = `
             const parser = new DOMParser();
             const newDoc = parser.parseFromString(html, 'text/html');
 
+            // Pages with a different layout or chrome need a full load to swap it
+            if (pageChrome(newDoc.body) !== pageChrome(document.body)) {
+                window.location.href = url;
+                return;
+            }
+
             // Function to perform the actual DOM updates
             const performUpdate = () => {
                 // Update URL FIRST so relative paths in new content resolve correctly
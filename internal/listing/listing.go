@@ -0,0 +1,216 @@
+// Package listing builds paginated listing pages for blog-style folders:
+// each page under the folder with its date, excerpt, reading time and
+// cover image, newest first.
+package listing
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/content"
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/navigation"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// Listing styles
+const (
+	StyleList     = "list"     // One entry per row
+	StyleCard     = "card"     // A grid of cards with cover images
+	StyleTimeline = "timeline" // Entries along a line, grouped by year
+)
+
+// DefaultPageSize is the number of entries per page when none is configured
+const DefaultPageSize = 10
+
+// PageSlug is the path segment of a listing's later pages (/blog/page/2/)
+const PageSlug = "page"
+
+// excerptLength is the longest excerpt taken from a page's content, in bytes
+const excerptLength = 220
+
+// RenderFunc renders a page to HTML, returning its title, content and front
+// matter
+type RenderFunc func(node *tree.Node) (title, content string, fm tree.FrontMatter, err error)
+
+// Entry is one page in a listing
+type Entry struct {
+	Title       string
+	URL         string
+	Date        time.Time // Zero if the page has no date
+	Excerpt     string
+	ReadingTime string
+	Cover       string // Cover image URL ("" if none)
+}
+
+// Page is one page of a listing
+type Page struct {
+	Number  int    // 1 for the folder's own URL
+	URLPath string // URL path without base path (/blog/, /blog/page/2/)
+	Entries []Entry
+}
+
+// Listing is a folder's pages split into listing pages
+type Listing struct {
+	Folder  *tree.Node
+	Title   string
+	Style   string
+	Pages   []Page // Always at least one, possibly empty
+	baseURL string
+}
+
+var (
+	paragraphRegex = regexp.MustCompile(`(?s)<p(?:\s[^>]*)?>(.*?)</p>`)
+	tagRegex       = regexp.MustCompile(`<[^>]*>`)
+	spaceRegex     = regexp.MustCompile(`\s+`)
+)
+
+// Validate checks a folder's listing settings
+func Validate(cfg config.ListingConfig) error {
+	switch cfg.Style {
+	case "", StyleList, StyleCard, StyleTimeline:
+	default:
+		return fmt.Errorf("unknown listing style %q (use %s, %s or %s)", cfg.Style, StyleList, StyleCard, StyleTimeline)
+	}
+	if cfg.PageSize < 0 {
+		return fmt.Errorf("listing pageSize must be positive, got %d", cfg.PageSize)
+	}
+	return nil
+}
+
+// Build lists the pages under folder, newest first (undated pages follow in
+// sidebar order), and splits them into pages of cfg.PageSize entries.
+// The folder's own index page isn't listed. baseURL is the site URL or base
+// path that entry and pagination links are prefixed with.
+func Build(folder *tree.Node, allPages []*tree.Node, cfg config.ListingConfig, baseURL string, messages i18n.Messages, render RenderFunc) (*Listing, error) {
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+	l := &Listing{Folder: folder, Title: folder.Name, Style: cfg.Style, baseURL: baseURL}
+	if l.Style == "" {
+		l.Style = StyleList
+	}
+	size := cfg.PageSize
+	if size == 0 {
+		size = DefaultPageSize
+	}
+
+	var entries []Entry
+	for _, page := range navigation.FlattenPagesForPagination(folder, allPages) {
+		if folder.HasIndex && page.Path == folder.IndexPath {
+			continue
+		}
+		title, body, fm, err := render(page)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", page.Path, err)
+		}
+		entries = append(entries, Entry{
+			Title:       title,
+			URL:         tree.PrefixURL(baseURL, tree.GetURLPath(page)),
+			Date:        tree.NodeDate(page),
+			Excerpt:     excerpt(body, fm),
+			ReadingTime: content.FormatReadingTimeIn(content.CalculateReadingTime(body), messages),
+			Cover:       cover(fm, baseURL),
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].Date, entries[j].Date
+		if a.IsZero() != b.IsZero() {
+			return !a.IsZero()
+		}
+		return a.After(b)
+	})
+
+	folderURL := tree.GetURLPath(folder)
+	for start := 0; start == 0 || start < len(entries); start += size {
+		end := start + size
+		if end > len(entries) {
+			end = len(entries)
+		}
+		n := len(l.Pages) + 1
+		l.Pages = append(l.Pages, Page{Number: n, URLPath: PageURLPath(folderURL, n), Entries: entries[start:end]})
+	}
+	return l, nil
+}
+
+// PageURLPath returns the URL path of page n of the listing at folderURL
+func PageURLPath(folderURL string, n int) string {
+	if n <= 1 {
+		return folderURL
+	}
+	return folderURL + PageSlug + "/" + strconv.Itoa(n) + "/"
+}
+
+// ParsePageURL splits the URL path of a listing's later page into the
+// folder's URL path and the page number
+func ParsePageURL(urlPath string) (folderURL string, n int, ok bool) {
+	if !strings.HasSuffix(urlPath, "/") {
+		return "", 0, false
+	}
+	rest := strings.TrimSuffix(urlPath, "/")
+	i := strings.LastIndex(rest, "/")
+	number := rest[i+1:]
+	n, err := strconv.Atoi(number)
+	folderURL, found := strings.CutSuffix(rest[:i+1], "/"+PageSlug+"/")
+	if err != nil || !found || n < 2 || strconv.Itoa(n) != number {
+		return "", 0, false
+	}
+	return folderURL + "/", n, true
+}
+
+// URLs returns the URL paths of the listing's later pages, which are
+// written in addition to the folder's own page
+func (l *Listing) URLs() []string {
+	var urls []string
+	for _, page := range l.Pages[1:] {
+		urls = append(urls, page.URLPath)
+	}
+	return urls
+}
+
+// excerpt returns the `excerpt`, `description` or `summary` front matter,
+// or else the start of the first paragraph of a page's content
+func excerpt(body string, fm tree.FrontMatter) string {
+	for _, key := range []string{"excerpt", "description", "summary"} {
+		if value := strings.TrimSpace(fm.String(key)); value != "" {
+			return value
+		}
+	}
+	match := paragraphRegex.FindStringSubmatch(body)
+	if match == nil {
+		return ""
+	}
+	text := html.UnescapeString(tagRegex.ReplaceAllString(match[1], ""))
+	text = strings.TrimSpace(spaceRegex.ReplaceAllString(text, " "))
+	if len(text) <= excerptLength {
+		return text
+	}
+	cut := strings.LastIndex(text[:excerptLength], " ")
+	if cut < excerptLength/2 {
+		cut = excerptLength
+	}
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return strings.TrimRight(text[:cut], " ,.;:") + "…"
+}
+
+// cover returns the URL of a page's `cover` (or `image`) front matter.
+// Paths are from the site root.
+func cover(fm tree.FrontMatter, baseURL string) string {
+	image := strings.TrimSpace(fm.String("cover"))
+	if image == "" {
+		image = strings.TrimSpace(fm.String("image"))
+	}
+	if image == "" || strings.Contains(image, "://") || strings.HasPrefix(image, "//") {
+		return image
+	}
+	return tree.PrefixURL(baseURL, "/"+strings.TrimPrefix(image, "/"))
+}
@@ -0,0 +1,246 @@
+package listing
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/markdown"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+func scanSite(t *testing.T, files map[string]string) *tree.Site {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	site, err := tree.Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return site
+}
+
+func folderAt(t *testing.T, site *tree.Site, path string) *tree.Node {
+	t.Helper()
+	for _, child := range site.Root.Children {
+		if child.IsFolder && child.Path == path {
+			return child
+		}
+	}
+	t.Fatalf("no folder %s", path)
+	return nil
+}
+
+// fakeRender renders every page as one paragraph, with front matter taken
+// from fm by page path
+func fakeRender(fm map[string]tree.FrontMatter) RenderFunc {
+	return func(node *tree.Node) (string, string, tree.FrontMatter, error) {
+		if node.Name == "Broken" {
+			return "", "", nil, fmt.Errorf("cannot render")
+		}
+		return node.Name, "<p>About <em>" + node.Name + "</em> &amp; more.</p>", fm[node.Path], nil
+	}
+}
+
+func blogSite(t *testing.T) *tree.Site {
+	return scanSite(t, map[string]string{
+		"index.md":                    "# Home",
+		"blog/index.md":               "# Blog",
+		"blog/2023-12-01-winter.md":   "# Winter",
+		"blog/2024-03-15-spring.md":   "# Spring",
+		"blog/2024-01-10-new-year.md": "# New Year",
+		"blog/about.md":               "---\ndate: 2024-02-01\n---\n# About",
+		"blog/hidden.md":              "---\nnav: false\n---\n# Hidden",
+	})
+}
+
+func titles(page Page) string {
+	var names []string
+	for _, entry := range page.Entries {
+		names = append(names, entry.Title)
+	}
+	return strings.Join(names, ", ")
+}
+
+func TestBuild(t *testing.T) {
+	site := blogSite(t)
+	blog := folderAt(t, site, "blog")
+	fm := map[string]tree.FrontMatter{
+		"blog/2024-03-15-spring.md": {"excerpt": "Flowers.", "cover": "images/spring.jpg"},
+	}
+
+	l, err := Build(blog, site.AllPages, config.ListingConfig{PageSize: 2}, "https://example.com/docs/", i18n.Load("en", nil), fakeRender(fm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Title != "Blog" || l.Style != StyleList || len(l.Pages) != 2 {
+		t.Fatalf("Build() = %q, %q, %d pages", l.Title, l.Style, len(l.Pages))
+	}
+	if got := titles(l.Pages[0]); got != "Spring, About" {
+		t.Errorf("page 1 = %q", got)
+	}
+	if got := titles(l.Pages[1]); got != "New Year, Winter" {
+		t.Errorf("page 2 = %q", got)
+	}
+	if l.Pages[1].URLPath != "/blog/page/2/" || strings.Join(l.URLs(), " ") != "/blog/page/2/" {
+		t.Errorf("page URLs = %q, %v", l.Pages[1].URLPath, l.URLs())
+	}
+
+	spring := l.Pages[0].Entries[0]
+	if spring.URL != "/docs/blog/spring/" || spring.Excerpt != "Flowers." || spring.Cover != "/docs/images/spring.jpg" {
+		t.Errorf("spring entry = %+v", spring)
+	}
+	if spring.Date.Format("2006-01-02") != "2024-03-15" || spring.ReadingTime == "" {
+		t.Errorf("spring date/reading time = %v, %q", spring.Date, spring.ReadingTime)
+	}
+	if winter := l.Pages[1].Entries[1]; winter.Excerpt != "About Winter & more." || winter.Cover != "" {
+		t.Errorf("winter entry = %+v", winter)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	site := blogSite(t)
+	blog := folderAt(t, site, "blog")
+
+	if _, err := Build(blog, site.AllPages, config.ListingConfig{Style: "grid"}, "", i18n.Load("en", nil), fakeRender(nil)); err == nil || !strings.Contains(err.Error(), `unknown listing style "grid"`) {
+		t.Errorf("style error = %v", err)
+	}
+	if err := Validate(config.ListingConfig{PageSize: -1}); err == nil {
+		t.Error("expected an error for a negative page size")
+	}
+
+	broken := scanSite(t, map[string]string{"posts/broken.md": "# Broken"})
+	_, err := Build(folderAt(t, broken, "posts"), broken.AllPages, config.ListingConfig{}, "", i18n.Load("en", nil), fakeRender(nil))
+	if err == nil || !strings.Contains(err.Error(), "posts/broken.md: cannot render") {
+		t.Errorf("render error = %v", err)
+	}
+}
+
+func TestBuildEmpty(t *testing.T) {
+	site := scanSite(t, map[string]string{"blog/index.md": "# Blog", "other.md": "# Other"})
+	l, err := Build(folderAt(t, site, "blog"), site.AllPages, config.ListingConfig{}, "", i18n.Load("en", nil), fakeRender(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Pages) != 1 || len(l.Pages[0].Entries) != 0 || l.URLs() != nil {
+		t.Errorf("empty listing = %+v", l.Pages)
+	}
+	if l.Render(1, i18n.Load("en", nil)) != "" {
+		t.Error("an empty listing should render nothing")
+	}
+	if got := string(l.RenderPage(1, i18n.Load("en", nil))); !strings.Contains(got, `class="empty-folder"`) {
+		t.Errorf("RenderPage() = %s", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	site := blogSite(t)
+	blog := folderAt(t, site, "blog")
+	fm := map[string]tree.FrontMatter{"blog/2024-03-15-spring.md": {"image": "https://cdn.example.com/spring.jpg"}}
+	messages := i18n.Load("en", nil)
+
+	l, err := Build(blog, site.AllPages, config.ListingConfig{Style: StyleTimeline, PageSize: 2}, "", messages, fakeRender(fm))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := string(l.Render(1, messages))
+	for _, want := range []string{
+		`<section class="listing listing-timeline">`,
+		`<li class="listing-year">2024</li>`,
+		`<img src="https://cdn.example.com/spring.jpg" alt="" loading="lazy">`,
+		`<h2 class="listing-title"><a href="/blog/spring/">Spring</a></h2>`,
+		`<time datetime="2024-03-15">2024-03-15</time>`,
+		`<p class="listing-excerpt">About Spring &amp; more.</p>`,
+		`<span class="listing-page-number">Page 1 of 2</span>`,
+		`<a href="/blog/page/2/" class="listing-older" rel="next">Older posts →</a>`,
+	} {
+		if !strings.Contains(first, want) {
+			t.Errorf("page 1 missing %s", want)
+		}
+	}
+	if strings.Contains(first, "listing-newer") {
+		t.Error("page 1 should not link to newer posts")
+	}
+
+	second := string(l.Render(2, messages))
+	for _, want := range []string{
+		`<li class="listing-year">2023</li>`,
+		`<a href="/blog/" class="listing-newer" rel="prev">← Newer posts</a>`,
+	} {
+		if !strings.Contains(second, want) {
+			t.Errorf("page 2 missing %s", want)
+		}
+	}
+
+	page := string(l.RenderPage(2, messages))
+	if !strings.Contains(page, `<h1>Blog</h1>`) || !strings.Contains(page, `class="listing-newer"`) || strings.Contains(page, `class="listing-older"`) {
+		t.Errorf("RenderPage(3) = %s", page)
+	}
+	if l.Render(3, messages) != "" {
+		t.Error("a page past the end should render nothing")
+	}
+}
+
+func TestPageURLs(t *testing.T) {
+	if got := PageURLPath("/blog/", 1); got != "/blog/" {
+		t.Errorf("PageURLPath(1) = %q", got)
+	}
+	if got := PageURLPath("/blog/", 3); got != "/blog/page/3/" {
+		t.Errorf("PageURLPath(3) = %q", got)
+	}
+
+	tests := []struct {
+		url    string
+		folder string
+		n      int
+		ok     bool
+	}{
+		{"/blog/page/2/", "/blog/", 2, true},
+		{"/fr/news/page/12/", "/fr/news/", 12, true},
+		{"/page/2/", "/", 2, true},
+		{"/blog/page/1/", "", 0, false},
+		{"/blog/page/02/", "", 0, false},
+		{"/blog/page/two/", "", 0, false},
+		{"/blog/page/2", "", 0, false},
+		{"/blog/2/", "", 0, false},
+	}
+	for _, tt := range tests {
+		folder, n, ok := ParsePageURL(tt.url)
+		if folder != tt.folder || n != tt.n || ok != tt.ok {
+			t.Errorf("ParsePageURL(%q) = %q, %d, %v", tt.url, folder, n, ok)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	// Rendered pages start with a heading whose anchor icon has <path>
+	// elements, which aren't paragraphs
+	long := strings.Repeat("word ", 60)
+	page, err := markdown.NewContentTransformer("").TransformMarkdown([]byte("# Title\n\n```\ncode\n```\n\n"+long), "/", "post.md", "post/index.html", "/post/", "Post")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := excerpt(page.Content, nil)
+	if !strings.HasPrefix(got, "word word") || !strings.HasSuffix(got, "word…") || len(got) > excerptLength+len("…") {
+		t.Errorf("excerpt() = %q, want the first paragraph", got)
+	}
+	if got := excerpt("<p>Body</p>", tree.FrontMatter{"description": " Described. "}); got != "Described." {
+		t.Errorf("excerpt() = %q, want the description", got)
+	}
+	if got := excerpt("<h2>No paragraphs</h2>", nil); got != "" {
+		t.Errorf("excerpt() = %q", got)
+	}
+}
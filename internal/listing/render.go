@@ -0,0 +1,136 @@
+package listing
+
+import (
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// Page returns page n of the listing, counting from 1
+func (l *Listing) Page(n int) (Page, bool) {
+	if n < 1 || n > len(l.Pages) {
+		return Page{}, false
+	}
+	return l.Pages[n-1], true
+}
+
+// Render renders page n of the listing: its entries and links to the newer
+// and older pages. It's empty for a listing without entries.
+func (l *Listing) Render(n int, messages i18n.Messages) template.HTML {
+	page, ok := l.Page(n)
+	if !ok || len(page.Entries) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<section class="listing listing-` + l.Style + `">`)
+	sb.WriteString("\n")
+	sb.WriteString(`<ol class="listing-entries">`)
+	sb.WriteString("\n")
+	year := 0
+	for _, entry := range page.Entries {
+		if l.Style == StyleTimeline && !entry.Date.IsZero() && entry.Date.Year() != year {
+			year = entry.Date.Year()
+			sb.WriteString(`<li class="listing-year">` + strconv.Itoa(year) + `</li>`)
+			sb.WriteString("\n")
+		}
+		writeEntry(&sb, entry)
+	}
+	sb.WriteString(`</ol>`)
+	sb.WriteString("\n")
+	l.writePagination(&sb, n, messages)
+	sb.WriteString(`</section>`)
+	sb.WriteString("\n")
+	return template.HTML(sb.String())
+}
+
+// RenderPage renders page n of the listing as the whole content of a page,
+// under the folder's title: for folders without an index page, and for the
+// later pages of every listing
+func (l *Listing) RenderPage(n int, messages i18n.Messages) template.HTML {
+	var sb strings.Builder
+	sb.WriteString(`<article class="auto-index-page listing-page">`)
+	sb.WriteString("\n")
+	sb.WriteString(`<h1>`)
+	sb.WriteString(template.HTMLEscapeString(l.Title))
+	sb.WriteString(`</h1>`)
+	sb.WriteString("\n")
+	if entries := l.Render(n, messages); entries != "" {
+		sb.WriteString(string(entries))
+	} else {
+		sb.WriteString(`<p class="empty-folder">This folder is empty.</p>`)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(`</article>`)
+	return template.HTML(sb.String())
+}
+
+// writeEntry writes one listing entry
+func writeEntry(sb *strings.Builder, entry Entry) {
+	url := template.HTMLEscapeString(entry.URL)
+	sb.WriteString(`<li class="listing-entry">`)
+	sb.WriteString("\n")
+	if entry.Cover != "" {
+		sb.WriteString(`<a class="listing-cover" href="` + url + `" tabindex="-1" aria-hidden="true">`)
+		sb.WriteString(`<img src="` + template.HTMLEscapeString(entry.Cover) + `" alt="" loading="lazy">`)
+		sb.WriteString(`</a>`)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(`<div class="listing-body">`)
+	sb.WriteString("\n")
+	sb.WriteString(`<h2 class="listing-title"><a href="` + url + `">`)
+	sb.WriteString(template.HTMLEscapeString(entry.Title))
+	sb.WriteString(`</a></h2>`)
+	sb.WriteString("\n")
+	sb.WriteString(`<p class="listing-meta">`)
+	if !entry.Date.IsZero() {
+		date := entry.Date.Format("2006-01-02")
+		sb.WriteString(`<time datetime="` + date + `">` + date + `</time>`)
+	}
+	sb.WriteString(`<span class="reading-time">` + template.HTMLEscapeString(entry.ReadingTime) + `</span>`)
+	sb.WriteString(`</p>`)
+	sb.WriteString("\n")
+	if entry.Excerpt != "" {
+		sb.WriteString(`<p class="listing-excerpt">`)
+		sb.WriteString(template.HTMLEscapeString(entry.Excerpt))
+		sb.WriteString(`</p>`)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(`</div>`)
+	sb.WriteString("\n")
+	sb.WriteString(`</li>`)
+	sb.WriteString("\n")
+}
+
+// writePagination writes the links between the pages of a listing
+func (l *Listing) writePagination(sb *strings.Builder, n int, messages i18n.Messages) {
+	if len(l.Pages) < 2 {
+		return
+	}
+	folderURL := tree.GetURLPath(l.Folder)
+	sb.WriteString(`<nav class="listing-pagination" aria-label="` + template.HTMLEscapeString(messages.PageOf(n, len(l.Pages))) + `">`)
+	sb.WriteString("\n")
+	if n > 1 {
+		url := tree.PrefixURL(l.baseURL, PageURLPath(folderURL, n-1))
+		sb.WriteString(`<a href="` + template.HTMLEscapeString(url) + `" class="listing-newer" rel="prev">`)
+		sb.WriteString(template.HTMLEscapeString("← " + messages.T("newerPosts")))
+		sb.WriteString(`</a>`)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(`<span class="listing-page-number">`)
+	sb.WriteString(template.HTMLEscapeString(messages.PageOf(n, len(l.Pages))))
+	sb.WriteString(`</span>`)
+	sb.WriteString("\n")
+	if n < len(l.Pages) {
+		url := tree.PrefixURL(l.baseURL, PageURLPath(folderURL, n+1))
+		sb.WriteString(`<a href="` + template.HTMLEscapeString(url) + `" class="listing-older" rel="next">`)
+		sb.WriteString(template.HTMLEscapeString(messages.T("olderPosts") + " →"))
+		sb.WriteString(`</a>`)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(`</nav>`)
+	sb.WriteString("\n")
+}
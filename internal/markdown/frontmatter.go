@@ -1,58 +1,20 @@
 package markdown
 
-import (
-	"bytes"
-)
+import "github.com/wusher/volcano/internal/tree"
 
-// StripFrontMatter removes YAML front matter from the beginning of markdown content.
-// Front matter is delimited by --- at the start of the file and closed by another ---.
-// Returns the content with front matter removed.
-func StripFrontMatter(content []byte) []byte {
-	// Must start with ---
-	if !bytes.HasPrefix(content, []byte("---")) {
-		return content
-	}
-
-	// Find the closing ---
-	// Skip the first 3 characters (the opening ---)
-	rest := content[3:]
-
-	// Skip optional newline after opening ---
-	if len(rest) > 0 && rest[0] == '\n' {
-		rest = rest[1:]
-	} else if len(rest) > 1 && rest[0] == '\r' && rest[1] == '\n' {
-		rest = rest[2:]
-	}
+// FrontMatter holds the top-level fields of a page's YAML front matter.
+// The parser lives in the tree package, so that the packages markdown
+// builds on can read front matter too.
+type FrontMatter = tree.FrontMatter
 
-	// Find the closing --- on its own line
-	idx := bytes.Index(rest, []byte("\n---"))
-	if idx == -1 {
-		// Try Windows line endings
-		idx = bytes.Index(rest, []byte("\r\n---"))
-		if idx == -1 {
-			// No closing ---, return original content
-			return content
-		}
-		// Skip past \r\n---
-		afterClose := rest[idx+5:]
-		return stripLeadingNewlines(afterClose)
-	}
-
-	// Skip past the closing \n---
-	afterClose := rest[idx+4:]
-	return stripLeadingNewlines(afterClose)
+// ParseFrontMatter splits YAML front matter from markdown content.
+// It returns the parsed fields (nil when there is no front matter) and the
+// content with the front matter removed, exactly as StripFrontMatter does.
+func ParseFrontMatter(content []byte) (FrontMatter, []byte) {
+	return tree.ParseFrontMatter(content)
 }
 
-// stripLeadingNewlines removes leading newlines (Unix or Windows style)
-func stripLeadingNewlines(content []byte) []byte {
-	for len(content) > 0 {
-		if content[0] == '\n' {
-			content = content[1:]
-		} else if len(content) > 1 && content[0] == '\r' && content[1] == '\n' {
-			content = content[2:]
-		} else {
-			break
-		}
-	}
-	return content
+// StripFrontMatter removes YAML front matter from the beginning of markdown content.
+func StripFrontMatter(content []byte) []byte {
+	return tree.StripFrontMatter(content)
 }
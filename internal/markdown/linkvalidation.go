@@ -58,6 +58,52 @@ func ExtractInternalLinks(htmlContent string) []string {
 	return links
 }
 
+// Kinds of internal links, by the syntax they're written in
+const (
+	LinkMarkdown = "link"     // [text](/page/)
+	LinkWiki     = "wikilink" // [[page]]
+	LinkEmbed    = "embed"    // ![[page]]
+)
+
+// PageLink is an internal link in a page's content
+type PageLink struct {
+	URL  string // As in the HTML content, with the base URL prefix
+	Kind string // LinkMarkdown, LinkWiki or LinkEmbed
+}
+
+// ExtractPageLinks extracts the internal links of a page's HTML content,
+// with the kind of syntax each one was written in. mdSource is the page's
+// markdown, sourceDir its directory for wiki link resolution ("/guides/")
+// and siteURL the site URL that internal links are prefixed with.
+func ExtractPageLinks(htmlContent, mdSource, sourceDir, siteURL string) []PageLink {
+	kinds := make(map[string]string)
+	for _, match := range wikiLinkRegex.FindAllStringSubmatch(mdSource, -1) {
+		urlPath := convertToURLPath(cleanWikiTarget(match[1]), sourceDir)
+		if !strings.HasPrefix(urlPath, "/") {
+			continue
+		}
+		url := normalizeLink(tree.PrefixURL(siteURL, urlPath))
+		if _, ok := kinds[url]; ok {
+			continue
+		}
+		kinds[url] = LinkWiki
+		if strings.HasPrefix(match[0], "!") {
+			kinds[url] = LinkEmbed
+		}
+	}
+
+	links := ExtractInternalLinks(htmlContent)
+	result := make([]PageLink, 0, len(links))
+	for _, link := range links {
+		kind, ok := kinds[normalizeLink(link)]
+		if !ok {
+			kind = LinkMarkdown
+		}
+		result = append(result, PageLink{URL: link, Kind: kind})
+	}
+	return result
+}
+
 // ValidateLinks checks if all internal links resolve to valid URLs in the site.
 // validURLs should be a map of all valid URL paths in the site (including base URL prefix if applicable).
 // Returns a list of broken links with detailed context.
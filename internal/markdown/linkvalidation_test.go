@@ -388,3 +388,31 @@ func TestFindSimilarURLs(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractPageLinks(t *testing.T) {
+	md := "# Setup\n\nSee [[Install]], [[reference/API.md#auth|the API]] and [the FAQ](/faq/).\n\n![[diagram.png]]\n\n![[Snippet]]\n"
+	page, err := NewContentTransformer("https://example.com/docs/").TransformMarkdown([]byte(md), "/guides/", "setup.md", "guides/setup/index.html", "/guides/setup/", "Setup")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]string)
+	for _, link := range ExtractPageLinks(page.Content, md, "/guides/", "https://example.com/docs/") {
+		got[link.URL] = link.Kind
+	}
+	want := map[string]string{
+		"/docs/guides/install/":     LinkWiki,
+		"/docs/reference/api/#auth": LinkWiki,
+		"/docs/faq/":                LinkMarkdown,
+		"/docs/guides/diagram.png":  LinkEmbed,
+		"/docs/guides/snippet/":     LinkEmbed,
+	}
+	if len(got) != len(want) {
+		t.Errorf("ExtractPageLinks() = %v, want %v", got, want)
+	}
+	for url, kind := range want {
+		if got[url] != kind {
+			t.Errorf("ExtractPageLinks()[%s] = %q, want %q", url, got[url], kind)
+		}
+	}
+}
@@ -13,6 +13,8 @@ type Page struct {
 	SourcePath string // Path to original .md file
 	OutputPath string // Path for output .html file
 	URLPath    string // URL path for navigation links
+
+	FrontMatter FrontMatter // Front matter fields (nil if the page has none)
 }
 
 // ParseFile reads and parses a markdown file, returning a Page
@@ -30,8 +32,8 @@ func ParseFile(sourcePath string, outputPath string, urlPath string, sourceDir s
 // This allows preprocessing (e.g., admonitions) before parsing.
 // sourceDir is the slugified source file directory (e.g., "/guides/") for wikilink resolution.
 func ParseContent(content []byte, sourcePath string, outputPath string, urlPath string, sourceDir string, fallbackTitle string) (*Page, error) {
-	// Strip YAML front matter if present
-	content = StripFrontMatter(content)
+	// Split off YAML front matter if present
+	frontMatter, content := ParseFrontMatter(content)
 
 	// Convert Obsidian-style wiki links to standard markdown links
 	// Pass the source file's directory for sibling resolution
@@ -56,6 +58,8 @@ func ParseContent(content []byte, sourcePath string, outputPath string, urlPath
 		SourcePath: sourcePath,
 		OutputPath: outputPath,
 		URLPath:    urlPath,
+
+		FrontMatter: frontMatter,
 	}, nil
 }
 
@@ -164,3 +164,19 @@ func TestIsDatePrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestParseContentFrontMatter(t *testing.T) {
+	page, err := ParseContent([]byte("---\nlayout: landing\n---\n# Welcome"), "index.md", "index.html", "/", "/", "Index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.FrontMatter.String("layout") != "landing" {
+		t.Errorf("FrontMatter[layout] = %q, want %q", page.FrontMatter.String("layout"), "landing")
+	}
+	if page.Title != "Welcome" {
+		t.Errorf("Title = %q, want %q", page.Title, "Welcome")
+	}
+	if strings.Contains(page.Content, "layout") {
+		t.Error("front matter should not be rendered")
+	}
+}
@@ -38,22 +38,12 @@ func ConvertWikiLinks(content []byte, sourceDir string) []byte {
 			return match
 		}
 
-		target := string(submatch[1])
+		target := cleanWikiTarget(string(submatch[1]))
 		displayText := ""
 		if len(submatch) >= 3 && len(submatch[2]) > 0 {
 			displayText = string(submatch[2])
 		}
 
-		// Clean up the target path
-		target = strings.TrimSpace(target)
-
-		// Remove .md extension if present (handle case with anchor: "file.md#section")
-		if idx := strings.Index(target, ".md#"); idx != -1 {
-			target = target[:idx] + target[idx+3:] // Remove .md but keep #anchor
-		} else {
-			target = strings.TrimSuffix(target, ".md")
-		}
-
 		// Get display text (use filename if not specified)
 		if displayText == "" {
 			// Use the last part of the path as display text
@@ -71,6 +61,18 @@ func ConvertWikiLinks(content []byte, sourceDir string) []byte {
 	return result
 }
 
+// cleanWikiTarget trims a wiki link target and removes its .md extension,
+// keeping any #anchor
+func cleanWikiTarget(target string) string {
+	target = strings.TrimSpace(target)
+
+	// Remove .md extension if present (handle case with anchor: "file.md#section")
+	if idx := strings.Index(target, ".md#"); idx != -1 {
+		return target[:idx] + target[idx+3:] // Remove .md but keep #anchor
+	}
+	return strings.TrimSuffix(target, ".md")
+}
+
 // isAttachment checks if a filename has an attachment extension
 func isAttachment(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
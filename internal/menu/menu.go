@@ -0,0 +1,192 @@
+// Package menu builds the top navigation bar from the menus config.
+package menu
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"strings"
+
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/templates"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// Main returns the items of the main menu, shown in the top navigation
+// bar. Menus under any other name are rejected, since nothing shows them.
+func Main(menus map[string][]config.MenuItem) ([]config.MenuItem, error) {
+	for name := range menus {
+		if name != config.MainMenu {
+			return nil, fmt.Errorf("unknown menu %q (only %q is supported)", name, config.MainMenu)
+		}
+	}
+	return menus[config.MainMenu], nil
+}
+
+// Build resolves configured menu items into top navigation items. Page
+// references are looked up in site; every reference that doesn't match
+// exactly one page or folder is reported in the returned error.
+func Build(items []config.MenuItem, site *tree.Site, baseURL string) ([]templates.TopNavItem, error) {
+	r := &resolver{baseURL: baseURL, lookup: tree.NewLookup(site)}
+
+	var problems []error
+	result := make([]templates.TopNavItem, 0, len(items))
+	for i, item := range items {
+		resolved, err := r.item(item, true)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("menu item %d (%s): %w", i+1, describe(item), err))
+			continue
+		}
+		result = append(result, resolved)
+	}
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	return result, nil
+}
+
+// Localize points the site links of a menu at their translations in a
+// language's site (served under prefix, such as "/fr"). Links to pages
+// that aren't translated keep pointing at the default language.
+func Localize(items []templates.TopNavItem, site *tree.Site, prefix, baseURL string) []templates.TopNavItem {
+	if prefix == "" {
+		return items
+	}
+	lookup := tree.NewLookup(site)
+	localized := make([]templates.TopNavItem, len(items))
+	for i, item := range items {
+		if item.Path != "" && lookup.Exists(prefix+item.Path) {
+			item.Path = prefix + item.Path
+			item.URL = tree.PrefixURL(baseURL, item.Path)
+			if item.Match != "" {
+				item.Match = prefix + item.Match
+			}
+		}
+		item.Children = Localize(item.Children, site, prefix, baseURL)
+		localized[i] = item
+	}
+	return localized
+}
+
+// resolver looks up page references in a site
+type resolver struct {
+	baseURL string
+	lookup  *tree.Lookup
+}
+
+// item resolves one menu item; dropdowns are allowed at the top level only
+func (r *resolver) item(item config.MenuItem, topLevel bool) (templates.TopNavItem, error) {
+	result := templates.TopNavItem{Name: item.Name, Icon: Icon(item.Icon, r.baseURL)}
+
+	if len(item.Children) > 0 {
+		if !topLevel {
+			return result, errors.New("dropdowns can't be nested")
+		}
+		if item.Name == "" {
+			return result, errors.New("a dropdown needs a name")
+		}
+		var problems []error
+		for i, child := range item.Children {
+			resolved, err := r.item(child, false)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("item %d (%s): %w", i+1, describe(child), err))
+				continue
+			}
+			result.Children = append(result.Children, resolved)
+		}
+		if len(problems) > 0 {
+			return result, errors.Join(problems...)
+		}
+		if item.Match != "" {
+			result.Match = matchPath(item.Match)
+		}
+		return result, nil
+	}
+
+	switch {
+	case item.Page != "" && item.URL != "":
+		return result, errors.New(`set either "page" or "url", not both`)
+	case item.Page != "":
+		target, label, anchor := tree.ParseReference(item.Page)
+		node, err := r.lookup.Find(target)
+		if err != nil {
+			return result, err
+		}
+		if result.Name == "" {
+			result.Name = label
+		}
+		if result.Name == "" {
+			result.Name = node.Name
+		}
+		result.Path = tree.GetURLPath(node)
+		result.URL = tree.PrefixURL(r.baseURL, result.Path) + anchor
+		if result.Path != "/" {
+			result.Match = result.Path
+		}
+	case item.URL != "":
+		if item.Name == "" {
+			return result, errors.New(`a "url" item needs a name`)
+		}
+		if strings.HasPrefix(item.URL, "/") && !strings.HasPrefix(item.URL, "//") {
+			result.Path = item.URL
+			result.URL = tree.PrefixURL(r.baseURL, item.URL)
+		} else {
+			result.URL = item.URL
+			result.External = true
+		}
+	default:
+		return result, errors.New(`needs a "page", a "url" or "children"`)
+	}
+
+	if item.Match != "" {
+		result.Match = matchPath(item.Match)
+	}
+	return result, nil
+}
+
+// matchPath normalizes a configured active-match prefix to a site path
+func matchPath(match string) string {
+	return "/" + strings.TrimPrefix(match, "/")
+}
+
+// describe names a menu item in error messages
+func describe(item config.MenuItem) string {
+	for _, s := range []string{item.Name, item.Page, item.URL} {
+		if s != "" {
+			return fmt.Sprintf("%q", s)
+		}
+	}
+	return "unnamed"
+}
+
+// Icon renders a menu icon: one of the built-in icons by name, an image
+// (any value with a "/" or file extension), or the text itself (an emoji)
+func Icon(icon, baseURL string) template.HTML {
+	icon = strings.TrimSpace(icon)
+	if icon == "" {
+		return ""
+	}
+	if svg, ok := icons[strings.ToLower(icon)]; ok {
+		return template.HTML(`<svg xmlns="http://www.w3.org/2000/svg" width="16" height="16" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round">` + svg + `</svg>`)
+	}
+	if strings.Contains(icon, "/") || filepath.Ext(icon) != "" {
+		src := icon
+		if strings.HasPrefix(src, "/") && !strings.HasPrefix(src, "//") {
+			src = tree.PrefixURL(baseURL, src)
+		}
+		return template.HTML(`<img src="` + template.HTMLEscapeString(src) + `" alt="" width="16" height="16">`)
+	}
+	return template.HTML(template.HTMLEscapeString(icon))
+}
+
+// icons are the built-in menu icons, as SVG contents
+var icons = map[string]string{
+	"book":     `<path d="M4 19.5A2.5 2.5 0 0 1 6.5 17H20"></path><path d="M6.5 2H20v20H6.5A2.5 2.5 0 0 1 4 19.5v-15A2.5 2.5 0 0 1 6.5 2z"></path>`,
+	"code":     `<polyline points="16 18 22 12 16 6"></polyline><polyline points="8 6 2 12 8 18"></polyline>`,
+	"external": `<path d="M18 13v6a2 2 0 0 1-2 2H5a2 2 0 0 1-2-2V8a2 2 0 0 1 2-2h6"></path><polyline points="15 3 21 3 21 9"></polyline><line x1="10" y1="14" x2="21" y2="3"></line>`,
+	"github":   `<path d="M9 19c-5 1.5-5-2.5-7-3m14 6v-3.87a3.37 3.37 0 0 0-.94-2.61c3.14-.35 6.44-1.54 6.44-7A5.44 5.44 0 0 0 20 4.77 5.07 5.07 0 0 0 19.91 1S18.73.65 16 2.48a13.38 13.38 0 0 0-7 0C6.27.65 5.09 1 5.09 1A5.07 5.07 0 0 0 5 4.77a5.44 5.44 0 0 0-1.5 3.78c0 5.42 3.3 6.61 6.44 7A3.37 3.37 0 0 0 9 18.13V22"></path>`,
+	"home":     `<path d="M3 9l9-7 9 7v11a2 2 0 0 1-2 2H5a2 2 0 0 1-2-2z"></path><polyline points="9 22 9 12 15 12 15 22"></polyline>`,
+	"mail":     `<path d="M4 4h16c1.1 0 2 .9 2 2v12c0 1.1-.9 2-2 2H4c-1.1 0-2-.9-2-2V6c0-1.1.9-2 2-2z"></path><polyline points="22,6 12,13 2,6"></polyline>`,
+	"rss":      `<path d="M4 11a9 9 0 0 1 9 9"></path><path d="M4 4a16 16 0 0 1 16 16"></path><circle cx="5" cy="19" r="1"></circle>`,
+}
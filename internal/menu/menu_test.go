@@ -0,0 +1,196 @@
+package menu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+func scanSite(t *testing.T, files map[string]string) *tree.Site {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	site, err := tree.Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return site
+}
+
+func testSite(t *testing.T) *tree.Site {
+	return scanSite(t, map[string]string{
+		"index.md":           "# Home",
+		"about.md":           "# About",
+		"guides/index.md":    "# Guides",
+		"guides/01-intro.md": "# Intro",
+		"guides/setup.md":    "# Setup",
+		"api/setup.md":       "# API Setup",
+	})
+}
+
+func TestMainMenu(t *testing.T) {
+	items, err := Main(map[string][]config.MenuItem{"main": {{Page: "about"}}})
+	if err != nil || len(items) != 1 {
+		t.Errorf("Main() = %v, %v", items, err)
+	}
+	if items, err := Main(nil); err != nil || items != nil {
+		t.Errorf("Main(nil) = %v, %v", items, err)
+	}
+	if _, err := Main(map[string][]config.MenuItem{"footer": {{Page: "about"}}}); err == nil {
+		t.Error("expected an error for an unknown menu")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	site := testSite(t)
+	items, err := Build([]config.MenuItem{
+		{Page: "index", Name: "Home", Icon: "home"},
+		{Page: "guides"},
+		{Page: "[[intro|Start here]]"},
+		{Page: "guides/setup.md#install", Match: "guides/"},
+		{Name: "GitHub", URL: "https://github.com/example/repo", Icon: "github"},
+		{Name: "More", Children: []config.MenuItem{
+			{Page: "About"},
+			{Name: "Changelog", URL: "/changelog/"},
+		}},
+	}, site, "/docs")
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if len(items) != 6 {
+		t.Fatalf("got %d items, want 6", len(items))
+	}
+
+	tests := []struct {
+		name, url, path, match string
+	}{
+		{"Home", "/docs/", "/", ""},
+		{"Guides", "/docs/guides/", "/guides/", "/guides/"},
+		{"Start here", "/docs/guides/intro/", "/guides/intro/", "/guides/intro/"},
+		{"Setup", "/docs/guides/setup/#install", "/guides/setup/", "/guides/"},
+		{"GitHub", "https://github.com/example/repo", "", ""},
+	}
+	for i, tt := range tests {
+		got := items[i]
+		if got.Name != tt.name || got.URL != tt.url || got.Path != tt.path || got.Match != tt.match {
+			t.Errorf("item %d = %+v, want name %q url %q path %q match %q", i, got, tt.name, tt.url, tt.path, tt.match)
+		}
+	}
+	if !items[4].External || items[1].External {
+		t.Error("only the GitHub item should be external")
+	}
+	if !strings.Contains(string(items[0].Icon), "<svg") {
+		t.Errorf("home icon = %q, want an SVG", items[0].Icon)
+	}
+
+	more := items[5]
+	if len(more.Children) != 2 || more.URL != "" {
+		t.Fatalf("dropdown = %+v", more)
+	}
+	if more.Children[0].Path != "/about/" || more.Children[1].URL != "/docs/changelog/" || more.Children[1].External {
+		t.Errorf("dropdown children = %+v", more.Children)
+	}
+	if !more.Active("/about/") || more.Active("/guides/") {
+		t.Error("the dropdown should be active on its pages only")
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	site := testSite(t)
+	_, err := Build([]config.MenuItem{
+		{Page: "setup"},
+		{Page: "missing"},
+		{Page: "guides/missing"},
+		{Page: "about", URL: "/about/"},
+		{URL: "https://example.com"},
+		{Name: "Empty"},
+		{Children: []config.MenuItem{{Page: "about"}}},
+		{Name: "Nested", Children: []config.MenuItem{
+			{Name: "Inner", Children: []config.MenuItem{{Page: "about"}}},
+			{Page: "nowhere"},
+		}},
+	}, site, "")
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{
+		`menu item 1 ("setup"): page "setup" is ambiguous (api/setup, guides/setup)`,
+		`menu item 2 ("missing"): page "missing" not found`,
+		`menu item 3 ("guides/missing"): page "guides/missing" not found`,
+		`menu item 4 ("about"): set either "page" or "url", not both`,
+		`menu item 5 ("https://example.com"): a "url" item needs a name`,
+		`menu item 6 ("Empty"): needs a "page", a "url" or "children"`,
+		`menu item 7 (unnamed): a dropdown needs a name`,
+		`item 1 ("Inner"): dropdowns can't be nested`,
+		`item 2 ("nowhere"): page "nowhere" not found`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q in:\n%v", want, err)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	site := testSite(t)
+	items, err := Build([]config.MenuItem{
+		{Page: "guides"},
+		{Page: "about"},
+		{Name: "Docs", Children: []config.MenuItem{{Page: "guides/intro"}}},
+	}, site, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fr := scanSite(t, map[string]string{
+		"fr/index.md":           "# Accueil",
+		"fr/guides/index.md":    "# Guides",
+		"fr/guides/01-intro.md": "# Intro",
+	})
+	localized := Localize(items, fr, "/fr", "")
+	if localized[0].URL != "/fr/guides/" || localized[0].Match != "/fr/guides/" {
+		t.Errorf("translated folder = %+v", localized[0])
+	}
+	if localized[1].URL != "/about/" {
+		t.Errorf("untranslated page = %+v, want the default language", localized[1])
+	}
+	if got := localized[2].Children[0].URL; got != "/fr/guides/intro/" {
+		t.Errorf("dropdown child URL = %q", got)
+	}
+	if items[0].URL != "/guides/" {
+		t.Error("Localize should not modify its input")
+	}
+	if got := Localize(items, site, "", ""); got[0].URL != "/guides/" {
+		t.Errorf("Localize without prefix = %+v", got[0])
+	}
+}
+
+func TestIcon(t *testing.T) {
+	tests := []struct {
+		icon, baseURL, want string
+	}{
+		{"", "", ""},
+		{"RSS", "", "<svg"},
+		{"/icons/logo.svg", "/docs", `<img src="/docs/icons/logo.svg"`},
+		{"https://example.com/logo.png", "/docs", `<img src="https://example.com/logo.png"`},
+		{"🚀", "", "🚀"},
+		{"<b>", "", "&lt;b&gt;"},
+	}
+	for _, tt := range tests {
+		got := string(Icon(tt.icon, tt.baseURL))
+		if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+			t.Errorf("Icon(%q) = %q, want %q", tt.icon, got, tt.want)
+		}
+	}
+}
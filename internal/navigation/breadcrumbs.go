@@ -29,8 +29,17 @@ func BuildBreadcrumbsWithBaseURL(node *tree.Node, siteTitle, baseURL string) []B
 
 	var crumbs []Breadcrumb
 
-	// Add home link with base URL prefix
-	homeURL := tree.PrefixURL(baseURL, "/")
+	// Add home link with base URL prefix. The root of a translation's
+	// tree (Path "fr") is that language's home page.
+	root := node
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	homePath := "/"
+	if root.IsFolder {
+		homePath = tree.GetURLPath(root)
+	}
+	homeURL := tree.PrefixURL(baseURL, homePath)
 	crumbs = append(crumbs, Breadcrumb{
 		Label:   siteTitle,
 		URL:     homeURL,
@@ -39,7 +48,7 @@ func BuildBreadcrumbsWithBaseURL(node *tree.Node, siteTitle, baseURL string) []B
 
 	// Get path segments
 	urlPath := tree.GetURLPath(node)
-	if urlPath == "/" {
+	if urlPath == homePath {
 		// We're on the home page, just return home as current
 		crumbs[0].Current = true
 		crumbs[0].URL = ""
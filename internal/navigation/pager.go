@@ -0,0 +1,155 @@
+package navigation
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// Pager builds the previous/next links of a site's pages. Pages link to
+// their neighbours in sidebar order, and front matter can point `prev` and
+// `next` somewhere else or turn them off.
+type Pager struct {
+	pages    []pagerEntry
+	position map[string]int // Index in pages, by page path
+	lookup   *tree.Lookup
+	root     string // Path of the site root ("fr" for a translation)
+	baseURL  string
+	sections bool
+}
+
+// pagerEntry is a page in sidebar order
+type pagerEntry struct {
+	page    *tree.Node
+	title   string // Link text: the folder's name for index pages
+	section string // Top-level folder the page is in ("" at the root)
+}
+
+// NewPager orders the pages of a site for prev/next links. With sections,
+// links stop at the edges of top-level folders instead of running on into
+// the next one.
+func NewPager(site *tree.Site, baseURL string, sections bool) *Pager {
+	p := &Pager{
+		position: make(map[string]int),
+		lookup:   tree.NewLookup(site),
+		root:     filepath.ToSlash(site.Root.Path),
+		baseURL:  baseURL,
+		sections: sections,
+	}
+	walkPagination(site.Root, site.AllPages, func(page, folder *tree.Node) {
+		entry := pagerEntry{page: page, title: page.Name, section: p.section(page.Path)}
+		if folder != nil && folder.Name != "" {
+			entry.title = folder.Name
+		}
+		p.position[page.Path] = len(p.pages)
+		p.pages = append(p.pages, entry)
+	})
+	return p
+}
+
+// section returns the top-level folder of a page path, relative to the
+// site root
+func (p *Pager) section(path string) string {
+	path = filepath.ToSlash(path)
+	if p.root != "" {
+		path = strings.TrimPrefix(strings.TrimPrefix(path, p.root), "/")
+	}
+	if i := strings.Index(path, "/"); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// Build returns the prev/next links of a page. `prev` and `next` in its
+// front matter override its neighbours: `false` hides the link, a URL
+// links outside the site, and anything else is a page reference
+// ("guides/setup", "[[setup|Next: Setup]]"). References that don't match
+// a page are returned as an error, along with the links that did resolve.
+func (p *Pager) Build(current *tree.Node, fm tree.FrontMatter) (PageNavigation, error) {
+	nav := PageNavigation{}
+	if current == nil {
+		return nav, nil
+	}
+
+	if i, ok := p.position[current.Path]; ok {
+		if i > 0 {
+			nav.Previous = p.neighbour(i, i-1)
+		}
+		if i < len(p.pages)-1 {
+			nav.Next = p.neighbour(i, i+1)
+		}
+	}
+
+	var problems []string
+	for _, o := range []struct {
+		key  string
+		link **NavLink
+	}{{"prev", &nav.Previous}, {"next", &nav.Next}} {
+		value := strings.TrimSpace(fm.String(o.key))
+		if show, ok := fm.Bool(o.key); ok {
+			if !show {
+				*o.link = nil
+			}
+			continue
+		}
+		if value == "" {
+			continue
+		}
+		link, err := p.override(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", o.key, err))
+			continue
+		}
+		*o.link = link
+	}
+	if len(problems) > 0 {
+		return nav, fmt.Errorf("invalid prev/next link: %s", strings.Join(problems, "; "))
+	}
+	return nav, nil
+}
+
+// neighbour returns the link from the page at position from to the page at
+// position to, or nil if sections are on and they're in different sections
+func (p *Pager) neighbour(from, to int) *NavLink {
+	entry := p.pages[to]
+	if p.sections && entry.section != p.pages[from].section {
+		return nil
+	}
+	return &NavLink{
+		Title:   entry.title,
+		URL:     tree.PrefixURL(p.baseURL, tree.GetURLPath(entry.page)),
+		Section: getSection(entry.page),
+	}
+}
+
+// override resolves a front matter prev/next value to a link: a URL
+// ("https://example.com|Example") or a page reference
+func (p *Pager) override(value string) (*NavLink, error) {
+	if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+		url, title, _ := strings.Cut(value, "|")
+		url, title = strings.TrimSpace(url), strings.TrimSpace(title)
+		if title == "" {
+			title = url
+		}
+		return &NavLink{Title: title, URL: url}, nil
+	}
+
+	target, label, anchor := tree.ParseReference(value)
+	node, err := p.lookup.Find(target)
+	if err != nil {
+		return nil, err
+	}
+	if label == "" {
+		label = node.Name
+	}
+	if i, ok := p.position[node.IndexPath]; ok && label == "" {
+		label = p.pages[i].title // The home page, named by its index page
+	}
+	return &NavLink{
+		Title:   label,
+		URL:     tree.PrefixURL(p.baseURL, tree.GetURLPath(node)) + anchor,
+		Section: getSection(node),
+	}, nil
+}
@@ -0,0 +1,186 @@
+package navigation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wusher/volcano/internal/tree"
+)
+
+func writeSite(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func scanSite(t *testing.T, files map[string]string) *tree.Site {
+	t.Helper()
+	site, err := tree.Scan(writeSite(t, files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return site
+}
+
+func pagerSite(t *testing.T) *tree.Site {
+	return scanSite(t, map[string]string{
+		"index.md":            "# Home",
+		"zebra.md":            "# Zebra",
+		"01-guides/index.md":  "# Guides",
+		"01-guides/02-b.md":   "# Second",
+		"01-guides/01-a.md":   "# First",
+		"01-guides/hidden.md": "---\nnav: false\n---\n# Hidden",
+		"02-api/index.md":     "# API",
+		"02-api/auth.md":      "# Auth",
+	})
+}
+
+func pageAt(t *testing.T, site *tree.Site, path string) *tree.Node {
+	t.Helper()
+	for _, page := range site.AllPages {
+		if page.Path == path {
+			return page
+		}
+	}
+	t.Fatalf("no page %s", path)
+	return nil
+}
+
+func links(nav PageNavigation) string {
+	var prev, next string
+	if nav.Previous != nil {
+		prev = nav.Previous.Title + " " + nav.Previous.URL
+	}
+	if nav.Next != nil {
+		next = nav.Next.Title + " " + nav.Next.URL
+	}
+	return prev + " | " + next
+}
+
+func TestPagerSidebarOrder(t *testing.T) {
+	site := pagerSite(t)
+	pager := NewPager(site, "https://example.com/docs/", false)
+
+	tests := []struct {
+		path, want string
+	}{
+		{"index.md", " | Zebra /docs/zebra/"},
+		{"zebra.md", "Home /docs/ | Guides /docs/guides/"},
+		{"01-guides/index.md", "Zebra /docs/zebra/ | First /docs/guides/a/"},
+		{"01-guides/02-b.md", "First /docs/guides/a/ | API /docs/api/"},
+		{"02-api/auth.md", "API /docs/api/ | "},
+		{"01-guides/hidden.md", " | "},
+	}
+	for _, tt := range tests {
+		nav, err := pager.Build(pageAt(t, site, tt.path), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := links(nav); got != tt.want {
+			t.Errorf("Build(%s) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	if nav, err := pager.Build(nil, nil); err != nil || nav.Previous != nil || nav.Next != nil {
+		t.Errorf("Build(nil) = %+v, %v", nav, err)
+	}
+}
+
+func TestPagerSections(t *testing.T) {
+	site := pagerSite(t)
+	pager := NewPager(site, "", true)
+
+	tests := []struct {
+		path, want string
+	}{
+		{"zebra.md", "Home / | "},
+		{"01-guides/index.md", " | First /guides/a/"},
+		{"01-guides/02-b.md", "First /guides/a/ | "},
+		{"02-api/index.md", " | Auth /api/auth/"},
+	}
+	for _, tt := range tests {
+		nav, _ := pager.Build(pageAt(t, site, tt.path), nil)
+		if got := links(nav); got != tt.want {
+			t.Errorf("Build(%s) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPagerFrontMatter(t *testing.T) {
+	site := pagerSite(t)
+	pager := NewPager(site, "/docs", false)
+	page := pageAt(t, site, "01-guides/01-a.md")
+
+	tests := []struct {
+		fm   tree.FrontMatter
+		want string
+	}{
+		{tree.FrontMatter{"prev": "false", "next": "true"}, " | Second /docs/guides/b/"},
+		{tree.FrontMatter{"next": "[[auth|Continue: Auth]]"}, "Guides /docs/guides/ | Continue: Auth /docs/api/auth/"},
+		{tree.FrontMatter{"next": "02-api/auth.md#tokens"}, "Guides /docs/guides/ | Auth /docs/api/auth/#tokens"},
+		{tree.FrontMatter{"prev": "index", "next": "api"}, "Home /docs/ | API /docs/api/"},
+		{tree.FrontMatter{"next": "https://example.com/more|More"}, "Guides /docs/guides/ | More https://example.com/more"},
+		{tree.FrontMatter{"next": "https://example.com/more"}, "Guides /docs/guides/ | https://example.com/more https://example.com/more"},
+	}
+	for _, tt := range tests {
+		nav, err := pager.Build(page, tt.fm)
+		if err != nil {
+			t.Fatalf("Build(%v) error = %v", tt.fm, err)
+		}
+		if got := links(nav); got != tt.want {
+			t.Errorf("Build(%v) = %q, want %q", tt.fm, got, tt.want)
+		}
+	}
+
+	// Pages hidden from navigation can still link somewhere explicitly
+	nav, err := pager.Build(pageAt(t, site, "01-guides/hidden.md"), tree.FrontMatter{"next": "zebra"})
+	if err != nil || links(nav) != " | Zebra /docs/zebra/" {
+		t.Errorf("hidden page Build() = %q, %v", links(nav), err)
+	}
+
+	nav, err = pager.Build(page, tree.FrontMatter{"prev": "missing", "next": "zebra"})
+	if err == nil || !strings.Contains(err.Error(), `prev: page "missing" not found`) {
+		t.Errorf("error = %v, want the missing page reported", err)
+	}
+	if nav.Next == nil || nav.Next.Title != "Zebra" {
+		t.Errorf("resolved links should still be returned, got %q", links(nav))
+	}
+}
+
+func TestPagerTranslation(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"index.md":           "# Home",
+		"about.md":           "# About",
+		"guides/index.md":    "# Guides",
+		"guides/intro.md":    "# Intro",
+		"index.fr.md":        "# Accueil",
+		"about.fr.md":        "# À propos",
+		"guides/index.fr.md": "# Guides",
+		"guides/intro.fr.md": "# Introduction",
+	})
+	site, err := tree.ScanLanguage(dir, nil, "fr", []string{"fr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pager := NewPager(site, "", true)
+
+	nav, _ := pager.Build(pageAt(t, site, "fr/guides/index.md"), nil)
+	if got := links(nav); got != " | Introduction /fr/guides/intro/" {
+		t.Errorf("Build(fr/guides/index.md) = %q", got)
+	}
+	nav, err = pager.Build(pageAt(t, site, "fr/about.md"), tree.FrontMatter{"next": "guides/intro"})
+	if err != nil || links(nav) != "Accueil /fr/ | Introduction /fr/guides/intro/" {
+		t.Errorf("Build(fr/about.md) = %q, %v", links(nav), err)
+	}
+}
@@ -2,7 +2,6 @@ package navigation
 
 import (
 	"html/template"
-	"path/filepath"
 	"strings"
 
 	"github.com/wusher/volcano/internal/tree"
@@ -28,10 +27,19 @@ func BuildPageNavigation(currentNode *tree.Node, allPages []*tree.Node) PageNavi
 
 // BuildPageNavigationWithBaseURL creates prev/next navigation for a page with base URL prefixing
 func BuildPageNavigationWithBaseURL(currentNode *tree.Node, allPages []*tree.Node, baseURL string) PageNavigation {
-	if currentNode == nil || len(allPages) == 0 {
+	if currentNode == nil || len(allPages) == 0 || !currentNode.InNav() {
 		return PageNavigation{}
 	}
 
+	// Pages hidden from navigation are skipped over
+	visible := make([]*tree.Node, 0, len(allPages))
+	for _, page := range allPages {
+		if page.InNav() {
+			visible = append(visible, page)
+		}
+	}
+	allPages = visible
+
 	// Find current page index
 	currentIdx := -1
 	for i, page := range allPages {
@@ -82,6 +90,12 @@ func getSection(node *tree.Node) string {
 
 // RenderPageNavigation renders the prev/next navigation as HTML
 func RenderPageNavigation(nav PageNavigation) template.HTML {
+	return RenderPageNavigationWithLabels(nav, "Previous", "Next")
+}
+
+// RenderPageNavigationWithLabels renders the prev/next navigation with
+// translated "Previous" and "Next" labels
+func RenderPageNavigationWithLabels(nav PageNavigation, previous, next string) template.HTML {
 	if nav.Previous == nil && nav.Next == nil {
 		return ""
 	}
@@ -95,7 +109,7 @@ func RenderPageNavigation(nav PageNavigation) template.HTML {
 		sb.WriteString(template.HTMLEscapeString(nav.Previous.URL))
 		sb.WriteString(`" class="page-nav-prev">`)
 		sb.WriteString("\n")
-		sb.WriteString(`    <span class="page-nav-label">Previous</span>`)
+		sb.WriteString(`    <span class="page-nav-label">` + template.HTMLEscapeString(previous) + `</span>`)
 		sb.WriteString("\n")
 		sb.WriteString(`    <span class="page-nav-title">`)
 		sb.WriteString(template.HTMLEscapeString("← " + nav.Previous.Title))
@@ -113,7 +127,7 @@ func RenderPageNavigation(nav PageNavigation) template.HTML {
 		sb.WriteString(template.HTMLEscapeString(nav.Next.URL))
 		sb.WriteString(`" class="page-nav-next">`)
 		sb.WriteString("\n")
-		sb.WriteString(`    <span class="page-nav-label">Next</span>`)
+		sb.WriteString(`    <span class="page-nav-label">` + template.HTMLEscapeString(next) + `</span>`)
 		sb.WriteString("\n")
 		sb.WriteString(`    <span class="page-nav-title">`)
 		sb.WriteString(template.HTMLEscapeString(nav.Next.Title + " →"))
@@ -129,37 +143,68 @@ func RenderPageNavigation(nav PageNavigation) template.HTML {
 	return template.HTML(sb.String())
 }
 
-// FlattenTreeForPagination returns all pages in depth-first order for pagination
+// FlattenTreeForPagination returns all pages in depth-first order for
+// pagination. It only finds index pages that are children of their folders;
+// for scanned sites, use FlattenPagesForPagination.
 func FlattenTreeForPagination(root *tree.Node) []*tree.Node {
 	var pages []*tree.Node
-	flattenNode(root, &pages)
+	var collect func(node *tree.Node)
+	collect = func(node *tree.Node) {
+		for _, child := range node.Children {
+			if child.IsFolder {
+				collect(child)
+			} else {
+				pages = append(pages, child)
+			}
+		}
+	}
+	if root != nil {
+		collect(root)
+	}
+	return FlattenPagesForPagination(root, pages)
+}
+
+// FlattenPagesForPagination returns the pages of a site in sidebar order:
+// the home page, then each folder's index page followed by its contents.
+// Index pages aren't children of their folders, so they're found in
+// allPages. Pages hidden from navigation are left out.
+func FlattenPagesForPagination(root *tree.Node, allPages []*tree.Node) []*tree.Node {
+	var pages []*tree.Node
+	walkPagination(root, allPages, func(page, _ *tree.Node) {
+		pages = append(pages, page)
+	})
 	return pages
 }
 
-func flattenNode(node *tree.Node, pages *[]*tree.Node) {
-	if node == nil {
-		return
-	}
-
-	for _, child := range node.Children {
-		if child.IsFolder {
-			// If folder has index, add it first
-			if child.HasIndex {
-				// Find the index node
-				for _, grandchild := range child.Children {
-					if tree.IsIndexFile(filepath.Base(grandchild.SourcePath)) {
-						*pages = append(*pages, grandchild)
-						break
-					}
-				}
-			}
-			// Recurse into folder
-			flattenNode(child, pages)
-		} else {
-			// Skip index files that were already added
-			if !tree.IsIndexFile(filepath.Base(node.SourcePath)) || node.Parent == nil || !node.Parent.HasIndex {
-				*pages = append(*pages, child)
+// walkPagination calls visit for each page in sidebar order, with the
+// folder it's the index page of (nil for other pages)
+func walkPagination(root *tree.Node, allPages []*tree.Node, visit func(page, folder *tree.Node)) {
+	byPath := make(map[string]*tree.Node, len(allPages))
+	for _, page := range allPages {
+		byPath[page.Path] = page
+	}
+	seen := make(map[string]bool)
+	add := func(page, folder *tree.Node) {
+		if page != nil && page.InNav() && !seen[page.Path] {
+			seen[page.Path] = true
+			visit(page, folder)
+		}
+	}
+
+	var walk func(folder *tree.Node)
+	walk = func(folder *tree.Node) {
+		if folder.HasIndex {
+			add(byPath[folder.IndexPath], folder)
+		}
+		for _, child := range folder.NavChildren() {
+			if child.IsFolder {
+				walk(child)
+			} else {
+				add(child, nil)
 			}
 		}
 	}
+	if root != nil {
+		walk(root)
+	}
 }
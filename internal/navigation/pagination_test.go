@@ -56,6 +56,44 @@ func TestFlattenTreeForPagination(t *testing.T) {
 	}
 }
 
+func TestFlattenPagesForPagination(t *testing.T) {
+	root := tree.NewNode("", "", true)
+	root.HasIndex = true
+	root.IndexPath = "index.md"
+	home := tree.NewNode("Home", "index.md", false)
+
+	about := tree.NewNode("About", "about.md", false)
+	root.AddChild(about)
+
+	docs := tree.NewNode("Docs", "docs", true)
+	docs.HasIndex = true
+	docs.IndexPath = "docs/index.md"
+	index := tree.NewNode("Docs", "docs/index.md", false)
+
+	intro := tree.NewNode("Intro", "docs/intro.md", false)
+	docs.AddChild(intro)
+	secret := tree.NewNode("Secret", "docs/secret.md", false)
+	secret.Hidden = true
+	docs.AddChild(secret)
+	root.AddChild(docs)
+
+	// Index pages aren't children of their folders; they're found in allPages
+	allPages := []*tree.Node{intro, secret, about, index, home}
+	pages := FlattenPagesForPagination(root, allPages)
+
+	var got []string
+	for _, page := range pages {
+		got = append(got, page.Path)
+	}
+	if want := "index.md about.md docs/index.md docs/intro.md"; strings.Join(got, " ") != want {
+		t.Errorf("FlattenPagesForPagination() = %v, want %s", got, want)
+	}
+
+	if pages := FlattenPagesForPagination(nil, allPages); len(pages) != 0 {
+		t.Errorf("FlattenPagesForPagination(nil) = %v, want no pages", pages)
+	}
+}
+
 func TestBuildPageNavigationWithSection(_ *testing.T) {
 	// Create a tree with grandparent -> parent -> child structure
 	root := tree.NewNode("", "", true)
@@ -153,3 +191,31 @@ func TestRenderPageNavigation_WithSection(t *testing.T) {
 		t.Error("Expected result to contain 'Next'")
 	}
 }
+
+func TestRenderPageNavigationWithLabels(t *testing.T) {
+	nav := PageNavigation{
+		Previous: &NavLink{Title: "Intro", URL: "/intro/"},
+		Next:     &NavLink{Title: "Setup", URL: "/setup/"},
+	}
+	result := string(RenderPageNavigationWithLabels(nav, "Précédent", "Suivant"))
+	for _, want := range []string{">Précédent<", ">Suivant<", "/intro/", "/setup/"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("RenderPageNavigationWithLabels() missing %q", want)
+		}
+	}
+}
+
+func TestBuildPageNavigationSkipsHiddenPages(t *testing.T) {
+	first := &tree.Node{Name: "First", Path: "first.md", SourcePath: "first.md"}
+	secret := &tree.Node{Name: "Secret", Path: "secret.md", SourcePath: "secret.md", Hidden: true}
+	last := &tree.Node{Name: "Last", Path: "last.md", SourcePath: "last.md"}
+	pages := []*tree.Node{first, secret, last}
+
+	nav := BuildPageNavigation(first, pages)
+	if nav.Next == nil || nav.Next.Title != "Last" {
+		t.Errorf("Next = %v, want the hidden page skipped", nav.Next)
+	}
+	if nav := BuildPageNavigation(secret, pages); nav.Previous != nil || nav.Next != nil {
+		t.Error("hidden pages should have no prev/next links")
+	}
+}
@@ -0,0 +1,406 @@
+// Package related suggests related pages for each page of a site, from the
+// tags they share, the links between them and the similarity of their text.
+package related
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// Weights of the three signals in a pair of pages' score
+const (
+	tagWeight  = 3.0
+	linkWeight = 2.0
+	textWeight = 2.0
+)
+
+// maxTerms is the number of highest-weighted terms kept per page, which
+// bounds the cost of comparing every pair of pages
+const maxTerms = 50
+
+// minScore is the lowest score a page needs to be suggested
+const minScore = 0.05
+
+var (
+	hrefRegex = regexp.MustCompile(`href="([^"]*)"`)
+	tagRegex  = regexp.MustCompile(`<[^>]*>`)
+)
+
+// stopWords are common English words left out of term similarity
+var stopWords = map[string]bool{
+	"about": true, "after": true, "all": true, "also": true, "and": true, "any": true,
+	"are": true, "because": true, "been": true, "before": true, "but": true, "can": true,
+	"could": true, "does": true, "each": true, "for": true, "from": true, "had": true,
+	"has": true, "have": true, "here": true, "how": true, "into": true, "its": true,
+	"just": true, "more": true, "most": true, "not": true, "now": true, "one": true,
+	"only": true, "other": true, "our": true, "out": true, "over": true, "same": true,
+	"should": true, "some": true, "such": true, "than": true, "that": true, "the": true,
+	"their": true, "them": true, "then": true, "there": true, "these": true, "they": true,
+	"this": true, "those": true, "through": true, "use": true, "used": true, "using": true,
+	"very": true, "was": true, "were": true, "what": true, "when": true, "where": true,
+	"which": true, "while": true, "who": true, "will": true, "with": true, "would": true,
+	"you": true, "your": true,
+}
+
+// Page is a rendered page of the site
+type Page struct {
+	Node        *tree.Node
+	Title       string
+	Content     string // Rendered HTML
+	FrontMatter tree.FrontMatter
+}
+
+// Link is a suggested page
+type Link struct {
+	Title string
+	URL   string
+}
+
+// doc is a page with the features it's compared by
+type doc struct {
+	page  Page
+	url   string   // URL path without base path
+	tags  []string // Sorted, lowercase
+	links []string // Sorted: "out:<url>" for pages it links to, "in:<url>" for pages linking to it
+	terms []term   // Sorted by text, unit length
+}
+
+// term is a word and its TF-IDF weight on a page
+type term struct {
+	text   string
+	weight float64
+}
+
+// Finder suggests related pages among a site's rendered pages
+type Finder struct {
+	docs    []*doc
+	byPath  map[string]*doc
+	linked  map[string]map[string]bool // Page URLs each page links to
+	lookup  *tree.Lookup
+	baseURL string
+	count   int
+}
+
+// New prepares suggestions of up to count related pages among the rendered
+// pages of site. baseURL is the site URL or base path that links in the
+// content, and the returned links, carry.
+func New(site *tree.Site, pages []Page, baseURL string, count int) *Finder {
+	f := &Finder{
+		byPath:  make(map[string]*doc, len(pages)),
+		linked:  make(map[string]map[string]bool, len(pages)),
+		lookup:  tree.NewLookup(site),
+		baseURL: baseURL,
+		count:   count,
+	}
+	byURL := make(map[string]*doc, len(pages))
+	for _, page := range pages {
+		d := &doc{page: page, url: tree.GetURLPath(page.Node), tags: normalizeTags(page.FrontMatter.List("tags"))}
+		f.docs = append(f.docs, d)
+		f.byPath[page.Node.Path] = d
+		byURL[d.url] = d
+	}
+
+	// Links between pages, by URL path
+	base := strings.TrimSuffix(tree.PrefixURL(baseURL, "/"), "/")
+	inbound := make(map[string][]string)
+	for _, d := range f.docs {
+		f.linked[d.url] = make(map[string]bool)
+		for _, match := range hrefRegex.FindAllStringSubmatch(d.page.Content, -1) {
+			target := linkTarget(html.UnescapeString(match[1]), base)
+			if _, ok := byURL[target]; !ok || target == d.url || f.linked[d.url][target] {
+				continue
+			}
+			f.linked[d.url][target] = true
+			inbound[target] = append(inbound[target], d.url)
+		}
+	}
+	for _, d := range f.docs {
+		for target := range f.linked[d.url] {
+			d.links = append(d.links, "out:"+target)
+		}
+		for _, source := range inbound[d.url] {
+			d.links = append(d.links, "in:"+source)
+		}
+		sort.Strings(d.links)
+	}
+
+	f.weighTerms()
+	return f
+}
+
+// Related returns the related pages of node. `related` in its front matter
+// overrides the suggestions: a list of page references ("guides/setup",
+// "[[setup|Setup guide]]") is shown as given, and `false` shows nothing.
+// References that don't match a page are returned as an error.
+func (f *Finder) Related(node *tree.Node) ([]Link, error) {
+	d, ok := f.byPath[node.Path]
+	if !ok {
+		return nil, nil
+	}
+	fm := d.page.FrontMatter
+	if show, ok := fm.Bool("related"); ok {
+		if !show {
+			return nil, nil
+		}
+	} else if refs := fm.List("related"); len(refs) > 0 {
+		return f.resolve(refs)
+	}
+	if f.count <= 0 {
+		return nil, nil
+	}
+
+	type candidate struct {
+		doc   *doc
+		score float64
+	}
+	var candidates []candidate
+	for _, other := range f.docs {
+		if other == d || !other.page.Node.InNav() {
+			continue
+		}
+		// Rounded so that float noise can't reorder equal scores between builds
+		score := math.Round(f.score(d, other)*1e9) / 1e9
+		if score >= minScore {
+			candidates = append(candidates, candidate{other, score})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].doc.url < candidates[j].doc.url
+	})
+
+	var links []Link
+	for _, c := range candidates {
+		if len(links) == f.count {
+			break
+		}
+		links = append(links, Link{Title: c.doc.page.Title, URL: tree.PrefixURL(f.baseURL, c.doc.url)})
+	}
+	return links, nil
+}
+
+// resolve turns `related` front matter references into links
+func (f *Finder) resolve(refs []string) ([]Link, error) {
+	var links []Link
+	var problems []string
+	for _, ref := range refs {
+		target, label, anchor := tree.ParseReference(ref)
+		node, err := f.lookup.Find(target)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if label == "" {
+			label = f.title(node)
+		}
+		links = append(links, Link{Title: label, URL: tree.PrefixURL(f.baseURL, tree.GetURLPath(node)) + anchor})
+	}
+	if len(problems) > 0 {
+		return links, fmt.Errorf("invalid related page: %s", strings.Join(problems, "; "))
+	}
+	return links, nil
+}
+
+// title returns the rendered title of a page or folder
+func (f *Finder) title(node *tree.Node) string {
+	path := node.Path
+	if node.IsFolder && node.HasIndex {
+		path = node.IndexPath
+	}
+	if d, ok := f.byPath[path]; ok && d.page.Title != "" {
+		return d.page.Title
+	}
+	return node.Name
+}
+
+// score rates how related two pages are, from 0 up
+func (f *Finder) score(a, b *doc) float64 {
+	var direct float64
+	if f.linked[a.url][b.url] {
+		direct += 0.5
+	}
+	if f.linked[b.url][a.url] {
+		direct += 0.5
+	}
+	return tagWeight*overlap(a.tags, b.tags) +
+		linkWeight*(direct+overlap(a.links, b.links)) +
+		textWeight*dot(a.terms, b.terms)
+}
+
+// weighTerms computes each page's TF-IDF vector over the words of its text
+func (f *Finder) weighTerms() {
+	counts := make([]map[string]int, len(f.docs))
+	df := make(map[string]int)
+	for i, d := range f.docs {
+		counts[i] = make(map[string]int)
+		for _, word := range words(d.page.Content) {
+			if counts[i][word] == 0 {
+				df[word]++
+			}
+			counts[i][word]++
+		}
+	}
+
+	n := float64(len(f.docs))
+	for i, d := range f.docs {
+		total := 0
+		for _, c := range counts[i] {
+			total += c
+		}
+		var terms []term
+		for word, c := range counts[i] {
+			weight := float64(c) / float64(total) * math.Log(n/float64(df[word]))
+			if weight > 0 {
+				terms = append(terms, term{word, weight})
+			}
+		}
+		sort.Slice(terms, func(a, b int) bool {
+			if terms[a].weight != terms[b].weight {
+				return terms[a].weight > terms[b].weight
+			}
+			return terms[a].text < terms[b].text
+		})
+		if len(terms) > maxTerms {
+			terms = terms[:maxTerms]
+		}
+		var norm float64
+		for _, t := range terms {
+			norm += t.weight * t.weight
+		}
+		norm = math.Sqrt(norm)
+		for j := range terms {
+			terms[j].weight /= norm
+		}
+		sort.Slice(terms, func(a, b int) bool { return terms[a].text < terms[b].text })
+		d.terms = terms
+	}
+}
+
+// Render renders related page links below a page's content. It's empty
+// when there are none.
+func Render(links []Link, messages i18n.Messages) template.HTML {
+	if len(links) == 0 {
+		return ""
+	}
+	title := template.HTMLEscapeString(messages.T("relatedPages"))
+	var sb strings.Builder
+	sb.WriteString(`<nav class="related-pages" aria-label="` + title + `">`)
+	sb.WriteString("\n")
+	sb.WriteString(`<h2 class="related-pages-title">` + title + `</h2>`)
+	sb.WriteString("\n")
+	sb.WriteString(`<ul>`)
+	sb.WriteString("\n")
+	for _, link := range links {
+		sb.WriteString(`<li><a href="` + template.HTMLEscapeString(link.URL) + `">`)
+		sb.WriteString(template.HTMLEscapeString(link.Title))
+		sb.WriteString(`</a></li>`)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(`</ul>`)
+	sb.WriteString("\n")
+	sb.WriteString(`</nav>`)
+	return template.HTML(sb.String())
+}
+
+// linkTarget returns the page URL path an href points to, without the
+// base path, fragment or query; "" for links outside the site
+func linkTarget(href, base string) string {
+	if !strings.HasPrefix(href, "/") || strings.HasPrefix(href, "//") {
+		return ""
+	}
+	if i := strings.IndexAny(href, "#?"); i >= 0 {
+		href = href[:i]
+	}
+	if base != "" {
+		rest, ok := strings.CutPrefix(href, base)
+		if !ok {
+			return ""
+		}
+		href = rest
+	}
+	if !strings.HasSuffix(href, "/") {
+		href += "/"
+	}
+	return href
+}
+
+// normalizeTags lowercases tags and removes duplicates, sorted
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	var result []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// words returns the lowercase words of HTML text that can tell pages apart:
+// three letters or more, not a number and not a stop word
+func words(content string) []string {
+	text := strings.ToLower(html.UnescapeString(tagRegex.ReplaceAllString(content, " ")))
+	var result []string
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) < 3 || stopWords[word] || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		result = append(result, word)
+	}
+	return result
+}
+
+// overlap is the cosine similarity of two sorted sets
+func overlap(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			shared++
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return float64(shared) / math.Sqrt(float64(len(a)*len(b)))
+}
+
+// dot is the dot product of two term vectors sorted by text
+func dot(a, b []term) float64 {
+	var sum float64
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i].text == b[j].text:
+			sum += a[i].weight * b[j].weight
+			i++
+			j++
+		case a[i].text < b[j].text:
+			i++
+		default:
+			j++
+		}
+	}
+	return sum
+}
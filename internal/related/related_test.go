@@ -0,0 +1,189 @@
+package related
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// testSite scans a site and renders each page as its markdown body wrapped
+// in a paragraph, which is enough for links and terms
+func testSite(t *testing.T, files map[string]string, base string) (*tree.Site, []Page) {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	site, err := tree.Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pages []Page
+	for _, node := range site.AllPages {
+		fm, body := tree.ParseFrontMatter([]byte(files[node.Path]))
+		content := strings.ReplaceAll(string(body), "HREF", base)
+		pages = append(pages, Page{Node: node, Title: node.Name, Content: "<p>" + content + "</p>", FrontMatter: fm})
+	}
+	return site, pages
+}
+
+func nodeAt(t *testing.T, site *tree.Site, path string) *tree.Node {
+	t.Helper()
+	for _, page := range site.AllPages {
+		if page.Path == path {
+			return page
+		}
+	}
+	t.Fatalf("no page %s", path)
+	return nil
+}
+
+func titles(links []Link) string {
+	var names []string
+	for _, link := range links {
+		names = append(names, link.Title)
+	}
+	return strings.Join(names, ", ")
+}
+
+var site = map[string]string{
+	"index.md":           "# Home\n\nWelcome.",
+	"kafka.md":           "---\ntags: [streaming, ops]\n---\n# Kafka\n\nPartitions, brokers and consumer groups for streaming.",
+	"pulsar.md":          "---\ntags: [Streaming]\n---\n# Pulsar\n\nTopics and brokers, with tiered storage for streaming.",
+	"postgres.md":        "---\ntags: [database]\n---\n# Postgres\n\nTables, indexes and vacuum.",
+	"guides/backups.md":  "# Backups\n\nSnapshots of tables and indexes. See <a href=\"HREF/postgres/#restore\">Postgres</a>.",
+	"guides/recovery.md": "# Recovery\n\nRestoring tables. See <a href=\"HREF/guides/backups/\">backups</a> and <a href=\"https://example.org/x\">elsewhere</a>.",
+	"guides/hidden.md":   "---\nnav: false\ntags: [streaming]\n---\n# Hidden\n\nStreaming brokers partitions.",
+	"guides/manual.md":   "---\nrelated: [\"[[kafka|Read Kafka]]\", guides/recovery#steps]\n---\n# Manual\n\nRead these first.",
+	"guides/none.md":     "---\nrelated: false\ntags: [streaming]\n---\n# None\n\nBrokers.",
+}
+
+func TestRelated(t *testing.T) {
+	s, pages := testSite(t, site, "/docs")
+	f := New(s, pages, "https://example.com/docs/", 2)
+
+	tests := []struct {
+		path, want string
+	}{
+		{"kafka.md", "Pulsar, None"},
+		{"postgres.md", "Backups, Recovery"},
+		{"guides/recovery.md", "Backups, Postgres"},
+		{"guides/hidden.md", "Pulsar, None"}, // Hidden pages get suggestions but aren't suggested
+		{"guides/none.md", ""},
+		{"guides/manual.md", "Read Kafka, Recovery"},
+	}
+	for _, tt := range tests {
+		links, err := f.Related(nodeAt(t, s, tt.path))
+		if err != nil {
+			t.Fatalf("Related(%s) error = %v", tt.path, err)
+		}
+		if got := titles(links); got != tt.want {
+			t.Errorf("Related(%s) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	links, _ := f.Related(nodeAt(t, s, "guides/manual.md"))
+	if links[0].URL != "/docs/kafka/" || links[1].URL != "/docs/guides/recovery/#steps" {
+		t.Errorf("manual links = %+v", links)
+	}
+	links, _ = f.Related(nodeAt(t, s, "postgres.md"))
+	if links[0].URL != "/docs/guides/backups/" {
+		t.Errorf("suggested link = %+v", links[0])
+	}
+	if links, err := f.Related(&tree.Node{Path: "missing.md"}); links != nil || err != nil {
+		t.Errorf("Related(unknown page) = %v, %v", links, err)
+	}
+}
+
+func TestRelatedStable(t *testing.T) {
+	s, pages := testSite(t, site, "")
+	want := ""
+	for i := 0; i < 5; i++ {
+		f := New(s, pages, "", 5)
+		var got []string
+		for _, page := range s.AllPages {
+			links, _ := f.Related(page)
+			got = append(got, page.Path+": "+titles(links))
+		}
+		if i == 0 {
+			want = strings.Join(got, "\n")
+		} else if strings.Join(got, "\n") != want {
+			t.Fatalf("results changed between runs:\n%s\nwant\n%s", strings.Join(got, "\n"), want)
+		}
+	}
+}
+
+func TestRelatedErrors(t *testing.T) {
+	files := map[string]string{
+		"a.md":       "---\nrelated: [missing, b]\n---\n# A",
+		"b.md":       "# B",
+		"off.md":     "# Off",
+		"x/setup.md": "# Setup",
+		"y/setup.md": "# Setup",
+	}
+	s, pages := testSite(t, files, "")
+	f := New(s, pages, "", 0)
+
+	links, err := f.Related(nodeAt(t, s, "a.md"))
+	if err == nil || !strings.Contains(err.Error(), `invalid related page: page "missing" not found`) {
+		t.Errorf("error = %v", err)
+	}
+	if titles(links) != "B" {
+		t.Errorf("resolved links should still be returned, got %q", titles(links))
+	}
+	if links, _ := f.Related(nodeAt(t, s, "off.md")); links != nil {
+		t.Errorf("a zero count should suggest nothing, got %q", titles(links))
+	}
+}
+
+func TestRender(t *testing.T) {
+	if Render(nil, i18n.English) != "" {
+		t.Error("Render(nil) should be empty")
+	}
+	got := string(Render([]Link{{Title: "Q&A", URL: "/qa/"}}, i18n.Load("de", nil)))
+	for _, want := range []string{
+		`<nav class="related-pages" aria-label="Verwandte Seiten">`,
+		`<h2 class="related-pages-title">Verwandte Seiten</h2>`,
+		`<li><a href="/qa/">Q&amp;A</a></li>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render() missing %s:\n%s", want, got)
+		}
+	}
+}
+
+func TestLinkTarget(t *testing.T) {
+	tests := []struct {
+		href, base, want string
+	}{
+		{"/guides/setup/", "", "/guides/setup/"},
+		{"/guides/setup#install", "", "/guides/setup/"},
+		{"/docs/guides/?q=1", "/docs", "/guides/"},
+		{"/other/guides/", "/docs", ""},
+		{"https://example.com/", "", ""},
+		{"//cdn.example.com/x", "", ""},
+		{"setup/", "", ""},
+	}
+	for _, tt := range tests {
+		if got := linkTarget(tt.href, tt.base); got != tt.want {
+			t.Errorf("linkTarget(%q, %q) = %q, want %q", tt.href, tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	got := strings.Join(words("<p>The <b>Kafka</b> brokers &amp; 2024 v2 topics, and you</p>"), " ")
+	if got != "kafka brokers topics" {
+		t.Errorf("words() = %q", got)
+	}
+}
@@ -0,0 +1,365 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// suffixRule replaces a word's suffix when at least min letters would be
+// left before it. A rule that replaces a suffix with itself keeps words with
+// that ending from matching the rules after it.
+type suffixRule struct {
+	suffix  string
+	replace string
+	min     int
+}
+
+// analyzer is a language's stop words, which aren't indexed, and stemming
+// steps. Each step applies the first of its rules that matches a word.
+type analyzer struct {
+	stopWords map[string]bool
+	steps     [][]suffixRule
+}
+
+// languageRules are the stop words and stemming steps of the languages
+// search stems, by base language code. Both work on folded words: lowercase,
+// without diacritics. The stemmers are light: they strip plurals and common
+// inflections so that "deploy" finds "deployments", not every derived form.
+var languageRules = map[string]struct {
+	stopWords string
+	steps     [][]suffixRule
+}{
+	"en": {
+		stopWords: `a all also an and any are as at be because been being both but by could did do does each
+			for from had has have he her here his i if in into is it its me my of on or our she should so
+			such than that the their them then there these they this those to too was we were which while
+			who with would you your`,
+		steps: [][]suffixRule{
+			{{"sses", "ss", 0}, {"ss", "ss", 0}, {"us", "us", 0}, {"ies", "y", 2}, {"s", "", 3}},
+			{{"eed", "eed", 0}, {"ied", "y", 2}, {"sed", "se", 2}, {"zed", "ze", 2}, {"ved", "ve", 2},
+				{"ation", "ate", 3}, {"ment", "", 3}, {"ness", "", 3}, {"ing", "", 3}, {"ed", "", 3}, {"ly", "", 5}},
+			{{"bb", "b", 2}, {"dd", "d", 2}, {"ff", "f", 2}, {"gg", "g", 2}, {"mm", "m", 2},
+				{"nn", "n", 2}, {"pp", "p", 2}, {"rr", "r", 2}, {"tt", "t", 2}},
+			{{"e", "", 3}},
+		},
+	},
+	"fr": {
+		stopWords: `a au aux avec c ce ces d dans de des du elle en est et etre eux il j je l la le les leur lui
+			m ma mais me meme mes moi mon n ne nos notre nous on ou par pas pour qu que qui s sa se ses son
+			sont sur t ta te tes toi ton tu un une vos votre vous y`,
+		steps: [][]suffixRule{
+			{{"eaux", "eau", 2}, {"aux", "al", 2}, {"s", "", 3}},
+			{{"issement", "", 3}, {"ement", "", 3}, {"ation", "", 3}, {"atrice", "", 3}, {"ateur", "", 3},
+				{"euse", "", 3}, {"eur", "", 3}, {"ite", "", 3}},
+			{{"ee", "", 3}, {"er", "", 3}, {"ez", "", 3}, {"ir", "", 3}, {"e", "", 3}},
+		},
+	},
+	"de": {
+		stopWords: `aber alle als also am an auch auf aus bei bin bis bist da damit dann das dass dem den der
+			des die dies diese dieser dieses doch dort du durch ein eine einem einen einer eines er es fur
+			hat hatte ich ihr im in ist ja kann mit nach nicht noch nur ob oder sich sie sind so uber um
+			und uns unter vom von vor war waren was weil wenn wer wie wir wird wo zu zum zur`,
+		steps: [][]suffixRule{
+			{{"ern", "", 3}, {"em", "", 3}, {"en", "", 3}, {"er", "", 3}, {"es", "", 3}, {"e", "", 3}, {"s", "", 3}},
+			{{"ung", "", 3}, {"heit", "", 3}, {"keit", "", 3}, {"lich", "", 3}, {"isch", "", 3}},
+		},
+	},
+	"es": {
+		stopWords: `a al algo como con de del el ella ellos en entre era es esta este esto fue ha hay la las
+			le les lo los mas me mi muy no o para pero por que se si sin sobre su sus tambien te un una uno
+			unos y ya`,
+		steps: [][]suffixRule{
+			{{"ces", "z", 2}, {"es", "", 3}, {"s", "", 3}},
+			{{"amiento", "", 3}, {"imiento", "", 3}, {"acion", "", 3}, {"ado", "", 3}, {"ido", "", 3},
+				{"ada", "", 3}, {"ida", "", 3}, {"ar", "", 3}, {"er", "", 3}, {"ir", "", 3}},
+			{{"a", "", 3}, {"o", "", 3}, {"e", "", 3}},
+		},
+	},
+	"pt": {
+		stopWords: `a ao aos as com como da das de do dos e ela ele em entre era essa esse esta este eu foi
+			ha isso mais mas me na nao nas no nos o os ou para pela pelo por que se sem seu sua sao tambem
+			um uma`,
+		steps: [][]suffixRule{
+			{{"coes", "cao", 2}, {"oes", "ao", 2}, {"ais", "al", 2}, {"ns", "m", 2}, {"s", "", 3}},
+			{{"amento", "", 3}, {"acao", "", 3}, {"ado", "", 3}, {"ido", "", 3}, {"ada", "", 3}, {"ida", "", 3},
+				{"ar", "", 3}, {"er", "", 3}, {"ir", "", 3}},
+			{{"a", "", 3}, {"o", "", 3}, {"e", "", 3}},
+		},
+	},
+	"it": {
+		stopWords: `a ad agli ai al alla alle allo anche che chi ci come con da dal dalla dei del della delle
+			di e ed gli ha i il in la le lo ma mi ne nei nel nella non o per piu questa questo se si sono
+			su sul sulla tra un una uno`,
+		steps: [][]suffixRule{
+			{{"azione", "", 3}, {"azioni", "", 3}, {"mente", "", 3}, {"ato", "", 3}, {"ata", "", 3},
+				{"ati", "", 3}, {"ate", "", 3}, {"are", "", 3}, {"ere", "", 3}, {"ire", "", 3}},
+			{{"a", "", 3}, {"e", "", 3}, {"i", "", 3}, {"o", "", 3}},
+		},
+	},
+}
+
+// folds maps lowercase letters with diacritics, and a few ligatures, to
+// plain letters. Combining diacritics (U+0300-U+036F) are dropped as well,
+// for text that isn't precomposed.
+var folds = pairs(`à:a á:a â:a ã:a ä:a å:a ā:a ă:a ą:a ǎ:a æ:ae ç:c ć:c ĉ:c ċ:c č:c ď:d đ:d ð:d
+	è:e é:e ê:e ë:e ē:e ĕ:e ė:e ę:e ě:e ĝ:g ğ:g ġ:g ģ:g ĥ:h ħ:h ì:i í:i î:i ï:i ĩ:i ī:i ĭ:i į:i ı:i ǐ:i
+	ĳ:ij ĵ:j ķ:k ĺ:l ļ:l ľ:l ŀ:l ł:l ñ:n ń:n ņ:n ň:n ŉ:n ò:o ó:o ô:o õ:o ö:o ø:o ō:o ŏ:o ő:o ơ:o ǒ:o
+	œ:oe ŕ:r ŗ:r ř:r ś:s ŝ:s ş:s š:s ș:s ſ:s ß:ss ţ:t ť:t ŧ:t ț:t þ:th ù:u ú:u û:u ü:u ũ:u ū:u ŭ:u
+	ů:u ű:u ų:u ư:u ǔ:u ŵ:w ý:y ÿ:y ŷ:y ź:z ż:z ž:z ά:α έ:ε ή:η ί:ι ϊ:ι ΐ:ι ό:ο ύ:υ ϋ:υ ΰ:υ ώ:ω ς:σ ё:е`)
+
+// analyzers are the analyzers of languageRules, by base language code
+var analyzers = func() map[string]*analyzer {
+	result := make(map[string]*analyzer, len(languageRules))
+	for code, rules := range languageRules {
+		a := &analyzer{stopWords: make(map[string]bool), steps: rules.steps}
+		for _, word := range strings.Fields(rules.stopWords) {
+			a.stopWords[word] = true
+		}
+		result[code] = a
+	}
+	return result
+}()
+
+// pairs parses "from:to" pairs separated by white space
+func pairs(s string) map[rune]string {
+	result := make(map[rune]string)
+	for _, pair := range strings.Fields(s) {
+		from, to, _ := strings.Cut(pair, ":")
+		r, _ := utf8.DecodeRuneInString(from)
+		result[r] = to
+	}
+	return result
+}
+
+// analyzerFor returns the analyzer of a language code, using its base
+// language ("pt-BR" uses "pt"), or nil for languages search doesn't stem
+func analyzerFor(lang string) *analyzer {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+	return analyzers[lang]
+}
+
+// analyze splits text into the terms search indexes, the way the palette
+// splits queries: runs of letters and digits, case and diacritics folded.
+// Chinese, Japanese and Korean text becomes overlapping pairs of characters,
+// since it isn't split into words by spaces. Other words are stemmed, and
+// stop words left out, by the rules of lang if it has any.
+func analyze(text, lang string) []string {
+	a := analyzerFor(lang)
+	var terms []string
+	for _, word := range strings.FieldsFunc(fold(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.IsMark(r)
+	}) {
+		for _, segment := range splitCJK(word) {
+			terms = append(terms, segmentTerms(segment, a)...)
+		}
+	}
+	return terms
+}
+
+// segmentTerms returns the terms of a folded run of CJK or other letters
+func segmentTerms(segment string, a *analyzer) []string {
+	switch {
+	case isCJK(firstRune(segment)):
+		return bigrams(segment)
+	case a == nil:
+		return []string{segment}
+	case a.stopWords[segment]:
+		return nil
+	}
+	return []string{a.stem(segment)}
+}
+
+// fold lowercases text and removes diacritics
+func fold(text string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if r >= 0x300 && r <= 0x36f {
+			continue
+		}
+		if to, ok := folds[r]; ok {
+			sb.WriteString(to)
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// isCJK reports whether r is a Chinese, Japanese or Korean character
+func isCJK(r rune) bool {
+	return r == '\u30fc' || unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+// splitCJK splits a word where it changes between CJK and other characters
+func splitCJK(word string) []string {
+	var segments []string
+	start := 0
+	for i, r := range word {
+		if i > start && isCJK(r) != isCJK(firstRune(word[start:])) {
+			segments = append(segments, word[start:i])
+			start = i
+		}
+	}
+	return append(segments, word[start:])
+}
+
+// bigrams returns the overlapping pairs of characters of s, or s itself if
+// it's a single character
+func bigrams(s string) []string {
+	runes := []rune(s)
+	if len(runes) == 1 {
+		return []string{s}
+	}
+	terms := make([]string, 0, len(runes)-1)
+	for i := 0; i+1 < len(runes); i++ {
+		terms = append(terms, string(runes[i:i+2]))
+	}
+	return terms
+}
+
+// stem applies the analyzer's stemming steps to a folded word. Words with
+// digits are left alone.
+func (a *analyzer) stem(word string) string {
+	if strings.IndexFunc(word, unicode.IsNumber) >= 0 {
+		return word
+	}
+	for _, step := range a.steps {
+		for _, rule := range step {
+			if strings.HasSuffix(word, rule.suffix) && utf8.RuneCountInString(word)-utf8.RuneCountInString(rule.suffix) >= rule.min {
+				word = word[:len(word)-len(rule.suffix)] + rule.replace
+				break
+			}
+		}
+	}
+	return word
+}
+
+// analyzeJS returns the JavaScript of the palette's analyze(text, lang),
+// which splits queries into terms the way analyze splits pages, and of
+// fold, isCJK and stemWord, from the same tables
+func analyzeJS() string {
+	languages := make(map[string]any, len(languageRules))
+	for code, rules := range languageRules {
+		steps := make([][][3]any, len(rules.steps))
+		for i, step := range rules.steps {
+			for _, rule := range step {
+				steps[i] = append(steps[i], [3]any{rule.suffix, rule.replace, rule.min})
+			}
+		}
+		languages[code] = map[string]any{"stop": strings.Join(strings.Fields(rules.stopWords), " "), "steps": steps}
+	}
+	foldTable := make(map[string]string, len(folds))
+	for r, to := range folds {
+		foldTable[string(r)] = to
+	}
+
+	return `    // Stop words and stemming steps by language, and letters to fold
+    const languages = ` + asciiJSON(languages) + `;
+    const folds = ` + asciiJSON(foldTable) + `;
+    const stopWords = new Map();
+    const cjkRegex = /[\p{Script=Han}\p{Script=Hiragana}\p{Script=Katakana}\p{Script=Hangul}\u30fc]/u;
+
+    // rulesFor returns the rules of a language code's base language
+    function rulesFor(lang) {
+        const code = (lang || '').toLowerCase().split(/[-_]/)[0];
+        const rules = languages[code];
+        if (rules && !stopWords.has(code)) stopWords.set(code, new Set(rules.stop.split(' ')));
+        return rules ? { stop: stopWords.get(code), steps: rules.steps } : null;
+    }
+
+    // fold lowercases text and removes diacritics
+    function fold(s) {
+        return Array.from(s.toLowerCase().replace(/[\u0300-\u036f]/g, '')).map(function(c) {
+            return folds[c] || c;
+        }).join('');
+    }
+
+    function isCJK(c) {
+        return cjkRegex.test(c);
+    }
+
+    // stemWord applies a language's stemming steps to a folded word
+    function stemWord(word, rules) {
+        if (/\p{N}/u.test(word)) return word;
+        rules.steps.forEach(function(step) {
+            for (let i = 0; i < step.length; i++) {
+                const rule = step[i];
+                if (word.endsWith(rule[0]) && Array.from(word).length - Array.from(rule[0]).length >= rule[2]) {
+                    word = word.slice(0, word.length - rule[0].length) + rule[1];
+                    break;
+                }
+            }
+        });
+        return word;
+    }
+
+    // analyzeWord returns the terms of a folded run of CJK or other letters
+    function analyzeWord(segment, rules) {
+        const chars = Array.from(segment);
+        if (isCJK(chars[0])) {
+            if (chars.length === 1) return chars;
+            const pairs = [];
+            for (let i = 0; i + 1 < chars.length; i++) pairs.push(chars[i] + chars[i + 1]);
+            return pairs;
+        }
+        if (!rules) return [segment];
+        return rules.stop.has(segment) ? [] : [stemWord(segment, rules)];
+    }
+
+    // segments splits a word where it changes between CJK and other letters
+    function segments(word) {
+        const chars = Array.from(word);
+        const result = [];
+        let start = 0;
+        for (let i = 1; i <= chars.length; i++) {
+            if (i === chars.length || isCJK(chars[i]) !== isCJK(chars[start])) {
+                result.push(chars.slice(start, i).join(''));
+                start = i;
+            }
+        }
+        return result;
+    }
+
+    // analyze splits text into terms the way the index was built
+    function analyze(s, lang) {
+        const rules = rulesFor(lang);
+        const terms = [];
+        fold(s).split(/[^\p{L}\p{N}\p{M}]+/u).forEach(function(word) {
+            if (!word) return;
+            segments(word).forEach(function(segment) {
+                analyzeWord(segment, rules).forEach(function(t) { terms.push(t); });
+            });
+        });
+        return terms;
+    }
+`
+}
+
+// asciiJSON returns v as JSON with non-ASCII characters escaped, so scripts
+// read the same whatever charset they're served with
+func asciiJSON(v any) string {
+	data, _ := json.Marshal(v)
+	var sb strings.Builder
+	for _, r := range string(data) {
+		if r < utf8.RuneSelf {
+			sb.WriteRune(r)
+		} else {
+			for _, u := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&sb, `\u%04x`, u)
+			}
+		}
+	}
+	return sb.String()
+}
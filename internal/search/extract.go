@@ -2,8 +2,11 @@ package search
 
 import (
 	"html"
+	"path"
 	"regexp"
 	"strings"
+
+	"github.com/wusher/volcano/internal/tree"
 )
 
 // headingRegex matches h2-h4 tags with id attribute.
@@ -12,6 +15,53 @@ var headingRegex = regexp.MustCompile(`(?i)<h([2-4])[^>]*\s+id="([^"]+)"[^>]*>(.
 // stripTagsRegex removes HTML tags from text.
 var stripTagsRegex = regexp.MustCompile(`<[^>]*>`)
 
+// sectionHeadingRegex matches the opening tag of h2-h4 tags with an id
+// attribute, where sections start.
+var sectionHeadingRegex = regexp.MustCompile(`(?i)<h[2-4][^>]*\s+id="([^"]+)"[^>]*>`)
+
+// nonTextRegex matches elements whose content isn't section text: the page
+// title, code block copy buttons, icons, scripts and styles.
+var nonTextRegex = regexp.MustCompile(`(?is)<h1\b.*?</h1>|<button\b.*?</button>|<svg\b.*?</svg>|<script\b.*?</script>|<style\b.*?</style>`)
+
+// blockTagRegex matches tags that separate words, such as paragraphs and
+// list items.
+var blockTagRegex = regexp.MustCompile(`(?i)</?(p|div|br|hr|li|ul|ol|dl|dt|dd|h[1-6]|pre|blockquote|table|thead|tbody|tr|td|th|section|article|aside|details|summary|figure|figcaption)\b[^>]*>`)
+
+// NewPageEntry builds the search index entry of a page from its node, its
+// language, its rendered HTML content and its front matter. Builds and the
+// dev server both use it, so their indexes match.
+func NewPageEntry(node *tree.Node, lang, title, htmlContent string, fm tree.FrontMatter) PageEntry {
+	entry := PageEntry{
+		Title:    title,
+		URL:      tree.GetURLPath(node),
+		Lang:     lang,
+		Headings: ExtractHeadings(htmlContent),
+		Sections: ExtractSections(htmlContent),
+	}
+
+	// The section is the top-level folder, below the language folder of a
+	// translation
+	for folder := node.Parent; folder != nil && folder.Parent != nil; folder = folder.Parent {
+		if folder.Parent.Parent == nil {
+			entry.Section = path.Base(strings.Trim(tree.GetURLPath(folder), "/"))
+			entry.SectionName = folder.Name
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, tag := range fm.List("tags") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			entry.Tags = append(entry.Tags, tag)
+		}
+	}
+	if date := tree.NodeDate(node); !date.IsZero() {
+		entry.Date = date.Format("2006-01-02")
+	}
+	return entry
+}
+
 // ExtractHeadings extracts heading entries from HTML content.
 func ExtractHeadings(htmlContent string) []HeadingEntry {
 	matches := headingRegex.FindAllStringSubmatch(htmlContent, -1)
@@ -36,3 +86,43 @@ func ExtractHeadings(htmlContent string) []HeadingEntry {
 	}
 	return entries
 }
+
+// ExtractSections splits HTML content into sections at H2-H4 headings and
+// returns the plain text of each, leaving out the headings themselves and
+// sections without text.
+func ExtractSections(htmlContent string) []SectionEntry {
+	htmlContent = nonTextRegex.ReplaceAllString(htmlContent, " ")
+
+	var sections []SectionEntry
+	add := func(anchor, content string) {
+		if text := plainText(content); text != "" {
+			sections = append(sections, SectionEntry{Anchor: anchor, Text: text})
+		}
+	}
+
+	anchor := ""
+	start := 0
+	for _, loc := range sectionHeadingRegex.FindAllStringSubmatchIndex(htmlContent, -1) {
+		add(anchor, htmlContent[start:loc[0]])
+		anchor = htmlContent[loc[2]:loc[3]]
+
+		// The section's text starts after its heading
+		start = len(htmlContent)
+		if end := strings.Index(htmlContent[loc[1]:], "</h"); end >= 0 {
+			start = loc[1] + end
+			if close := strings.Index(htmlContent[start:], ">"); close >= 0 {
+				start += close + 1
+			}
+		}
+	}
+	add(anchor, htmlContent[start:])
+	return sections
+}
+
+// plainText returns the text of HTML, with tags removed and whitespace
+// collapsed.
+func plainText(htmlContent string) string {
+	text := blockTagRegex.ReplaceAllString(htmlContent, " ")
+	text = html.UnescapeString(stripTagsRegex.ReplaceAllString(text, ""))
+	return strings.Join(strings.Fields(text), " ")
+}
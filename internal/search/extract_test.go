@@ -1,7 +1,10 @@
 package search
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/wusher/volcano/internal/tree"
 )
 
 func TestExtractHeadings(t *testing.T) {
@@ -61,3 +64,80 @@ func TestExtractHeadings(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractSections(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected []SectionEntry
+	}{
+		{
+			name: "splits at h2-h4 headings",
+			html: `<h1 id="title">Title</h1>
+<p>Intro text.</p>
+<h2 id="install">Install <a class="heading-anchor" href="#install">#</a></h2>
+<p>Run the <code>install</code> script.</p><ul><li>One</li><li>Two</li></ul>
+<h3 id="empty">Empty</h3>
+<h4 id="config">Config</h4>
+<p>Set &amp; forget.</p>
+<h5 id="minor">Minor</h5>
+<p>Still config.</p>`,
+			expected: []SectionEntry{
+				{Text: "Intro text."},
+				{Anchor: "install", Text: "Run the install script. One Two"},
+				{Anchor: "config", Text: "Set & forget. Minor Still config."},
+			},
+		},
+		{
+			name: "leaves out buttons and scripts",
+			html: `<div class="code-block"><button class="copy-button"><svg><path d=""/></svg>Copy</button><pre><code>go test</code></pre></div><script>var x;</script>`,
+			expected: []SectionEntry{
+				{Text: "go test"},
+			},
+		},
+		{
+			name:     "returns nil without text",
+			html:     `<h2 id="a">A</h2>`,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ExtractSections(tt.html)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("ExtractSections() = %+v, want %+v", result, tt.expected)
+			}
+			for i, section := range result {
+				if section != tt.expected[i] {
+					t.Errorf("section %d = %+v, want %+v", i, section, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewPageEntry(t *testing.T) {
+	root := &tree.Node{IsFolder: true}
+	guides := &tree.Node{Name: "User Guides", Path: "User Guides", IsFolder: true, Parent: root}
+	kafka := &tree.Node{Name: "Kafka", Path: "User Guides/kafka", IsFolder: true, Parent: guides}
+	node := &tree.Node{Name: "Setup", Path: "User Guides/kafka/2024-03-15-setup.md", SourcePath: "/src/User Guides/kafka/2024-03-15-setup.md", Parent: kafka}
+	fm := tree.FrontMatter{"tags": "[Ops, api, ops]"}
+
+	entry := NewPageEntry(node, "fr", "Guide", `<p>Start here.</p><h2 id="next">Next</h2><p>Then this.</p>`, fm)
+	if entry.Title != "Guide" || entry.URL != "/user-guides/kafka/setup/" || entry.Lang != "fr" {
+		t.Errorf("NewPageEntry() = %+v", entry)
+	}
+	if entry.Section != "user-guides" || entry.SectionName != "User Guides" {
+		t.Errorf("Section = %q, %q", entry.Section, entry.SectionName)
+	}
+	if strings.Join(entry.Tags, ",") != "ops,api" || entry.Date != "2024-03-15" {
+		t.Errorf("Tags = %v, Date = %q", entry.Tags, entry.Date)
+	}
+	if len(entry.Headings) != 1 || entry.Headings[0].Anchor != "next" {
+		t.Errorf("Headings = %+v", entry.Headings)
+	}
+	if len(entry.Sections) != 2 || entry.Sections[1] != (SectionEntry{Anchor: "next", Text: "Then this."}) {
+		t.Errorf("Sections = %+v", entry.Sections)
+	}
+}
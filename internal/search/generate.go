@@ -1,17 +1,35 @@
 package search
 
 import (
-	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
 )
 
-// GenerateSearchIndex writes the search-index.json file to the output directory.
-func GenerateSearchIndex(outputDir string, index *Index) error {
-	data, err := json.MarshalIndent(index, "", "  ")
+// GenerateSearchIndex writes the search index to the output directory: the
+// search-index.json manifest and its shards of about shardSize KB. Shards of
+// an earlier build are removed. It returns the names of the files written,
+// relative to the output directory and sorted.
+func GenerateSearchIndex(outputDir string, index *Index, shardSize int) ([]string, error) {
+	files, err := index.Files(shardSize)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return os.WriteFile(filepath.Join(outputDir, "search-index.json"), data, 0644)
+	shardDir := filepath.Join(outputDir, ShardDir)
+	if err := os.RemoveAll(shardDir); err != nil {
+		return nil, err
+	}
+	if err := os.Mkdir(shardDir, 0755); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(outputDir, filepath.FromSlash(name)), data, 0644); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
 }
@@ -3,6 +3,7 @@ package search
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -29,20 +30,13 @@ func TestGenerateSearchIndex(t *testing.T) {
 		},
 	}
 
-	err := GenerateSearchIndex(tmpDir, index)
+	_, err := GenerateSearchIndex(tmpDir, index, 0)
 	if err != nil {
 		t.Fatalf("GenerateSearchIndex() error = %v", err)
 	}
 
-	// Check file was created
-	indexPath := filepath.Join(tmpDir, "search-index.json")
-	content, err := os.ReadFile(indexPath)
-	if err != nil {
-		t.Fatalf("Failed to read search-index.json: %v", err)
-	}
-
-	// Verify content
-	contentStr := string(content)
+	// Check files were created
+	contentStr := readIndex(t, tmpDir)
 	if contentStr == "" {
 		t.Error("search-index.json should not be empty")
 	}
@@ -69,27 +63,26 @@ func TestGenerateSearchIndex_EmptyIndex(t *testing.T) {
 		Pages: []PageEntry{},
 	}
 
-	err := GenerateSearchIndex(tmpDir, index)
+	_, err := GenerateSearchIndex(tmpDir, index, 0)
 	if err != nil {
 		t.Fatalf("GenerateSearchIndex() error = %v", err)
 	}
 
 	// Check file was created
-	indexPath := filepath.Join(tmpDir, "search-index.json")
-	content, err := os.ReadFile(indexPath)
+	content, err := os.ReadFile(filepath.Join(tmpDir, "search-index.json"))
 	if err != nil {
 		t.Fatalf("Failed to read search-index.json: %v", err)
 	}
 
-	// Verify it's valid JSON with empty pages array
-	if !contains(string(content), `"pages": []`) {
-		t.Error("Empty index should produce JSON with empty pages array")
+	// Verify it's a manifest without shards
+	if string(content) != `{"docs":0,"avgLength":0,"terms":[],"stores":[],"facets":""}` {
+		t.Errorf("Empty index manifest = %s", content)
 	}
 }
 
 func TestGenerateSearchIndex_InvalidDir(t *testing.T) {
 	// Try to write to a non-existent directory
-	err := GenerateSearchIndex("/nonexistent/path/does/not/exist", &Index{Pages: []PageEntry{}})
+	_, err := GenerateSearchIndex("/nonexistent/path/does/not/exist", &Index{Pages: []PageEntry{}}, 0)
 	if err == nil {
 		t.Error("GenerateSearchIndex() should return error for invalid directory")
 	}
@@ -108,21 +101,63 @@ func TestGenerateSearchIndex_WithNilHeadings(t *testing.T) {
 		},
 	}
 
-	err := GenerateSearchIndex(tmpDir, index)
+	_, err := GenerateSearchIndex(tmpDir, index, 0)
 	if err != nil {
 		t.Fatalf("GenerateSearchIndex() error = %v", err)
 	}
 
-	// Check file was created and is valid
-	indexPath := filepath.Join(tmpDir, "search-index.json")
-	content, err := os.ReadFile(indexPath)
+	// Check files were created
+	if !contains(readIndex(t, tmpDir), "Page Without Headings") {
+		t.Error("search-index.json should contain page title")
+	}
+}
+
+func TestGenerateSearchIndex_RemovesOldShards(t *testing.T) {
+	tmpDir := t.TempDir()
+	stale := filepath.Join(tmpDir, "search-index", "terms.00000000.json")
+	if err := os.MkdirAll(filepath.Dir(stale), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	index := &Index{Pages: []PageEntry{{Title: "Home", URL: "/"}}}
+	names, err := GenerateSearchIndex(tmpDir, index, 0)
 	if err != nil {
-		t.Fatalf("Failed to read search-index.json: %v", err)
+		t.Fatalf("GenerateSearchIndex() error = %v", err)
+	}
+	if len(names) < 2 || names[0] != ManifestFile || !strings.HasPrefix(names[1], ShardDir+"/") {
+		t.Errorf("GenerateSearchIndex() = %v, want the manifest and its shards", names)
 	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("shards of an earlier build should be removed")
+	}
+	entries, _ := os.ReadDir(filepath.Join(tmpDir, "search-index"))
+	if len(entries) != 3 {
+		t.Errorf("search-index/ has %d files, want a terms, a docs and a facets file", len(entries))
+	}
+}
 
-	if !contains(string(content), "Page Without Headings") {
-		t.Error("search-index.json should contain page title")
+// readIndex returns the manifest and shards written to dir, concatenated
+func readIndex(t *testing.T, dir string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(dir, "search-index.json"))
+	if err != nil {
+		t.Fatalf("Failed to read search-index.json: %v", err)
+	}
+	shards, err := filepath.Glob(filepath.Join(dir, "search-index", "*.json"))
+	if err != nil || len(shards) == 0 {
+		t.Fatalf("no shards written: %v", err)
+	}
+	for _, shard := range shards {
+		data, err := os.ReadFile(shard)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content = append(content, data...)
 	}
+	return string(content)
 }
 
 // Helper function
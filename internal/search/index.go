@@ -10,7 +10,15 @@ type Index struct {
 type PageEntry struct {
 	Title    string         `json:"title"`
 	URL      string         `json:"url"`
+	Lang     string         `json:"lang,omitempty"` // Language code, which sets how words are stemmed
 	Headings []HeadingEntry `json:"headings,omitempty"`
+	Sections []SectionEntry `json:"sections,omitempty"`
+
+	// Facets results can be filtered by
+	Section     string   `json:"section,omitempty"`     // URL name of the top-level folder the page is in ("" at the top)
+	SectionName string   `json:"sectionName,omitempty"` // Display name of that folder
+	Tags        []string `json:"tags,omitempty"`        // Lowercase, without duplicates
+	Date        string   `json:"date,omitempty"`        // YYYY-MM-DD, from the filename or front matter
 }
 
 // HeadingEntry represents a heading within a page.
@@ -19,3 +27,10 @@ type HeadingEntry struct {
 	Anchor string `json:"anchor"`
 	Level  int    `json:"level"`
 }
+
+// SectionEntry represents the body text of a page section: the text before
+// the first heading, or from an H2-H4 heading to the next one.
+type SectionEntry struct {
+	Anchor string `json:"anchor,omitempty"` // Anchor of the section's heading ("" before the first heading)
+	Text   string `json:"text"`
+}
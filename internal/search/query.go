@@ -0,0 +1,614 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// BM25 parameters: term frequency saturation and length normalization
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// snippetSize is the length of a result's snippet, in characters
+const snippetSize = 160
+
+// filterKeys are the filters a query can hold besides words, like tag:api
+// or in:guides
+var filterKeys = map[string]bool{"tag": true, "in": true, "type": true, "date": true, "from": true, "to": true}
+
+var (
+	filterRegex    = regexp.MustCompile(`^(\w+):(.+)$`)
+	dateRangeRegex = regexp.MustCompile(`^(\d{4})(?:-(\d{1,2})(?:-(\d{1,2}))?)?$`)
+)
+
+// Result is a search result: a page, or the section of one under a heading
+type Result struct {
+	Type      string   `json:"type"`                // "page", or the heading level of a section ("h2"-"h4")
+	Title     string   `json:"title"`               // Page title or heading
+	URL       string   `json:"url"`                 // URL path, with the heading's anchor for sections
+	PageTitle string   `json:"pageTitle,omitempty"` // Title of a section's page
+	Snippet   string   `json:"snippet"`             // Text around the first match
+	Score     float64  `json:"score"`
+	Words     []string `json:"-"` // Indexed terms the query matched, for Highlight
+}
+
+// Searcher runs queries against the files of a search index the way the
+// command palette does, loading shards as queries need them
+type Searcher struct {
+	files    map[string][]byte
+	manifest Manifest
+	terms    map[string]map[string][]int
+	docs     map[string][][5]string
+	facets   *Facets
+}
+
+// filter is a query filter, like tag:api
+type filter struct {
+	key, value string
+}
+
+// match is a document that matched a query
+type match struct {
+	doc   int
+	score float64
+	terms int
+	words map[string]bool
+}
+
+// NewSearcher returns a searcher for the files Index.Files returns
+func NewSearcher(files map[string][]byte) (*Searcher, error) {
+	s := &Searcher{files: files, terms: make(map[string]map[string][]int), docs: make(map[string][][5]string)}
+	if err := s.load(ManifestFile, &s.manifest); err != nil {
+		return nil, err
+	}
+	if s.manifest.Facets != "" {
+		s.facets = &Facets{}
+		if err := s.load(s.manifest.Facets, s.facets); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// load decodes one of the index's files
+func (s *Searcher) load(file string, v any) error {
+	data, ok := s.files[file]
+	if !ok {
+		return fmt.Errorf("search index file %s not found", file)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid search index file %s: %w", file, err)
+	}
+	return nil
+}
+
+// Search returns the best results of a query, at most limit of them, and
+// how many documents matched in all. Words are analyzed in lang. Every word
+// has to match, and filters narrow the results; a query of filters alone
+// lists pages, newest first. Results are ranked with BM25, the same way the
+// palette ranks them.
+func (s *Searcher) Search(query, lang string, limit int) ([]Result, int, error) {
+	terms, filters := parseQuery(query, lang)
+	if len(terms) == 0 && len(filters) == 0 {
+		return nil, 0, nil
+	}
+
+	var matches []*match
+	if len(terms) > 0 {
+		var err error
+		if matches, err = s.rank(terms); err != nil {
+			return nil, 0, err
+		}
+	} else {
+		// Filters alone list pages (or headings, with type:), newest first
+		hasType := false
+		for _, f := range filters {
+			hasType = hasType || f.key == "type"
+		}
+		if !hasType {
+			filters = append(filters, filter{"type", "page"})
+		}
+		for doc := 0; doc < s.manifest.Docs; doc++ {
+			m := &match{doc: doc, words: map[string]bool{}}
+			if s.facets != nil {
+				m.score = float64(s.facets.of(doc).date)
+			}
+			matches = append(matches, m)
+		}
+		sortMatches(matches)
+	}
+	if len(filters) > 0 {
+		kept := matches[:0]
+		for _, m := range matches {
+			if s.facets != nil && s.facets.of(m.doc).passes(filters) {
+				kept = append(kept, m)
+			}
+		}
+		matches = kept
+	}
+
+	total := len(matches)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	results := make([]Result, 0, len(matches))
+	for _, m := range matches {
+		fields, err := s.doc(m.doc)
+		if err != nil {
+			return nil, 0, err
+		}
+		words := make([]string, 0, len(m.words))
+		for w := range m.words {
+			words = append(words, w)
+		}
+		sort.Strings(words)
+		results = append(results, Result{
+			Type:      fields[0],
+			Title:     fields[1],
+			URL:       fields[2],
+			PageTitle: fields[3],
+			Snippet:   snippet(fields[4], m.words, lang),
+			Score:     m.score,
+			Words:     words,
+		})
+	}
+	return results, total, nil
+}
+
+// parseQuery splits a query into its terms and its filters
+func parseQuery(query, lang string) ([]string, []filter) {
+	var words []string
+	var filters []filter
+	for _, part := range strings.Fields(query) {
+		if m := filterRegex.FindStringSubmatch(part); m != nil && filterKeys[strings.ToLower(m[1])] {
+			filters = append(filters, filter{strings.ToLower(m[1]), strings.ToLower(m[2])})
+		} else {
+			words = append(words, part)
+		}
+	}
+	return analyze(strings.Join(words, " "), lang), filters
+}
+
+// rank scores documents with BM25. Each query term scores by its best
+// match in a document, and a document must match every term.
+func (s *Searcher) rank(terms []string) ([]*match, error) {
+	matches := make(map[int]*match)
+	n := float64(s.manifest.Docs)
+	for _, term := range terms {
+		found, err := s.lookup(term)
+		if err != nil {
+			return nil, err
+		}
+		best := make(map[int]*match)
+		for _, f := range found {
+			df := float64(len(f.postings) / 3)
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			for i := 0; i+2 < len(f.postings); i += 3 {
+				tf := float64(f.postings[i+1])
+				length := float64(f.postings[i+2])
+				score := f.weight * idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*length/s.manifest.AvgLength))
+				entry := best[f.postings[i]]
+				if entry == nil {
+					entry = &match{words: make(map[string]bool)}
+					best[f.postings[i]] = entry
+				}
+				entry.score = math.Max(entry.score, score)
+				entry.words[f.word] = true
+			}
+		}
+		for doc, entry := range best {
+			m := matches[doc]
+			if m == nil {
+				m = &match{doc: doc, words: make(map[string]bool)}
+				matches[doc] = m
+			}
+			m.score += entry.score
+			m.terms++
+			for w := range entry.words {
+				m.words[w] = true
+			}
+		}
+	}
+
+	var result []*match
+	for _, m := range matches {
+		if m.terms == len(terms) {
+			result = append(result, m)
+		}
+	}
+	sortMatches(result)
+	return result, nil
+}
+
+// sortMatches sorts matches by score, best first, then by document
+func sortMatches(matches []*match) {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].doc < matches[j].doc
+	})
+}
+
+// found is an indexed word a query term matched, weighted by how well
+type found struct {
+	word     string
+	weight   float64
+	postings []int
+}
+
+// lookup returns the postings of the indexed words a query term matches,
+// weighted: the word itself, words it starts, and words a typo or two away.
+// It looks in the same shards as the palette, so typos are looked for among
+// the words of the shards for the term's first two letters.
+func (s *Searcher) lookup(term string) ([]found, error) {
+	letters := []rune(term)
+	prefixes := len(letters) >= 2 || strings.IndexFunc(term, isCJK) >= 0
+	prefix := term
+	if len(letters) >= 4 {
+		prefix = string(letters[:2])
+	}
+	typos := 1
+	if len(letters) >= 8 {
+		typos = 2
+	}
+
+	var result []found
+	for _, shard := range s.termShards(prefix, !prefixes) {
+		postings, ok := s.terms[shard.File]
+		if !ok {
+			if err := s.load(shard.File, &postings); err != nil {
+				return nil, err
+			}
+			s.terms[shard.File] = postings
+		}
+		for word, p := range postings {
+			weight := 0.0
+			switch {
+			case word == term:
+				weight = 1
+			case prefixes && strings.HasPrefix(word, term):
+				weight = 0.7
+			case len(letters) >= 4 && abs(len([]rune(word))-len(letters)) <= typos && editDistance(letters, []rune(word), typos) <= typos:
+				weight = 0.4
+			}
+			if weight > 0 {
+				result = append(result, found{word, weight, p})
+			}
+		}
+	}
+	return result, nil
+}
+
+// termShards returns the postings shards that hold the words starting with
+// prefix, or only the one that would hold prefix itself
+func (s *Searcher) termShards(prefix string, only bool) []TermShard {
+	list := s.manifest.Terms
+	if len(list) == 0 {
+		return nil
+	}
+	first := 0
+	for first+1 < len(list) && list[first+1].From <= prefix {
+		first++
+	}
+	last := first
+	for !only && last+1 < len(list) && strings.HasPrefix(list[last+1].From, prefix) {
+		last++
+	}
+	return list[first : last+1]
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of
+// adjacent letters between two words, giving up past limit
+func editDistance(a, b []rune, limit int) int {
+	var before []int
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		row := []int{i}
+		least := i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := min(prev[j]+1, row[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d = min(d, before[j-2]+1)
+			}
+			row = append(row, d)
+			least = min(least, d)
+		}
+		if least > limit {
+			return limit + 1
+		}
+		before = prev
+		prev = row
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// doc returns a document's type, title, URL, page title and text
+func (s *Searcher) doc(doc int) ([5]string, error) {
+	stores := s.manifest.Stores
+	i := 0
+	for i+1 < len(stores) && stores[i+1].From <= doc {
+		i++
+	}
+	if len(stores) == 0 {
+		return [5]string{}, fmt.Errorf("search index has no documents")
+	}
+	store, ok := s.docs[stores[i].File]
+	if !ok {
+		if err := s.load(stores[i].File, &store); err != nil {
+			return [5]string{}, err
+		}
+		s.docs[stores[i].File] = store
+	}
+	if doc-stores[i].From >= len(store) {
+		return [5]string{}, fmt.Errorf("search index file %s has no document %d", stores[i].File, doc)
+	}
+	return store[doc-stores[i].From], nil
+}
+
+// facetValues are the facets of a document
+type facetValues struct {
+	section string
+	isPage  bool
+	date    int // YYYYMMDD, 0 for none
+	tags    []string
+}
+
+// of returns the facets of a document, from the row of the page it belongs to
+func (f *Facets) of(doc int) facetValues {
+	if len(f.Pages) == 0 {
+		return facetValues{}
+	}
+	i := sort.Search(len(f.Pages), func(i int) bool { return f.Pages[i][0] > doc }) - 1
+	if i < 0 {
+		i = 0
+	}
+	row := f.Pages[i]
+	values := facetValues{isPage: row[0] == doc, date: row[2]}
+	if row[1] > 0 && row[1] <= len(f.Sections) {
+		values.section = f.Sections[row[1]-1][0]
+	}
+	for _, t := range row[3:] {
+		if t < len(f.Tags) {
+			values.tags = append(values.tags, f.Tags[t])
+		}
+	}
+	return values
+}
+
+// passes reports whether a document's facets pass the filters. A document
+// needs every tag and date filter, and one of the sections or types when
+// there are several.
+func (v facetValues) passes(filters []filter) bool {
+	inOK, typeOK := -1, -1 // -1: no such filter, 0: none matched, 1: one matched
+	either := func(ok *int, matched bool) {
+		if *ok < 1 {
+			*ok = 0
+			if matched {
+				*ok = 1
+			}
+		}
+	}
+	for _, f := range filters {
+		switch f.key {
+		case "tag":
+			found := false
+			for _, tag := range v.tags {
+				found = found || tag == f.value
+			}
+			if !found {
+				return false
+			}
+		case "in":
+			either(&inOK, v.section == f.value)
+		case "type":
+			docType := "heading"
+			if v.isPage {
+				docType = "page"
+			}
+			either(&typeOK, docType == f.value)
+		default:
+			first, last, ok := dateRange(f.value)
+			if !ok || v.date == 0 {
+				return false
+			}
+			if f.key != "to" && v.date < first {
+				return false
+			}
+			if f.key != "from" && v.date > last {
+				return false
+			}
+		}
+	}
+	return inOK != 0 && typeOK != 0
+}
+
+// dateRange turns YYYY, YYYY-MM or YYYY-MM-DD into the first and last
+// YYYYMMDD numbers it covers
+func dateRange(value string) (int, int, bool) {
+	m := dateRangeRegex.FindStringSubmatch(value)
+	if m == nil {
+		return 0, 0, false
+	}
+	year, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	day, _ := strconv.Atoi(m[3])
+	start := year*10000 + month*100 + day
+	switch {
+	case m[3] != "":
+		return start, start, true
+	case m[2] != "":
+		return start, start + 99, true
+	}
+	return start, start + 9999, true
+}
+
+// isWordRune reports whether r is part of a run of letters
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r)
+}
+
+// matched returns which characters of a run of letters belong to terms
+// that matched: whole words, or the CJK characters of matched pairs
+func matched(run []rune, words map[string]bool, a *analyzer) []bool {
+	hits := make([]bool, len(run))
+	for start := 0; start < len(run); {
+		end := start + 1
+		for end < len(run) && isCJK(run[end]) == isCJK(run[start]) {
+			end++
+		}
+		segment := run[start:end]
+		if isCJK(segment[0]) {
+			if len(segment) == 1 {
+				hits[start] = words[string(segment)]
+			}
+			for i := 0; i+1 < len(segment); i++ {
+				if words[string(segment[i:i+2])] {
+					hits[start+i], hits[start+i+1] = true, true
+				}
+			}
+		} else {
+			for _, term := range segmentTerms(fold(string(segment)), a) {
+				if words[term] {
+					for i := start; i < end; i++ {
+						hits[i] = true
+					}
+				}
+			}
+		}
+		start = end
+	}
+	return hits
+}
+
+// runs calls fn with each run of letters of text and its offset in runes
+func runs(text []rune, fn func(run []rune, at int)) {
+	for i := 0; i < len(text); {
+		if !isWordRune(text[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(text) && isWordRune(text[j]) {
+			j++
+		}
+		fn(text[i:j], i)
+		i = j
+	}
+}
+
+// Highlight returns text with the words a result matched passed through
+// mark, analyzing text in lang as the query was
+func (r Result) Highlight(text, lang string, mark func(string) string) string {
+	words := make(map[string]bool, len(r.Words))
+	for _, w := range r.Words {
+		words[w] = true
+	}
+	chars := []rune(text)
+	hits := make([]bool, len(chars))
+	a := analyzerFor(lang)
+	runs(chars, func(run []rune, at int) {
+		copy(hits[at:], matched(run, words, a))
+	})
+
+	var sb strings.Builder
+	for i := 0; i < len(chars); {
+		j := i + 1
+		for j < len(chars) && hits[j] == hits[i] {
+			j++
+		}
+		if hits[i] {
+			sb.WriteString(mark(string(chars[i:j])))
+		} else {
+			sb.WriteString(string(chars[i:j]))
+		}
+		i = j
+	}
+	return sb.String()
+}
+
+// snippet cuts the part of a section's text around the first matched word
+func snippet(text string, words map[string]bool, lang string) string {
+	chars := []rune(text)
+	if len(chars) <= snippetSize {
+		return text
+	}
+	at := -1
+	a := analyzerFor(lang)
+	runs(chars, func(run []rune, offset int) {
+		if at >= 0 {
+			return
+		}
+		for i, hit := range matched(run, words, a) {
+			if hit {
+				at = offset + i
+				return
+			}
+		}
+	})
+	at = max(at, 0)
+
+	// Start a little before the word, on a word boundary
+	start := max(0, min(at-60, len(chars)-snippetSize))
+	end := start + snippetSize
+	if start > 0 {
+		if space := indexRune(chars, ' ', start); space >= 0 && space < at {
+			start = space + 1
+		}
+	}
+	if end < len(chars) {
+		if space := lastIndexRune(chars, ' ', end); space > at {
+			end = space
+		}
+	}
+	result := strings.TrimSpace(string(chars[start:end]))
+	if start > 0 {
+		result = "…" + result
+	}
+	if end < len(chars) {
+		result += "…"
+	}
+	return result
+}
+
+// indexRune returns the first index of r in chars from from on, or -1
+func indexRune(chars []rune, r rune, from int) int {
+	for i := from; i < len(chars); i++ {
+		if chars[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastIndexRune returns the last index of r in chars at or before from, or -1
+func lastIndexRune(chars []rune, r rune, from int) int {
+	for i := min(from, len(chars)-1); i >= 0; i-- {
+		if chars[i] == r {
+			return i
+		}
+	}
+	return -1
+}
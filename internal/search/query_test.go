@@ -0,0 +1,145 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func testSearcher(t *testing.T, idx *Index, shardSize int) *Searcher {
+	t.Helper()
+	files, err := idx.Files(shardSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSearcher(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func urls(results []Result) string {
+	var list []string
+	for _, r := range results {
+		list = append(list, r.URL)
+	}
+	return strings.Join(list, " ")
+}
+
+func TestSearch(t *testing.T) {
+	idx := testIndex()
+	idx.Pages = append(idx.Pages,
+		PageEntry{Title: "Pulsar", URL: "/guides/pulsar/", Section: "guides", SectionName: "Guides", Date: "2023-06-01",
+			Sections: []SectionEntry{{Text: "Pulsar has consumer groups too."}}},
+		PageEntry{Title: "Kafkaesque", URL: "/books/", Tags: []string{"ops"},
+			Sections: []SectionEntry{{Text: "Stories of trials, castles and beetles, strange and absurd."}}},
+	)
+	s := testSearcher(t, idx, 0)
+
+	tests := []struct {
+		query, want string
+		total       int
+	}{
+		{"kafka", "/guides/kafka/ /books/ /guides/kafka/#groups", 3}, // Exact, then prefix
+		{"kafak", "/guides/kafka/ /guides/kafka/#groups", 2},         // A typo
+		{"consumer groups", "/guides/kafka/#groups /guides/pulsar/", 2},
+		{"consumer zebra", "", 0},
+		{"groups in:guides type:page", "/guides/pulsar/", 1},
+		{"groups type:heading", "/guides/kafka/#groups", 1},
+		{"tag:ops", "/guides/kafka/ / /books/", 3}, // Filters alone list pages, newest first
+		{"tag:ops tag:streaming", "/guides/kafka/", 1},
+		{"from:2023-07", "/guides/kafka/", 1},
+		{"date:2023 in:guides in:books", "/guides/pulsar/", 1},
+		{"to:bad", "", 0},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		results, total, err := s.Search(tt.query, "", 10)
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", tt.query, err)
+		}
+		if urls(results) != tt.want || total != tt.total {
+			t.Errorf("Search(%q) = %q (%d), want %q (%d)", tt.query, urls(results), total, tt.want, tt.total)
+		}
+	}
+
+	results, total, _ := s.Search("kafka", "", 1)
+	if len(results) != 1 || total != 3 {
+		t.Errorf("limit 1 = %d results of %d", len(results), total)
+	}
+	r := results[0]
+	if r.Type != "page" || r.Title != "Kafka Guide" || r.Snippet != "Kafka is a log." || r.Score <= 0 {
+		t.Errorf("result = %+v", r)
+	}
+}
+
+func TestSearchSharded(t *testing.T) {
+	idx := &Index{}
+	for i := 0; i < 200; i++ {
+		idx.Pages = append(idx.Pages, PageEntry{
+			Title:    fmt.Sprintf("Page %d", i),
+			URL:      fmt.Sprintf("/page-%03d/", i),
+			Sections: []SectionEntry{{Text: fmt.Sprintf("word%d", i)}},
+		})
+	}
+	s := testSearcher(t, idx, 1)
+	if len(s.manifest.Terms) < 3 {
+		t.Fatal("index should be sharded")
+	}
+	// Typos are found across the shards of words with the same first letters
+	for _, query := range []string{"word150", "wodr150", "word150 page"} {
+		results, _, err := s.Search(query, "", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) == 0 || results[0].URL != "/page-150/" {
+			t.Errorf("Search(%q) = %q", query, urls(results))
+		}
+	}
+
+	s = testSearcher(t, idx, 1)
+	delete(s.files, s.manifest.Stores[0].File)
+	if _, _, err := s.Search("word1", "", 10); err == nil {
+		t.Error("a missing shard should be an error")
+	}
+	if _, err := NewSearcher(map[string][]byte{ManifestFile: []byte("{")}); err == nil {
+		t.Error("an invalid manifest should be an error")
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	r := Result{Words: []string{"deploy", "設定"}}
+	mark := func(s string) string { return "[" + s + "]" }
+	tests := []struct {
+		text, lang, want string
+	}{
+		{"Deployments, deployed and deploys", "en", "[Deployments], [deployed] and [deploys]"},
+		{"Deployments", "", "Deployments"}, // Without stemming only the word itself matches
+		{"deploy", "", "[deploy]"},
+		{"検索の設定です", "ja", "検索の[設定]です"},
+	}
+	for _, tt := range tests {
+		if got := r.Highlight(tt.text, tt.lang, mark); got != tt.want {
+			t.Errorf("Highlight(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	words := map[string]bool{"target": true}
+	text := strings.Repeat("lorem ipsum ", 30) + "the target word " + strings.Repeat("dolor sit ", 30)
+	got := snippet(text, words, "")
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "the target word") {
+		t.Errorf("snippet() = %q", got)
+	}
+	if n := len([]rune(got)); n > snippetSize+2 {
+		t.Errorf("snippet() is %d characters long", n)
+	}
+	if got := snippet(text, map[string]bool{}, ""); !strings.HasPrefix(got, "lorem") {
+		t.Errorf("snippet without matches should start at the beginning, got %q", got)
+	}
+	if got := snippet("short", words, ""); got != "short" {
+		t.Errorf("short snippet = %q", got)
+	}
+}
@@ -1,10 +1,21 @@
 package search
 
+import (
+	"encoding/json"
+
+	"github.com/wusher/volcano/internal/i18n"
+)
+
 // GenerateSearchJS returns the JavaScript code for the command palette.
-// This is loaded on-demand when user first presses Cmd+K.
+// This is loaded on-demand when user first presses Cmd+K. It loads the index
+// manifest when the palette opens, then the shards each query needs as the
+// user types. Interface text comes from window.VOLCANO_I18N (set by each
+// page), falling back to English.
 func GenerateSearchJS(baseURL string) string {
+	defaults, _ := json.Marshal(i18n.English.Client())
 	return `(function() {
     const baseURL = '` + baseURL + `';
+    const text = Object.assign(` + string(defaults) + `, window.VOLCANO_I18N || {});
     let searchIndex = null;
     let searchLoading = false;
     let selectedIndex = -1;
@@ -12,13 +23,14 @@ func GenerateSearchJS(baseURL string) string {
     // Inject HTML
     const html = '<div id="command-palette" class="command-palette">' +
         '<div class="command-palette-backdrop"></div>' +
-        '<div class="command-palette-modal" role="dialog" aria-label="Search">' +
+        '<div class="command-palette-modal" role="dialog" aria-label="' + escapeHtml(text.search) + '">' +
             '<div class="command-palette-header">' +
-                '<input type="text" id="command-palette-input" placeholder="Search..." autocomplete="off" spellcheck="false">' +
+                '<input type="text" id="command-palette-input" placeholder="' + escapeHtml(text.search) + '" autocomplete="off" spellcheck="false">' +
                 '<kbd class="command-palette-hint">esc</kbd>' +
             '</div>' +
+            '<div class="command-palette-facets" id="command-palette-facets"></div>' +
             '<div class="command-palette-results" id="command-palette-results">' +
-                '<div class="command-palette-empty">Type to search...</div>' +
+                '<div class="command-palette-empty">' + escapeHtml(text.typeToSearch) + '</div>' +
             '</div>' +
         '</div>' +
     '</div>';
@@ -41,20 +53,28 @@ func GenerateSearchJS(baseURL string) string {
     const palette = document.getElementById('command-palette');
     const input = document.getElementById('command-palette-input');
     const results = document.getElementById('command-palette-results');
+    const facetBar = document.getElementById('command-palette-facets');
+
+    // A ?q= link, the target of the site's SearchAction, opens the palette
+    // with its query until it is closed
+    let linkedQuery = new URLSearchParams(window.location.search).get('q') || '';
 
     function openCommandPalette() {
         palette.classList.add('open');
         document.body.classList.add('command-palette-open');
-        input.value = '';
+        input.value = linkedQuery;
         selectedIndex = -1;
-        results.innerHTML = '<div class="command-palette-empty">Type to search...</div>';
+        results.innerHTML = '<div class="command-palette-empty">' + escapeHtml(text.typeToSearch) + '</div>';
+        facetBar.innerHTML = '';
         input.focus();
-        loadSearchIndex();
+        const loading = loadSearchIndex();
+        if (linkedQuery) loading.then(doSearch);
     }
 
     function closeCommandPalette() {
         palette.classList.remove('open');
         document.body.classList.remove('command-palette-open');
+        linkedQuery = '';
     }
 
     window.openCommandPalette = openCommandPalette;
@@ -79,46 +99,371 @@ func GenerateSearchJS(baseURL string) string {
         return s.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
     }
 
-    function doSearch() {
-        const query = input.value.trim().toLowerCase();
-        if (!searchIndex || !query) {
-            results.innerHTML = '<div class="command-palette-empty">Type to search...</div>';
-            selectedIndex = -1;
-            return;
+    // BM25 parameters: term frequency saturation and length normalization
+    const k1 = 1.2;
+    const b = 0.75;
+
+    // Shards of the index, loaded as queries need them, by file
+    const shards = new Map();
+
+    function loadShard(file) {
+        if (!shards.has(file)) {
+            shards.set(file, fetch(baseURL + '/' + file).then(function(res) {
+                if (!res.ok) throw new Error('HTTP ' + res.status);
+                return res.json();
+            }).catch(function(e) {
+                console.error('Failed to load search shard:', e);
+                shards.delete(file);
+                return null;
+            }));
         }
+        return shards.get(file);
+    }
+
+` + analyzeJS() + `
+    // queryLang is the language queries are analyzed in: the page's
+    function queryLang() {
+        return document.documentElement.lang;
+    }
+
+    // compareTerms orders words by code point, as the index sorts them
+    function compareTerms(x, y) {
+        const a = Array.from(x);
+        const c = Array.from(y);
+        for (let i = 0; i < a.length && i < c.length; i++) {
+            if (a[i] !== c[i]) return a[i].codePointAt(0) - c[i].codePointAt(0);
+        }
+        return a.length - c.length;
+    }
+
+    // termShards returns the postings shards that hold the words starting
+    // with prefix, or only the one that would hold prefix itself
+    function termShards(prefix, only) {
+        const list = searchIndex.terms;
+        let first = 0;
+        while (first + 1 < list.length && compareTerms(list[first + 1].from, prefix) <= 0) first++;
+        let last = first;
+        while (!only && last + 1 < list.length && list[last + 1].from.startsWith(prefix)) last++;
+        return list.slice(first, last + 1);
+    }
+
+    // lookup returns the postings of the indexed words a query term matches,
+    // weighted: the word itself, words it starts, and words a typo or two
+    // away. Typos are looked for in the shards of words that start with the
+    // term's first two letters. A single CJK character matches the pairs of
+    // characters it starts.
+    async function lookup(term) {
+        const letters = Array.from(term);
+        const prefixes = letters.length >= 2 || isCJK(term);
+        const prefix = letters.length >= 4 ? letters.slice(0, 2).join('') : term;
+        const loaded = await Promise.all(termShards(prefix, !prefixes).map(function(s) { return loadShard(s.file); }));
+        const typos = letters.length >= 8 ? 2 : 1;
+        const found = [];
+        loaded.forEach(function(shard) {
+            if (!shard) return;
+            Object.keys(shard).forEach(function(word) {
+                let weight = 0;
+                if (word === term) {
+                    weight = 1;
+                } else if (prefixes && word.startsWith(term)) {
+                    weight = 0.7;
+                } else if (letters.length >= 4 && Math.abs(word.length - term.length) <= typos && editDistance(term, word, typos) <= typos) {
+                    weight = 0.4;
+                }
+                if (weight > 0) found.push({ word: word, weight: weight, postings: shard[word] });
+            });
+        });
+        return found;
+    }
 
-        const terms = query.split(/\s+/).filter(t => t.length > 0);
-        const matches = [];
-        for (const page of searchIndex.pages) {
-            const pageTitle = page.title.toLowerCase();
-            const pagePath = page.url.toLowerCase().replace(/\//g, ' ');
-            const pageText = pageTitle + ' ' + pagePath;
-            // Page matches if all terms are in title or path
-            if (terms.every(t => pageText.includes(t))) {
-                matches.push({ type: 'page', title: page.title, url: page.url, snippet: '' });
+    // editDistance counts the insertions, deletions, substitutions and
+    // swaps of adjacent letters between two words, giving up past max
+    function editDistance(a, b, max) {
+        let before = null;
+        let prev = [];
+        for (let j = 0; j <= b.length; j++) prev.push(j);
+        for (let i = 1; i <= a.length; i++) {
+            const row = [i];
+            let least = i;
+            for (let j = 1; j <= b.length; j++) {
+                let d = Math.min(prev[j] + 1, row[j - 1] + 1, prev[j - 1] + (a[i - 1] === b[j - 1] ? 0 : 1));
+                if (i > 1 && j > 1 && a[i - 1] === b[j - 2] && a[i - 2] === b[j - 1]) {
+                    d = Math.min(d, before[j - 2] + 1);
+                }
+                row.push(d);
+                least = Math.min(least, d);
             }
-            // Heading matches if all terms are in heading, title, or path combined
-            for (const h of (page.headings || [])) {
-                const combined = h.text.toLowerCase() + ' ' + pageText;
-                if (terms.every(t => combined.includes(t))) {
-                    matches.push({ type: 'h' + h.level, title: h.text, url: page.url + '#' + h.anchor, snippet: page.title });
+            if (least > max) return max + 1;
+            before = prev;
+            prev = row;
+        }
+        return prev[b.length];
+    }
+
+    // rank scores documents with BM25. Each query term scores by its best
+    // match in a document, and a document must match every term.
+    async function rank(terms) {
+        const found = await Promise.all(terms.map(lookup));
+        const n = searchIndex.docs;
+        const matches = new Map();
+        found.forEach(function(words) {
+            const best = new Map();
+            words.forEach(function(m) {
+                const p = m.postings;
+                const df = p.length / 3;
+                const idf = Math.log(1 + (n - df + 0.5) / (df + 0.5));
+                for (let i = 0; i < p.length; i += 3) {
+                    const tf = p[i + 1];
+                    const score = m.weight * idf * tf * (k1 + 1) / (tf + k1 * (1 - b + b * p[i + 2] / searchIndex.avgLength));
+                    const entry = best.get(p[i]) || { score: 0, words: [] };
+                    entry.score = Math.max(entry.score, score);
+                    entry.words.push(m.word);
+                    best.set(p[i], entry);
                 }
+            });
+            best.forEach(function(entry, doc) {
+                const match = matches.get(doc) || { doc: doc, score: 0, terms: 0, words: new Set() };
+                match.score += entry.score;
+                match.terms++;
+                entry.words.forEach(function(w) { match.words.add(w); });
+                matches.set(doc, match);
+            });
+        });
+        return Array.from(matches.values()).filter(function(m) {
+            return m.terms === terms.length;
+        }).sort(function(x, y) {
+            return x.score !== y.score ? y.score - x.score : x.doc - y.doc;
+        });
+    }
+
+    // loadDoc returns a document's type, title, URL, page title and text
+    async function loadDoc(doc) {
+        const stores = searchIndex.stores;
+        let i = 0;
+        while (i + 1 < stores.length && stores[i + 1].from <= doc) i++;
+        const store = await loadShard(stores[i].file);
+        return store ? store[doc - stores[i].from] : null;
+    }
+
+    // matched returns which characters of a run of letters belong to terms
+    // that matched: whole words, or the CJK characters of matched pairs
+    function matched(run, words) {
+        const rules = rulesFor(queryLang());
+        const hits = [];
+        segments(run).forEach(function(segment) {
+            const chars = Array.from(segment);
+            const start = hits.length;
+            chars.forEach(function() { hits.push(false); });
+            if (isCJK(chars[0])) {
+                if (chars.length === 1) hits[start] = words.has(segment);
+                for (let i = 0; i + 1 < chars.length; i++) {
+                    if (words.has(chars[i] + chars[i + 1])) hits[start + i] = hits[start + i + 1] = true;
+                }
+            } else if (analyzeWord(fold(segment), rules).some(function(t) { return words.has(t); })) {
+                chars.forEach(function(c, i) { hits[start + i] = true; });
+            }
+        });
+        return hits;
+    }
+
+    // highlight escapes text and marks the words that matched
+    function highlight(s, words) {
+        return s.split(/([\p{L}\p{N}\p{M}]+)/u).map(function(part, i) {
+            if (i % 2 === 0) return escapeHtml(part);
+            const chars = Array.from(part);
+            const hits = matched(part, words);
+            let html = '';
+            for (let j = 0; j < chars.length; j++) {
+                if (hits[j] && !hits[j - 1]) html += '<mark>';
+                html += escapeHtml(chars[j]);
+                if (hits[j] && !hits[j + 1]) html += '</mark>';
+            }
+            return html;
+        }).join('');
+    }
+
+    // snippet cuts the part of a section's text around the first matched word
+    function snippet(s, words) {
+        const size = 160;
+        if (s.length <= size) return s;
+        let at = 0;
+        const wordRegex = /[\p{L}\p{N}\p{M}]+/gu;
+        let m;
+        while ((m = wordRegex.exec(s)) !== null) {
+            const first = matched(m[0], words).indexOf(true);
+            if (first >= 0) {
+                at = m.index + Array.from(m[0]).slice(0, first).join('').length;
+                break;
             }
         }
+        // Start a little before the word, on a word boundary
+        let start = Math.max(0, Math.min(at - 60, s.length - size));
+        let end = start + size;
+        if (start > 0) {
+            const space = s.indexOf(' ', start);
+            if (space >= 0 && space < at) start = space + 1;
+        }
+        if (end < s.length) {
+            const space = s.lastIndexOf(' ', end);
+            if (space > at) end = space;
+        }
+        return (start > 0 ? '\u2026' : '') + s.slice(start, end).trim() + (end < s.length ? '\u2026' : '');
+    }
+
+    // Filters a query can hold besides words, like tag:api or in:guides
+    const filterKeys = ['tag', 'in', 'type', 'date', 'from', 'to'];
+
+    // parseQuery splits a query into its words and its filters
+    function parseQuery(value) {
+        const words = [];
+        const filters = [];
+        value.split(/\s+/).forEach(function(part) {
+            const m = part.match(/^(\w+):(.+)$/);
+            if (m && filterKeys.indexOf(m[1].toLowerCase()) >= 0) {
+                filters.push({ key: m[1].toLowerCase(), value: m[2].toLowerCase() });
+            } else if (part) {
+                words.push(part);
+            }
+        });
+        return { terms: analyze(words.join(' '), queryLang()), filters: filters };
+    }
+
+    // dateRange turns YYYY, YYYY-MM or YYYY-MM-DD into the first and last
+    // YYYYMMDD numbers it covers
+    function dateRange(value) {
+        const m = value.match(/^(\d{4})(?:-(\d{1,2})(?:-(\d{1,2}))?)?$/);
+        if (!m) return null;
+        const start = parseInt(m[1], 10) * 10000 + (m[2] ? parseInt(m[2], 10) * 100 : 0) + (m[3] ? parseInt(m[3], 10) : 0);
+        return [start, start + (m[3] ? 0 : (m[2] ? 99 : 9999))];
+    }
 
+    // facetsOf returns the section, type, date and tags of a document, from
+    // the row of the page it belongs to
+    function facetsOf(facets, doc) {
+        const pages = facets.pages;
+        let lo = 0;
+        let hi = pages.length - 1;
+        while (lo < hi) {
+            const mid = (lo + hi + 1) >> 1;
+            if (pages[mid][0] <= doc) lo = mid; else hi = mid - 1;
+        }
+        const page = pages[lo];
+        return {
+            in: page[1] ? facets.sections[page[1] - 1][0] : '',
+            type: page[0] === doc ? 'page' : 'heading',
+            date: page[2],
+            tag: page.slice(3).map(function(t) { return facets.tags[t]; })
+        };
+    }
+
+    // passes reports whether a document's facets pass the filters. A
+    // document needs every tag and date filter, and one of the sections or
+    // types when there are several.
+    function passes(values, filters) {
+        const any = { in: null, type: null };
+        for (const f of filters) {
+            if (f.key === 'tag') {
+                if (values.tag.indexOf(f.value) < 0) return false;
+            } else if (f.key === 'in' || f.key === 'type') {
+                any[f.key] = any[f.key] || values[f.key] === f.value;
+            } else {
+                const range = dateRange(f.value);
+                if (!range || !values.date) return false;
+                if (f.key !== 'to' && values.date < range[0]) return false;
+                if (f.key !== 'from' && values.date > range[1]) return false;
+            }
+        }
+        return any.in !== false && any.type !== false;
+    }
+
+    // renderFacets shows the query's filters, which remove themselves when
+    // clicked, and the facets of the results with their counts, which add
+    // a filter. Facets every result shares are left out.
+    function renderFacets(facets, query, matches) {
+        const counts = { type: new Map(), in: new Map(), date: new Map(), tag: new Map() };
+        const add = function(key, value) { counts[key].set(value, (counts[key].get(value) || 0) + 1); };
+        if (facets) {
+            matches.forEach(function(m) {
+                const values = facetsOf(facets, m.doc);
+                add('type', values.type);
+                if (values.in) add('in', values.in);
+                if (values.date) add('date', String(Math.floor(values.date / 10000)));
+                values.tag.forEach(function(t) { add('tag', t); });
+            });
+        }
+        const sectionNames = new Map((facets ? facets.sections : []).map(function(s) { return [s[0], s[1]]; }));
+
+        let html = query.filters.map(function(f) {
+            const filter = escapeHtml(f.key + ':' + f.value);
+            return '<button type="button" class="search-chip active" data-filter="' + filter + '">' + filter + ' <span aria-hidden="true">\u00d7</span></button>';
+        }).join('');
+        Object.keys(counts).forEach(function(key) {
+            Array.from(counts[key].entries()).filter(function(entry) {
+                return entry[1] < matches.length && !query.filters.some(function(f) { return f.key === key && f.value === entry[0]; });
+            }).sort(function(x, y) {
+                return y[1] - x[1] || (x[0] < y[0] ? -1 : 1);
+            }).slice(0, 5).forEach(function(entry) {
+                const label = key === 'in' ? sectionNames.get(entry[0]) : (key === 'tag' ? '#' + entry[0] : entry[0]);
+                html += '<button type="button" class="search-chip" data-filter="' + escapeHtml(key + ':' + entry[0]) + '">' +
+                    escapeHtml(label) + ' <span class="search-chip-count">' + entry[1] + '</span></button>';
+            });
+        });
+        facetBar.innerHTML = html;
+    }
+
+    // Results of a query still loading shards are dropped once the user
+    // types on
+    let searchSeq = 0;
+
+    async function doSearch() {
+        const seq = ++searchSeq;
+        const query = parseQuery(input.value);
+        if (!searchIndex || (query.terms.length === 0 && query.filters.length === 0)) {
+            results.innerHTML = '<div class="command-palette-empty">' + escapeHtml(text.typeToSearch) + '</div>';
+            facetBar.innerHTML = '';
+            selectedIndex = -1;
+            return;
+        }
+
+        const facets = searchIndex.facets ? await loadShard(searchIndex.facets) : null;
+        let filters = query.filters;
+        let matches;
+        if (query.terms.length > 0) {
+            matches = await rank(query.terms);
+        } else {
+            // Filters alone list pages (or headings, with type:), newest first
+            if (!filters.some(function(f) { return f.key === 'type'; })) {
+                filters = filters.concat([{ key: 'type', value: 'page' }]);
+            }
+            matches = [];
+            for (let doc = 0; doc < searchIndex.docs; doc++) {
+                matches.push({ doc: doc, score: facets ? facetsOf(facets, doc).date : 0, words: new Set() });
+            }
+            matches.sort(function(x, y) { return y.score - x.score || x.doc - y.doc; });
+        }
+        if (filters.length > 0) {
+            matches = matches.filter(function(m) { return facets && passes(facetsOf(facets, m.doc), filters); });
+        }
+        const shown = matches.slice(0, 10);
+        const docs = await Promise.all(shown.map(function(m) { return loadDoc(m.doc); }));
+        if (seq !== searchSeq) return;
+        renderFacets(facets, query, matches);
         if (matches.length === 0) {
-            results.innerHTML = '<div class="command-palette-empty">No results found</div>';
+            results.innerHTML = '<div class="command-palette-empty">' + escapeHtml(text.noResults) + '</div>';
             selectedIndex = -1;
             return;
         }
 
-        const limited = matches.slice(0, 10);
         const prefixedBaseURL = baseURL || '';
-        results.innerHTML = limited.map(function(m, i) {
-            return '<a href="' + prefixedBaseURL + m.url + '" class="command-palette-result' + (i === 0 ? ' selected' : '') + '" data-index="' + i + '">' +
-                '<span class="result-type">' + m.type + '</span>' +
-                '<span class="result-title">' + escapeHtml(m.title) + '</span>' +
-                (m.snippet ? '<span class="result-snippet">' + escapeHtml(m.snippet) + '</span>' : '') +
+        results.innerHTML = shown.map(function(m, i) {
+            const doc = docs[i];
+            if (!doc) return '';
+            const excerpt = snippet(doc[4], m.words);
+            return '<a href="' + prefixedBaseURL + doc[2] + '" class="command-palette-result' + (i === 0 ? ' selected' : '') + '" data-index="' + i + '">' +
+                '<span class="result-type">' + doc[0] + '</span>' +
+                '<span class="result-title">' + highlight(doc[1], m.words) + '</span>' +
+                (doc[3] ? '<span class="result-page">' + escapeHtml(doc[3]) + '</span>' : '') +
+                (excerpt ? '<span class="result-snippet">' + highlight(excerpt, m.words) + '</span>' : '') +
             '</a>';
         }).join('');
         selectedIndex = 0;
@@ -161,6 +506,20 @@ func GenerateSearchJS(baseURL string) string {
         }
     });
 
+    // Chips add their filter to the query, or remove it when it's there
+    facetBar.addEventListener('click', function(e) {
+        const chip = e.target.closest('.search-chip');
+        if (!chip) return;
+        const filter = chip.dataset.filter;
+        if (chip.classList.contains('active')) {
+            input.value = input.value.split(/\s+/).filter(function(part) { return part.toLowerCase() !== filter; }).join(' ');
+        } else {
+            input.value = (input.value.trim() + ' ' + filter).trim();
+        }
+        input.focus();
+        doSearch();
+    });
+
     // Listen for open-search event (from mobile button)
     window.addEventListener('open-search', openCommandPalette);
 
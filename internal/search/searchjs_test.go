@@ -23,6 +23,7 @@ func TestGenerateSearchJS(t *testing.T) {
 				"ArrowDown",
 				"ArrowUp",
 				"Escape",
+				"get('q')", // ?q= links, the SearchAction target, open with a query
 			},
 		},
 		{
@@ -78,6 +79,42 @@ func TestGenerateSearchJS_ContainsEventListeners(t *testing.T) {
 	}
 }
 
+func TestGenerateSearchJS_ContainsRanking(t *testing.T) {
+	result := GenerateSearchJS("")
+
+	for _, expected := range []string{
+		"searchIndex.terms",                     // Loads term shards
+		"function rank",                         // BM25 scoring
+		"startsWith",                            // Prefix matches
+		"editDistance",                          // Typo matches
+		"<mark>",                                // Highlighted words
+		"result-snippet",                        // Snippets
+		"result-page",                           // Parent page of section results
+		"analyze(words.join(' '), queryLang())", // Queries are analyzed like pages
+		"function matched",                      // Highlights stemmed words and CJK pairs
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("GenerateSearchJS() should contain %q", expected)
+		}
+	}
+}
+
+func TestGenerateSearchJS_ContainsFacets(t *testing.T) {
+	result := GenerateSearchJS("")
+
+	for _, expected := range []string{
+		"command-palette-facets",                    // Chip bar
+		"'tag', 'in', 'type', 'date', 'from', 'to'", // Filter syntax
+		"searchIndex.facets",                        // Facets file
+		"search-chip-count",                         // Facet counts
+		"chip.classList.contains('active')",         // Removing a filter
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("GenerateSearchJS() should contain %q", expected)
+		}
+	}
+}
+
 func TestGenerateSearchJS_ContainsHTMLInjection(t *testing.T) {
 	result := GenerateSearchJS("")
 
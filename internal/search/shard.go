@@ -0,0 +1,255 @@
+package search
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wusher/volcano/internal/assets"
+)
+
+// Files of a search index: the manifest the palette loads when it opens, and
+// the folder of shards it loads as the user types
+const (
+	ManifestFile = "search-index.json"
+	ShardDir     = "search-index"
+)
+
+// DefaultShardSize is the target size of a shard in KB
+const DefaultShardSize = 64
+
+// Weights of a word in a document's term counts, by where it appears
+const (
+	titleWeight   = 3
+	headingWeight = 2
+	textWeight    = 1
+)
+
+// Manifest lists the shards of a search index, with the statistics BM25
+// ranking needs across all of them
+type Manifest struct {
+	Docs      int         `json:"docs"`      // Number of documents
+	AvgLength float64     `json:"avgLength"` // Average weighted length of a document
+	Terms     []TermShard `json:"terms"`     // Postings shards, in term order
+	Stores    []DocShard  `json:"stores"`    // Document shards, in document order
+	Facets    string      `json:"facets"`    // File of the facets of each page ("" without pages)
+}
+
+// Facets are the facets of an index's pages, written to the manifest's
+// facets file
+type Facets struct {
+	Sections [][2]string `json:"sections"` // URL name and display name of each section
+	Tags     []string    `json:"tags"`
+	// Pages lists each page's first document (the documents of a page
+	// follow each other), its section (index into Sections plus one, 0 for
+	// none), its date as YYYYMMDD (0 for none) and its tags (indexes into Tags)
+	Pages [][]int `json:"pages"`
+}
+
+// TermShard is a file of postings for the terms from From up to the next
+// shard's first term. Each term maps to a flat list of numbers: a document,
+// the term's weighted count in it and its weighted length, for each document
+// the term is in.
+type TermShard struct {
+	From string `json:"from"`
+	File string `json:"file"` // Path relative to the output directory
+}
+
+// DocShard is a file of documents, from document number From on. Each
+// document is a list of its type ("page" or "h2"-"h4"), title, URL, page
+// title (sections only) and text.
+type DocShard struct {
+	From int    `json:"from"`
+	File string `json:"file"` // Path relative to the output directory
+}
+
+// document is what a search result points to: a page's intro (its text
+// before the first heading) or one of its sections
+type document struct {
+	fields [5]string // Type, title, URL, page title, text
+	terms  map[string]int
+	length int
+}
+
+// weighted is text whose words count weight times
+type weighted struct {
+	text   string
+	weight int
+}
+
+// Files splits the index into a manifest and content-hashed shards of about
+// shardSize KB (DefaultShardSize if 0 or less). It returns their content by
+// path relative to the output directory, manifest included. The same index
+// always gives the same files.
+func (idx *Index) Files(shardSize int) (map[string][]byte, error) {
+	if shardSize <= 0 {
+		shardSize = DefaultShardSize
+	}
+	limit := shardSize * 1024
+
+	pages := make([]PageEntry, len(idx.Pages))
+	copy(pages, idx.Pages)
+	sort.SliceStable(pages, func(i, j int) bool { return pages[i].URL < pages[j].URL })
+
+	docs := documents(pages)
+	manifest := Manifest{Docs: len(docs), Terms: []TermShard{}, Stores: []DocShard{}}
+	postings := make(map[string][]int)
+	total := 0
+	for i, doc := range docs {
+		total += doc.length
+		for term, count := range doc.terms {
+			postings[term] = append(postings[term], i, count, doc.length)
+		}
+	}
+	if len(docs) > 0 {
+		manifest.AvgLength = float64(total) / float64(len(docs))
+	}
+
+	files := make(map[string][]byte)
+	add := func(name string, value any) (string, error) {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		file := ShardDir + "/" + assets.HashedFileName(name, "json", string(data))
+		files[file] = data
+		return file, nil
+	}
+
+	// Postings, in shards of sorted terms
+	terms := make([]string, 0, len(postings))
+	for term := range postings {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	for start := 0; start < len(terms); {
+		shard := make(map[string][]int)
+		size := 0
+		end := start
+		for ; end < len(terms) && (end == start || size < limit); end++ {
+			data, _ := json.Marshal(postings[terms[end]])
+			size += len(terms[end]) + len(data) + 4
+			shard[terms[end]] = postings[terms[end]]
+		}
+		file, err := add("terms", shard)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Terms = append(manifest.Terms, TermShard{From: terms[start], File: file})
+		start = end
+	}
+
+	// Documents, in shards of consecutive documents
+	for start := 0; start < len(docs); {
+		var shard [][5]string
+		size := 0
+		end := start
+		for ; end < len(docs) && (end == start || size < limit); end++ {
+			for _, field := range docs[end].fields {
+				size += len(field) + 3
+			}
+			shard = append(shard, docs[end].fields)
+		}
+		file, err := add("docs", shard)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Stores = append(manifest.Stores, DocShard{From: start, File: file})
+		start = end
+	}
+
+	if len(pages) > 0 {
+		file, err := add("facets", facets(pages))
+		if err != nil {
+			return nil, err
+		}
+		manifest.Facets = file
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	files[ManifestFile] = data
+	return files, nil
+}
+
+// facets collects the facets of pages, in the order documents lists them
+func facets(pages []PageEntry) Facets {
+	f := Facets{Sections: [][2]string{}, Tags: []string{}, Pages: [][]int{}}
+	sections := make(map[string]int)
+	tags := make(map[string]int)
+	for _, page := range pages {
+		if page.Section != "" {
+			if _, ok := sections[page.Section]; !ok {
+				sections[page.Section] = 0
+				f.Sections = append(f.Sections, [2]string{page.Section, page.SectionName})
+			}
+		}
+		for _, tag := range page.Tags {
+			if _, ok := tags[tag]; !ok {
+				tags[tag] = 0
+				f.Tags = append(f.Tags, tag)
+			}
+		}
+	}
+	sort.Slice(f.Sections, func(i, j int) bool { return f.Sections[i][0] < f.Sections[j][0] })
+	sort.Strings(f.Tags)
+	for i, section := range f.Sections {
+		sections[section[0]] = i + 1
+	}
+	for i, tag := range f.Tags {
+		tags[tag] = i
+	}
+
+	doc := 0
+	for _, page := range pages {
+		date, _ := strconv.Atoi(strings.ReplaceAll(page.Date, "-", ""))
+		row := []int{doc, sections[page.Section], date}
+		for _, tag := range page.Tags {
+			row = append(row, tags[tag])
+		}
+		f.Pages = append(f.Pages, row)
+		doc += 1 + len(page.Headings)
+	}
+	return f
+}
+
+// documents returns the searchable documents of pages, with their terms
+// counted: each page's intro, then its sections. Words count more in the
+// page title and headings than in body text.
+func documents(pages []PageEntry) []document {
+	var docs []document
+	for _, page := range pages {
+		texts := make(map[string]string, len(page.Sections))
+		for _, section := range page.Sections {
+			texts[section.Anchor] = section.Text
+		}
+		docs = append(docs, newDocument(page.Lang,
+			[5]string{"page", page.Title, page.URL, "", texts[""]},
+			weighted{page.Title, titleWeight}, weighted{page.URL, textWeight}, weighted{texts[""], textWeight},
+		))
+		for _, h := range page.Headings {
+			body := texts[h.Anchor]
+			docs = append(docs, newDocument(page.Lang,
+				[5]string{"h" + strconv.Itoa(h.Level), h.Text, page.URL + "#" + h.Anchor, page.Title, body},
+				weighted{h.Text, headingWeight}, weighted{page.Title, textWeight}, weighted{body, textWeight},
+			))
+		}
+	}
+	return docs
+}
+
+// newDocument counts the terms of a document's weighted text, analyzed by
+// the rules of its page's language
+func newDocument(lang string, fields [5]string, texts ...weighted) document {
+	doc := document{fields: fields, terms: make(map[string]int)}
+	for _, t := range texts {
+		for _, word := range analyze(t.text, lang) {
+			doc.terms[word] += t.weight
+			doc.length += t.weight
+		}
+	}
+	return doc
+}
@@ -0,0 +1,201 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func testIndex() *Index {
+	return &Index{Pages: []PageEntry{
+		{
+			Title:    "Kafka Guide",
+			URL:      "/guides/kafka/",
+			Headings: []HeadingEntry{{Text: "Consumer Groups", Anchor: "groups", Level: 2}},
+			Sections: []SectionEntry{{Text: "Kafka is a log."}, {Anchor: "groups", Text: "Groups share partitions."}},
+			Section:  "guides", SectionName: "Guides", Tags: []string{"streaming", "ops"}, Date: "2024-03-15",
+		},
+		{Title: "Home", URL: "/", Sections: []SectionEntry{{Text: "Welcome home."}}, Tags: []string{"ops"}},
+	}}
+}
+
+func TestFiles(t *testing.T) {
+	files, err := testIndex().Files(0)
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+	if len(files) != 4 {
+		t.Fatalf("Files() = %d files, want a manifest, a terms and a docs shard and facets", len(files))
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(files[ManifestFile], &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Docs != 3 || len(manifest.Terms) != 1 || len(manifest.Stores) != 1 {
+		t.Fatalf("manifest = %+v", manifest)
+	}
+	// Lengths: intro 3*2 + 2 + 4, section 2*2 + 2 + 3, home 3 + 0 + 2
+	if manifest.AvgLength != 26.0/3 {
+		t.Errorf("AvgLength = %v, want 26/3", manifest.AvgLength)
+	}
+	if !regexp.MustCompile(`^search-index/terms\.[0-9a-f]{8}\.json$`).MatchString(manifest.Terms[0].File) {
+		t.Errorf("terms file = %q", manifest.Terms[0].File)
+	}
+
+	var postings map[string][]int
+	if err := json.Unmarshal(files[manifest.Terms[0].File], &postings); err != nil {
+		t.Fatal(err)
+	}
+	// Pages are in URL order: the home page is document 0
+	for term, want := range map[string][]int{
+		"kafka":  {1, 5, 12, 2, 1, 9}, // Title, URL and text of the intro, page title of the section
+		"groups": {2, 3, 9},           // Heading and text of the section
+		"home":   {0, 4, 5},           // Title and text
+		"guides": {1, 1, 12},          // URL of the intro
+	} {
+		if !reflect.DeepEqual(postings[term], want) {
+			t.Errorf("postings[%q] = %v, want %v", term, postings[term], want)
+		}
+	}
+
+	var docs [][]string
+	if err := json.Unmarshal(files[manifest.Stores[0].File], &docs); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"h2", "Consumer Groups", "/guides/kafka/#groups", "Kafka Guide", "Groups share partitions."}
+	if !reflect.DeepEqual(docs[2], want) {
+		t.Errorf("docs[2] = %q, want %q", docs[2], want)
+	}
+
+	var facets Facets
+	if err := json.Unmarshal(files[manifest.Facets], &facets); err != nil {
+		t.Fatal(err)
+	}
+	wantFacets := Facets{
+		Sections: [][2]string{{"guides", "Guides"}},
+		Tags:     []string{"ops", "streaming"},
+		Pages:    [][]int{{0, 0, 0, 0}, {1, 1, 20240315, 1, 0}},
+	}
+	if !reflect.DeepEqual(facets, wantFacets) {
+		t.Errorf("facets = %+v, want %+v", facets, wantFacets)
+	}
+}
+
+func TestFilesSharded(t *testing.T) {
+	index := &Index{}
+	for i := 0; i < 200; i++ {
+		index.Pages = append(index.Pages, PageEntry{
+			Title:    fmt.Sprintf("Page %d", i),
+			URL:      fmt.Sprintf("/page-%d/", i),
+			Sections: []SectionEntry{{Text: strings.Repeat(fmt.Sprintf("word%d ", i), 20)}},
+		})
+	}
+	files, err := index.Files(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(files[ManifestFile], &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Terms) < 3 || len(manifest.Stores) < 3 {
+		t.Fatalf("1 KB shards should split the index, got %d term and %d doc shards", len(manifest.Terms), len(manifest.Stores))
+	}
+	if len(files) != 2+len(manifest.Terms)+len(manifest.Stores) {
+		t.Errorf("Files() = %d files, want one per shard, the manifest and facets", len(files))
+	}
+
+	// Term shards are in order and each holds the terms up to the next
+	var terms []string
+	for i, shard := range manifest.Terms {
+		var postings map[string][]int
+		if err := json.Unmarshal(files[shard.File], &postings); err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for term := range postings {
+			keys = append(keys, term)
+		}
+		sort.Strings(keys)
+		if keys[0] != shard.From {
+			t.Errorf("shard %d starts at %q, manifest says %q", i, keys[0], shard.From)
+		}
+		terms = append(terms, keys...)
+	}
+	// "page", the page numbers and the words
+	if !sort.StringsAreSorted(terms) || len(terms) != 401 {
+		t.Errorf("term shards hold %d terms, want 401 in order", len(terms))
+	}
+
+	// Doc shards cover every document in order
+	count := 0
+	for _, store := range manifest.Stores {
+		if store.From != count {
+			t.Errorf("store starts at %d, want %d", store.From, count)
+		}
+		var docs [][]string
+		if err := json.Unmarshal(files[store.File], &docs); err != nil {
+			t.Fatal(err)
+		}
+		count += len(docs)
+	}
+	if count != 200 {
+		t.Errorf("doc shards hold %d documents, want 200", count)
+	}
+
+	again, _ := index.Files(1)
+	if !reflect.DeepEqual(files, again) {
+		t.Error("Files() should give the same files for the same index")
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		text, lang, want string
+	}{
+		// Without stemming rules, words are only folded
+		{"Héllo, WORLD! v2.0 /guides/get-started/", "", "hello world v2 0 guides get started"},
+		{"Ærø straße, ΟΔΟΣ, cafe\u0301", "xx", "aero strasse οδοσ cafe"},
+		// Stop words are left out and words stemmed
+		{"The deployments of a deployed service", "en", "deploy deploy servic"},
+		{"Configuring libraries, running tests quickly", "en-GB", "configur library run test quick"},
+		{"Les configurations des serveurs installées", "fr", "configur serv install"},
+		{"Die Einstellungen der Server", "de", "einstell serv"},
+		{"La configuración de los clientes", "es", "configur client"},
+		{"As configurações dos clientes", "pt_BR", "configur client"},
+		{"Le configurazioni dei server", "it", "configur server"},
+		// Chinese, Japanese and Korean text becomes pairs of characters
+		{"日本語の設定 API設定", "ja", "日本 本語 語の の設 設定 api 設定"},
+		{"搜索 字", "zh", "搜索 字"},
+		{"データ", "ja", "デー ータ"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(analyze(tt.text, tt.lang), " "); got != tt.want {
+			t.Errorf("analyze(%q, %q) = %q, want %q", tt.text, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeJS(t *testing.T) {
+	js := analyzeJS()
+	for _, want := range []string{
+		`"en":{"steps":[[["sses","ss",0]`,
+		`"\u00e9":"e"`, // Non-ASCII letters are escaped
+		"function analyze(s, lang)",
+	} {
+		if !strings.Contains(js, want) {
+			t.Errorf("analyzeJS() should contain %s", want)
+		}
+	}
+	for _, r := range js {
+		if r > 127 {
+			t.Fatalf("analyzeJS() has a non-ASCII character %q", r)
+		}
+	}
+}
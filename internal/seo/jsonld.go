@@ -0,0 +1,198 @@
+package seo
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// Schema.org types a page can be described as
+const (
+	SchemaTechArticle = "TechArticle"    // Documentation (the default)
+	SchemaArticle     = "Article"        // Dated pages outside a listing
+	SchemaBlogPosting = "BlogPosting"    // Dated pages in a listing
+	SchemaWebPage     = "WebPage"        // The home page
+	SchemaCollection  = "CollectionPage" // Generated folder, listing and archive pages
+	SchemaNone        = "none"           // No structured data
+)
+
+// searchTerm is the placeholder SearchAction targets put the query in
+const searchTerm = "{search_term_string}"
+
+// ValidateSchema checks a `schema` setting from front matter or a folder
+func ValidateSchema(schema string) error {
+	switch schema {
+	case "", SchemaTechArticle, SchemaArticle, SchemaBlogPosting, SchemaWebPage, SchemaCollection, SchemaNone:
+		return nil
+	}
+	return fmt.Errorf("unknown schema %q (use %s, %s, %s, %s, %s or %s)", schema,
+		SchemaTechArticle, SchemaArticle, SchemaBlogPosting, SchemaWebPage, SchemaCollection, SchemaNone)
+}
+
+// Breadcrumb is one step of a page's BreadcrumbList
+type Breadcrumb struct {
+	Name string
+	URL  string // Absolute URL
+}
+
+// StructuredData describes a page as schema.org JSON-LD
+type StructuredData struct {
+	Type          string // Schema type of the page
+	SiteName      string
+	SiteURL       string // Absolute URL of the home page
+	SearchURL     string // Absolute URL of a search, with {search_term_string} for the query ("" without search)
+	Home          bool   // The page is the home page, which also describes the WebSite
+	Title         string
+	Description   string
+	URL           string // Absolute URL of the page
+	Image         string
+	Author        string // Author's name ("" for the site)
+	Language      string
+	DatePublished time.Time
+	DateModified  time.Time
+	Breadcrumbs   []Breadcrumb
+}
+
+// Validate checks that the page, its breadcrumbs and the site have the
+// properties schema.org types need to be understood by search engines
+func (d StructuredData) Validate() error {
+	if err := ValidateSchema(d.Type); err != nil {
+		return err
+	}
+	if d.Type == "" || d.Type == SchemaNone {
+		return nil
+	}
+	if d.Title == "" {
+		return fmt.Errorf("%s needs a title", d.Type)
+	}
+	if !isAbsoluteURL(d.URL) {
+		return fmt.Errorf("%s needs an absolute URL, got %q (set --url)", d.Type, d.URL)
+	}
+	if d.Type == SchemaArticle || d.Type == SchemaBlogPosting {
+		if d.DatePublished.IsZero() {
+			return fmt.Errorf("%s needs a date (from a date prefix or `date` front matter)", d.Type)
+		}
+		if d.Author == "" && d.SiteName == "" {
+			return fmt.Errorf("%s needs an author (set `author` front matter, --author or --title)", d.Type)
+		}
+	}
+	if d.Home {
+		if d.SiteName == "" || !isAbsoluteURL(d.SiteURL) {
+			return fmt.Errorf("WebSite needs a name and an absolute URL")
+		}
+		if d.SearchURL != "" && (!isAbsoluteURL(d.SearchURL) || !strings.Contains(d.SearchURL, searchTerm)) {
+			return fmt.Errorf("SearchAction target %q needs an absolute URL with %s", d.SearchURL, searchTerm)
+		}
+	}
+	for i, crumb := range d.Breadcrumbs {
+		if crumb.Name == "" || !isAbsoluteURL(crumb.URL) {
+			return fmt.Errorf("breadcrumb %d needs a name and an absolute URL", i+1)
+		}
+	}
+	return nil
+}
+
+// isAbsoluteURL reports whether u is an http or https URL
+func isAbsoluteURL(u string) bool {
+	return strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "http://")
+}
+
+// Graph returns the page's JSON-LD: the page itself, its BreadcrumbList
+// and, on the home page, the WebSite with its SearchAction
+func (d StructuredData) Graph() map[string]any {
+	if d.Type == "" || d.Type == SchemaNone {
+		return nil
+	}
+	var nodes []map[string]any
+
+	if d.Home {
+		site := map[string]any{
+			"@type": "WebSite",
+			"@id":   d.SiteURL + "#website",
+			"name":  d.SiteName,
+			"url":   d.SiteURL,
+		}
+		if d.Language != "" {
+			site["inLanguage"] = d.Language
+		}
+		if d.SearchURL != "" {
+			site["potentialAction"] = map[string]any{
+				"@type":       "SearchAction",
+				"target":      map[string]any{"@type": "EntryPoint", "urlTemplate": d.SearchURL},
+				"query-input": "required name=search_term_string",
+			}
+		}
+		nodes = append(nodes, site)
+	}
+
+	page := map[string]any{
+		"@type":    d.Type,
+		"@id":      d.URL,
+		"url":      d.URL,
+		"name":     d.Title,
+		"isPartOf": map[string]any{"@type": "WebSite", "name": d.SiteName, "url": d.SiteURL},
+	}
+	switch d.Type {
+	case SchemaTechArticle, SchemaArticle, SchemaBlogPosting:
+		page["headline"] = d.Title
+		page["mainEntityOfPage"] = d.URL
+		publisher := map[string]any{"@type": "Organization", "name": d.SiteName, "url": d.SiteURL}
+		if d.Author != "" {
+			page["author"] = map[string]any{"@type": "Person", "name": d.Author}
+		} else if d.SiteName != "" {
+			page["author"] = publisher
+		}
+		if d.SiteName != "" {
+			page["publisher"] = publisher
+		}
+	}
+	if d.Description != "" {
+		page["description"] = d.Description
+	}
+	if d.Image != "" {
+		page["image"] = d.Image
+	}
+	if d.Language != "" {
+		page["inLanguage"] = d.Language
+	}
+	if !d.DatePublished.IsZero() {
+		page["datePublished"] = d.DatePublished.Format("2006-01-02")
+		modified := d.DateModified
+		if modified.IsZero() {
+			modified = d.DatePublished
+		}
+		page["dateModified"] = modified.Format("2006-01-02")
+	}
+	nodes = append(nodes, page)
+
+	if len(d.Breadcrumbs) > 1 {
+		items := make([]map[string]any, len(d.Breadcrumbs))
+		for i, crumb := range d.Breadcrumbs {
+			items[i] = map[string]any{
+				"@type":    "ListItem",
+				"position": i + 1,
+				"name":     crumb.Name,
+				"item":     crumb.URL,
+			}
+		}
+		nodes = append(nodes, map[string]any{"@type": "BreadcrumbList", "itemListElement": items})
+	}
+
+	return map[string]any{"@context": "https://schema.org", "@graph": nodes}
+}
+
+// RenderJSONLD renders the page's JSON-LD script tag ("" without a type)
+func RenderJSONLD(d StructuredData) template.HTML {
+	graph := d.Graph()
+	if graph == nil {
+		return ""
+	}
+	// Marshal escapes <, > and &, so the JSON can't close the script
+	data, err := json.Marshal(graph)
+	if err != nil {
+		return ""
+	}
+	return template.HTML(`  <script type="application/ld+json">` + string(data) + "</script>\n")
+}
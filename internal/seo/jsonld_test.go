@@ -0,0 +1,174 @@
+package seo
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testStructuredData() StructuredData {
+	return StructuredData{
+		Type:          SchemaBlogPosting,
+		SiteName:      "My Site",
+		SiteURL:       "https://example.com/",
+		Title:         "Launch",
+		Description:   "We launched.",
+		URL:           "https://example.com/blog/launch/",
+		Image:         "https://example.com/og/abc.png",
+		Author:        "Ada",
+		Language:      "en",
+		DatePublished: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		Breadcrumbs: []Breadcrumb{
+			{Name: "My Site", URL: "https://example.com/"},
+			{Name: "Blog", URL: "https://example.com/blog/"},
+			{Name: "Launch", URL: "https://example.com/blog/launch/"},
+		},
+	}
+}
+
+func TestStructuredDataGraph(t *testing.T) {
+	d := testStructuredData()
+	data, err := json.Marshal(d.Graph())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var graph struct {
+		Context string           `json:"@context"`
+		Graph   []map[string]any `json:"@graph"`
+	}
+	if err := json.Unmarshal(data, &graph); err != nil {
+		t.Fatal(err)
+	}
+	if graph.Context != "https://schema.org" || len(graph.Graph) != 2 {
+		t.Fatalf("Graph() = %s, want the page and its breadcrumbs", data)
+	}
+
+	page := graph.Graph[0]
+	for key, want := range map[string]any{
+		"@type":         "BlogPosting",
+		"headline":      "Launch",
+		"url":           "https://example.com/blog/launch/",
+		"image":         "https://example.com/og/abc.png",
+		"inLanguage":    "en",
+		"datePublished": "2024-03-15",
+		"dateModified":  "2024-03-15", // Published, without an update
+	} {
+		if page[key] != want {
+			t.Errorf("page %s = %v, want %v", key, page[key], want)
+		}
+	}
+	if author, _ := page["author"].(map[string]any); author["@type"] != "Person" || author["name"] != "Ada" {
+		t.Errorf("page author = %v, want Person Ada", page["author"])
+	}
+
+	crumbs := graph.Graph[1]
+	items, _ := crumbs["itemListElement"].([]any)
+	if crumbs["@type"] != "BreadcrumbList" || len(items) != 3 {
+		t.Fatalf("breadcrumbs = %v, want a BreadcrumbList of 3", crumbs)
+	}
+	if last, _ := items[2].(map[string]any); last["position"] != float64(3) || last["name"] != "Launch" {
+		t.Errorf("last breadcrumb = %v", last)
+	}
+}
+
+func TestStructuredDataGraphHome(t *testing.T) {
+	d := StructuredData{
+		Type:      SchemaWebPage,
+		SiteName:  "My Site",
+		SiteURL:   "https://example.com/",
+		SearchURL: "https://example.com/?q={search_term_string}",
+		Home:      true,
+		Title:     "Home",
+		URL:       "https://example.com/",
+	}
+	graph := d.Graph()["@graph"].([]map[string]any)
+	if len(graph) != 2 || graph[0]["@type"] != "WebSite" || graph[1]["@type"] != "WebPage" {
+		t.Fatalf("Graph() = %v, want the WebSite and the home page", graph)
+	}
+	action, _ := graph[0]["potentialAction"].(map[string]any)
+	if action["@type"] != "SearchAction" || action["query-input"] != "required name=search_term_string" {
+		t.Errorf("WebSite potentialAction = %v, want a SearchAction", action)
+	}
+	if _, ok := graph[1]["headline"]; ok {
+		t.Error("a WebPage should have no headline")
+	}
+
+	// Without search, the WebSite has no SearchAction
+	d.SearchURL = ""
+	if _, ok := d.Graph()["@graph"].([]map[string]any)[0]["potentialAction"]; ok {
+		t.Error("WebSite without search should have no potentialAction")
+	}
+
+	// An article without an author is by the site
+	d = testStructuredData()
+	d.Author = ""
+	author := d.Graph()["@graph"].([]map[string]any)[0]["author"].(map[string]any)
+	if author["@type"] != "Organization" || author["name"] != "My Site" {
+		t.Errorf("author = %v, want the site", author)
+	}
+}
+
+func TestStructuredDataValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(d *StructuredData)
+		err    string
+	}{
+		{"valid", func(d *StructuredData) {}, ""},
+		{"no type", func(d *StructuredData) { d.Type, d.Title = "", "" }, ""},
+		{"none", func(d *StructuredData) { d.Type, d.URL = SchemaNone, "" }, ""},
+		{"unknown type", func(d *StructuredData) { d.Type = "Recipe" }, "unknown schema"},
+		{"no title", func(d *StructuredData) { d.Title = "" }, "needs a title"},
+		{"relative URL", func(d *StructuredData) { d.URL = "/blog/launch/" }, "absolute URL"},
+		{"undated post", func(d *StructuredData) { d.DatePublished = time.Time{} }, "needs a date"},
+		{"undated docs", func(d *StructuredData) { d.Type, d.DatePublished = SchemaTechArticle, time.Time{} }, ""},
+		{"no author", func(d *StructuredData) { d.Author, d.SiteName = "", "" }, "needs an author"},
+		{"site without URL", func(d *StructuredData) { d.Home, d.SiteURL = true, "" }, "WebSite needs"},
+		{"search without term", func(d *StructuredData) {
+			d.Home, d.SearchURL = true, "https://example.com/?q="
+		}, "SearchAction"},
+		{"relative breadcrumb", func(d *StructuredData) { d.Breadcrumbs[1].URL = "/blog/" }, "breadcrumb 2"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := testStructuredData()
+			tc.modify(&d)
+			err := d.Validate()
+			if tc.err == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("Validate() error = %v, want %q", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestRenderJSONLD(t *testing.T) {
+	d := testStructuredData()
+	d.Title = "</script><script>alert(1)</script>"
+	html := string(RenderJSONLD(d))
+	if !strings.HasPrefix(html, `  <script type="application/ld+json">{"@context":"https://schema.org"`) {
+		t.Errorf("RenderJSONLD() = %q", html)
+	}
+	if strings.Count(html, "</script>") != 1 {
+		t.Errorf("RenderJSONLD() should escape </script> in values: %q", html)
+	}
+
+	d.Type = SchemaNone
+	if html := RenderJSONLD(d); html != "" {
+		t.Errorf("RenderJSONLD() with no schema = %q, want empty", html)
+	}
+
+	// Meta tags include it
+	meta := GeneratePageMeta("Launch", "<p>We launched.</p>", "/blog/launch/", Config{SiteURL: "https://example.com"})
+	d = testStructuredData()
+	meta.StructuredData = &d
+	if !strings.Contains(string(RenderMetaTags(meta)), `<script type="application/ld+json">`) {
+		t.Error("RenderMetaTags() should include the structured data")
+	}
+}
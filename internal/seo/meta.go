@@ -2,6 +2,7 @@
 package seo
 
 import (
+	"fmt"
 	"html/template"
 	"regexp"
 	"strings"
@@ -24,6 +25,8 @@ type OpenGraph struct {
 	URL         string // og:url
 	SiteName    string // og:site_name
 	Image       string // og:image
+	ImageWidth  int    // og:image:width (0 if unknown)
+	ImageHeight int    // og:image:height (0 if unknown)
 }
 
 // TwitterCard contains Twitter Card meta tag data
@@ -36,9 +39,10 @@ type TwitterCard struct {
 
 // PageMeta combines all meta information for a page
 type PageMeta struct {
-	SEO     Meta
-	OG      OpenGraph
-	Twitter TwitterCard
+	SEO            Meta
+	OG             OpenGraph
+	Twitter        TwitterCard
+	StructuredData *StructuredData // schema.org JSON-LD (nil for none)
 }
 
 // Config holds site-wide SEO configuration
@@ -48,7 +52,11 @@ type Config struct {
 	DefaultDesc   string
 	Author        string
 	OGImage       string
+	OGImageWidth  int // Size of OGImage, when known (generated images)
+	OGImageHeight int
 	TwitterHandle string
+	Robots        string // Robots directive of the page ("" for DefaultRobots)
+	Canonical     string // Canonical URL of the page, when not its own URL
 }
 
 var htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
@@ -66,17 +74,22 @@ func GeneratePageMeta(pageTitle, pageContent, urlPath string, config Config) Pag
 		fullTitle = pageTitle + " - " + config.SiteTitle
 	}
 
-	canonical := ""
-	if config.SiteURL != "" {
+	canonical := config.Canonical
+	if canonical == "" && config.SiteURL != "" {
 		canonical = strings.TrimSuffix(config.SiteURL, "/") + urlPath
 	}
 
+	robots := config.Robots
+	if robots == "" {
+		robots = DefaultRobots
+	}
+
 	return PageMeta{
 		SEO: Meta{
 			Title:       fullTitle,
 			Description: description,
 			Canonical:   canonical,
-			Robots:      "index, follow",
+			Robots:      robots,
 			Author:      config.Author,
 		},
 		OG: OpenGraph{
@@ -86,6 +99,8 @@ func GeneratePageMeta(pageTitle, pageContent, urlPath string, config Config) Pag
 			URL:         canonical,
 			SiteName:    config.SiteTitle,
 			Image:       config.OGImage,
+			ImageWidth:  config.OGImageWidth,
+			ImageHeight: config.OGImageHeight,
 		},
 		Twitter: TwitterCard{
 			Card:        getTwitterCardType(config.OGImage),
@@ -207,6 +222,13 @@ func RenderMetaTags(meta PageMeta) template.HTML {
 		sb.WriteString(template.HTMLEscapeString(meta.OG.Image))
 		sb.WriteString(`">`)
 		sb.WriteString("\n")
+
+		if meta.OG.ImageWidth > 0 && meta.OG.ImageHeight > 0 {
+			sb.WriteString(fmt.Sprintf(`  <meta property="og:image:width" content="%d">`, meta.OG.ImageWidth))
+			sb.WriteString("\n")
+			sb.WriteString(fmt.Sprintf(`  <meta property="og:image:height" content="%d">`, meta.OG.ImageHeight))
+			sb.WriteString("\n")
+		}
 	}
 
 	// Twitter Card tags
@@ -238,6 +260,16 @@ func RenderMetaTags(meta PageMeta) template.HTML {
 		sb.WriteString("\n")
 	}
 
+	// Structured data
+	if meta.StructuredData != nil {
+		if jsonLD := RenderJSONLD(*meta.StructuredData); jsonLD != "" {
+			sb.WriteString("\n")
+			sb.WriteString(`  <!-- Structured Data -->`)
+			sb.WriteString("\n")
+			sb.WriteString(string(jsonLD))
+		}
+	}
+
 	// Browser theme color tags (single tag, updated via JS to sync with theme toggle)
 	sb.WriteString("\n")
 	sb.WriteString(`  <!-- Browser Theme Colors -->`)
@@ -57,6 +57,25 @@ func TestGeneratePageMetaNoImage(t *testing.T) {
 	}
 }
 
+func TestGeneratePageMetaIndexing(t *testing.T) {
+	meta := GeneratePageMeta("Test", "<p>Test</p>", "/posts/test/", Config{SiteURL: "https://example.com"})
+	if meta.SEO.Robots != DefaultRobots || meta.SEO.Canonical != "https://example.com/posts/test/" {
+		t.Errorf("default SEO = %+v", meta.SEO)
+	}
+
+	meta = GeneratePageMeta("Test", "<p>Test</p>", "/posts/test/", Config{
+		SiteURL:   "https://example.com",
+		Robots:    "noindex, nofollow",
+		Canonical: "https://blog.example.org/test",
+	})
+	if meta.SEO.Robots != "noindex, nofollow" {
+		t.Errorf("Robots = %q, want the override", meta.SEO.Robots)
+	}
+	if meta.SEO.Canonical != "https://blog.example.org/test" || meta.OG.URL != meta.SEO.Canonical {
+		t.Errorf("Canonical = %q, og:url = %q, want the override", meta.SEO.Canonical, meta.OG.URL)
+	}
+}
+
 func TestExtractDescription(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -145,3 +164,29 @@ func TestRenderMetaTags(t *testing.T) {
 		t.Error("should contain twitter:card")
 	}
 }
+
+func TestRenderMetaTagsImageSize(t *testing.T) {
+	config := Config{
+		SiteURL:       "https://example.com",
+		OGImage:       "https://example.com/og/abc.png",
+		OGImageWidth:  1200,
+		OGImageHeight: 630,
+	}
+	html := string(RenderMetaTags(GeneratePageMeta("Test", "<p>Test</p>", "/", config)))
+	for _, want := range []string{
+		`<meta property="og:image" content="https://example.com/og/abc.png">`,
+		`<meta property="og:image:width" content="1200">`,
+		`<meta property="og:image:height" content="630">`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("should contain %s", want)
+		}
+	}
+
+	// Images of unknown size have no size tags
+	config.OGImageWidth, config.OGImageHeight = 0, 0
+	html = string(RenderMetaTags(GeneratePageMeta("Test", "<p>Test</p>", "/", config)))
+	if strings.Contains(html, "og:image:width") {
+		t.Error("should not contain og:image:width without a size")
+	}
+}
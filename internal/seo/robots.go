@@ -0,0 +1,121 @@
+package seo
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// DefaultRobots is the robots directive of pages that don't set one
+const DefaultRobots = "index, follow"
+
+// robotsTokens are the robots meta directives without a value
+var robotsTokens = map[string]bool{
+	"all": true, "none": true,
+	"index": true, "noindex": true,
+	"follow": true, "nofollow": true,
+	"noarchive": true, "nosnippet": true, "noimageindex": true, "notranslate": true,
+}
+
+// ValidateRobots checks a `robots` setting from front matter or a folder:
+// comma-separated directives such as "noindex, nofollow". Directives with a
+// value, such as "max-snippet:50", are passed through as written.
+func ValidateRobots(directive string) error {
+	for _, token := range strings.Split(directive, ",") {
+		token = strings.ToLower(strings.TrimSpace(token))
+		if token == "" || strings.Contains(token, ":") || robotsTokens[token] {
+			continue
+		}
+		return fmt.Errorf("unknown robots directive %q (use index, noindex, follow, nofollow, none, noarchive, nosnippet, noimageindex or notranslate)", token)
+	}
+	return nil
+}
+
+// PageRobots returns a page's robots directive: its `robots` front matter,
+// else its folder's setting ("" for DefaultRobots)
+func PageRobots(fm tree.FrontMatter, folder config.FolderConfig) (string, error) {
+	robots := strings.TrimSpace(fm.String("robots"))
+	if robots == "" {
+		robots = folder.Robots
+	}
+	if err := ValidateRobots(robots); err != nil {
+		return "", err
+	}
+	return robots, nil
+}
+
+// PageCanonical returns a page's canonical URL: its `canonical` front
+// matter, resolved against siteURL when it's a path, else its folder's
+// canonical site URL, else its own URL ("" without siteURL)
+func PageCanonical(siteURL, urlPath string, fm tree.FrontMatter, folder config.FolderConfig) (string, error) {
+	siteURL = strings.TrimSuffix(siteURL, "/")
+	if canonical := strings.TrimSpace(fm.String("canonical")); canonical != "" {
+		if !strings.Contains(canonical, "://") {
+			canonical = siteURL + "/" + strings.TrimPrefix(canonical, "/")
+		}
+		return canonical, nil
+	}
+	if folder.Canonical != "" {
+		if !strings.Contains(folder.Canonical, "://") {
+			return "", fmt.Errorf("folder canonical %q needs a full URL", folder.Canonical)
+		}
+		return strings.TrimSuffix(folder.Canonical, "/") + urlPath, nil
+	}
+	if siteURL == "" {
+		return "", nil
+	}
+	return siteURL + urlPath, nil
+}
+
+// Indexable reports whether a robots directive lets search engines index
+// the page, which it doesn't with noindex or none
+func Indexable(directive string) bool {
+	for _, token := range strings.Split(directive, ",") {
+		switch strings.ToLower(strings.TrimSpace(token)) {
+		case "noindex", "none":
+			return false
+		}
+	}
+	return true
+}
+
+// RenderIndexingTags renders only the robots and canonical tags of a page,
+// for pages without the rest of its meta tags
+func RenderIndexingTags(robots, canonical string) template.HTML {
+	if robots == "" {
+		robots = DefaultRobots
+	}
+	var sb strings.Builder
+	sb.WriteString(`  <meta name="robots" content="` + template.HTMLEscapeString(robots) + `">` + "\n")
+	if canonical != "" {
+		sb.WriteString(`  <link rel="canonical" href="` + template.HTMLEscapeString(canonical) + `">` + "\n")
+	}
+	return template.HTML(sb.String())
+}
+
+// RenderRobotsTxt renders a robots.txt that lets every crawler in except
+// under the disallowed paths, unless under an allowed path within them, and
+// names the sitemaps (absolute URLs)
+func RenderRobotsTxt(disallow, allow, sitemaps []string) string {
+	var sb strings.Builder
+	sb.WriteString("User-agent: *\n")
+	if len(disallow) == 0 {
+		sb.WriteString("Allow: /\n")
+	}
+	for _, path := range disallow {
+		sb.WriteString("Disallow: " + path + "\n")
+	}
+	for _, path := range allow {
+		sb.WriteString("Allow: " + path + "\n")
+	}
+	for _, sitemap := range sitemaps {
+		sb.WriteString("\nSitemap: " + sitemap)
+	}
+	if len(sitemaps) > 0 {
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
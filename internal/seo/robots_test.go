@@ -0,0 +1,99 @@
+package seo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+func TestValidateRobots(t *testing.T) {
+	for _, directive := range []string{"", "noindex", "noindex, nofollow", "NoIndex,Follow", "index, follow, max-snippet:50", "none"} {
+		if err := ValidateRobots(directive); err != nil {
+			t.Errorf("ValidateRobots(%q) error = %v", directive, err)
+		}
+	}
+	for _, directive := range []string{"no-index", "noindex, private"} {
+		if err := ValidateRobots(directive); err == nil {
+			t.Errorf("ValidateRobots(%q) should fail", directive)
+		}
+	}
+}
+
+func TestIndexable(t *testing.T) {
+	tests := map[string]bool{
+		"":                  true,
+		DefaultRobots:       true,
+		"nofollow":          true,
+		"noindex, nofollow": false,
+		"NOINDEX":           false,
+		"none":              false,
+	}
+	for directive, want := range tests {
+		if got := Indexable(directive); got != want {
+			t.Errorf("Indexable(%q) = %v, want %v", directive, got, want)
+		}
+	}
+}
+
+func TestRenderRobotsTxt(t *testing.T) {
+	if got, want := RenderRobotsTxt(nil, nil, nil), "User-agent: *\nAllow: /\n"; got != want {
+		t.Errorf("RenderRobotsTxt() = %q, want %q", got, want)
+	}
+
+	got := RenderRobotsTxt([]string{"/internal/", "/docs/drafts/"}, []string{"/internal/public/"}, []string{"https://example.com/sitemap.xml"})
+	want := "User-agent: *\nDisallow: /internal/\nDisallow: /docs/drafts/\nAllow: /internal/public/\n\nSitemap: https://example.com/sitemap.xml\n"
+	if got != want {
+		t.Errorf("RenderRobotsTxt() = %q, want %q", got, want)
+	}
+}
+
+func TestPageRobots(t *testing.T) {
+	folder := config.FolderConfig{Robots: "noindex"}
+	if got, _ := PageRobots(nil, folder); got != "noindex" {
+		t.Errorf("PageRobots() = %q, want the folder's noindex", got)
+	}
+	if got, _ := PageRobots(tree.FrontMatter{"robots": "index, nofollow"}, folder); got != "index, nofollow" {
+		t.Errorf("PageRobots() = %q, want the front matter's directive", got)
+	}
+	if _, err := PageRobots(tree.FrontMatter{"robots": "no-index"}, config.FolderConfig{}); err == nil {
+		t.Error("PageRobots() should reject unknown directives")
+	}
+}
+
+func TestPageCanonical(t *testing.T) {
+	tests := []struct {
+		name    string
+		siteURL string
+		fm      tree.FrontMatter
+		folder  config.FolderConfig
+		want    string
+	}{
+		{"own URL", "https://example.com/", nil, config.FolderConfig{}, "https://example.com/guide/"},
+		{"no site URL", "", nil, config.FolderConfig{}, ""},
+		{"front matter path", "https://example.com", tree.FrontMatter{"canonical": "other/"}, config.FolderConfig{}, "https://example.com/other/"},
+		{"front matter URL", "", tree.FrontMatter{"canonical": "https://blog.example.com/post/"}, config.FolderConfig{}, "https://blog.example.com/post/"},
+		{"folder", "https://example.com", nil, config.FolderConfig{Canonical: "https://origin.example.com/"}, "https://origin.example.com/guide/"},
+	}
+	for _, tt := range tests {
+		got, err := PageCanonical(tt.siteURL, "/guide/", tt.fm, tt.folder)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: PageCanonical() = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+	if _, err := PageCanonical("", "/guide/", nil, config.FolderConfig{Canonical: "origin.example.com"}); err == nil {
+		t.Error("PageCanonical() should reject a folder canonical without a scheme")
+	}
+}
+
+func TestRenderIndexingTags(t *testing.T) {
+	got := string(RenderIndexingTags("", ""))
+	if !strings.Contains(got, `<meta name="robots" content="index, follow">`) || strings.Contains(got, "canonical") {
+		t.Errorf("RenderIndexingTags() = %q, want the default robots tag only", got)
+	}
+	got = string(RenderIndexingTags("noindex", "https://example.com/a/"))
+	if !strings.Contains(got, `content="noindex"`) || !strings.Contains(got, `<link rel="canonical" href="https://example.com/a/">`) {
+		t.Errorf("RenderIndexingTags() = %q", got)
+	}
+}
@@ -0,0 +1,42 @@
+package seo
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// SitemapFile is the name of the sitemap written to the output directory
+const SitemapFile = "sitemap.xml"
+
+// SitemapEntry is one page of a sitemap
+type SitemapEntry struct {
+	URL     string    // Absolute canonical URL
+	LastMod time.Time // Zero when unknown
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// RenderSitemap renders entries as a sitemaps.org XML sitemap
+func RenderSitemap(entries []SitemapEntry) ([]byte, error) {
+	set := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9", URLs: make([]sitemapURL, len(entries))}
+	for i, entry := range entries {
+		set.URLs[i].Loc = entry.URL
+		if !entry.LastMod.IsZero() {
+			set.URLs[i].LastMod = entry.LastMod.Format("2006-01-02")
+		}
+	}
+	data, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
@@ -0,0 +1,40 @@
+package seo
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderSitemap(t *testing.T) {
+	data, err := RenderSitemap([]SitemapEntry{
+		{URL: "https://example.com/"},
+		{URL: "https://example.com/blog/launch/?a=1&b=2", LastMod: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if !strings.HasPrefix(content, xml.Header) {
+		t.Error("sitemap should start with the XML declaration")
+	}
+	for _, want := range []string{
+		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`,
+		"<url>\n    <loc>https://example.com/</loc>\n  </url>",
+		"<loc>https://example.com/blog/launch/?a=1&amp;b=2</loc>",
+		"<lastmod>2024-03-15</lastmod>",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("sitemap should contain %q, got:\n%s", want, content)
+		}
+	}
+
+	var set sitemapURLSet
+	if err := xml.Unmarshal(data, &set); err != nil {
+		t.Fatalf("sitemap is not valid XML: %v", err)
+	}
+	if len(set.URLs) != 2 {
+		t.Errorf("got %d URLs, want 2", len(set.URLs))
+	}
+}
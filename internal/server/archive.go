@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/wusher/volcano/internal/archive"
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// archives builds the date archives of a site's folders that have archives
+// turned on
+func (s *DynamicServer) archives(site *tree.Site) []*archive.Archive {
+	folders := archive.Collect(site.Root, func(folder *tree.Node) bool {
+		return config.ResolveFolderConfig(s.config.Folders, filepath.Join(folder.Path, "index.md")).Archive
+	})
+	archives := make([]*archive.Archive, 0, len(folders))
+	for _, folder := range folders {
+		archives = append(archives, archive.Build(folder, site.AllPages, ""))
+	}
+	return archives
+}
+
+// archiveDate returns a page's date and the link to its month archive, or
+// empty strings if the page isn't in an archive
+func (s *DynamicServer) archiveDate(site *tree.Site, node *tree.Node) (string, string) {
+	for _, a := range s.archives(site) {
+		if url := a.MonthURL(node); url != "" {
+			return tree.NodeDate(node).Format("2006-01-02"), url
+		}
+	}
+	return "", ""
+}
+
+// tryArchive renders a date archive page (/archive/, /2024/, /2024/03/).
+// Real pages and folders at the same URL are served first.
+func (s *DynamicServer) tryArchive(w http.ResponseWriter, urlPath string) bool {
+	if !strings.HasSuffix(urlPath, "/") {
+		return false
+	}
+
+	lang := s.languageOf(urlPath)
+	site, err := s.scan(lang)
+	if err != nil {
+		return false
+	}
+	messages := s.messages(lang)
+	for _, a := range s.archives(site) {
+		if page, ok := a.Find(urlPath, messages); ok {
+			return s.renderFolderPage(w, urlPath, a.Folder, site, lang, page.Title, page.Content)
+		}
+	}
+	return false
+}
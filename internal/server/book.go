@@ -0,0 +1,120 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/wusher/volcano/internal/book"
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/templates"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// servePrintBook renders print.html or <folder>/print/ from the current sources
+func (s *DynamicServer) servePrintBook(w http.ResponseWriter, urlPath string) bool {
+	if !s.config.Print {
+		return false
+	}
+	if !strings.HasSuffix(urlPath, book.SiteURLPath) && !strings.HasSuffix(urlPath, "/"+book.FolderSlug+"/") {
+		return false
+	}
+
+	lang := s.languageOf(urlPath)
+	site, err := s.scan(lang)
+	if err != nil {
+		return false
+	}
+
+	folder := site.Root
+	if urlPath != book.URLPath(site.Root) {
+		folderPath := strings.TrimSuffix(urlPath, book.FolderSlug+"/")
+		if folderPath == "/" || folderPath == tree.GetURLPath(site.Root) {
+			return false
+		}
+		folder = findFolderByPath(site.Root, folderPath)
+		if folder == nil {
+			return false
+		}
+	}
+
+	b, err := book.Build(folder, site.AllPages, "", s.messages(lang), s.renderNodeContent)
+	if err != nil {
+		s.serveTemplateError(w, err)
+		return true
+	}
+	if b.Chapters == 0 {
+		return false
+	}
+	title := b.Title
+	if title == "" {
+		title = s.config.Title
+	}
+
+	data := templates.PageData{
+		SiteTitle:    s.config.Title,
+		PageTitle:    title,
+		Content:      b.Content,
+		TOC:          b.TOC,
+		CurrentPath:  urlPath,
+		FaviconLinks: s.faviconLinks,
+		FontPreloads: s.fonts.RenderPreloadLinks(),
+		BaseURL:      "", // Empty for dev server (no base URL prefix)
+	}
+	data.Lang = s.code(lang)
+	data.Dir = i18n.Dir(data.Lang)
+	data.I18n = s.messages(lang)
+	templates.ResolveLayout(nil, templates.LayoutPrint).Apply(&data)
+
+	renderer, err := s.getRenderer()
+	if err != nil {
+		s.serveTemplateError(w, err)
+		return true
+	}
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, data); err != nil {
+		s.serveTemplateError(w, err)
+		return true
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(buf.Bytes())
+	return true
+}
+
+// renderNodeContent renders a page's markdown for inclusion in a book
+func (s *DynamicServer) renderNodeContent(node *tree.Node) (string, string, error) {
+	title, content, _, err := s.renderNodeEntry(node)
+	return title, content, err
+}
+
+// renderNodeEntry renders a page's markdown, returning its front matter too
+func (s *DynamicServer) renderNodeEntry(node *tree.Node) (string, string, tree.FrontMatter, error) {
+	fullMdPath := s.nodeFile(node)
+	mdContent, err := s.fs.ReadFile(fullMdPath)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	// Compute source directory for wikilink resolution
+	relDir := filepath.Dir(node.Path)
+	sourceDir := "/"
+	if relDir != "." && relDir != "" {
+		sourceDir = "/" + tree.SlugifyPath(relDir) + "/"
+	}
+
+	page, err := s.transformer.TransformMarkdown(
+		mdContent,
+		sourceDir,
+		fullMdPath,
+		tree.GetOutputPath(node),
+		tree.GetURLPath(node),
+		node.Name,
+	)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return page.Title, page.Content, page.FrontMatter, nil
+}
@@ -4,7 +4,6 @@ package server
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"html/template"
 	"io"
@@ -12,18 +11,25 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/wusher/volcano/internal/assets"
 	"github.com/wusher/volcano/internal/autoindex"
+	"github.com/wusher/volcano/internal/config"
 	"github.com/wusher/volcano/internal/content"
+	"github.com/wusher/volcano/internal/graph"
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/includes"
 	"github.com/wusher/volcano/internal/instant"
 	"github.com/wusher/volcano/internal/markdown"
+	"github.com/wusher/volcano/internal/menu"
 	"github.com/wusher/volcano/internal/navigation"
 	"github.com/wusher/volcano/internal/pwa"
 	"github.com/wusher/volcano/internal/search"
+	"github.com/wusher/volcano/internal/seo"
 	"github.com/wusher/volcano/internal/styles"
 	"github.com/wusher/volcano/internal/templates"
 	"github.com/wusher/volcano/internal/toc"
@@ -39,16 +45,33 @@ type DynamicConfig struct {
 	Verbose         bool
 	TopNav          bool
 	ShowPageNav     bool
+	PageNavSections bool // Keep prev/next links within top-level folders
+	Related         int  // Number of related pages shown below each page (0: none)
+	Graph           bool // Serve graph.json and the /graph/ page, and show a local graph below each page
 	ShowBreadcrumbs bool // Show breadcrumb navigation
 	Theme           string
 	CSSPath         string
+	LayoutsDir      string // Directory with template overrides (default: <source>/_layouts)
 	AccentColor     string // Custom accent color in hex format (e.g., "#ff6600")
+	AccentColorDark string // Dark-mode accent color (derived from AccentColor if empty)
 	FaviconPath     string // Path to favicon file
 	InstantNav      bool   // Enable instant navigation with hover prefetching
 	ViewTransitions bool   // Enable browser view transitions API
 	PWA             bool   // Enable PWA manifest and service worker
 	Search          bool   // Enable search index and command palette
+	SearchShardSize int    // Target size of each search index shard in KB (0: search.DefaultShardSize)
+	Print           bool   // Serve printable books (print.html and <folder>/print/)
 	NoVerify        bool   // Skip internal-link validation (no console warnings, no inline banner)
+
+	Folders map[string]config.FolderConfig // Per-folder settings, keyed by folder path
+	Fonts   *config.FontsConfig            // Self-hosted fonts for body, headings and code
+	Menus   map[string][]config.MenuItem   // Navigation menus; "main" replaces the automatic top nav
+
+	Includes includes.Paths // Header, footer and banner overrides (defaults: _header.md etc.)
+
+	Language     string                       // Default language code (default: en)
+	Languages    []string                     // Translation languages, served from page.<lang>.md files at /<lang>/
+	Translations map[string]map[string]string // Interface text overrides, keyed by language then message
 }
 
 // DynamicServer serves markdown files with live rendering
@@ -74,14 +97,24 @@ type DynamicServer struct {
 	pwaIcon512      []byte        // PWA 512x512 icon (generated from favicon)
 	pwaHasIcons     bool          // Whether PWA icons were generated
 	searchEnabled   bool          // Whether search is enabled
+	fonts           *assets.Fonts // Self-hosted fonts (nil if none configured)
+	defaultLang     string        // Default language code
+	languages       []string      // Translation language codes
 }
 
 // NewDynamicServer creates a new dynamic server
 func NewDynamicServer(config DynamicConfig, writer io.Writer) (*DynamicServer, error) {
+	fonts, err := assets.LoadFonts(config.Fonts, config.SourceDir, "")
+	if err != nil {
+		return nil, err
+	}
+
 	cssConfig := styles.CSSConfig{
-		Theme:       config.Theme,
-		CSSPath:     config.CSSPath,
-		AccentColor: config.AccentColor,
+		Theme:           config.Theme,
+		CSSPath:         config.CSSPath,
+		AccentColor:     config.AccentColor,
+		AccentColorDark: config.AccentColorDark,
+		FontCSS:         fonts.StyleSheet(),
 	}
 	cssLoader := styles.NewCSSLoader(cssConfig, os.ReadFile)
 	css, err := cssLoader.LoadCSS()
@@ -89,22 +122,42 @@ func NewDynamicServer(config DynamicConfig, writer io.Writer) (*DynamicServer, e
 		return nil, fmt.Errorf("failed to load CSS: %w", err)
 	}
 
-	renderer, err := templates.NewRenderer(css)
+	if config.LayoutsDir == "" {
+		config.LayoutsDir = templates.DiscoverLayoutsDir(config.SourceDir)
+	}
+
+	renderer, err := templates.NewRendererWithLayouts(css, config.LayoutsDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create renderer: %w", err)
 	}
 
+	defaultLang := i18n.Normalize(config.Language)
+	var languages []string
+	for _, lang := range config.Languages {
+		if lang = i18n.Normalize(lang); lang != defaultLang {
+			languages = append(languages, lang)
+		}
+	}
+
 	srv := &DynamicServer{
 		config:          config,
 		renderer:        renderer,
 		transformer:     markdown.NewContentTransformer(""), // Dynamic server doesn't use site URL for external links
 		writer:          writer,
 		fs:              osFileSystem{},
-		scanner:         defaultScanner{},
+		scanner:         defaultScanner{includes: config.Includes, languages: languages},
 		cssLoader:       cssLoader,
 		viewTransitions: config.ViewTransitions,
 		pwaEnabled:      config.PWA,
 		searchEnabled:   config.Search,
+		fonts:           fonts,
+		defaultLang:     defaultLang,
+		languages:       languages,
+	}
+
+	// Fail fast on include paths that don't exist; includes are re-read per request
+	if _, err := includes.Load(config.SourceDir, config.Includes, srv.transformer); err != nil {
+		return nil, err
 	}
 
 	// Initialize instant navigation JS if enabled
@@ -227,7 +280,18 @@ func (s *DynamicServer) serveFavicon(w http.ResponseWriter, urlPath string) bool
 	return true
 }
 
-// getRenderer returns a renderer, re-reading CSS on each request for live reload
+// serveFont serves a self-hosted font file from memory
+func (s *DynamicServer) serveFont(w http.ResponseWriter, urlPath string) bool {
+	font, ok := s.fonts.Find(urlPath)
+	if !ok {
+		return false
+	}
+	w.Header().Set("Content-Type", font.MimeType)
+	_, _ = w.Write(font.Data)
+	return true
+}
+
+// getRenderer returns a renderer, re-reading CSS and layouts on each request for live reload
 func (s *DynamicServer) getRenderer() (*templates.Renderer, error) {
 	// Always reload CSS for live reload (works for both custom CSS and theme files in development)
 	css, err := s.cssLoader.LoadCSS()
@@ -236,7 +300,7 @@ func (s *DynamicServer) getRenderer() (*templates.Renderer, error) {
 		s.logError("Failed to load CSS, using cached: %v", err)
 		return s.renderer, nil
 	}
-	return templates.NewRenderer(css)
+	return templates.NewRendererWithLayouts(css, s.config.LayoutsDir)
 }
 
 // WithFileSystem sets a custom FileSystem (for testing)
@@ -321,6 +385,12 @@ func (s *DynamicServer) handleRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Serve the link graph if enabled
+	if s.serveGraph(rec, urlPath) {
+		s.logRequest(r.Method, urlPath, rec.statusCode, time.Since(start))
+		return
+	}
+
 	// Serve PWA files (manifest.json, sw.js, icons) if PWA is enabled
 	if s.servePWA(rec, urlPath) {
 		s.logRequest(r.Method, urlPath, rec.statusCode, time.Since(start))
@@ -333,6 +403,12 @@ func (s *DynamicServer) handleRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Serve self-hosted fonts from memory
+	if s.serveFont(rec, urlPath) {
+		s.logRequest(r.Method, urlPath, rec.statusCode, time.Since(start))
+		return
+	}
+
 	// Try to serve static files first (images, CSS, JS, etc.)
 	if s.serveStaticFile(rec, r, urlPath) {
 		s.logRequest(r.Method, urlPath, rec.statusCode, time.Since(start))
@@ -345,12 +421,36 @@ func (s *DynamicServer) handleRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Try to render a later page of a folder's listing
+	if s.tryListingPage(rec, urlPath) {
+		s.logRequest(r.Method, urlPath, rec.statusCode, time.Since(start))
+		return
+	}
+
 	// Try to render an auto-generated folder index
 	if s.tryAutoIndex(rec, urlPath) {
 		s.logRequest(r.Method, urlPath, rec.statusCode, time.Since(start))
 		return
 	}
 
+	// Try to render a date archive page
+	if s.tryArchive(rec, urlPath) {
+		s.logRequest(r.Method, urlPath, rec.statusCode, time.Since(start))
+		return
+	}
+
+	// Try to render the full link graph page
+	if s.tryGraphPage(rec, urlPath) {
+		s.logRequest(r.Method, urlPath, rec.statusCode, time.Since(start))
+		return
+	}
+
+	// Try to render a printable book (real pages and folders at the same URL win)
+	if s.servePrintBook(rec, urlPath) {
+		s.logRequest(r.Method, urlPath, rec.statusCode, time.Since(start))
+		return
+	}
+
 	// Serve 404
 	s.serve404(rec, r)
 	s.logRequest(r.Method, urlPath, rec.statusCode, time.Since(start))
@@ -363,8 +463,9 @@ func (s *DynamicServer) tryAutoIndex(w http.ResponseWriter, urlPath string) bool
 		return false
 	}
 
-	// Scan the tree
-	site, err := s.scanner.Scan(s.config.SourceDir)
+	// Scan the tree of the URL's language
+	lang := s.languageOf(urlPath)
+	site, err := s.scan(lang)
 	if err != nil {
 		return false
 	}
@@ -380,8 +481,13 @@ func (s *DynamicServer) tryAutoIndex(w http.ResponseWriter, urlPath string) bool
 		return false
 	}
 
+	// A listing takes the auto-index's place
+	if l := s.listing(site, folderNode, lang); l != nil {
+		return s.renderFolderPage(w, urlPath, folderNode, site, lang, l.Title, l.RenderPage(1, s.messages(lang)))
+	}
+
 	// Render the auto-index
-	return s.renderAutoIndex(w, urlPath, folderNode, site)
+	return s.renderAutoIndex(w, urlPath, folderNode, site, lang)
 }
 
 // serveStaticFile tries to serve a static file (non-markdown)
@@ -412,30 +518,48 @@ func (s *DynamicServer) serveStaticFile(w http.ResponseWriter, r *http.Request,
 
 // renderPage tries to render a markdown page for the given URL
 func (s *DynamicServer) renderPage(w http.ResponseWriter, _ *http.Request, urlPath string) bool {
-	// Find the markdown file for this URL
-	mdPath := s.resolveMarkdownPath(urlPath)
-	if mdPath == "" {
-		return false
-	}
+	var site *tree.Site
+	var node *tree.Node
+	var fullMdPath string
+	var err error
+
+	lang := s.languageOf(urlPath)
+	if lang != "" {
+		// Translations are found by URL in their language's tree
+		site, err = s.scan(lang)
+		if err != nil {
+			s.logError("Failed to scan directory: %v", err)
+			return false
+		}
+		if node = findPageByURL(site, urlPath); node == nil {
+			return false
+		}
+		fullMdPath = node.SourcePath
+	} else {
+		// Find the markdown file for this URL
+		mdPath := s.resolveMarkdownPath(urlPath)
+		if mdPath == "" {
+			return false
+		}
 
-	fullMdPath := filepath.Join(s.config.SourceDir, mdPath)
+		fullMdPath = filepath.Join(s.config.SourceDir, mdPath)
 
-	// Check if the file exists
-	if _, err := s.fs.Stat(fullMdPath); err != nil {
-		return false
-	}
+		// Check if the file exists
+		if _, err := s.fs.Stat(fullMdPath); err != nil {
+			return false
+		}
 
-	// Scan the directory tree for navigation (fresh on every request)
-	site, err := s.scanner.Scan(s.config.SourceDir)
-	if err != nil {
-		s.logError("Failed to scan directory: %v", err)
-		return false
-	}
+		// Scan the directory tree for navigation (fresh on every request)
+		site, err = s.scanner.Scan(s.config.SourceDir)
+		if err != nil {
+			s.logError("Failed to scan directory: %v", err)
+			return false
+		}
 
-	// Find the tree node for this page
-	node := findNodeBySourcePath(site.Root, mdPath)
-	if node == nil {
-		return false
+		// Find the tree node for this page
+		if node = findNodeBySourcePath(site.Root, mdPath); node == nil {
+			return false
+		}
 	}
 
 	// Get paths
@@ -472,6 +596,14 @@ func (s *DynamicServer) renderPage(w http.ResponseWriter, _ *http.Request, urlPa
 
 	htmlContent := page.Content
 
+	// Pick the layout from front matter, falling back to the folder default
+	// and then to the theme's default (slides for the presentation theme)
+	defaultLayout := config.ResolveFolderConfig(s.config.Folders, node.Path).Layout
+	if defaultLayout == "" {
+		defaultLayout = templates.ThemeLayout(s.config.Theme)
+	}
+	layout := templates.ResolveLayout(page.FrontMatter, defaultLayout)
+
 	// Validate internal links (no base URL for dev server). Skipped entirely
 	// when the user passed --no-verify — no console output, no inline banner.
 	if !s.config.NoVerify {
@@ -496,7 +628,8 @@ func (s *DynamicServer) renderPage(w http.ResponseWriter, _ *http.Request, urlPa
 
 	// Calculate reading time
 	rt := content.CalculateReadingTime(htmlContent)
-	readingTime := content.FormatReadingTime(rt)
+	messages := s.messages(lang)
+	readingTime := content.FormatReadingTimeIn(rt, messages)
 
 	// Build breadcrumbs - only if enabled
 	var breadcrumbsHTML template.HTML
@@ -508,9 +641,12 @@ func (s *DynamicServer) renderPage(w http.ResponseWriter, _ *http.Request, urlPa
 	// Build page navigation (only if enabled)
 	var pageNavHTML template.HTML
 	if s.config.ShowPageNav {
-		allPages := collectAllPages(site.Root)
-		pageNav := navigation.BuildPageNavigation(node, allPages)
-		pageNavHTML = navigation.RenderPageNavigation(pageNav)
+		pageNav, err := navigation.NewPager(site, "", s.config.PageNavSections).Build(node, page.FrontMatter)
+		if err != nil {
+			s.serveTemplateError(w, fmt.Errorf("%s: %w", fullMdPath, err))
+			return true
+		}
+		pageNavHTML = navigation.RenderPageNavigationWithLabels(pageNav, messages.T("previous"), messages.T("next"))
 	}
 
 	// Extract TOC
@@ -518,12 +654,28 @@ func (s *DynamicServer) renderPage(w http.ResponseWriter, _ *http.Request, urlPa
 	tocHTML := toc.RenderTOC(pageTOC)
 	hasTOC := pageTOC != nil && len(pageTOC.Items) > 0
 
+	// A listing folder's index page lists the folder's pages below its content
+	if folder := findFolderByPath(site.Root, nodeURLPath); folder != nil && folder.HasIndex && folder.IndexPath == node.Path {
+		if l := s.listing(site, folder, lang); l != nil {
+			htmlContent += string(l.Render(1, messages))
+		}
+	}
+
 	// Build top nav items if enabled
-	topNavItems := templates.BuildTopNavItems(site.Root, s.config.TopNav)
+	topNavItems := s.topNavItems(site, lang)
 
 	// Render navigation (filtered when top nav is enabled)
 	nav := templates.RenderNavigationWithTopNav(site.Root, nodeURLPath, topNavItems)
 
+	// Dated pages in an archive show their date, linked to its month
+	date, dateURL := s.archiveDate(site, node)
+
+	// Robots and canonical tags, as builds set them
+	metaTagsHTML := s.indexingTags(node.Path, nodeURLPath, page.FrontMatter)
+
+	// Related pages, suggested or listed in front matter
+	relatedHTML := s.relatedPages(site, node, page.FrontMatter, messages)
+
 	// Prepare template data
 	data := templates.PageData{
 		SiteTitle:       s.config.Title,
@@ -533,9 +685,14 @@ func (s *DynamicServer) renderPage(w http.ResponseWriter, _ *http.Request, urlPa
 		CurrentPath:     nodeURLPath,
 		Breadcrumbs:     breadcrumbsHTML,
 		PageNav:         pageNavHTML,
+		Related:         relatedHTML,
 		TOC:             tocHTML,
+		MetaTags:        metaTagsHTML,
 		FaviconLinks:    s.faviconLinks,
+		FontPreloads:    s.fonts.RenderPreloadLinks(),
 		ReadingTime:     readingTime,
+		Date:            date,
+		DateURL:         dateURL,
 		HasTOC:          hasTOC,
 		ShowSearch:      true,
 		TopNavItems:     topNavItems,
@@ -544,20 +701,30 @@ func (s *DynamicServer) renderPage(w http.ResponseWriter, _ *http.Request, urlPa
 		ViewTransitions: s.viewTransitions,
 		PWAEnabled:      s.pwaEnabled,
 		SearchEnabled:   s.searchEnabled,
+		GraphEnabled:    s.config.Graph,
+	}
+	if s.config.Graph {
+		data.Graph = graph.RenderLocal(nodeURLPath, "", messages)
 	}
+	s.applyLanguage(&data, lang, nodeURLPath)
+	layout.Apply(&data)
 
-	// Get renderer (re-reads CSS if using custom CSS file)
+	// Get renderer (re-reads CSS and layouts for live reload)
 	renderer, err := s.getRenderer()
 	if err != nil {
-		s.logError("Failed to get renderer: %v", err)
-		return false
+		s.serveTemplateError(w, err)
+		return true
+	}
+	if err := renderer.ValidateLayout(layout.Name); err != nil {
+		s.serveTemplateError(w, fmt.Errorf("%s: %w", fullMdPath, err))
+		return true
 	}
 
 	// Render the page
 	var buf bytes.Buffer
 	if err := renderer.Render(&buf, data); err != nil {
-		s.logError("Failed to render page: %v", err)
-		return false
+		s.serveTemplateError(w, err)
+		return true
 	}
 
 	// Write response
@@ -566,6 +733,13 @@ func (s *DynamicServer) renderPage(w http.ResponseWriter, _ *http.Request, urlPa
 	return true
 }
 
+// serveTemplateError reports a layout/partial failure as plain text so the
+// file:line from the template error is visible in the browser
+func (s *DynamicServer) serveTemplateError(w http.ResponseWriter, err error) {
+	s.logError("Failed to render page: %v", err)
+	http.Error(w, "Template error: "+err.Error(), http.StatusInternalServerError)
+}
+
 // resolveMarkdownPath resolves a URL path to a markdown file path
 func (s *DynamicServer) resolveMarkdownPath(urlPath string) string {
 	// Remove leading slash
@@ -937,6 +1111,7 @@ func (s *DynamicServer) serveBrokenLinksError(w http.ResponseWriter, sourcePage
 		Navigation:      nav,
 		CurrentPath:     "",
 		FaviconLinks:    s.faviconLinks,
+		FontPreloads:    s.fonts.RenderPreloadLinks(),
 		BaseURL:         "", // Empty for dev server (no base URL prefix)
 		InstantNavJS:    s.instantNavJS,
 		ViewTransitions: s.viewTransitions,
@@ -971,23 +1146,21 @@ func (s *DynamicServer) serve404(w http.ResponseWriter, _ *http.Request) {
 		nav = templates.RenderNavigation(site.Root, "")
 	}
 
-	content := `<h1>404 - Page Not Found</h1>
-<p>The page you're looking for doesn't exist.</p>
-<p><a href="/">Return to home</a></p>`
-
 	data := templates.PageData{
 		SiteTitle:       s.config.Title,
-		PageTitle:       "Page Not Found",
-		Content:         template.HTML(content),
+		PageTitle:       s.messages("").T("pageNotFound"),
+		NotFound:        true,
 		Navigation:      nav,
 		CurrentPath:     "",
 		FaviconLinks:    s.faviconLinks,
+		FontPreloads:    s.fonts.RenderPreloadLinks(),
 		BaseURL:         "", // Empty for dev server (no base URL prefix)
 		InstantNavJS:    s.instantNavJS,
 		ViewTransitions: s.viewTransitions,
 		PWAEnabled:      s.pwaEnabled,
 		SearchEnabled:   s.searchEnabled,
 	}
+	s.applyLanguage(&data, "", "")
 
 	// Get renderer (re-reads CSS if using custom CSS file)
 	renderer, err := s.getRenderer()
@@ -1045,44 +1218,48 @@ func (s *DynamicServer) logRequest(method, path string, status int, duration tim
 	}
 }
 
-// collectAllPages collects all non-folder nodes from the tree for prev/next navigation
-func collectAllPages(node *tree.Node) []*tree.Node {
-	var pages []*tree.Node
-	collectPagesRecursive(node, &pages)
-	return pages
-}
-
-func collectPagesRecursive(node *tree.Node, pages *[]*tree.Node) {
-	if node == nil {
-		return
+// topNavItems returns the top navigation for a language's site: the main
+// menu if one is configured, otherwise the root items when top nav is on.
+// Menu errors are logged and the page renders without a top nav.
+func (s *DynamicServer) topNavItems(site *tree.Site, lang string) []templates.TopNavItem {
+	items, err := menu.Main(s.config.Menus)
+	if err == nil && len(items) == 0 {
+		return templates.BuildTopNavItems(site.Root, s.config.TopNav)
 	}
 
-	// Add non-folder nodes (but skip the root if it has no path)
-	if !node.IsFolder && node.Path != "" {
-		*pages = append(*pages, node)
+	// Menu pages are looked up in the default language
+	defaultSite := site
+	if err == nil && lang != "" {
+		defaultSite, err = s.scan("")
 	}
-
-	// If folder has an index, add a virtual node for it
-	if node.IsFolder && node.HasIndex {
-		indexNode := &tree.Node{
-			Path:       node.IndexPath,
-			Name:       node.Name,
-			SourcePath: filepath.Join(node.SourcePath, "index.md"),
-		}
-		*pages = append(*pages, indexNode)
+	var mainMenu []templates.TopNavItem
+	if err == nil {
+		mainMenu, err = menu.Build(items, defaultSite, "")
 	}
-
-	// Recurse into children
-	for _, child := range node.Children {
-		collectPagesRecursive(child, pages)
+	if err != nil {
+		s.logError("Invalid menu:\n%v", err)
+		return nil
+	}
+	if lang != "" {
+		mainMenu = menu.Localize(mainMenu, site, "/"+lang, "")
 	}
+	return mainMenu
 }
 
 // renderAutoIndex renders an auto-generated index page for a folder
-func (s *DynamicServer) renderAutoIndex(w http.ResponseWriter, urlPath string, node *tree.Node, site *tree.Site) bool {
+func (s *DynamicServer) renderAutoIndex(w http.ResponseWriter, urlPath string, node *tree.Node, site *tree.Site, lang string) bool {
 	// Build index using shared autoindex package
 	index := autoindex.Build(node)
-	htmlContent := autoindex.RenderContent(index)
+	return s.renderFolderPage(w, urlPath, node, site, lang, node.Name, autoindex.RenderContent(index))
+}
+
+// renderFolderPage renders a generated page for a folder, such as its
+// auto-index or a listing page
+func (s *DynamicServer) renderFolderPage(w http.ResponseWriter, urlPath string, node *tree.Node, site *tree.Site, lang, title string, htmlContent template.HTML) bool {
+	// Generated folder pages have no front matter, so they use the folder
+	// default layout. Unlike pages, they skip the theme's default: they list
+	// pages to navigate to, so the presentation theme doesn't turn them into decks.
+	layout := templates.ResolveLayout(nil, config.ResolveFolderConfig(s.config.Folders, filepath.Join(node.Path, "index.md")).Layout)
 
 	// Build breadcrumbs - only if enabled
 	var breadcrumbsHTML template.HTML
@@ -1092,20 +1269,22 @@ func (s *DynamicServer) renderAutoIndex(w http.ResponseWriter, urlPath string, n
 	}
 
 	// Build top nav items if enabled
-	topNavItems := templates.BuildTopNavItems(site.Root, s.config.TopNav)
+	topNavItems := s.topNavItems(site, lang)
 
 	// Render navigation (filtered when top nav is enabled)
-	nav := templates.RenderNavigationWithTopNav(site.Root, urlPath, topNavItems)
+	nav := templates.RenderNavigationWithTopNav(site.Root, tree.GetURLPath(node), topNavItems)
 
 	// Prepare template data
 	data := templates.PageData{
 		SiteTitle:       s.config.Title,
-		PageTitle:       node.Name,
+		PageTitle:       title,
 		Content:         htmlContent,
 		Navigation:      nav,
 		CurrentPath:     urlPath,
 		Breadcrumbs:     breadcrumbsHTML,
+		MetaTags:        s.indexingTags(filepath.Join(node.Path, "index.md"), urlPath, nil),
 		FaviconLinks:    s.faviconLinks,
+		FontPreloads:    s.fonts.RenderPreloadLinks(),
 		ShowSearch:      true,
 		TopNavItems:     topNavItems,
 		BaseURL:         "", // Empty for dev server (no base URL prefix)
@@ -1113,7 +1292,10 @@ func (s *DynamicServer) renderAutoIndex(w http.ResponseWriter, urlPath string, n
 		ViewTransitions: s.viewTransitions,
 		PWAEnabled:      s.pwaEnabled,
 		SearchEnabled:   s.searchEnabled,
+		GraphEnabled:    s.config.Graph,
 	}
+	s.applyLanguage(&data, lang, urlPath)
+	layout.Apply(&data)
 
 	// Get renderer (re-reads CSS if using custom CSS file)
 	renderer, err := s.getRenderer()
@@ -1125,7 +1307,7 @@ func (s *DynamicServer) renderAutoIndex(w http.ResponseWriter, urlPath string, n
 	// Render the page
 	var buf bytes.Buffer
 	if err := renderer.Render(&buf, data); err != nil {
-		s.logError("Failed to render auto-index: %v", err)
+		s.logError("Failed to render folder page: %v", err)
 		return false
 	}
 
@@ -1135,6 +1317,22 @@ func (s *DynamicServer) renderAutoIndex(w http.ResponseWriter, urlPath string, n
 	return true
 }
 
+// indexingTags renders a page's robots and canonical tags from its front
+// matter and folder settings. The dev server has no site URL, so pages
+// only get a canonical tag when one is set.
+func (s *DynamicServer) indexingTags(pagePath, urlPath string, fm tree.FrontMatter) template.HTML {
+	folder := config.ResolveFolderConfig(s.config.Folders, pagePath)
+	robots, err := seo.PageRobots(fm, folder)
+	if err != nil {
+		s.logError("%s: %v", pagePath, err)
+	}
+	canonical, err := seo.PageCanonical("", urlPath, fm, folder)
+	if err != nil {
+		s.logError("%s: %v", pagePath, err)
+	}
+	return seo.RenderIndexingTags(robots, canonical)
+}
+
 // findFolderByPath finds a folder node by its URL path (slugified)
 func findFolderByPath(node *tree.Node, urlPath string) *tree.Node {
 	if node == nil {
@@ -1246,8 +1444,8 @@ func (s *DynamicServer) serveManifest(w http.ResponseWriter) {
 
 // serveServiceWorker generates and serves sw.js dynamically
 func (s *DynamicServer) serveServiceWorker(w http.ResponseWriter) {
-	// Scan the tree to get all page URLs
-	site, err := s.scanner.Scan(s.config.SourceDir)
+	// Scan every language to get all page URLs
+	sites, codes, err := s.languageSites()
 	if err != nil {
 		http.Error(w, "Failed to scan site", http.StatusInternalServerError)
 		return
@@ -1255,7 +1453,9 @@ func (s *DynamicServer) serveServiceWorker(w http.ResponseWriter) {
 
 	// Collect all page URLs
 	var pageURLs []string
-	collectPageURLs(site.Root, &pageURLs)
+	for _, lang := range codes {
+		collectPageURLs(sites[lang].Root, &pageURLs)
+	}
 
 	// Add root path
 	pageURLs = append([]string{"/"}, pageURLs...)
@@ -1266,7 +1466,18 @@ func (s *DynamicServer) serveServiceWorker(w http.ResponseWriter) {
 		assetURLs = append(assetURLs, "/icon-192.png", "/icon-512.png")
 	}
 	if s.searchEnabled {
-		assetURLs = append(assetURLs, "/search.js", "/search-index.json")
+		assetURLs = append(assetURLs, "/search.js")
+		if files, _, err := s.SearchFiles(); err == nil {
+			names := make([]string, 0, len(files))
+			for name := range files {
+				names = append(names, "/"+name)
+			}
+			sort.Strings(names)
+			assetURLs = append(assetURLs, names...)
+		}
+	}
+	if s.config.Graph {
+		assetURLs = append(assetURLs, "/"+graph.ScriptName, "/"+graph.FileName)
 	}
 	if s.faviconName != "" {
 		assetURLs = append(assetURLs, "/"+s.faviconName)
@@ -1277,6 +1488,7 @@ func (s *DynamicServer) serveServiceWorker(w http.ResponseWriter) {
 	if s.faviconIco != nil {
 		assetURLs = append(assetURLs, "/favicon.ico")
 	}
+	assetURLs = append(assetURLs, s.fonts.URLs()...)
 
 	config := pwa.ServiceWorkerConfig{
 		BaseURL:   "", // No base URL for dev server
@@ -1310,25 +1522,55 @@ func collectPageURLs(node *tree.Node, urls *[]string) {
 	}
 }
 
-// serveSearchIndex generates and serves search-index.json dynamically
+// serveSearchIndex generates and serves the search index dynamically: the
+// search-index.json manifest and its shards
 func (s *DynamicServer) serveSearchIndex(w http.ResponseWriter, urlPath string) bool {
-	if !s.searchEnabled || urlPath != "/search-index.json" {
+	if !s.searchEnabled || (urlPath != "/"+search.ManifestFile && !strings.HasPrefix(urlPath, "/"+search.ShardDir+"/")) {
 		return false
 	}
 
-	// Scan the tree to get all pages
-	site, err := s.scanner.Scan(s.config.SourceDir)
+	files, _, err := s.SearchFiles()
 	if err != nil {
-		http.Error(w, "Failed to scan site", http.StatusInternalServerError)
+		http.Error(w, "Failed to generate search index", http.StatusInternalServerError)
+		return true
+	}
+	data, ok := files[strings.TrimPrefix(urlPath, "/")]
+	if !ok {
+		http.Error(w, "Search index shard not found", http.StatusNotFound)
 		return true
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(data)
+	return true
+}
+
+// SearchFiles builds the search index of every language's pages and splits
+// it into files, the same way builds do. It also returns the markdown file
+// of each page, by URL path.
+func (s *DynamicServer) SearchFiles() (map[string][]byte, map[string]string, error) {
+	// Scan every language to get all pages
+	sites, codes, err := s.languageSites()
+	if err != nil {
+		return nil, nil, err
+	}
+	var allPages []*tree.Node
+	pageLang := make(map[*tree.Node]string)
+	for _, lang := range codes {
+		allPages = append(allPages, sites[lang].AllPages...)
+		for _, node := range sites[lang].AllPages {
+			pageLang[node] = s.code(lang)
+		}
+	}
+
 	// Build search index
 	index := &search.Index{Pages: []search.PageEntry{}}
+	sources := make(map[string]string)
 
 	// Process each page
-	for _, node := range site.AllPages {
-		fullMdPath := filepath.Join(s.config.SourceDir, node.Path)
+	for _, node := range allPages {
+		fullMdPath := s.nodeFile(node)
 
 		// Read markdown content
 		mdContent, err := s.fs.ReadFile(fullMdPath)
@@ -1358,26 +1600,21 @@ func (s *DynamicServer) serveSearchIndex(w http.ResponseWriter, urlPath string)
 			continue
 		}
 
-		// Extract search data
-		entry := search.PageEntry{
-			Title:    page.Title,
-			URL:      urlPath,
-			Headings: search.ExtractHeadings(page.Content),
+		// Extract search data, leaving out noindex pages as builds do
+		robots, err := seo.PageRobots(page.FrontMatter, config.ResolveFolderConfig(s.config.Folders, node.Path))
+		if err != nil || !seo.Indexable(robots) {
+			continue
 		}
-		index.Pages = append(index.Pages, entry)
+		index.Pages = append(index.Pages, search.NewPageEntry(node, pageLang[node], page.Title, page.Content, page.FrontMatter))
+		sources[urlPath] = fullMdPath
 	}
 
-	// Serialize to JSON
-	data, err := json.MarshalIndent(index, "", "  ")
+	// Split into shards, the same way builds do
+	files, err := index.Files(s.config.SearchShardSize)
 	if err != nil {
-		http.Error(w, "Failed to generate search index", http.StatusInternalServerError)
-		return true
+		return nil, nil, err
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Cache-Control", "no-cache")
-	_, _ = w.Write(data)
-	return true
+	return files, sources, nil
 }
 
 // serveSearchJS generates and serves search.js dynamically
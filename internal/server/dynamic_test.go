@@ -2,6 +2,7 @@ package server
 
 import (
 	"bytes"
+	"encoding/json"
 	"image"
 	"image/png"
 	"io"
@@ -13,7 +14,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/generator"
+	"github.com/wusher/volcano/internal/includes"
 	"github.com/wusher/volcano/internal/markdown"
+	"github.com/wusher/volcano/internal/search"
 	"github.com/wusher/volcano/internal/tree"
 )
 
@@ -531,28 +536,6 @@ func TestFindFolderByPath(t *testing.T) {
 	}
 }
 
-func TestCollectAllPages(t *testing.T) {
-	root := tree.NewNode("", "", true)
-
-	file1 := tree.NewNode("File 1", "file1.md", false)
-	file1.Path = "file1.md"
-	root.AddChild(file1)
-
-	folder := tree.NewNode("Folder", "folder", true)
-	folder.Path = "folder"
-	root.AddChild(folder)
-
-	file2 := tree.NewNode("File 2", "folder/file2.md", false)
-	file2.Path = "folder/file2.md"
-	folder.AddChild(file2)
-
-	pages := collectAllPages(root)
-
-	if len(pages) != 2 {
-		t.Errorf("collectAllPages() returned %d pages, want 2", len(pages))
-	}
-}
-
 func TestDynamicServer_AutoIndex(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -675,6 +658,108 @@ func TestDynamicServer_TopNav(t *testing.T) {
 	}
 }
 
+func TestDynamicServer_Menu(t *testing.T) {
+	tmpDir := t.TempDir()
+	for name, content := range map[string]string{
+		"index.md":        "# Home",
+		"about.md":        "# About",
+		"guides/index.md": "# Guides",
+		"guides/intro.md": "# Intro",
+	} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	menus := map[string][]config.MenuItem{
+		"main": {
+			{Page: "guides", Icon: "book"},
+			{Name: "Blog", URL: "https://example.com/blog"},
+		},
+	}
+	server, err := NewDynamicServer(DynamicConfig{SourceDir: tmpDir, Title: "Test Site", Menus: menus}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/guides/intro/", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, `<a href="/guides/" aria-current="page" class="active"><span class="top-nav-icon" aria-hidden="true"><svg`) {
+		t.Error("the Guides item should be active on pages in the guides folder")
+	}
+	if !strings.Contains(body, `<a href="https://example.com/blog" rel="noopener">Blog</a>`) {
+		t.Error("response should contain the external Blog link")
+	}
+
+	// An invalid menu is logged and the page renders without a top nav
+	var logs bytes.Buffer
+	menus["main"] = []config.MenuItem{{Page: "missing"}}
+	server, err = NewDynamicServer(DynamicConfig{SourceDir: tmpDir, Title: "Test Site", Menus: menus}, &logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), `class="top-nav"`) {
+		t.Errorf("GET / with an invalid menu: status %d, top nav shown = %v", rec.Code, strings.Contains(rec.Body.String(), `class="top-nav"`))
+	}
+	if !strings.Contains(logs.String(), `page "missing" not found`) {
+		t.Errorf("logs = %q, want the menu error", logs.String())
+	}
+}
+
+func TestDynamicServer_PageNav(t *testing.T) {
+	tmpDir := t.TempDir()
+	for name, content := range map[string]string{
+		"index.md":        "# Home",
+		"about.md":        "---\nnext: missing\n---\n# About",
+		"guides/index.md": "# Guides",
+		"guides/intro.md": "---\nnext: \"[[about|Back to About]]\"\n---\n# Intro",
+	} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var logs bytes.Buffer
+	server, err := NewDynamicServer(DynamicConfig{SourceDir: tmpDir, Title: "Test Site", ShowPageNav: true}, &logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(path string) string {
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Body.String()
+	}
+
+	guides := get("/guides/")
+	if !strings.Contains(guides, `<a href="/about/" class="page-nav-prev">`) || !strings.Contains(guides, `<a href="/guides/intro/" class="page-nav-next">`) {
+		t.Error("the folder index page should sit between about and intro")
+	}
+	if intro := get("/guides/intro/"); !strings.Contains(intro, "Back to About →") {
+		t.Error("intro should use its front matter next link")
+	}
+
+	// A missing reference is an error, as it is for builds
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/about/", nil))
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), `next: page "missing" not found`) {
+		t.Errorf("GET /about/ = %d %q, want the prev/next error", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(logs.String(), `next: page "missing" not found`) {
+		t.Errorf("logs = %q, want the prev/next error", logs.String())
+	}
+}
+
 func TestDynamicServer_ServeStaticFile_WithMockFS(t *testing.T) {
 	mockFS := &mockFileSystem{
 		files: map[string]mockFileInfo{
@@ -1204,13 +1289,139 @@ func TestDynamicServer_ServeSearchIndex(t *testing.T) {
 			t.Errorf("Content-Type = %q, want application/json", rec.Header().Get("Content-Type"))
 		}
 
+		var manifest search.Manifest
+		if err := json.Unmarshal(rec.Body.Bytes(), &manifest); err != nil || len(manifest.Stores) != 1 {
+			t.Fatalf("manifest = %s (%v)", rec.Body.String(), err)
+		}
+
+		// Shards are served under the names the manifest gives
+		rec = httptest.NewRecorder()
+		if !server.serveSearchIndex(rec, "/"+manifest.Stores[0].File) || rec.Code != http.StatusOK {
+			t.Fatalf("serveSearchIndex(%s) status = %d", manifest.Stores[0].File, rec.Code)
+		}
 		body := rec.Body.String()
 		if !strings.Contains(body, "Home") {
 			t.Error("search index should contain page title")
 		}
+		if !strings.Contains(body, `["h2","Section One","/#section-one","Home","Content"]`) {
+			t.Errorf("search index should contain section text:\n%s", body)
+		}
+	})
+
+	t.Run("unknown shard is not found", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		if !server.serveSearchIndex(rec, "/search-index/terms.00000000.json") || rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
 	})
 }
 
+func TestDynamicServer_SearchIndexMatchesBuild(t *testing.T) {
+	srcDir := t.TempDir()
+	files := map[string]string{
+		"index.md":                  "# Home\n\nWelcome.\n\n## Start\n\nRead [Kafka](/guides/kafka/).",
+		"guides/kafka.md":           "---\ntags: [API, ops]\n---\n# Kafka\n\nBrokers.\n\n## Consumers\n\nGroups & offsets.",
+		"blog/2024-03-15-launch.md": "# Launch\n\n```go\nfmt.Println(1)\n```",
+		"hidden.md":                 "---\nrobots: noindex\n---\n# Hidden\n\nA zebra.",
+		"internal/notes.md":         "# Notes\n\nAnother zebra.",
+	}
+	folders := map[string]config.FolderConfig{"internal": {Robots: "noindex, nofollow"}}
+	for path, content := range files {
+		fullPath := filepath.Join(srcDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outDir := t.TempDir()
+	g, err := generator.New(generator.Config{InputDir: srcDir, OutputDir: outDir, Title: "Test", Search: true, Folders: folders}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewDynamicServer(DynamicConfig{SourceDir: srcDir, Title: "Test", Search: true, Folders: folders}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	built, err := os.ReadFile(filepath.Join(outDir, "search-index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest search.Manifest
+	if err := json.Unmarshal(built, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{"search-index.json", manifest.Facets}
+	for _, shard := range manifest.Terms {
+		paths = append(paths, shard.File)
+	}
+	for _, store := range manifest.Stores {
+		paths = append(paths, store.File)
+	}
+	for _, path := range paths {
+		want, err := os.ReadFile(filepath.Join(outDir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := httptest.NewRecorder()
+		server.serveSearchIndex(rec, "/"+path)
+		if rec.Body.String() != string(want) {
+			t.Errorf("served %s differs from the build:\n%s\nwant\n%s", path, rec.Body.String(), want)
+		}
+		if strings.Contains(rec.Body.String(), "zebra") {
+			t.Errorf("served %s indexes noindex pages", path)
+		}
+	}
+}
+
+func TestDynamicServer_IndexingTags(t *testing.T) {
+	srcDir := t.TempDir()
+	files := map[string]string{
+		"index.md":          "# Home",
+		"hidden.md":         "---\nrobots: noindex\ncanonical: https://example.com/elsewhere/\n---\n# Hidden",
+		"internal/notes.md": "# Notes",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(srcDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server, err := NewDynamicServer(DynamicConfig{
+		SourceDir: srcDir,
+		Title:     "Test",
+		Folders:   map[string]config.FolderConfig{"internal": {Robots: "noindex, nofollow"}},
+	}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string][]string{
+		"/":                {`<meta name="robots" content="index, follow">`},
+		"/hidden/":         {`<meta name="robots" content="noindex">`, `<link rel="canonical" href="https://example.com/elsewhere/">`},
+		"/internal/notes/": {`<meta name="robots" content="noindex, nofollow">`},
+		"/internal/":       {`<meta name="robots" content="noindex, nofollow">`},
+	}
+	for path, wants := range tests {
+		rec := httptest.NewRecorder()
+		server.handleRequest(rec, httptest.NewRequest("GET", path, nil))
+		for _, want := range wants {
+			if !strings.Contains(rec.Body.String(), want) {
+				t.Errorf("%s should contain %s", path, want)
+			}
+		}
+	}
+}
+
 func TestDynamicServer_ServeSearchJS(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -1436,33 +1647,6 @@ func TestDynamicServer_HandleRequest_WithBrokenLinks(t *testing.T) {
 	}
 }
 
-func TestDynamicServer_CollectPagesRecursive_WithFolderIndex(t *testing.T) {
-	root := tree.NewNode("", "", true)
-
-	// Add a folder with index
-	folder := tree.NewNode("Folder", "folder", true)
-	folder.Path = "folder"
-	folder.IsFolder = true
-	folder.HasIndex = true
-	folder.IndexPath = "folder/index.md"
-	folder.SourcePath = "/test/folder"
-	root.AddChild(folder)
-
-	pages := collectAllPages(root)
-
-	// Should include the folder's index as a page
-	found := false
-	for _, p := range pages {
-		if strings.Contains(p.Path, "index.md") {
-			found = true
-			break
-		}
-	}
-	if !found {
-		t.Error("collectAllPages should include folder index page")
-	}
-}
-
 func TestDynamicServer_RenderAutoIndex_Error(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -2076,40 +2260,6 @@ func TestDynamicServer_FindFolderByPath_NilRoot(t *testing.T) {
 	}
 }
 
-func TestDynamicServer_CollectPagesRecursive(t *testing.T) {
-	root := &tree.Node{
-		Name:     "Root",
-		IsFolder: true,
-		Children: []*tree.Node{
-			{Name: "Page1", Path: "page1.md", IsFolder: false},
-			{
-				Name:     "Folder",
-				Path:     "folder",
-				IsFolder: true,
-				Children: []*tree.Node{
-					{Name: "Page2", Path: "folder/page2.md", IsFolder: false},
-				},
-			},
-		},
-	}
-
-	var pages []*tree.Node
-	collectPagesRecursive(root, &pages)
-
-	if len(pages) != 2 {
-		t.Errorf("len(pages) = %d, want 2", len(pages))
-	}
-}
-
-func TestDynamicServer_CollectPagesRecursive_NilNode(t *testing.T) {
-	var pages []*tree.Node
-	collectPagesRecursive(nil, &pages)
-
-	if len(pages) != 0 {
-		t.Errorf("len(pages) = %d, want 0 for nil node", len(pages))
-	}
-}
-
 func TestDynamicServer_HandleRequestWithDifferentPaths(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -2285,3 +2435,634 @@ func TestDynamicServer_ServeSearchEnabled(t *testing.T) {
 		t.Errorf("status = %d, want 200 for search.js when search enabled", rec.Code)
 	}
 }
+
+func TestDynamicServer_LayoutsOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte("# Home\n\nWelcome."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	layoutsDir := filepath.Join(tmpDir, "_layouts")
+	if err := os.MkdirAll(layoutsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	footer := filepath.Join(layoutsDir, "footer.html")
+	if err := os.WriteFile(footer, []byte(`<footer class="custom-footer">ok</footer>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := NewDynamicServer(DynamicConfig{SourceDir: tmpDir, Title: "Test Site"}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	server.renderPage(rec, httptest.NewRequest(http.MethodGet, "/", nil), "/")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status code = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.Contains(rec.Body.String(), `<footer class="custom-footer">ok</footer>`) {
+		t.Error("response should use the footer partial from _layouts")
+	}
+
+	// Break the partial: the next request reports the file and line
+	if err := os.WriteFile(footer, []byte("<footer>\n{{.Broken</footer>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	if !server.renderPage(rec, httptest.NewRequest(http.MethodGet, "/", nil), "/") {
+		t.Error("renderPage() should handle template errors")
+	}
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status code = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if !strings.Contains(rec.Body.String(), "footer.html:2") {
+		t.Errorf("body should point at footer.html:2, got %q", rec.Body.String())
+	}
+}
+
+func TestDynamicServer_PageLayouts(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"index.md":                "---\nlayout: landing\n---\n# Home\n\n## A\n\n## B\n\n## C\n",
+		"guide.md":                "# Guide\n\n## A\n\n## B\n\n## C\n",
+		"talks/intro.md":          "# Intro\n\n## A\n\n## B\n\n## C\n",
+		"talks/keynote.md":        "---\nlayout: default\ntoc: false\n---\n# Keynote\n\n## A\n\n## B\n\n## C\n",
+		"_layouts/dashboard.html": `<html><body class="dash">{{.Content}}</body></html>`,
+		"stats.md":                "---\nlayout: dashboard\n---\n# Stats",
+		"broken.md":               "---\nlayout: nope\n---\n# Broken",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server, err := NewDynamicServer(DynamicConfig{
+		SourceDir: tmpDir,
+		Title:     "Layouts",
+		NoVerify:  true,
+		Folders:   map[string]config.FolderConfig{"talks": {Layout: "wide"}},
+	}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := server.Handler()
+
+	// Same expectations as the generator's TestGenerateWithPageLayouts
+	tests := []struct {
+		path    string
+		want    []string
+		notWant []string
+	}{
+		{"/", []string{`class="layout-landing no-sidebar"`, `data-layout="landing"`}, []string{`<aside class="sidebar"`, `class="toc-sidebar"`}},
+		{"/guide/", []string{`<aside class="sidebar"`, `class="toc-sidebar"`, `data-layout=""`}, []string{`class="layout-`}},
+		{"/talks/intro/", []string{`class="layout-wide wide"`, `data-layout="wide"`, `<aside class="sidebar"`}, []string{`class="toc-sidebar"`}},
+		{"/talks/keynote/", []string{`<aside class="sidebar"`, `data-layout="default"`}, []string{`class="layout-wide`, `class="toc-sidebar"`}},
+		{"/talks/", []string{`class="layout-wide wide"`}, nil},
+		{"/stats/", []string{`<html><body class="dash">`}, []string{`<aside class="sidebar"`}},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status code = %d, want %d", tt.path, rec.Code, http.StatusOK)
+			continue
+		}
+		html := rec.Body.String()
+		for _, want := range tt.want {
+			if !strings.Contains(html, want) {
+				t.Errorf("%s should contain %q", tt.path, want)
+			}
+		}
+		for _, notWant := range tt.notWant {
+			if strings.Contains(html, notWant) {
+				t.Errorf("%s should not contain %q", tt.path, notWant)
+			}
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/broken/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("unknown layout: status code = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if !strings.Contains(rec.Body.String(), `unknown layout "nope"`) {
+		t.Errorf("unknown layout: body = %q", rec.Body.String())
+	}
+}
+
+func TestDynamicServer_Slides(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := "---\nlayout: slides\n---\n# Deck\n\n---\n\n## First\n\nNote: speak slowly\n\n## Second\n\nBye"
+	if err := os.WriteFile(filepath.Join(tmpDir, "deck.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := NewDynamicServer(DynamicConfig{SourceDir: tmpDir, Title: "Test"}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/deck/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status code = %d, want %d", rec.Code, http.StatusOK)
+	}
+	html := rec.Body.String()
+	for _, want := range []string{`class="slide-deck"`, `id="slide-3"`, "<p>speak slowly</p>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("slides page should contain %q", want)
+		}
+	}
+}
+
+func TestDynamicServer_Fonts(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"index.md":          "# Home",
+		"fonts/brand.woff2": "brand-font-bytes",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server, err := NewDynamicServer(DynamicConfig{
+		SourceDir: tmpDir,
+		Title:     "Fonts",
+		NoVerify:  true,
+		PWA:       true,
+		Fonts: &config.FontsConfig{
+			Body: &config.FontConfig{Family: "Brand", Files: []config.FontFile{{Path: "fonts/brand.woff2"}}},
+		},
+	}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := server.Handler()
+	fontURL := server.fonts.Files[0].URLPath
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	html := rec.Body.String()
+	if !strings.Contains(html, `<link rel="preload" href="`+fontURL+`" as="font"`) {
+		t.Error("page should preload the font")
+	}
+	if !strings.Contains(html, "@font-face") {
+		t.Error("inline CSS should declare the font")
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, fontURL, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "brand-font-bytes" {
+		t.Errorf("font request: status %d, body %q", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "font/woff2" {
+		t.Errorf("Content-Type = %q, want font/woff2", ct)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sw.js", nil))
+	if !strings.Contains(rec.Body.String(), fontURL) {
+		t.Error("service worker should precache the font")
+	}
+}
+
+func TestDynamicServer_Includes(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"index.md":   "# Home",
+		"_header.md": "Header **notice**",
+		"_footer.md": "Footer text",
+		"_banner.md": "Version 2 is out",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server, err := NewDynamicServer(DynamicConfig{
+		SourceDir: tmpDir,
+		Title:     "Includes",
+		NoVerify:  true,
+	}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := server.Handler()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	html := get("/").Body.String()
+	for _, want := range []string{"<strong>notice</strong>", "Footer text", `class="site-banner"`, `data-banner-id="`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+
+	// Edits show up on the next request
+	if err := os.WriteFile(filepath.Join(tmpDir, "_footer.md"), []byte("Updated footer"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(get("/").Body.String(), "Updated footer") {
+		t.Error("footer edits should be picked up without a restart")
+	}
+
+	// Includes are not served as pages
+	for _, path := range []string{"/header/", "/_header/", "/banner/"} {
+		if rec := get(path); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, rec.Code)
+		} else if !strings.Contains(rec.Body.String(), "Updated footer") {
+			t.Errorf("GET %s: 404 page should carry the includes too", path)
+		}
+	}
+}
+
+func TestDynamicServer_MissingInclude(t *testing.T) {
+	_, err := NewDynamicServer(DynamicConfig{
+		SourceDir: t.TempDir(),
+		Includes:  includes.Paths{Banner: "missing.md"},
+	}, io.Discard)
+	if err == nil {
+		t.Error("NewDynamicServer() should fail for a missing configured include")
+	}
+}
+
+func TestDynamicServer_PrintBook(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"index.md":            "# Home\n\nSee [[runbooks/restart|restart]].",
+		"runbooks/index.md":   "# Runbooks\n\n## Steps",
+		"runbooks/restart.md": "# Restart\n\n## Steps",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	newServer := func(print bool) http.Handler {
+		server, err := NewDynamicServer(DynamicConfig{
+			SourceDir: tmpDir,
+			Title:     "Ops",
+			NoVerify:  true,
+			Print:     print,
+		}, io.Discard)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return server.Handler()
+	}
+	get := func(handler http.Handler, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	handler := newServer(true)
+	rec := get(handler, "/print.html")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /print.html = %d, want 200", rec.Code)
+	}
+	for _, want := range []string{`<nav class="print-toc"`, `id="page-home"`, `id="steps-2"`, `<a href="#page-runbooks-restart">restart</a>`} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("site book should contain %q", want)
+		}
+	}
+
+	rec = get(handler, "/runbooks/print/")
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), `id="page-home"`) {
+		t.Errorf("GET /runbooks/print/ = %d, want the folder's pages only", rec.Code)
+	}
+
+	for _, path := range []string{"/print/", "/missing/print/"} {
+		if rec := get(handler, path); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, rec.Code)
+		}
+	}
+
+	if rec := get(newServer(false), "/print.html"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /print.html without Print = %d, want 404", rec.Code)
+	}
+}
+
+func TestDynamicServer_Multilingual(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"index.md":           "# Home",
+		"index.fr.md":        "# Accueil",
+		"guides/intro.md":    "# Intro",
+		"guides/intro.fr.md": "# Introduction",
+		"guides/setup.md":    "# Setup",
+		"_footer.fr.md":      "Pied de page",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server, err := NewDynamicServer(DynamicConfig{
+		SourceDir:    tmpDir,
+		Title:        "Docs",
+		NoVerify:     true,
+		Languages:    []string{"fr"},
+		Translations: map[string]map[string]string{"en": {"pageNotFound": "Lost"}},
+	}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := server.Handler()
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/fr/guides/intro/")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /fr/guides/intro/ = %d, want 200", rec.Code)
+	}
+	for _, want := range []string{
+		`<html lang="fr" dir="ltr">`,
+		"Introduction",
+		"Pied de page",
+		`class="language-switcher"`,
+		`<link rel="alternate" hreflang="en" href="/guides/intro/">`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("fr page missing %q", want)
+		}
+	}
+
+	html := get("/guides/setup/").Body.String()
+	if !strings.Contains(html, `<html lang="en" dir="ltr">`) || strings.Contains(html, "Pied de page") {
+		t.Error("default-language pages should use the default footer and language")
+	}
+	if !strings.Contains(html, `href="/fr/"`) {
+		t.Error("the switcher should link untranslated pages to the language's home page")
+	}
+
+	// Translation files are not served as default-language pages
+	rec = get("/guides/intro.fr/")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /guides/intro.fr/ = %d, want 404", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Lost") {
+		t.Error("404 page should use the translated text")
+	}
+	if rec := get("/fr/guides/setup/"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /fr/guides/setup/ = %d, want 404", rec.Code)
+	}
+}
+
+func TestDynamicServer_Listing(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"index.md":                     "# Home",
+		"blog/index.md":                "# Blog\n\nNews from the team.",
+		"blog/2024-01-10-launch.md":    "# Launch\n\nWe launched today.",
+		"blog/2024-02-20-update.md":    "# Update\n\nA small update.",
+		"changelog/2024-04-01-v1.md":   "# v1.0",
+		"changelog/2023-11-12-beta.md": "# Beta",
+		"guides/intro.md":              "# Intro",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server, err := NewDynamicServer(DynamicConfig{
+		SourceDir: tmpDir,
+		Title:     "News",
+		NoVerify:  true,
+		Folders: map[string]config.FolderConfig{
+			"blog":      {Listing: &config.ListingConfig{PageSize: 1}},
+			"changelog": {Listing: &config.ListingConfig{Style: "card"}},
+		},
+	}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := server.Handler()
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/blog/")
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, "News from the team.") || !strings.Contains(body, `<a href="/blog/update/">Update</a>`) {
+		t.Errorf("GET /blog/ = %d, want the index content and the newest post", rec.Code)
+	}
+	if !strings.Contains(body, `<a href="/blog/page/2/" class="listing-older" rel="next">`) {
+		t.Error("GET /blog/ should link to page 2")
+	}
+
+	rec = get("/blog/page/2/")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `<a href="/blog/launch/">Launch</a>`) {
+		t.Errorf("GET /blog/page/2/ = %d, want the older post", rec.Code)
+	}
+
+	rec = get("/changelog/")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `<section class="listing listing-card">`) {
+		t.Errorf("GET /changelog/ = %d, want the card listing", rec.Code)
+	}
+
+	for _, path := range []string{"/blog/page/3/", "/guides/page/2/", "/missing/page/2/"} {
+		if rec := get(path); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, rec.Code)
+		}
+	}
+	if rec := get("/guides/"); !strings.Contains(rec.Body.String(), `class="folder-index"`) {
+		t.Error("folders without listing settings should keep their auto-index")
+	}
+}
+
+func TestDynamicServer_Archives(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"index.md":                  "# Home",
+		"2023/index.md":             "# The 2023 Page",
+		"blog/2024-03-15-spring.md": "# Spring",
+		"blog/2023-11-02-fall.md":   "# Fall",
+		"notes/undated.md":          "# Undated",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server, err := NewDynamicServer(DynamicConfig{
+		SourceDir: tmpDir,
+		Title:     "News",
+		NoVerify:  true,
+		Folders:   map[string]config.FolderConfig{"/": {Archive: true}},
+	}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := server.Handler()
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/archive/")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `<h3><a href="/2023/11/">November</a></h3>`) {
+		t.Errorf("GET /archive/ = %d, want the site-wide archive", rec.Code)
+	}
+	rec = get("/2024/03/")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `<a href="/blog/spring/">Spring</a>`) {
+		t.Errorf("GET /2024/03/ = %d, want the month's pages", rec.Code)
+	}
+	if rec := get("/2023/"); !strings.Contains(rec.Body.String(), "The 2023 Page") {
+		t.Error("a real folder should win over the year page")
+	}
+	if rec := get("/2022/"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /2022/ = %d, want 404", rec.Code)
+	}
+
+	rec = get("/blog/spring/")
+	if !strings.Contains(rec.Body.String(), `<time datetime="2024-03-15"><a href="/2024/03/">2024-03-15</a></time>`) {
+		t.Error("dated pages should link their date to the month archive")
+	}
+	if rec := get("/notes/undated/"); strings.Contains(rec.Body.String(), `class="page-date"`) {
+		t.Error("undated pages should not show a date")
+	}
+}
+
+func TestDynamicServer_Related(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"index.md":    "# Home",
+		"kafka.md":    "---\ntags: [streaming]\n---\n# Kafka\n\nBrokers and partitions.",
+		"pulsar.md":   "---\ntags: [streaming]\n---\n# Pulsar\n\nBrokers and topics.",
+		"postgres.md": "---\nrelated: [missing]\n---\n# Postgres\n\nTables and indexes.",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var logs bytes.Buffer
+	server, err := NewDynamicServer(DynamicConfig{
+		SourceDir: tmpDir,
+		Title:     "Docs",
+		NoVerify:  true,
+		Related:   3,
+	}, &logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(path string) string {
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Body.String()
+	}
+
+	if body := get("/kafka/"); !strings.Contains(body, `<li><a href="/pulsar/">Pulsar</a></li>`) {
+		t.Error("GET /kafka/ should suggest Pulsar")
+	}
+	if body := get("/postgres/"); strings.Contains(body, `class="related-pages"`) {
+		t.Error("GET /postgres/ should not show unresolved related pages")
+	}
+	if !strings.Contains(logs.String(), `invalid related page: page "missing" not found`) {
+		t.Errorf("expected the invalid related page to be logged:\n%s", logs.String())
+	}
+}
+
+func TestDynamicServer_Graph(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"index.md":          "# Home\n\nSee [[guides/setup]].",
+		"guides/setup.md":   "---\ntags: [ops]\n---\n# Setup\n\n![[Install]]",
+		"guides/install.md": "# Install",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server, err := NewDynamicServer(DynamicConfig{
+		SourceDir: tmpDir,
+		Title:     "Docs",
+		NoVerify:  true,
+		Graph:     true,
+	}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/graph.json")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("GET /graph.json = %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{
+		`{"source":"/","target":"/guides/setup/","type":"wikilink"}`,
+		`{"source":"/guides/setup/","target":"/guides/install/","type":"embed"}`,
+		`{"source":"/guides/setup/","target":"tag:ops","type":"tag"}`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("graph.json should contain %s:\n%s", want, rec.Body.String())
+		}
+	}
+	if rec := get("/graph.js"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "const baseURL = ''") {
+		t.Errorf("GET /graph.js = %d", rec.Code)
+	}
+	if rec := get("/graph/"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `<article class="graph-page">`) {
+		t.Errorf("GET /graph/ = %d, want the full graph", rec.Code)
+	}
+	if body := get("/guides/setup/").Body.String(); !strings.Contains(body, `data-graph-center="/guides/setup/"`) || !strings.Contains(body, `<script defer src="/graph.js"></script>`) {
+		t.Error("pages should show their local graph")
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http"
+	"path/filepath"
+
+	"github.com/wusher/volcano/internal/graph"
+	"github.com/wusher/volcano/internal/markdown"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// serveGraph generates and serves graph.json and graph.js from the current
+// sources
+func (s *DynamicServer) serveGraph(w http.ResponseWriter, urlPath string) bool {
+	if !s.config.Graph {
+		return false
+	}
+	switch urlPath {
+	case "/" + graph.ScriptName:
+		w.Header().Set("Content-Type", "application/javascript")
+		w.Header().Set("Cache-Control", "no-cache")
+		_, _ = w.Write([]byte(graph.GenerateJS("")))
+		return true
+	case "/" + graph.FileName:
+	default:
+		return false
+	}
+
+	// Every language's pages, as the build writes them
+	sites, codes, err := s.languageSites()
+	if err != nil {
+		http.Error(w, "Failed to scan site", http.StatusInternalServerError)
+		return true
+	}
+	var pages []graph.Page
+	for _, lang := range codes {
+		for _, node := range sites[lang].AllPages {
+			mdContent, err := s.fs.ReadFile(s.nodeFile(node))
+			if err != nil {
+				continue
+			}
+			title, content, fm, err := s.renderNodeEntry(node)
+			if err != nil {
+				continue
+			}
+			relDir := filepath.Dir(node.Path)
+			sourceDir := "/"
+			if relDir != "." && relDir != "" {
+				sourceDir = "/" + tree.SlugifyPath(relDir) + "/"
+			}
+			pages = append(pages, graph.Page{
+				Node:  node,
+				Title: title,
+				Links: markdown.ExtractPageLinks(content, string(mdContent), sourceDir, ""),
+				Tags:  fm.List("tags"),
+			})
+		}
+	}
+
+	data, err := graph.Build(pages, "").JSON()
+	if err != nil {
+		http.Error(w, "Failed to generate graph", http.StatusInternalServerError)
+		return true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(data)
+	return true
+}
+
+// tryGraphPage renders the full graph page. Real pages and folders at the
+// same URL are served first.
+func (s *DynamicServer) tryGraphPage(w http.ResponseWriter, urlPath string) bool {
+	if !s.config.Graph || urlPath != graph.URLPath {
+		return false
+	}
+	site, err := s.scan("")
+	if err != nil {
+		return false
+	}
+	messages := s.messages("")
+	return s.renderFolderPage(w, urlPath, site.Root, site, "", messages.T("graph"), graph.RenderPage(messages))
+}
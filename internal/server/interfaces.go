@@ -5,6 +5,7 @@ import (
 	"io/fs"
 	"os"
 
+	"github.com/wusher/volcano/internal/includes"
 	"github.com/wusher/volcano/internal/tree"
 )
 
@@ -41,10 +42,14 @@ func (osFileSystem) ReadFile(path string) ([]byte, error) {
 	return osReadFile(path)
 }
 
-// defaultScanner is the default TreeScanner using tree.Scan
-type defaultScanner struct{}
+// defaultScanner is the default TreeScanner using tree.Scan, leaving out
+// configured include files
+type defaultScanner struct {
+	includes  includes.Paths
+	languages []string // Translation languages, whose page.<lang>.md files are left out
+}
 
 // Scan implements TreeScanner
-func (defaultScanner) Scan(dir string) (*tree.Site, error) {
-	return tree.Scan(dir)
+func (s defaultScanner) Scan(dir string) (*tree.Site, error) {
+	return s.includes.ScanLanguage(dir, "", s.languages)
 }
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/includes"
+	"github.com/wusher/volcano/internal/templates"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// languageOf returns the translation language a URL path belongs to
+// ("/fr/guides/" -> "fr"), or "" for the default language
+func (s *DynamicServer) languageOf(urlPath string) string {
+	first := strings.TrimPrefix(urlPath, "/")
+	if i := strings.IndexByte(first, '/'); i >= 0 {
+		first = first[:i]
+	}
+	for _, lang := range s.languages {
+		if first == lang {
+			return lang
+		}
+	}
+	return ""
+}
+
+// scan scans the pages of one language ("" for the default language)
+func (s *DynamicServer) scan(lang string) (*tree.Site, error) {
+	if lang == "" {
+		return s.scanner.Scan(s.config.SourceDir)
+	}
+	return s.config.Includes.ScanLanguage(s.config.SourceDir, lang, s.languages)
+}
+
+// languageSites scans every language that has pages, default language first
+func (s *DynamicServer) languageSites() (map[string]*tree.Site, []string, error) {
+	sites := make(map[string]*tree.Site, len(s.languages)+1)
+	var codes []string
+	for _, lang := range append([]string{""}, s.languages...) {
+		site, err := s.scan(lang)
+		if err != nil {
+			return nil, nil, err
+		}
+		if lang != "" && len(site.AllPages) == 0 {
+			continue
+		}
+		sites[lang] = site
+		codes = append(codes, lang)
+	}
+	return sites, codes, nil
+}
+
+// nodeFile returns the markdown file of a page. Translated pages live next
+// to the page they translate (guides/intro.fr.md), not at their tree path.
+func (s *DynamicServer) nodeFile(node *tree.Node) string {
+	if s.languageOf("/"+filepath.ToSlash(node.Path)) != "" && node.SourcePath != "" {
+		return node.SourcePath
+	}
+	return filepath.Join(s.config.SourceDir, node.Path)
+}
+
+// messages returns the interface text for a language ("" for the default)
+func (s *DynamicServer) messages(lang string) i18n.Messages {
+	code := s.code(lang)
+	return i18n.Load(code, s.config.Translations[code])
+}
+
+// code returns the language code of lang, mapping "" to the default language
+func (s *DynamicServer) code(lang string) string {
+	if lang == "" {
+		return s.defaultLang
+	}
+	return lang
+}
+
+// applyLanguage sets a language's text, direction and includes on page
+// data, with the language switcher and hreflang links for urlPath (empty
+// for pages that have no translations)
+func (s *DynamicServer) applyLanguage(data *templates.PageData, lang, urlPath string) {
+	code := s.code(lang)
+	data.Lang = code
+	data.Dir = i18n.Dir(code)
+	data.I18n = s.messages(lang)
+	s.loadIncludes(lang).Apply(data)
+	if urlPath == "" || len(s.languages) == 0 {
+		return
+	}
+
+	sites, codes, err := s.languageSites()
+	if err != nil || len(codes) < 2 {
+		return
+	}
+	names := make(map[string]string, len(codes))
+	var translationCodes []string
+	for _, c := range codes {
+		names[s.code(c)] = s.messages(c).T("languageName")
+		if c != "" {
+			translationCodes = append(translationCodes, c)
+		}
+	}
+	translations := i18n.NewTranslations(s.defaultLang, translationCodes, names)
+	for _, c := range codes {
+		translations.AddSite(s.code(c), sites[c])
+	}
+	data.Translations = translations.Alternates(urlPath, code, "")
+	data.MetaTags += i18n.HreflangLinks(data.Translations, "")
+}
+
+// loadIncludes re-reads the header, footer and banner (or their
+// translations) so edits show up on refresh. Errors are logged and the
+// page renders without includes.
+func (s *DynamicServer) loadIncludes(lang string) *includes.Includes {
+	inc, err := includes.LoadLanguage(s.config.SourceDir, s.config.Includes, lang, s.transformer)
+	if err != nil {
+		s.logError("%v", err)
+		return nil
+	}
+	return inc
+}
+
+// findPageByURL finds the page served at urlPath
+func findPageByURL(site *tree.Site, urlPath string) *tree.Node {
+	if !strings.HasSuffix(urlPath, "/") {
+		urlPath += "/"
+	}
+	for _, page := range site.AllPages {
+		if tree.GetURLPath(page) == urlPath {
+			return page
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"net/http"
+	"path/filepath"
+
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/listing"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// listing builds the listing of a folder from the current sources, or
+// returns nil if the folder has no listing settings or it can't be built
+func (s *DynamicServer) listing(site *tree.Site, folder *tree.Node, lang string) *listing.Listing {
+	cfg := config.ResolveFolderConfig(s.config.Folders, filepath.Join(folder.Path, "index.md")).Listing
+	if cfg == nil {
+		return nil
+	}
+	l, err := listing.Build(folder, site.AllPages, *cfg, "", s.messages(lang), s.renderNodeEntry)
+	if err != nil {
+		s.logError("Failed to build listing for %s: %v", folder.Path, err)
+		return nil
+	}
+	return l
+}
+
+// tryListingPage renders a later page of a folder's listing (/blog/page/2/)
+func (s *DynamicServer) tryListingPage(w http.ResponseWriter, urlPath string) bool {
+	folderURL, n, ok := listing.ParsePageURL(urlPath)
+	if !ok {
+		return false
+	}
+
+	lang := s.languageOf(urlPath)
+	site, err := s.scan(lang)
+	if err != nil {
+		return false
+	}
+	folder := findFolderByPath(site.Root, folderURL)
+	if folder == nil {
+		return false
+	}
+	l := s.listing(site, folder, lang)
+	if l == nil {
+		return false
+	}
+	if _, ok := l.Page(n); !ok {
+		return false
+	}
+
+	messages := s.messages(lang)
+	title := l.Title + " – " + messages.PageOf(n, len(l.Pages))
+	return s.renderFolderPage(w, urlPath, folder, site, lang, title, l.RenderPage(n, messages))
+}
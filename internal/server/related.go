@@ -0,0 +1,33 @@
+package server
+
+import (
+	"html/template"
+
+	"github.com/wusher/volcano/internal/i18n"
+	"github.com/wusher/volcano/internal/related"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// relatedPages renders the related pages of node, comparing it with every
+// page of the site as rendered from the current sources
+func (s *DynamicServer) relatedPages(site *tree.Site, node *tree.Node, fm tree.FrontMatter, messages i18n.Messages) template.HTML {
+	if _, ok := fm["related"]; !ok && s.config.Related <= 0 {
+		return ""
+	}
+
+	pages := make([]related.Page, 0, len(site.AllPages))
+	for _, page := range site.AllPages {
+		title, content, pageFM, err := s.renderNodeEntry(page)
+		if err != nil {
+			s.logError("Failed to render %s for related pages: %v", page.Path, err)
+			continue
+		}
+		pages = append(pages, related.Page{Node: page, Title: title, Content: content, FrontMatter: pageFM})
+	}
+
+	links, err := related.New(site, pages, "", s.config.Related).Related(node)
+	if err != nil {
+		s.logError("%s: %v", node.Path, err)
+	}
+	return related.Render(links, messages)
+}
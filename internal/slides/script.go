@@ -0,0 +1,178 @@
+package slides
+
+import "github.com/wusher/volcano/internal/minify"
+
+// slidesJSRaw is the unminified JavaScript for the slide deck.
+// It provides:
+//   - Keyboard (arrows, space, Page Up/Down, Home/End), button and swipe navigation
+//   - The current slide number in the URL hash (#3)
+//   - Full-screen toggle (f)
+//   - A presenter window with notes, next slide and timer (p), kept in sync
+//     with the audience window over BroadcastChannel
+//   - An on-screen handout view with notes (h), also used for printing
+const slidesJSRaw = `
+(function() {
+    'use strict';
+
+    const deck = document.querySelector('.slide-deck');
+    if (!deck) return;
+    const slides = Array.prototype.slice.call(deck.querySelectorAll('.slide'));
+    if (slides.length === 0) return;
+
+    const isPresenter = new URLSearchParams(window.location.search).has('presenter');
+    const channel = 'BroadcastChannel' in window ? new BroadcastChannel('volcano-slides:' + window.location.pathname) : null;
+    const counter = document.querySelector('.slide-counter');
+    let current = 0;
+
+    function clamp(index) {
+        return Math.max(0, Math.min(slides.length - 1, index));
+    }
+
+    function slideFromHash() {
+        const n = parseInt(window.location.hash.slice(1), 10);
+        return isNaN(n) ? 0 : clamp(n - 1);
+    }
+
+    function show(index, broadcast) {
+        current = clamp(index);
+        slides.forEach(function(slide, i) {
+            slide.classList.toggle('active', i === current);
+            slide.setAttribute('aria-hidden', i === current ? 'false' : 'true');
+        });
+        if (counter) counter.textContent = (current + 1) + ' / ' + slides.length;
+
+        const hash = '#' + (current + 1);
+        if (window.location.hash !== hash) history.replaceState(null, '', hash);
+
+        if (isPresenter) updatePresenter();
+        if (broadcast !== false && channel) channel.postMessage({ slide: current });
+    }
+
+    // Presenter view: notes, next slide and elapsed time beside the current slide
+    const startedAt = Date.now();
+
+    function updatePresenter() {
+        const notes = document.querySelector('.presenter-notes');
+        const next = document.querySelector('.presenter-next');
+        if (notes) {
+            const source = slides[current].querySelector('.slide-notes');
+            notes.innerHTML = source ? source.innerHTML : '';
+        }
+        if (next) {
+            next.innerHTML = '';
+            if (current + 1 < slides.length) {
+                const preview = slides[current + 1].cloneNode(true);
+                preview.removeAttribute('id');
+                preview.classList.add('active');
+                next.appendChild(preview);
+            }
+        }
+    }
+
+    function updateTimer() {
+        const timer = document.querySelector('.presenter-timer');
+        if (!timer) return;
+        const seconds = Math.floor((Date.now() - startedAt) / 1000);
+        const mm = String(Math.floor(seconds / 60)).padStart(2, '0');
+        const ss = String(seconds % 60).padStart(2, '0');
+        timer.textContent = mm + ':' + ss;
+    }
+
+    function openPresenter() {
+        const url = window.location.pathname + '?presenter' + window.location.hash;
+        window.open(url, 'volcano-presenter', 'width=1100,height=700');
+    }
+
+    function toggleFullscreen() {
+        if (document.fullscreenElement) {
+            document.exitFullscreen();
+        } else if (document.documentElement.requestFullscreen) {
+            document.documentElement.requestFullscreen();
+        }
+    }
+
+    document.addEventListener('keydown', function(e) {
+        if (e.metaKey || e.ctrlKey || e.altKey) return;
+        switch (e.key) {
+            case 'ArrowRight':
+            case 'ArrowDown':
+            case 'PageDown':
+            case 'Enter':
+                show(current + 1);
+                break;
+            case ' ':
+                show(e.shiftKey ? current - 1 : current + 1);
+                break;
+            case 'ArrowLeft':
+            case 'ArrowUp':
+            case 'PageUp':
+            case 'Backspace':
+                show(current - 1);
+                break;
+            case 'Home':
+                show(0);
+                break;
+            case 'End':
+                show(slides.length - 1);
+                break;
+            case 'f':
+                toggleFullscreen();
+                break;
+            case 'p':
+                if (!isPresenter) openPresenter();
+                break;
+            case 'h':
+                document.body.classList.toggle('handout');
+                break;
+            default:
+                return;
+        }
+        e.preventDefault();
+    });
+
+    const prev = document.querySelector('.slide-prev');
+    const next = document.querySelector('.slide-next');
+    if (prev) prev.addEventListener('click', function() { show(current - 1); });
+    if (next) next.addEventListener('click', function() { show(current + 1); });
+
+    // Swipe on touch screens
+    let touchX = null;
+    deck.addEventListener('touchstart', function(e) {
+        touchX = e.touches[0].clientX;
+    }, { passive: true });
+    deck.addEventListener('touchend', function(e) {
+        if (touchX === null) return;
+        const dx = e.changedTouches[0].clientX - touchX;
+        touchX = null;
+        if (Math.abs(dx) > 50) show(dx < 0 ? current + 1 : current - 1);
+    }, { passive: true });
+
+    window.addEventListener('hashchange', function() {
+        show(slideFromHash());
+    });
+
+    if (channel) {
+        channel.onmessage = function(e) {
+            if (e.data && typeof e.data.slide === 'number' && e.data.slide !== current) {
+                show(e.data.slide, false);
+            }
+        };
+    }
+
+    if (isPresenter) {
+        document.body.classList.add('presenter');
+        updateTimer();
+        setInterval(updateTimer, 1000);
+    }
+
+    show(slideFromHash(), false);
+})();
+`
+
+// JS is the minified JavaScript for the slide deck.
+// It is initialized in init() to ensure proper package initialization order.
+var JS string
+
+func init() {
+	JS = minify.JS(slidesJSRaw)
+}
@@ -0,0 +1,88 @@
+// Package slides splits rendered page content into a slide deck.
+package slides
+
+import (
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// Slide is one slide of a deck
+type Slide struct {
+	Content template.HTML // Slide body
+	Notes   template.HTML // Speaker notes (from a "Note:" paragraph to the end of the slide)
+}
+
+// boundaryRegex matches everything the splitter cares about: block containers
+// (to track nesting), thematic breaks, H2 headings and "Note:" paragraphs
+var boundaryRegex = regexp.MustCompile(`(?i)<(/?)(blockquote|details|div|figure|li|ol|pre|section|table|ul)\b[^>]*>|<hr\s*/?>|<h2[\s>]|<p>\s*notes?:\s*`)
+
+// Split breaks rendered HTML into slides. A new slide starts at every
+// top-level <hr> (a "---" line in markdown) and at every top-level H2.
+// A paragraph starting with "Note:" begins the slide's speaker notes.
+// Content that produces no slides is returned as a single slide.
+func Split(html string) []Slide {
+	var slides []Slide
+	var content, notes strings.Builder
+	inNotes := false
+
+	flush := func() {
+		if strings.TrimSpace(content.String()) != "" || strings.TrimSpace(notes.String()) != "" {
+			slides = append(slides, Slide{
+				Content: template.HTML(strings.TrimSpace(content.String())),
+				Notes:   template.HTML(strings.TrimSpace(notes.String())),
+			})
+		}
+		content.Reset()
+		notes.Reset()
+		inNotes = false
+	}
+	write := func(s string) {
+		if inNotes {
+			notes.WriteString(s)
+		} else {
+			content.WriteString(s)
+		}
+	}
+
+	depth := 0
+	last := 0
+	for _, m := range boundaryRegex.FindAllStringSubmatchIndex(html, -1) {
+		// Block container: track nesting so only top-level boundaries split
+		if m[4] != -1 {
+			if m[3] > m[2] {
+				depth--
+			} else {
+				depth++
+			}
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+
+		write(html[last:m[0]])
+		tag := strings.ToLower(html[m[0]:m[1]])
+		switch {
+		case strings.HasPrefix(tag, "<hr"):
+			flush()
+			last = m[1]
+		case strings.HasPrefix(tag, "<h2"):
+			if strings.TrimSpace(content.String()) != "" || inNotes {
+				flush()
+			}
+			last = m[0]
+		default: // "Note:" paragraph
+			inNotes = true
+			notes.WriteString("<p>")
+			last = m[1]
+		}
+	}
+	write(html[last:])
+	flush()
+
+	if len(slides) == 0 {
+		return []Slide{{Content: template.HTML(html)}}
+	}
+	return slides
+}
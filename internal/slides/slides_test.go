@@ -0,0 +1,102 @@
+package slides
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Slide
+	}{
+		{
+			name:  "split on hr",
+			input: "<h1>Title</h1>\n<hr>\n<p>Two</p>\n<hr />\n<p>Three</p>",
+			want: []Slide{
+				{Content: "<h1>Title</h1>"},
+				{Content: "<p>Two</p>"},
+				{Content: "<p>Three</p>"},
+			},
+		},
+		{
+			name:  "split on h2",
+			input: "<h1>Deck</h1>\n<h2 id=\"a\">A</h2>\n<p>a</p>\n<h2 id=\"b\">B</h2>\n<p>b</p>",
+			want: []Slide{
+				{Content: "<h1>Deck</h1>"},
+				{Content: "<h2 id=\"a\">A</h2>\n<p>a</p>"},
+				{Content: "<h2 id=\"b\">B</h2>\n<p>b</p>"},
+			},
+		},
+		{
+			name:  "h2 right after hr does not add an empty slide",
+			input: "<p>intro</p>\n<hr>\n<h2>A</h2>\n<p>a</p>",
+			want: []Slide{
+				{Content: "<p>intro</p>"},
+				{Content: "<h2>A</h2>\n<p>a</p>"},
+			},
+		},
+		{
+			name:  "speaker notes",
+			input: "<h2>A</h2>\n<p>Note: say hello</p>\n<ul>\n<li>wave</li>\n</ul>\n<h2>B</h2>\n<p>NOTES:quiet</p>",
+			want: []Slide{
+				{Content: "<h2>A</h2>", Notes: "<p>say hello</p>\n<ul>\n<li>wave</li>\n</ul>"},
+				{Content: "<h2>B</h2>", Notes: "<p>quiet</p>"},
+			},
+		},
+		{
+			name:  "nested boundaries are ignored",
+			input: "<blockquote>\n<hr>\n<h2>Quote</h2>\n<p>Note: not notes</p>\n</blockquote>\n<div class=\"code-block\"><pre><code>x</code></pre></div>",
+			want: []Slide{
+				{Content: "<blockquote>\n<hr>\n<h2>Quote</h2>\n<p>Note: not notes</p>\n</blockquote>\n<div class=\"code-block\"><pre><code>x</code></pre></div>"},
+			},
+		},
+		{
+			name:  "leading and trailing hr",
+			input: "<hr>\n<p>only</p>\n<hr>",
+			want:  []Slide{{Content: "<p>only</p>"}},
+		},
+		{
+			name:  "empty content",
+			input: "",
+			want:  []Slide{{Content: ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Split(tt.input)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Split() returned %d slides, want %d: %q", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("slide %d = %q, want %q", i+1, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitKeepsHTMLType(t *testing.T) {
+	var content template.HTML = Split("<p>a</p>")[0].Content
+	if content != "<p>a</p>" {
+		t.Errorf("Content = %q", content)
+	}
+}
+
+func TestJSIsMinified(t *testing.T) {
+	if len(JS) >= len(slidesJSRaw) {
+		t.Errorf("JS should be minified (raw %d, minified %d)", len(slidesJSRaw), len(JS))
+	}
+	if strings.Contains(JS, "    ") {
+		t.Error("JS contains indentation, should be minified")
+	}
+	for _, s := range []string{".slide-deck", ".slide-counter", ".presenter-notes", "volcano-slides:", "presenter", "handout"} {
+		if !strings.Contains(JS, s) {
+			t.Errorf("JS should contain %q", s)
+		}
+	}
+}
@@ -70,6 +70,24 @@ func GetThemeCSS(theme string) string {
 	}
 }
 
+// themeDarkBackgrounds holds each theme's dark-mode --bg-primary, used to
+// derive a readable dark-mode accent. Vanilla has no dark palette.
+var themeDarkBackgrounds = map[string]string{
+	"docs":         "#000000",
+	"blog":         "#262626",
+	"presentation": "#000000",
+	"readable":     "#2b2620",
+}
+
+// DarkBackground returns the dark-mode page background of a theme, or ""
+// if the theme has no dark palette. Empty means the docs theme.
+func DarkBackground(theme string) string {
+	if theme == "" {
+		theme = "docs"
+	}
+	return themeDarkBackgrounds[theme]
+}
+
 // ValidateTheme checks if the given theme name is valid
 func ValidateTheme(theme string) error {
 	if theme == "" {
@@ -91,9 +109,11 @@ type CSSLoader interface {
 
 // CSSConfig holds configuration for loading CSS
 type CSSConfig struct {
-	Theme       string // Theme name (docs, blog, vanilla)
-	CSSPath     string // Path to custom CSS file (takes precedence over Theme)
-	AccentColor string // Custom accent color in hex format (e.g., "#ff6600")
+	Theme           string // Theme name (docs, blog, vanilla)
+	CSSPath         string // Path to custom CSS file (takes precedence over Theme)
+	AccentColor     string // Custom accent color in hex format (e.g., "#ff6600")
+	AccentColorDark string // Dark-mode accent color (derived from AccentColor if empty)
+	FontCSS         string // @font-face rules for self-hosted fonts, appended last
 }
 
 // cssLoader implements CSSLoader
@@ -131,15 +151,25 @@ func (l *cssLoader) LoadCSS() (string, error) {
 		}
 	}
 
-	// Append accent color CSS if configured
-	if l.config.AccentColor != "" {
-		accentCSS, err := color.GenerateAccentCSS(l.config.AccentColor)
+	// Append accent color CSS if configured. The dark-mode accent is derived
+	// against the theme's dark background; a custom stylesheet's background
+	// is unknown, so only an explicit AccentColorDark applies there.
+	if l.config.AccentColor != "" || l.config.AccentColorDark != "" {
+		darkBackground := ""
+		if l.config.CSSPath == "" {
+			darkBackground = DarkBackground(l.config.Theme)
+		}
+		accentCSS, err := color.GenerateAccentCSS(l.config.AccentColor, l.config.AccentColorDark, darkBackground)
 		if err != nil {
 			return "", fmt.Errorf("invalid accent color: %w", err)
 		}
 		css = css + "\n" + accentCSS
 	}
 
+	if l.config.FontCSS != "" {
+		css = css + "\n" + l.config.FontCSS
+	}
+
 	return MinifyCSS(css)
 }
 
@@ -303,6 +303,66 @@ func TestCSSLoader_LoadCSS_WithAccentColor(t *testing.T) {
 	}
 }
 
+func TestCSSLoader_LoadCSS_WithAccentColorDark(t *testing.T) {
+	config := CSSConfig{
+		Theme:           "docs",
+		AccentColor:     "sky",
+		AccentColorDark: "#fbbf24",
+	}
+
+	loader := NewCSSLoader(config, func(_ string) ([]byte, error) {
+		return nil, errors.New("file not found")
+	})
+
+	css, err := loader.LoadCSS()
+	if err != nil {
+		t.Fatalf("LoadCSS() error = %v", err)
+	}
+	if !strings.Contains(css, "#0ea5e9") || !strings.Contains(css, "#fbbf24") {
+		t.Error("LoadCSS() should contain both light and dark accent colors")
+	}
+}
+
+func TestCSSLoader_LoadCSS_DerivesDarkAccent(t *testing.T) {
+	// Indigo-900 is unreadable on the docs theme's black background
+	config := CSSConfig{Theme: "docs", AccentColor: "#312e81"}
+	loader := NewCSSLoader(config, func(_ string) ([]byte, error) {
+		return nil, errors.New("file not found")
+	})
+	css, err := loader.LoadCSS()
+	if err != nil {
+		t.Fatalf("LoadCSS() error = %v", err)
+	}
+	if strings.Contains(css, `:root,[data-theme="dark"]`) || strings.Count(css, "--accent:") < 2 {
+		t.Error("LoadCSS() should emit a separate dark-mode accent")
+	}
+
+	// A custom stylesheet's background is unknown, so nothing is derived
+	config = CSSConfig{CSSPath: "custom.css", AccentColor: "#312e81"}
+	loader = NewCSSLoader(config, func(_ string) ([]byte, error) {
+		return []byte("body{color:red}"), nil
+	})
+	css, err = loader.LoadCSS()
+	if err != nil {
+		t.Fatalf("LoadCSS() error = %v", err)
+	}
+	if !strings.Contains(css, "--accent:#312e81") || strings.Count(css, "--accent:") != 1 {
+		t.Errorf("custom CSS should share one accent rule, got accent count %d", strings.Count(css, "--accent:"))
+	}
+}
+
+func TestDarkBackground(t *testing.T) {
+	if got := DarkBackground(""); got != "#000000" {
+		t.Errorf("DarkBackground(\"\") = %q, want docs background", got)
+	}
+	if got := DarkBackground("blog"); got != "#262626" {
+		t.Errorf("DarkBackground(blog) = %q", got)
+	}
+	if got := DarkBackground("vanilla"); got != "" {
+		t.Errorf("DarkBackground(vanilla) = %q, want empty", got)
+	}
+}
+
 func TestCSSLoader_LoadCSS_InvalidAccentColor(t *testing.T) {
 	config := CSSConfig{
 		Theme:       "docs",
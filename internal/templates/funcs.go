@@ -0,0 +1,132 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wusher/volcano/internal/slides"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// FuncMap returns the helper functions available to layouts and partials.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		// Mark trusted strings as safe for their output context
+		"safeHTML": func(s string) template.HTML { return template.HTML(s) }, //nolint:gosec // explicit opt-in by template authors
+		"safeCSS":  func(s string) template.CSS { return template.CSS(s) },   //nolint:gosec // explicit opt-in by template authors
+		"safeJS":   func(s string) template.JS { return template.JS(s) },     //nolint:gosec // explicit opt-in by template authors
+		"safeURL":  func(s string) template.URL { return template.URL(s) },   //nolint:gosec // explicit opt-in by template authors
+
+		// String helpers
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"trim":      strings.TrimSpace,
+		"contains":  strings.Contains,
+		"hasPrefix": strings.HasPrefix,
+		"hasSuffix": strings.HasSuffix,
+		"replace":   strings.ReplaceAll,
+		"split":     strings.Split,
+		"join":      strings.Join,
+
+		// relURL prefixes a site path with the base URL: {{relURL .BaseURL "/about/"}}
+		"relURL": relURL,
+
+		// default returns fallback when value is empty: {{default "Docs" .SiteTitle}}
+		"default": defaultValue,
+
+		// add sums two integers, e.g. for 1-based counters: {{add $i 1}}
+		"add": func(a, b int) int { return a + b },
+
+		// slides splits page content into slides: {{range slides .Content}}
+		"slides": func(content template.HTML) []slides.Slide { return slides.Split(string(content)) },
+
+		// year returns the current year, e.g. for copyright lines
+		"year": func() int { return time.Now().Year() },
+	}
+}
+
+// relURL joins a base URL path (e.g. "/volcano") and a site path
+func relURL(baseURL, path string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return tree.PrefixURL(baseURL, path)
+}
+
+// defaultValue returns value unless it is the zero value of its type
+func defaultValue(fallback, value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return fallback
+	case string:
+		if v == "" {
+			return fallback
+		}
+	case template.HTML:
+		if v == "" {
+			return fallback
+		}
+	case bool:
+		if !v {
+			return fallback
+		}
+	case int:
+		if v == 0 {
+			return fallback
+		}
+	}
+	return value
+}
+
+// TemplateError describes a template failure at a specific file and line
+type TemplateError struct {
+	File    string // Source file of the failing template
+	Line    int    // 1-based line number (0 if unknown)
+	Message string // Underlying error message without the location prefix
+}
+
+// Error implements the error interface as "file:line: message"
+func (e *TemplateError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Message)
+}
+
+// templateLocationRegex matches the location prefix Go's template packages put on errors:
+//
+//	template: footer:12: function "foo" not defined
+//	template: footer:12:5: executing "footer" at <.Foo>: can't evaluate field Foo
+//	html/template:footer:12:5: {{.X}} appears in an ambiguous context
+var templateLocationRegex = regexp.MustCompile(`(?s)^(?:html/)?template: ?([^:\s]+):(\d+)(?::\d+)?: (.*)$`)
+
+// wrapTemplateError rewrites a template error so it points at the source file
+// and line. Errors that don't carry a location are returned unchanged.
+func wrapTemplateError(err error, files map[string]string) error {
+	var tmplErr *TemplateError
+	if errors.As(err, &tmplErr) {
+		return err
+	}
+
+	matches := templateLocationRegex.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return err
+	}
+
+	file, ok := files[matches[1]]
+	if !ok {
+		return err
+	}
+	line, _ := strconv.Atoi(matches[2])
+
+	return &TemplateError{
+		File:    file,
+		Line:    line,
+		Message: matches[3],
+	}
+}
@@ -0,0 +1,101 @@
+package templates
+
+import (
+	"errors"
+	"html/template"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRelURL(t *testing.T) {
+	tests := []struct {
+		base, path, want string
+	}{
+		{"", "/about/", "/about/"},
+		{"", "about/", "/about/"},
+		{"/docs", "/about/", "/docs/about/"},
+		{"/docs", "/", "/docs/"},
+	}
+	for _, tc := range tests {
+		if got := relURL(tc.base, tc.path); got != tc.want {
+			t.Errorf("relURL(%q, %q) = %q, want %q", tc.base, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestDefaultValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{"nil", nil, "fallback"},
+		{"empty string", "", "fallback"},
+		{"string", "set", "set"},
+		{"empty html", template.HTML(""), "fallback"},
+		{"false", false, "fallback"},
+		{"true", true, true},
+		{"zero", 0, "fallback"},
+		{"int", 3, 3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := defaultValue("fallback", tc.value); got != tc.want {
+				t.Errorf("defaultValue() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFuncMapHelpers(t *testing.T) {
+	funcs := FuncMap()
+	for _, name := range []string{"safeHTML", "safeCSS", "safeJS", "safeURL", "lower", "upper", "trim", "contains", "hasPrefix", "hasSuffix", "replace", "split", "join", "relURL", "default", "add", "slides", "year"} {
+		if _, ok := funcs[name]; !ok {
+			t.Errorf("FuncMap() missing %q", name)
+		}
+	}
+
+	tmpl := template.Must(template.New("t").Funcs(funcs).Parse(`{{safeHTML "<b>x</b>"}}|{{year}}|{{add 1 2}}|{{len (slides (safeHTML "<p>a</p><hr><p>b</p>"))}}`))
+	var out strings.Builder
+	if err := tmpl.Execute(&out, nil); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := "<b>x</b>|" + strconv.Itoa(time.Now().Year()) + "|3|2"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestWrapTemplateError(t *testing.T) {
+	files := map[string]string{"footer": "_layouts/footer.html"}
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"parse error", errors.New(`template: footer:3: unexpected "}" in operand`), `_layouts/footer.html:3: unexpected "}" in operand`},
+		{"exec error", errors.New(`template: footer:7:12: executing "footer" at <.X>: can't evaluate field X`), `_layouts/footer.html:7: executing "footer" at <.X>: can't evaluate field X`},
+		{"escape error", errors.New(`html/template:footer:2:5: {{.}} appears in an ambiguous context`), `_layouts/footer.html:2: {{.}} appears in an ambiguous context`},
+		{"unknown template", errors.New(`template: other:1: boom`), `template: other:1: boom`},
+		{"no location", errors.New("boom"), "boom"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := wrapTemplateError(tc.err, files).Error(); got != tc.want {
+				t.Errorf("wrapTemplateError() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	// Already-wrapped errors pass through unchanged
+	wrapped := &TemplateError{File: "x.html", Message: "m"}
+	if got := wrapTemplateError(wrapped, files); got != wrapped {
+		t.Error("wrapTemplateError() should not re-wrap a TemplateError")
+	}
+	if wrapped.Error() != "x.html: m" {
+		t.Errorf("Error() without line = %q", wrapped.Error())
+	}
+}
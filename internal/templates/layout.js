@@ -55,6 +55,21 @@ document.addEventListener('click', function(e) {
     }
 });
 
+// Dismiss the site banner; it stays hidden until its content changes.
+// Delegated so it keeps working after instant navigation swaps the content.
+document.addEventListener('click', function(e) {
+    const close = e.target.closest && e.target.closest('.site-banner-close');
+    if (!close) return;
+    const banner = close.closest('.site-banner');
+    if (!banner) return;
+    try {
+        localStorage.setItem('banner-dismissed', banner.dataset.bannerId);
+    } catch (err) {
+        // Storage unavailable: hide for this page view only
+    }
+    banner.hidden = true;
+});
+
 // Close mobile TOC when clicking a TOC link
 (function() {
     const tocSidebar = document.querySelector('.toc-sidebar');
@@ -67,22 +82,28 @@ document.addEventListener('click', function(e) {
     }
 })();
 
-// Tree navigation toggle (uses event delegation to survive instant nav)
-document.querySelector('.tree-nav').addEventListener('click', function(e) {
-    const toggle = e.target.closest('.folder-toggle');
-    if (!toggle) return;
-    e.preventDefault();
-    const li = toggle.closest('li');
-    li.classList.toggle('expanded');
-});
+// Tree navigation (uses event delegation to survive instant nav). Layouts
+// without the sidebar have no tree navigation.
+(function() {
+    const nav = document.querySelector('.tree-nav');
+    if (!nav) return;
 
-// Close drawer on mobile when navigation link is clicked
-document.querySelector('.tree-nav').addEventListener('click', function(e) {
-    const link = e.target.closest('a.file-link, a.folder-link');
-    if (!link) return;
-    // Close the mobile drawer when a link is clicked
-    closeDrawer();
-});
+    // Folder toggle
+    nav.addEventListener('click', function(e) {
+        const toggle = e.target.closest('.folder-toggle');
+        if (!toggle) return;
+        e.preventDefault();
+        const li = toggle.closest('li');
+        li.classList.toggle('expanded');
+    });
+
+    // Close drawer on mobile when navigation link is clicked
+    nav.addEventListener('click', function(e) {
+        const link = e.target.closest('a.file-link, a.folder-link');
+        if (!link) return;
+        closeDrawer();
+    });
+})();
 
 // Expand path to current page
 function expandActivePath() {
@@ -0,0 +1,158 @@
+package templates
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/wusher/volcano/internal/markdown"
+)
+
+// Built-in page layouts. Each one renders the standard "layout" template with
+// parts of the page chrome switched off. Any other layout name must match a
+// template in the layouts directory (layout: dashboard -> _layouts/dashboard.html).
+const (
+	LayoutDefault = "default" // Sidebar, TOC and breadcrumbs as configured
+	LayoutLanding = "landing" // No sidebar, TOC or breadcrumbs
+	LayoutWide    = "wide"    // No TOC; content spans the full width
+	LayoutSlides  = "slides"  // Full-screen slide deck, split on --- and H2
+	LayoutPrint   = "print"   // Printable book: cover, contents and chapters, no chrome
+)
+
+// builtinLayouts maps each built-in layout to the chrome it hides
+var builtinLayouts = map[string]Layout{
+	LayoutDefault: {},
+	LayoutLanding: {HideSidebar: true, HideTOC: true, HideBreadcrumbs: true},
+	LayoutWide:    {HideTOC: true, Wide: true},
+	LayoutSlides:  {HideSidebar: true, HideTOC: true, HideBreadcrumbs: true},
+	LayoutPrint:   {HideSidebar: true, HideTOC: true, HideBreadcrumbs: true},
+}
+
+// builtinLayoutTemplates maps built-in layouts that have their own skeleton
+// to its template; the others render "layout"
+var builtinLayoutTemplates = map[string]string{
+	LayoutSlides: "slides",
+	LayoutPrint:  "print",
+}
+
+// ThemeLayout returns the layout a theme uses for pages that set none
+// themselves: the presentation theme turns pages into slide decks.
+func ThemeLayout(theme string) string {
+	if theme == "presentation" {
+		return LayoutSlides
+	}
+	return ""
+}
+
+// Layout describes how a page is framed: the layout it uses and which
+// parts of the built-in chrome are hidden
+type Layout struct {
+	Name            string // Layout name ("" means default)
+	HideSidebar     bool   // Hide the navigation sidebar
+	HideTOC         bool   // Hide the table of contents
+	HideBreadcrumbs bool   // Hide breadcrumbs
+	Wide            bool   // Let content use the full width
+}
+
+// BuiltinLayoutNames returns the names of the built-in layouts in sorted order
+func BuiltinLayoutNames() []string {
+	names := make([]string, 0, len(builtinLayouts))
+	for name := range builtinLayouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveLayout picks the layout for a page. Front matter `layout:` wins over
+// the folder default; the front matter switches `sidebar:`, `toc:` and
+// `breadcrumbs:` then turn individual parts of the chrome on or off.
+func ResolveLayout(fm markdown.FrontMatter, folderDefault string) Layout {
+	name := strings.TrimSpace(fm.String("layout"))
+	if name == "" {
+		name = strings.TrimSpace(folderDefault)
+	}
+
+	layout := builtinLayouts[name]
+	layout.Name = name
+
+	if show, ok := fm.Bool("sidebar"); ok {
+		layout.HideSidebar = !show
+	}
+	if show, ok := fm.Bool("toc"); ok {
+		layout.HideTOC = !show
+	}
+	if show, ok := fm.Bool("breadcrumbs"); ok {
+		layout.HideBreadcrumbs = !show
+	}
+
+	return layout
+}
+
+// Apply copies the layout onto page data
+func (l Layout) Apply(data *PageData) {
+	data.Layout = l.Name
+	data.HideSidebar = l.HideSidebar
+	data.HideTOC = l.HideTOC
+	data.HideBreadcrumbs = l.HideBreadcrumbs
+	data.Wide = l.Wide
+}
+
+// BodyClass returns the classes for the <body> element, reflecting the
+// top navigation and the page layout
+func (d PageData) BodyClass() string {
+	var classes []string
+	if len(d.TopNavItems) > 0 {
+		classes = append(classes, "has-top-nav")
+	}
+	if d.Layout != "" && d.Layout != LayoutDefault {
+		classes = append(classes, "layout-"+d.Layout)
+	}
+	if d.HideSidebar {
+		classes = append(classes, "no-sidebar")
+	}
+	if d.Wide {
+		classes = append(classes, "wide")
+	}
+	return strings.Join(classes, " ")
+}
+
+// ShowTOC reports whether the page has a table of contents and its layout shows it
+func (d PageData) ShowTOC() bool {
+	return d.HasTOC && !d.HideTOC
+}
+
+// ValidateLayout returns an error if the named layout is neither built in
+// nor defined in the layouts directory
+func (r *Renderer) ValidateLayout(name string) error {
+	_, err := r.layoutTemplate(name)
+	return err
+}
+
+// layoutTemplate returns the name of the template that renders a layout.
+// A user template with the layout's name takes precedence over a built-in layout.
+func (r *Renderer) layoutTemplate(name string) (string, error) {
+	if name == "" || name == LayoutDefault {
+		return "layout", nil
+	}
+	if !isPartialName(name) && r.HasTemplate(name) {
+		return name, nil
+	}
+	if _, ok := builtinLayouts[name]; ok {
+		if tmpl, ok := builtinLayoutTemplates[name]; ok {
+			return tmpl, nil
+		}
+		return "layout", nil
+	}
+	return "", fmt.Errorf("unknown layout %q (built-in layouts: %s)", name, strings.Join(BuiltinLayoutNames(), ", "))
+}
+
+// isPartialName reports whether name is one of the built-in partials
+func isPartialName(name string) bool {
+	for _, partial := range PartialNames {
+		if name == partial {
+			return true
+		}
+	}
+	return false
+}
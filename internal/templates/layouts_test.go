@@ -0,0 +1,307 @@
+package templates
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/wusher/volcano/internal/markdown"
+)
+
+func TestResolveLayout(t *testing.T) {
+	tests := []struct {
+		name          string
+		fm            markdown.FrontMatter
+		folderDefault string
+		want          Layout
+	}{
+		{
+			name: "no front matter or folder default",
+			want: Layout{},
+		},
+		{
+			name:          "folder default",
+			folderDefault: "wide",
+			want:          Layout{Name: "wide", HideTOC: true, Wide: true},
+		},
+		{
+			name:          "front matter wins over folder default",
+			fm:            markdown.FrontMatter{"layout": "landing"},
+			folderDefault: "wide",
+			want:          Layout{Name: "landing", HideSidebar: true, HideTOC: true, HideBreadcrumbs: true},
+		},
+		{
+			name: "user layout has no built-in switches",
+			fm:   markdown.FrontMatter{"layout": "dashboard"},
+			want: Layout{Name: "dashboard"},
+		},
+		{
+			name: "individual switches",
+			fm:   markdown.FrontMatter{"sidebar": "false", "toc": "no", "breadcrumbs": "off"},
+			want: Layout{HideSidebar: true, HideTOC: true, HideBreadcrumbs: true},
+		},
+		{
+			name: "switches override the layout",
+			fm:   markdown.FrontMatter{"layout": "landing", "sidebar": "true"},
+			want: Layout{Name: "landing", HideTOC: true, HideBreadcrumbs: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveLayout(tt.fm, tt.folderDefault); got != tt.want {
+				t.Errorf("ResolveLayout() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuiltinLayoutNames(t *testing.T) {
+	got := strings.Join(BuiltinLayoutNames(), ",")
+	if got != "default,landing,print,slides,wide" {
+		t.Errorf("BuiltinLayoutNames() = %q", got)
+	}
+}
+
+func TestPageDataBodyClass(t *testing.T) {
+	tests := []struct {
+		name string
+		data PageData
+		want string
+	}{
+		{"default", PageData{}, ""},
+		{"explicit default", PageData{Layout: "default"}, ""},
+		{"top nav", PageData{TopNavItems: []TopNavItem{{Name: "A", URL: "/a/"}}}, "has-top-nav"},
+		{"landing", PageData{Layout: "landing", HideSidebar: true}, "layout-landing no-sidebar"},
+		{"wide", PageData{Layout: "wide", Wide: true}, "layout-wide wide"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.data.BodyClass(); got != tt.want {
+				t.Errorf("BodyClass() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderLayouts(t *testing.T) {
+	r, err := NewRenderer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := PageData{
+		SiteTitle:   "Site",
+		PageTitle:   "Page",
+		Content:     "<p>Body</p>",
+		Navigation:  "<ul></ul>",
+		Breadcrumbs: `<nav class="breadcrumbs">crumbs</nav>`,
+		TOC:         `<aside class="toc-sidebar">toc</aside>`,
+		HasTOC:      true,
+	}
+
+	html, err := r.RenderToString(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`class="sidebar"`, "crumbs", `class="toc-sidebar"`, "main-wrapper has-toc", "mobile-menu-btn"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("default layout should contain %q", want)
+		}
+	}
+
+	landing := base
+	ResolveLayout(markdown.FrontMatter{"layout": "landing"}, "").Apply(&landing)
+	html, err = r.RenderToString(landing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, unwanted := range []string{`<aside class="sidebar"`, "crumbs", `class="toc-sidebar"`, "has-toc", `<button class="mobile-menu-btn"`, `<button class="mobile-toc-toggle"`} {
+		if strings.Contains(html, unwanted) {
+			t.Errorf("landing layout should not contain %q", unwanted)
+		}
+	}
+	if !strings.Contains(html, `<body class="layout-landing no-sidebar" data-layout="landing">`) {
+		t.Error("landing layout should set body classes")
+	}
+	if !strings.Contains(html, "<p>Body</p>") {
+		t.Error("landing layout should render content")
+	}
+
+	wide := base
+	ResolveLayout(nil, "wide").Apply(&wide)
+	html, err = r.RenderToString(wide)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, `class="sidebar"`) || strings.Contains(html, `class="toc-sidebar"`) {
+		t.Error("wide layout should keep the sidebar and drop the TOC")
+	}
+}
+
+func TestRenderUserLayout(t *testing.T) {
+	dir := t.TempDir()
+	writeLayout(t, dir, "dashboard.html", `<html><body class="dash">{{.Content}}{{template "page-meta" .}}</body></html>`)
+	writeLayout(t, dir, "landing.html", `<main class="my-landing">{{.Content}}</main>`)
+
+	r, err := NewRendererWithLayouts("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	html, err := r.RenderToString(PageData{Layout: "dashboard", Content: "<p>Stats</p>", ReadingTime: "1 min read"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, `<body class="dash"><p>Stats</p>`) || !strings.Contains(html, "1 min read") {
+		t.Errorf("user layout not used: %s", html)
+	}
+
+	// A user template named after a built-in layout replaces it
+	html, err = r.RenderToString(PageData{Layout: "landing", Content: "<p>Hi</p>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html != `<main class="my-landing"><p>Hi</p></main>` {
+		t.Errorf("user landing layout not used: %s", html)
+	}
+}
+
+func TestValidateLayout(t *testing.T) {
+	r, err := NewRenderer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"", "default", "landing", "wide"} {
+		if err := r.ValidateLayout(name); err != nil {
+			t.Errorf("ValidateLayout(%q) error = %v", name, err)
+		}
+	}
+
+	// Partials are not layouts
+	for _, name := range []string{"dashboard", "footer"} {
+		err := r.ValidateLayout(name)
+		if err == nil {
+			t.Errorf("ValidateLayout(%q) should fail", name)
+			continue
+		}
+		if !strings.Contains(err.Error(), "default, landing, print, slides, wide") {
+			t.Errorf("error should list built-in layouts, got %v", err)
+		}
+	}
+
+	if _, err := r.RenderToString(PageData{Layout: "missing"}); err == nil {
+		t.Error("Render() should fail for an unknown layout")
+	}
+}
+
+func TestRenderSlidesLayout(t *testing.T) {
+	r, err := NewRenderer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := PageData{
+		PageTitle: "Talk",
+		Content:   "<h1>Talk</h1>\n<h2 id=\"one\">One</h2>\n<p>Note: remember</p>\n<hr>\n<p>Two</p>",
+		TOC:       `<aside class="toc-sidebar">toc</aside>`,
+		HasTOC:    true,
+	}
+	ResolveLayout(nil, LayoutSlides).Apply(&data)
+
+	html, err := r.RenderToString(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<body class="layout-slides no-sidebar"`,
+		`<section class="slide active" id="slide-1" aria-roledescription="slide" aria-label="1">`,
+		`id="slide-2"`,
+		`id="slide-3"`,
+		`<aside class="slide-notes">`,
+		"<p>remember</p>",
+		`class="slide-counter"`,
+		`class="presenter-panel"`,
+		"volcano-slides:",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("slides layout should contain %q", want)
+		}
+	}
+	if strings.Contains(html, `id="slide-4"`) {
+		t.Error("slides layout should have 3 slides")
+	}
+	if strings.Contains(html, `class="toc-sidebar"`) || strings.Contains(html, `<aside class="sidebar"`) {
+		t.Error("slides layout should not render the sidebar or TOC")
+	}
+}
+
+func TestRenderPrintLayout(t *testing.T) {
+	r, err := NewRenderer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := PageData{
+		SiteTitle: "Docs",
+		PageTitle: "Runbooks",
+		Content:   `<section class="print-chapter prose" id="page-runbooks"><h1>Runbooks</h1></section>`,
+		TOC:       `<nav class="print-toc" aria-label="Contents"></nav>`,
+	}
+	ResolveLayout(nil, LayoutPrint).Apply(&data)
+
+	html, err := r.RenderToString(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<body class="layout-print no-sidebar"`,
+		`<main class="print-book">`,
+		`<p class="print-site">Docs</p>`,
+		`<nav class="print-toc"`,
+		`<section class="print-chapter prose" id="page-runbooks">`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("print layout should contain %q", want)
+		}
+	}
+	if strings.Contains(html, `<aside class="sidebar"`) || strings.Contains(html, "tree-nav") {
+		t.Error("print layout should not render the sidebar")
+	}
+}
+
+func TestThemeLayout(t *testing.T) {
+	if got := ThemeLayout("presentation"); got != LayoutSlides {
+		t.Errorf("ThemeLayout(presentation) = %q, want %q", got, LayoutSlides)
+	}
+	for _, theme := range []string{"", "docs", "blog"} {
+		if got := ThemeLayout(theme); got != "" {
+			t.Errorf("ThemeLayout(%q) = %q, want empty", theme, got)
+		}
+	}
+}
+
+func TestLandingLayoutScript(t *testing.T) {
+	r, err := NewRenderer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := PageData{SiteTitle: "Site", PageTitle: "Page", Content: "<p>Body</p>", Navigation: `<nav class="tree-nav"></nav>`}
+	ResolveLayout(markdown.FrontMatter{"layout": "landing"}, "").Apply(&data)
+	html, err := r.RenderToString(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html, `class="tree-nav"`) {
+		t.Fatal("landing layout should not render the tree navigation")
+	}
+
+	// The inline script must not stop at elements the layout leaves out, or
+	// everything after it (code copy, TOC, keyboard navigation) breaks
+	unguarded := regexp.MustCompile(`document\.querySelector\(["']([^"']+)["']\)\.`)
+	for _, match := range unguarded.FindAllStringSubmatch(html, -1) {
+		t.Errorf("inline script uses %s without checking it exists", match[1])
+	}
+}
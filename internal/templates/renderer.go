@@ -4,25 +4,66 @@ package templates
 import (
 	"bytes"
 	"embed"
+	"fmt"
 	"html/template"
 	"io"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/wusher/volcano/internal/i18n"
 	"github.com/wusher/volcano/internal/minify"
+	"github.com/wusher/volcano/internal/slides"
 	"github.com/wusher/volcano/internal/tree"
+	"github.com/wusher/volcano/internal/versions"
 )
 
-//go:embed layout.html layout.js
+//go:embed layout.html slides.html print.html layout.js partials/*.html
 var layoutFS embed.FS
 
+// LayoutsDirName is the project directory searched for template overrides.
+const LayoutsDirName = "_layouts"
+
+// PartialNames lists the built-in partials that a layouts directory can override.
+// Each partial lives in a file named after it (e.g. _layouts/footer.html).
+// "layout" is the page skeleton that includes all the others; "slides" is the
+// skeleton for slide decks and "print" for printable books.
+var PartialNames = []string{"layout", "slides", "print", "head", "header", "sidebar", "banner", "page-meta", "footer", "scripts", "404"}
+
 // TopNavItem represents an item in the top navigation bar
 type TopNavItem struct {
-	Name string // Display name
-	URL  string // URL path
+	Name     string        // Display name
+	URL      string        // URL path (base-prefixed), or an external URL
+	Path     string        // Site path without the base prefix ("" for external links)
+	Match    string        // Path prefix that marks the item active ("" to match Path exactly)
+	Icon     template.HTML // Icon shown before the name
+	External bool          // Whether URL points off the site
+	Children []TopNavItem  // Dropdown items
+}
+
+// Active reports whether the page at currentPath belongs to the item or
+// one of its dropdown items
+func (i TopNavItem) Active(currentPath string) bool {
+	if i.Match != "" && strings.HasPrefix(currentPath, i.Match) {
+		return true
+	}
+	if i.Match == "" && i.Path != "" && currentPath == i.Path {
+		return true
+	}
+	for _, child := range i.Children {
+		if child.Active(currentPath) {
+			return true
+		}
+	}
+	return false
 }
 
-// PageData contains all data needed to render a page
+// PageData contains all data needed to render a page.
+//
+// PageData is the contract for user templates: every exported field below is
+// available as {{.FieldName}} in layout.html and in each partial. Fields typed
+// template.HTML are pre-rendered and safe to output as-is.
 type PageData struct {
 	SiteTitle       string        // Site title for header
 	PageTitle       string        // Current page title
@@ -34,10 +75,15 @@ type PageData struct {
 	JSURL           string        // External JS file URL (when set, InstantNavJS is ignored)
 	Breadcrumbs     template.HTML // Breadcrumb navigation
 	PageNav         template.HTML // Previous/Next navigation
+	Related         template.HTML // Related pages shown below the content
+	Graph           template.HTML // Local link graph shown below the content
 	TOC             template.HTML // Table of contents
 	MetaTags        template.HTML // SEO meta tags
 	FaviconLinks    template.HTML // Favicon link tags
+	FontPreloads    template.HTML // Preload link tags for self-hosted fonts
 	ReadingTime     string        // Reading time display (e.g., "5 min read")
+	Date            string        // Page date (2006-01-02), shown for pages in a date archive
+	DateURL         string        // Link from the date to its month in the archive
 	HasTOC          bool          // Whether to show TOC sidebar
 	ShowSearch      bool          // Whether to show nav search
 	TopNavItems     []TopNavItem  // Items for top navigation bar (when --top-nav enabled)
@@ -46,7 +92,34 @@ type PageData struct {
 	ViewTransitions bool          // Enable browser view transitions API (when --view-transitions enabled)
 	PWAEnabled      bool          // Whether PWA is enabled (adds manifest link + SW registration)
 	SearchEnabled   bool          // Whether search is enabled (adds command palette + lazy load)
+	GraphEnabled    bool          // Whether the link graph is enabled (loads graph.js)
 	InlineJS        template.JS   // Minified inline JavaScript for page functionality
+	SlidesJS        template.JS   // Minified slide-deck JavaScript (used by the slides layout)
+	NotFound        bool          // Whether this is the 404 page (renders the "404" partial instead of Content)
+
+	// Page layout (from front matter `layout:` or the folder default)
+	Layout          string // Layout name ("" or "default" for the standard layout)
+	HideSidebar     bool   // Don't render the navigation sidebar
+	HideTOC         bool   // Don't render the table of contents
+	HideBreadcrumbs bool   // Don't render breadcrumbs
+	Wide            bool   // Let content use the full width
+
+	// Site-wide includes (_header.md, _footer.md, _banner.md)
+	SiteHeader template.HTML // Rendered header shown above the content
+	SiteFooter template.HTML // Rendered footer shown below the content
+	Banner     template.HTML // Rendered dismissible banner
+	BannerID   string        // Banner content hash, remembered when dismissed
+
+	// Localization
+	Lang         string           // Page language code for <html lang> (empty means "en")
+	Dir          string           // Text direction for <html dir>: "ltr" or "rtl"
+	I18n         i18n.Messages    // Interface text: {{.I18n.T "nextPage"}} (nil means English)
+	Translations []i18n.Alternate // The page in each language, for the language switcher (nil on single-language sites)
+
+	// Versioned documentation
+	Version       string          // Label of the version being viewed ("" when the site isn't versioned)
+	Versions      []versions.Link // The page in each version, newest first, for the version menu
+	LatestVersion string          // The page in the latest version; set on outdated versions only
 }
 
 // Renderer handles HTML template rendering
@@ -54,18 +127,50 @@ type Renderer struct {
 	tmpl     *template.Template
 	css      string
 	inlineJS string
+	files    map[string]string // Template name -> source file, for error locations
 }
 
-// NewRenderer creates a new template renderer
+// templateSource is the text of a named template and the file it came from
+type templateSource struct {
+	file    string
+	content string
+}
+
+// NewRenderer creates a new template renderer using the built-in templates
 func NewRenderer(css string) (*Renderer, error) {
-	tmplContent, err := layoutFS.ReadFile("layout.html")
+	return NewRendererWithLayouts(css, "")
+}
+
+// NewRendererWithLayouts creates a template renderer that applies overrides
+// from layoutsDir. Any .html file in the directory replaces the built-in
+// template of the same name (layout.html, head.html, footer.html, ...); files
+// with other names are added as extra named templates. An empty layoutsDir
+// uses only the built-in templates.
+func NewRendererWithLayouts(css, layoutsDir string) (*Renderer, error) {
+	sources, err := loadTemplateSources(layoutsDir)
 	if err != nil {
 		return nil, err
 	}
 
-	tmpl, err := template.New("layout").Parse(string(tmplContent))
-	if err != nil {
-		return nil, err
+	// Parse in a stable order so errors are reproducible
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make(map[string]string, len(sources))
+	tmpl := template.New("layout").Funcs(FuncMap())
+	for _, name := range names {
+		src := sources[name]
+		files[name] = src.file
+		t := tmpl
+		if name != "layout" {
+			t = tmpl.New(name)
+		}
+		if _, err := t.Parse(src.content); err != nil {
+			return nil, wrapTemplateError(err, files)
+		}
 	}
 
 	// Load and minify inline JavaScript
@@ -79,14 +184,87 @@ func NewRenderer(css string) (*Renderer, error) {
 		tmpl:     tmpl,
 		css:      css,
 		inlineJS: inlineJS,
+		files:    files,
 	}, nil
 }
 
+// DiscoverLayoutsDir returns the _layouts directory inside inputDir,
+// or an empty string if there isn't one.
+func DiscoverLayoutsDir(inputDir string) string {
+	dir := filepath.Join(inputDir, LayoutsDirName)
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return dir
+	}
+	return ""
+}
+
+// loadTemplateSources collects the built-in templates and overlays any
+// overrides found in layoutsDir
+func loadTemplateSources(layoutsDir string) (map[string]templateSource, error) {
+	sources := make(map[string]templateSource)
+
+	for _, name := range PartialNames {
+		path := "partials/" + name + ".html"
+		if name == "layout" || name == "slides" || name == "print" {
+			path = name + ".html"
+		}
+		content, err := layoutFS.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		sources[name] = templateSource{file: path, content: trimTrailingNewline(string(content))}
+	}
+
+	if layoutsDir == "" {
+		return sources, nil
+	}
+
+	entries, err := os.ReadDir(layoutsDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read layouts directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.ToLower(filepath.Ext(entry.Name())) != ".html" {
+			continue
+		}
+		path := filepath.Join(layoutsDir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read layout %s: %w", path, err)
+		}
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		sources[name] = templateSource{file: path, content: trimTrailingNewline(string(content))}
+	}
+
+	return sources, nil
+}
+
+// trimTrailingNewline drops the final newline of a template file so
+// {{template}} calls on their own line don't add blank lines to the output
+func trimTrailingNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
+
+// HasTemplate reports whether a template with the given name is defined
+func (r *Renderer) HasTemplate(name string) bool {
+	return r.tmpl.Lookup(name) != nil
+}
+
 // Render renders a page with the given data
 func (r *Renderer) Render(w io.Writer, data PageData) error {
 	data.CSS = template.CSS(r.css)
 	data.InlineJS = template.JS(r.inlineJS)
-	return r.tmpl.Execute(w, data)
+	data.SlidesJS = template.JS(slides.JS)
+
+	name, err := r.layoutTemplate(data.Layout)
+	if err != nil {
+		return err
+	}
+	if err := r.tmpl.ExecuteTemplate(w, name, data); err != nil {
+		return wrapTemplateError(err, r.files)
+	}
+	return nil
 }
 
 // RenderToString renders a page and returns the result as a string
@@ -119,6 +297,9 @@ func renderNavNode(buf *bytes.Buffer, nodes []*tree.Node, currentPath string, de
 	buf.WriteString("<ul role=\"tree\">\n")
 
 	for _, node := range nodes {
+		if node.Hidden {
+			continue
+		}
 		if node.IsFolder {
 			renderFolderNode(buf, node, currentPath, depth, baseURL)
 		} else {
@@ -158,9 +339,9 @@ func renderFolderNode(buf *bytes.Buffer, node *tree.Node, currentPath string, de
 	buf.WriteString("</div>\n")
 
 	// Render children
-	if len(node.Children) > 0 {
+	if children := node.NavChildren(); len(children) > 0 {
 		buf.WriteString("<div class=\"folder-children\">\n")
-		renderNavNode(buf, node.Children, currentPath, depth+1, baseURL)
+		renderNavNode(buf, children, currentPath, depth+1, baseURL)
 		buf.WriteString("</div>\n")
 	}
 
@@ -185,7 +366,7 @@ func renderFileNode(buf *bytes.Buffer, node *tree.Node, currentPath string, base
 
 // BuildTopNavItems extracts root-level items for top navigation bar
 // Returns nil if topNav is disabled or there are no eligible items
-// Items are sorted: files first, then folders, each sorted by date/number/name
+// Items follow the sidebar order; pages hidden from navigation are left out
 func BuildTopNavItems(root *tree.Node, topNav bool) []TopNavItem {
 	return BuildTopNavItemsWithBaseURL(root, topNav, "")
 }
@@ -198,7 +379,7 @@ func BuildTopNavItemsWithBaseURL(root *tree.Node, topNav bool, baseURL string) [
 
 	// Collect root items (excluding index/readme files)
 	var rootItems []*tree.Node
-	for _, child := range root.Children {
+	for _, child := range root.NavChildren() {
 		// Skip index files using the same logic as tree package
 		if !child.IsFolder {
 			filename := strings.ToLower(child.FileName)
@@ -215,63 +396,20 @@ func BuildTopNavItemsWithBaseURL(root *tree.Node, topNav bool, baseURL string) [
 		return nil
 	}
 
-	// Sort: files first, then folders
-	// Within each category: by date (newest first), then number, then name alphabetically
-	// Date is extracted from filename prefix only (e.g., 2024-01-15-title.md)
-	sort.Slice(rootItems, func(i, j int) bool {
-		a, b := rootItems[i], rootItems[j]
-
-		// Files before folders
-		if a.IsFolder != b.IsFolder {
-			return !a.IsFolder // files (false) before folders (true)
-		}
-
-		// Both are same type - sort by date/number/name using tree metadata
-		aMeta := tree.GetNodeMetadata(a)
-		bMeta := tree.GetNodeMetadata(b)
-
-		// Primary: Date (from filename only)
-		// Items with dates come before items without dates
-		if aMeta.HasDate != bMeta.HasDate {
-			return aMeta.HasDate // items with dates first
-		}
-		// Both have dates - sort by date (newest first)
-		if aMeta.HasDate && bMeta.HasDate && !aMeta.Date.Equal(bMeta.Date) {
-			return aMeta.Date.After(bMeta.Date)
-		}
-
-		// Secondary: Number (lower numbers first, nil sorted last)
-		aNum := topNavNumberForSort(aMeta.Number)
-		bNum := topNavNumberForSort(bMeta.Number)
-		if aNum != bNum {
-			return aNum < bNum
-		}
-
-		// Tertiary: Name (alphabetical)
-		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
-	})
-
-	// Build top nav items with base URL prefixing
+	// Build top nav items in sidebar order, with base URL prefixing
 	var items []TopNavItem
 	for _, node := range rootItems {
 		urlPath := tree.GetURLPath(node)
 		items = append(items, TopNavItem{
 			Name: node.Name,
 			URL:  tree.PrefixURL(baseURL, urlPath),
+			Path: urlPath,
 		})
 	}
 
 	return items
 }
 
-// topNavNumberForSort returns the number for sorting, with nil treated as max
-func topNavNumberForSort(n *int) int {
-	if n == nil {
-		return 999999 // Sort after numbered items
-	}
-	return *n
-}
-
 // RenderNavigationWithTopNav renders navigation excluding root files when top nav is enabled
 func RenderNavigationWithTopNav(root *tree.Node, currentPath string, topNavItems []TopNavItem) template.HTML {
 	return RenderNavigationWithTopNavAndBaseURL(root, currentPath, topNavItems, "")
@@ -304,6 +442,9 @@ func renderNavNodeFiltered(buf *bytes.Buffer, nodes []*tree.Node, currentPath st
 	buf.WriteString("<ul role=\"tree\">\n")
 
 	for _, node := range nodes {
+		if node.Hidden {
+			continue
+		}
 		// Skip excluded files - compare against prefixed URL since that's what's in excludeURLs
 		if !node.IsFolder {
 			urlPath := tree.GetURLPath(node)
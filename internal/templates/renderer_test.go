@@ -2,7 +2,10 @@ package templates
 
 import (
 	"bytes"
+	"errors"
 	"html/template"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -277,6 +280,38 @@ func TestBuildTopNavItems(t *testing.T) {
 	}
 }
 
+func TestNavigationSkipsHiddenPages(t *testing.T) {
+	root := tree.NewNode("", "", true)
+	for _, name := range []string{"about", "secret"} {
+		page := tree.NewNode(name, name+".md", false)
+		page.FileName = name + ".md"
+		page.SourcePath = name + ".md"
+		page.Hidden = name == "secret"
+		root.AddChild(page)
+	}
+	guides := tree.NewNode("Guides", "guides", true)
+	root.AddChild(guides)
+	hidden := tree.NewNode("hidden-guide", "guides/hidden-guide.md", false)
+	hidden.Hidden = true
+	guides.AddChild(hidden)
+	guides.AddChild(tree.NewNode("visible-guide", "guides/visible-guide.md", false))
+
+	nav := string(RenderNavigation(root, ""))
+	for _, hidden := range []string{"secret", "hidden-guide"} {
+		if strings.Contains(nav, hidden) {
+			t.Errorf("sidebar should not list hidden page %q", hidden)
+		}
+	}
+	if !strings.Contains(nav, "visible-guide") {
+		t.Error("sidebar should list visible pages")
+	}
+
+	items := BuildTopNavItems(root, true)
+	if len(items) != 2 || items[0].Name != "about" || items[1].Name != "Guides" {
+		t.Errorf("BuildTopNavItems() = %v, want hidden pages left out", items)
+	}
+}
+
 func TestBuildTopNavItemsLimits(t *testing.T) {
 	root := tree.NewNode("", "", true)
 	for i := 0; i < 9; i++ {
@@ -295,13 +330,58 @@ func TestBuildTopNavItemsLimits(t *testing.T) {
 	}
 }
 
+func TestTopNavItemActive(t *testing.T) {
+	page := TopNavItem{Name: "About", URL: "/docs/about/", Path: "/about/"}
+	folder := TopNavItem{Name: "Guides", URL: "/docs/guides/", Path: "/guides/", Match: "/guides/"}
+	external := TopNavItem{Name: "GitHub", URL: "https://github.com", External: true}
+	dropdown := TopNavItem{Name: "More", Children: []TopNavItem{page, external}}
+
+	tests := []struct {
+		item        TopNavItem
+		currentPath string
+		want        bool
+	}{
+		{page, "/about/", true},
+		{page, "/about/team/", false},
+		{folder, "/guides/", true},
+		{folder, "/guides/intro/", true},
+		{folder, "/", false},
+		{external, "", false},
+		{dropdown, "/about/", true},
+		{dropdown, "/guides/", false},
+	}
+	for _, tt := range tests {
+		if got := tt.item.Active(tt.currentPath); got != tt.want {
+			t.Errorf("%s.Active(%q) = %v, want %v", tt.item.Name, tt.currentPath, got, tt.want)
+		}
+	}
+}
+
 func TestTopNavNumberForSort(t *testing.T) {
-	if got := topNavNumberForSort(nil); got != 999999 {
-		t.Errorf("topNavNumberForSort(nil) = %d, want 999999", got)
+	// Top nav items follow the scanned tree's order: numbered files first,
+	// lowest number first, then the unnumbered ones
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"zeta.md":     "# Zeta",
+		"02-beta.md":  "# Beta",
+		"01-alpha.md": "# Alpha",
+		"index.md":    "# Home",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
 	}
-	val := 3
-	if got := topNavNumberForSort(&val); got != 3 {
-		t.Errorf("topNavNumberForSort(3) = %d, want 3", got)
+	site, err := tree.Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, item := range BuildTopNavItems(site.Root, true) {
+		names = append(names, item.Name)
+	}
+	if strings.Join(names, ",") != "Alpha,Beta,Zeta" {
+		t.Errorf("BuildTopNavItems() = %v, want numbered items first", names)
 	}
 }
 
@@ -340,3 +420,178 @@ func TestRenderNavigationWithTopNav(t *testing.T) {
 		t.Error("RenderNavigationWithTopNav() should include folder children")
 	}
 }
+
+func writeLayout(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewRendererWithLayouts_PartialOverride(t *testing.T) {
+	dir := t.TempDir()
+	writeLayout(t, dir, "footer.html", `<footer class="custom-footer">© {{.SiteTitle}}</footer>`)
+
+	r, err := NewRendererWithLayouts("", dir)
+	if err != nil {
+		t.Fatalf("NewRendererWithLayouts() error = %v", err)
+	}
+
+	html, err := r.RenderToString(PageData{SiteTitle: "Acme", PageTitle: "Home"})
+	if err != nil {
+		t.Fatalf("RenderToString() error = %v", err)
+	}
+
+	if !strings.Contains(html, `<footer class="custom-footer">© Acme</footer>`) {
+		t.Error("Should render the overridden footer partial")
+	}
+	if strings.Contains(html, `class="desktop-toolbar"`) {
+		t.Error("Built-in footer should be replaced by the override")
+	}
+	if !strings.Contains(html, `class="sidebar"`) {
+		t.Error("Partials that were not overridden should still render")
+	}
+}
+
+func TestNewRendererWithLayouts_FullLayoutOverride(t *testing.T) {
+	dir := t.TempDir()
+	writeLayout(t, dir, "layout.html", `<!DOCTYPE html><html><head>{{template "head" .}}</head><body class="landing">{{.Content}}{{template "scripts" .}}</body></html>`)
+
+	r, err := NewRendererWithLayouts("", dir)
+	if err != nil {
+		t.Fatalf("NewRendererWithLayouts() error = %v", err)
+	}
+
+	html, err := r.RenderToString(PageData{PageTitle: "Home", Content: template.HTML("<p>Hi</p>")})
+	if err != nil {
+		t.Fatalf("RenderToString() error = %v", err)
+	}
+
+	if !strings.Contains(html, `<body class="landing"><p>Hi</p>`) {
+		t.Error("Should render the overridden layout")
+	}
+	if strings.Contains(html, `class="sidebar"`) {
+		t.Error("Overridden layout should not include the sidebar")
+	}
+	if !strings.Contains(html, "<title>Home</title>") {
+		t.Error("Overridden layout should be able to use built-in partials")
+	}
+}
+
+func TestNewRendererWithLayouts_ExtraPartialAndFuncs(t *testing.T) {
+	dir := t.TempDir()
+	writeLayout(t, dir, "footer.html", `{{template "legal" .}}`)
+	writeLayout(t, dir, "legal.html", `<p>{{upper .SiteTitle}} <a href="{{relURL .BaseURL "/terms/"}}">Terms</a> {{default "Untitled" .PageTitle}}</p>`)
+
+	r, err := NewRendererWithLayouts("", dir)
+	if err != nil {
+		t.Fatalf("NewRendererWithLayouts() error = %v", err)
+	}
+	if !r.HasTemplate("legal") {
+		t.Error("HasTemplate(legal) should be true for an extra layout file")
+	}
+
+	html, err := r.RenderToString(PageData{SiteTitle: "acme", BaseURL: "/docs"})
+	if err != nil {
+		t.Fatalf("RenderToString() error = %v", err)
+	}
+	if !strings.Contains(html, `<p>ACME <a href="/docs/terms/">Terms</a> Untitled</p>`) {
+		t.Error("Extra partial should render with helper funcs")
+	}
+}
+
+func TestNewRendererWithLayouts_IgnoresNonHTML(t *testing.T) {
+	dir := t.TempDir()
+	writeLayout(t, dir, "footer.txt", `{{broken`)
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewRendererWithLayouts("", dir); err != nil {
+		t.Fatalf("NewRendererWithLayouts() should ignore non-.html files, got %v", err)
+	}
+}
+
+func TestNewRendererWithLayouts_MissingDir(t *testing.T) {
+	_, err := NewRendererWithLayouts("", filepath.Join(t.TempDir(), "missing"))
+	if err == nil {
+		t.Fatal("NewRendererWithLayouts() should fail for a missing directory")
+	}
+}
+
+func TestNewRendererWithLayouts_ParseErrorLocation(t *testing.T) {
+	dir := t.TempDir()
+	writeLayout(t, dir, "footer.html", "<footer>\n<p>ok</p>\n{{if .SiteTitle}\n</footer>")
+
+	_, err := NewRendererWithLayouts("", dir)
+	if err == nil {
+		t.Fatal("NewRendererWithLayouts() should fail on a syntax error")
+	}
+
+	var tmplErr *TemplateError
+	if !errors.As(err, &tmplErr) {
+		t.Fatalf("error should be a *TemplateError, got %T: %v", err, err)
+	}
+	wantFile := filepath.Join(dir, "footer.html")
+	if tmplErr.File != wantFile || tmplErr.Line != 3 {
+		t.Errorf("location = %s:%d, want %s:3", tmplErr.File, tmplErr.Line, wantFile)
+	}
+	if !strings.HasPrefix(err.Error(), wantFile+":3: ") {
+		t.Errorf("Error() = %q, want file:line prefix", err.Error())
+	}
+}
+
+func TestRender_ExecErrorLocation(t *testing.T) {
+	dir := t.TempDir()
+	writeLayout(t, dir, "page-meta.html", "<div>\n{{.NoSuchField}}\n</div>")
+
+	r, err := NewRendererWithLayouts("", dir)
+	if err != nil {
+		t.Fatalf("NewRendererWithLayouts() error = %v", err)
+	}
+
+	err = r.Render(&bytes.Buffer{}, PageData{})
+	if err == nil {
+		t.Fatal("Render() should fail when a template references an unknown field")
+	}
+	want := filepath.Join(dir, "page-meta.html") + ":2: "
+	if !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("Error() = %q, want prefix %q", err.Error(), want)
+	}
+}
+
+func TestRender_NotFound(t *testing.T) {
+	r, err := NewRenderer("")
+	if err != nil {
+		t.Fatalf("NewRenderer() error = %v", err)
+	}
+
+	html, err := r.RenderToString(PageData{PageTitle: "Page Not Found", NotFound: true, BaseURL: "/docs", Content: "<p>ignored</p>"})
+	if err != nil {
+		t.Fatalf("RenderToString() error = %v", err)
+	}
+	if !strings.Contains(html, "404 - Page Not Found") {
+		t.Error("Should render the 404 partial")
+	}
+	if !strings.Contains(html, `<a href="/docs/">Return to home</a>`) {
+		t.Error("404 partial should link to the base URL")
+	}
+	if strings.Contains(html, "ignored") {
+		t.Error("Content should not render on the 404 page")
+	}
+}
+
+func TestDiscoverLayoutsDir(t *testing.T) {
+	dir := t.TempDir()
+	if got := DiscoverLayoutsDir(dir); got != "" {
+		t.Errorf("DiscoverLayoutsDir() = %q, want empty when missing", got)
+	}
+
+	layouts := filepath.Join(dir, LayoutsDirName)
+	if err := os.Mkdir(layouts, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := DiscoverLayoutsDir(dir); got != layouts {
+		t.Errorf("DiscoverLayoutsDir() = %q, want %q", got, layouts)
+	}
+}
@@ -0,0 +1,186 @@
+package tree
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// FrontMatter holds the top-level fields of a page's YAML front matter.
+// Only the simple subset pages need is understood: "key: value" pairs,
+// inline lists ("tags: [a, b]") and block lists ("- item" lines).
+// Keys are lowercased; values are kept as strings, with lists stored
+// in their inline "[a, b]" form.
+type FrontMatter map[string]string
+
+// String returns the value of key, or "" if it is not set
+func (fm FrontMatter) String(key string) string {
+	return fm[key]
+}
+
+// Bool returns the boolean value of key and whether it was set to a recognised value
+func (fm FrontMatter) Bool(key string) (value bool, ok bool) {
+	switch strings.ToLower(fm[key]) {
+	case "true", "yes", "on":
+		return true, true
+	case "false", "no", "off":
+		return false, true
+	}
+	return false, false
+}
+
+// Int returns the integer value of key and whether it was set to a valid integer
+func (fm FrontMatter) Int(key string) (int, bool) {
+	v, err := strconv.Atoi(fm[key])
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// List returns the items of a list value. Inline lists ("[a, b]"),
+// block lists and plain comma-separated values are all accepted.
+func (fm FrontMatter) List(key string) []string {
+	value := strings.TrimSpace(fm[key])
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		value = value[1 : len(value)-1]
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = unquote(strings.TrimSpace(item))
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ParseFrontMatter splits YAML front matter from markdown content.
+// It returns the parsed fields (nil when there is no front matter) and the
+// content with the front matter removed, exactly as StripFrontMatter does.
+func ParseFrontMatter(content []byte) (FrontMatter, []byte) {
+	block, body, ok := splitFrontMatter(content)
+	if !ok {
+		return nil, content
+	}
+	return parseFrontMatterBlock(block), body
+}
+
+// StripFrontMatter removes YAML front matter from the beginning of markdown content.
+// Front matter is delimited by --- at the start of the file and closed by another ---.
+// Returns the content with front matter removed.
+func StripFrontMatter(content []byte) []byte {
+	_, body, ok := splitFrontMatter(content)
+	if !ok {
+		return content
+	}
+	return body
+}
+
+// splitFrontMatter returns the front matter block and the content after it.
+// ok is false when the content has no complete front matter.
+func splitFrontMatter(content []byte) (block []byte, body []byte, ok bool) {
+	// Must start with ---
+	if !bytes.HasPrefix(content, []byte("---")) {
+		return nil, content, false
+	}
+
+	// Find the closing ---
+	// Skip the first 3 characters (the opening ---)
+	rest := content[3:]
+
+	// Skip optional newline after opening ---
+	if len(rest) > 0 && rest[0] == '\n' {
+		rest = rest[1:]
+	} else if len(rest) > 1 && rest[0] == '\r' && rest[1] == '\n' {
+		rest = rest[2:]
+	}
+
+	// Find the closing --- on its own line
+	idx := bytes.Index(rest, []byte("\n---"))
+	if idx == -1 {
+		// Try Windows line endings
+		idx = bytes.Index(rest, []byte("\r\n---"))
+		if idx == -1 {
+			// No closing ---, return original content
+			return nil, content, false
+		}
+		// Skip past \r\n---
+		return rest[:idx], stripLeadingNewlines(rest[idx+5:]), true
+	}
+
+	// Skip past the closing \n---
+	return rest[:idx], stripLeadingNewlines(rest[idx+4:]), true
+}
+
+// parseFrontMatterBlock parses the lines between the front matter delimiters
+func parseFrontMatterBlock(block []byte) FrontMatter {
+	fm := FrontMatter{}
+	var listKey string
+	var listItems []string
+
+	flushList := func() {
+		if listKey != "" && len(listItems) > 0 {
+			fm[listKey] = "[" + strings.Join(listItems, ", ") + "]"
+		}
+		listKey, listItems = "", nil
+	}
+
+	for _, line := range strings.Split(string(block), "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// Block list item belonging to the last empty key
+		if listKey != "" && strings.HasPrefix(trimmed, "- ") {
+			listItems = append(listItems, unquote(strings.TrimSpace(trimmed[2:])))
+			continue
+		}
+
+		// Nested values are not supported; skip indented lines
+		if line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+
+		flushList()
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if value == "" {
+			listKey = key
+		}
+		fm[key] = unquote(value)
+	}
+	flushList()
+
+	return fm
+}
+
+// unquote strips matching single or double quotes around a value
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// stripLeadingNewlines removes leading newlines (Unix or Windows style)
+func stripLeadingNewlines(content []byte) []byte {
+	for len(content) > 0 {
+		if content[0] == '\n' {
+			content = content[1:]
+		} else if len(content) > 1 && content[0] == '\r' && content[1] == '\n' {
+			content = content[2:]
+		} else {
+			break
+		}
+	}
+	return content
+}
@@ -0,0 +1,167 @@
+package tree
+
+import (
+	"testing"
+)
+
+func TestStripFrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "no front matter",
+			input:    "# Hello\n\nContent here",
+			expected: "# Hello\n\nContent here",
+		},
+		{
+			name: "simple front matter",
+			input: `---
+title: My Page
+date: 2024-01-01
+---
+
+# Hello
+
+Content here`,
+			expected: `# Hello
+
+Content here`,
+		},
+		{
+			name: "front matter with empty value",
+			input: `---
+title: Test
+tags:
+---
+
+Content`,
+			expected: `Content`,
+		},
+		{
+			name:     "only opening delimiter",
+			input:    "---\ntitle: Test\nNo closing delimiter",
+			expected: "---\ntitle: Test\nNo closing delimiter",
+		},
+		{
+			name:     "delimiter not at start",
+			input:    "Some text\n---\ntitle: Test\n---\nContent",
+			expected: "Some text\n---\ntitle: Test\n---\nContent",
+		},
+		{
+			name: "multiple dashes in content",
+			input: `---
+title: Test
+---
+
+Some content with --- dashes`,
+			expected: `Some content with --- dashes`,
+		},
+		{
+			name:     "windows line endings",
+			input:    "---\r\ntitle: Test\r\n---\r\n\r\nContent",
+			expected: "Content",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := StripFrontMatter([]byte(tt.input))
+			if string(result) != tt.expected {
+				t.Errorf("StripFrontMatter() = %q, want %q", string(result), tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	input := `---
+title: "My Page"
+Layout: landing
+sidebar: no
+weight: 3
+tags: [go, "api docs", ]
+aliases:
+  - /old/
+  - '/older/'
+# a comment
+nested:
+  key: ignored
+empty:
+---
+
+# Body`
+
+	fm, body := ParseFrontMatter([]byte(input))
+	if string(body) != "# Body" {
+		t.Errorf("body = %q, want %q", body, "# Body")
+	}
+
+	if got := fm.String("title"); got != "My Page" {
+		t.Errorf("title = %q, want %q", got, "My Page")
+	}
+	if got := fm.String("layout"); got != "landing" {
+		t.Errorf("layout = %q, want %q (keys are lowercased)", got, "landing")
+	}
+	if v, ok := fm.Bool("sidebar"); !ok || v {
+		t.Errorf("Bool(sidebar) = %v, %v, want false, true", v, ok)
+	}
+	if _, ok := fm.Bool("title"); ok {
+		t.Error("Bool(title) should not be ok")
+	}
+	if v, ok := fm.Int("weight"); !ok || v != 3 {
+		t.Errorf("Int(weight) = %v, %v, want 3, true", v, ok)
+	}
+	if _, ok := fm.Int("title"); ok {
+		t.Error("Int(title) should not be ok")
+	}
+
+	tags := fm.List("tags")
+	if len(tags) != 2 || tags[0] != "go" || tags[1] != "api docs" {
+		t.Errorf("List(tags) = %q", tags)
+	}
+	aliases := fm.List("aliases")
+	if len(aliases) != 2 || aliases[0] != "/old/" || aliases[1] != "/older/" {
+		t.Errorf("List(aliases) = %q", aliases)
+	}
+	if got := fm.String("nested"); got != "" {
+		t.Errorf("nested = %q, want empty", got)
+	}
+	if _, ok := fm["key"]; ok {
+		t.Error("indented keys should be ignored")
+	}
+	if got := fm.List("empty"); got != nil {
+		t.Errorf("List(empty) = %q, want nil", got)
+	}
+	if got := fm.List("missing"); got != nil {
+		t.Errorf("List(missing) = %q, want nil", got)
+	}
+}
+
+func TestParseFrontMatterNone(t *testing.T) {
+	input := "# Hello\n\nContent"
+	fm, body := ParseFrontMatter([]byte(input))
+	if fm != nil {
+		t.Errorf("fm = %v, want nil", fm)
+	}
+	if string(body) != input {
+		t.Errorf("body = %q, want unchanged", body)
+	}
+	if fm.String("layout") != "" {
+		t.Error("String on nil FrontMatter should be empty")
+	}
+}
+
+func TestParseFrontMatterWindowsLineEndings(t *testing.T) {
+	fm, body := ParseFrontMatter([]byte("---\r\nlayout: wide\r\ntoc: false\r\n---\r\n\r\nContent"))
+	if string(body) != "Content" {
+		t.Errorf("body = %q, want %q", body, "Content")
+	}
+	if fm.String("layout") != "wide" {
+		t.Errorf("layout = %q, want %q", fm.String("layout"), "wide")
+	}
+	if v, ok := fm.Bool("toc"); !ok || v {
+		t.Errorf("Bool(toc) = %v, %v, want false, true", v, ok)
+	}
+}
@@ -0,0 +1,123 @@
+package tree
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Lookup finds the pages and folders of a site by reference, as written in
+// configuration and front matter: a path ("guides/intro", "guides/intro.md",
+// "/guides/intro/"), a bare name ("intro") or a wikilink ("[[intro]]")
+type Lookup struct {
+	nodes  []*Node // The root, then the other folders, then the pages
+	prefix string  // Path of the site root ("fr" for a translation)
+	urls   map[string]bool
+}
+
+// NewLookup indexes the pages and folders of a site
+func NewLookup(site *Site) *Lookup {
+	l := &Lookup{prefix: filepath.ToSlash(site.Root.Path), urls: make(map[string]bool)}
+	l.collect(site.Root)
+	l.nodes = append(l.nodes, site.AllPages...)
+	for _, node := range l.nodes {
+		l.urls[GetURLPath(node)] = true
+	}
+	return l
+}
+
+// collect adds a folder and its subfolders
+func (l *Lookup) collect(node *Node) {
+	l.nodes = append(l.nodes, node)
+	for _, child := range node.Children {
+		if child.IsFolder {
+			l.collect(child)
+		}
+	}
+}
+
+// Exists reports whether a page or folder has the URL path
+func (l *Lookup) Exists(urlPath string) bool {
+	return l.urls[urlPath]
+}
+
+// Find returns the page or folder a reference target (see ParseReference)
+// points to. Targets with a folder ("guides/intro") are paths from the site
+// root, on disk or as in URLs; bare names ("intro") are looked up at the
+// root first, then by file name or title anywhere in the site.
+func (l *Lookup) Find(target string) (*Node, error) {
+	if target == "" || strings.EqualFold(target, "index") {
+		return l.nodes[0], nil // The site root
+	}
+
+	targetURL := "/" + SlugifyPath(target) + "/"
+	if l.prefix != "" {
+		targetURL = "/" + l.prefix + targetURL
+	}
+	for _, node := range l.nodes {
+		if GetURLPath(node) == targetURL || strings.EqualFold(l.sourcePath(node), target) {
+			return node, nil
+		}
+	}
+	if strings.Contains(target, "/") {
+		return nil, fmt.Errorf("page %q not found", target)
+	}
+
+	var matches []*Node
+	seen := make(map[string]bool)
+	for _, node := range l.nodes {
+		stem := filepath.Base(l.sourcePath(node))
+		if !strings.EqualFold(stem, target) && Slugify(stem) != Slugify(target) && !strings.EqualFold(node.Name, target) {
+			continue
+		}
+		if url := GetURLPath(node); !seen[url] {
+			seen[url] = true
+			matches = append(matches, node)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("page %q not found", target)
+	case 1:
+		return matches[0], nil
+	}
+	var paths []string
+	for _, node := range matches {
+		paths = append(paths, l.sourcePath(node))
+	}
+	sort.Strings(paths)
+	return nil, fmt.Errorf("page %q is ambiguous (%s); use its path", target, strings.Join(paths, ", "))
+}
+
+// sourcePath returns a node's path from the site root, without its
+// markdown extension
+func (l *Lookup) sourcePath(node *Node) string {
+	p := filepath.ToSlash(node.Path)
+	if l.prefix != "" {
+		p = strings.TrimPrefix(strings.TrimPrefix(p, l.prefix), "/")
+	}
+	if !node.IsFolder {
+		p = strings.TrimSuffix(p, filepath.Ext(p))
+	}
+	return p
+}
+
+// ParseReference splits a page reference ("[[guides/intro#setup|Intro]]")
+// into its target, label and anchor
+func ParseReference(ref string) (target, label, anchor string) {
+	target = strings.TrimSpace(ref)
+	target = strings.TrimSuffix(strings.TrimPrefix(target, "[["), "]]")
+	if i := strings.Index(target, "|"); i >= 0 {
+		target, label = target[:i], strings.TrimSpace(target[i+1:])
+	}
+	if i := strings.Index(target, "#"); i >= 0 {
+		target, anchor = target[:i], target[i:]
+	}
+	for _, ext := range []string{".md", ".markdown"} {
+		if strings.HasSuffix(strings.ToLower(target), ext) {
+			target = target[:len(target)-len(ext)]
+		}
+	}
+	return strings.Trim(strings.TrimSpace(target), "/"), label, anchor
+}
@@ -0,0 +1,76 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		ref, target, label, anchor string
+	}{
+		{"guides/intro", "guides/intro", "", ""},
+		{" /guides/intro/ ", "guides/intro", "", ""},
+		{"guides/intro.md#setup", "guides/intro", "", "#setup"},
+		{"[[intro|Start here]]", "intro", "Start here", ""},
+		{"[[Intro.markdown#usage|Usage]]", "Intro", "Usage", "#usage"},
+	}
+	for _, tt := range tests {
+		target, label, anchor := ParseReference(tt.ref)
+		if target != tt.target || label != tt.label || anchor != tt.anchor {
+			t.Errorf("ParseReference(%q) = %q, %q, %q", tt.ref, target, label, anchor)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"index.md":              "# Home",
+		"guides/index.md":       "# Guides",
+		"guides/01-intro.md":    "# Getting Started",
+		"guides/setup.md":       "# Setup",
+		"api/setup.md":          "# API Setup",
+		"index.fr.md":           "# Accueil",
+		"guides/01-intro.fr.md": "# Introduction",
+	})
+	site, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup := NewLookup(site)
+
+	for target, want := range map[string]string{
+		"":                "/",
+		"index":           "/",
+		"guides":          "/guides/",
+		"guides/intro":    "/guides/intro/",
+		"guides/01-intro": "/guides/intro/",
+		"intro":           "/guides/intro/",
+		"getting started": "/guides/intro/",
+		"api/setup":       "/api/setup/",
+	} {
+		node, err := lookup.Find(target)
+		if err != nil || GetURLPath(node) != want {
+			t.Errorf("Find(%q) = %v, %v, want %s", target, node, err, want)
+		}
+	}
+	if _, err := lookup.Find("setup"); err == nil || !strings.Contains(err.Error(), "ambiguous (api/setup, guides/setup)") {
+		t.Errorf("Find(setup) error = %v", err)
+	}
+	if _, err := lookup.Find("guides/missing"); err == nil {
+		t.Error("expected an error for a missing page")
+	}
+	if !lookup.Exists("/guides/") || lookup.Exists("/fr/") {
+		t.Error("Exists() should report the site's URLs")
+	}
+
+	// A translation's references are relative to its own root
+	fr, err := ScanLanguage(dir, nil, "fr", []string{"fr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := NewLookup(fr).Find("guides/intro")
+	if err != nil || GetURLPath(node) != "/fr/guides/intro/" {
+		t.Errorf("Find(guides/intro) in fr = %v, %v", node, err)
+	}
+}
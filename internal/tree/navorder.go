@@ -0,0 +1,231 @@
+package tree
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FolderSettingsFile is the optional per-folder file that sets how the
+// folder's pages are ordered and shown in navigation. It takes the same
+// keys as an index page's front matter, and wins over them.
+const FolderSettingsFile = "_folder.yml"
+
+// Sort modes for a folder's children
+const (
+	SortDefault  = ""          // Dated first (newest first), then number prefix, then name
+	SortManual   = "manual"    // The folder's `order` list, then the rest in default order
+	SortAlpha    = "alpha"     // By name, case-insensitive
+	SortDateDesc = "date-desc" // Newest first, undated last
+	SortDateAsc  = "date-asc"  // Oldest first, undated last
+)
+
+// NavOrder holds a folder's navigation ordering settings
+type NavOrder struct {
+	Sort         string   // Sort mode (one of the Sort constants)
+	Order        []string // Children listed first, by file or folder name, for SortManual
+	FoldersFirst bool     // List subfolders before pages (default: pages first)
+}
+
+// InNav reports whether a node is shown in navigation: the sidebar, top
+// nav, prev/next links and auto-index listings. Pages hidden with
+// `nav: false` or `hidden: true`, and everything in a hidden folder, are
+// still built and reachable by URL.
+func (n *Node) InNav() bool {
+	for node := n; node != nil; node = node.Parent {
+		if node.Hidden {
+			return false
+		}
+	}
+	return true
+}
+
+// NavChildren returns the children of a node that are shown in navigation
+func (n *Node) NavChildren() []*Node {
+	children := make([]*Node, 0, len(n.Children))
+	for _, child := range n.Children {
+		if !child.Hidden {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// applyNavSettings reads the navigation keys of front matter onto a node:
+// `weight`, `date`, `nav: false` / `hidden: true`, and for folders `sort`,
+// `order` and `folders: first`
+func applyNavSettings(node *Node, fm FrontMatter) {
+	if fm == nil {
+		return
+	}
+	if weight, ok := fm.Int("weight"); ok {
+		node.Weight = &weight
+	}
+	if date, err := time.Parse("2006-01-02", fm.String("date")); err == nil {
+		node.Date = date
+	}
+	if nav, ok := fm.Bool("nav"); ok && !nav {
+		node.Hidden = true
+	}
+	if hidden, ok := fm.Bool("hidden"); ok && hidden {
+		node.Hidden = true
+	}
+	if !node.IsFolder {
+		return
+	}
+
+	order := NavOrder{}
+	if node.NavOrder != nil {
+		order = *node.NavOrder
+	}
+	set := false
+	if mode := strings.ToLower(fm.String("sort")); mode != "" {
+		switch mode {
+		case "date":
+			mode = SortDateDesc
+		case "default", "auto":
+			mode = SortDefault
+		}
+		order.Sort = mode
+		set = true
+	}
+	if items := fm.List("order"); len(items) > 0 {
+		order.Order = items
+		if fm.String("sort") == "" {
+			order.Sort = SortManual
+		}
+		set = true
+	}
+	if folders := strings.ToLower(fm.String("folders")); folders == "first" || folders == "last" {
+		order.FoldersFirst = folders == "first"
+		set = true
+	}
+	if set {
+		node.NavOrder = &order
+	}
+}
+
+// readFolderSettings applies a folder's FolderSettingsFile, if it has one
+func readFolderSettings(dir string, folder *Node) {
+	content, err := os.ReadFile(filepath.Join(dir, FolderSettingsFile))
+	if err != nil {
+		return
+	}
+	applyNavSettings(folder, parseFrontMatterBlock(content))
+}
+
+// sortChildren orders a folder's children by its NavOrder
+func sortChildren(node *Node) {
+	order := NavOrder{}
+	if node.NavOrder != nil {
+		order = *node.NavOrder
+	}
+
+	// Position of each manually ordered child
+	manual := make(map[string]int, len(order.Order))
+	if order.Sort == SortManual {
+		for i, name := range order.Order {
+			manual[strings.ToLower(strings.TrimSuffix(name, "/"))] = i
+		}
+	}
+	position := func(n *Node) (int, bool) {
+		if len(manual) == 0 {
+			return 0, false
+		}
+		// Match the file name with or without its extension and prefixes
+		name := strings.ToLower(filepath.Base(n.Path))
+		keys := []string{name, Slugify(name)}
+		if !n.IsFolder {
+			keys = append(keys, strings.TrimSuffix(name, filepath.Ext(name)), GetNodeMetadata(n).Slug)
+		}
+		for _, key := range keys {
+			if i, ok := manual[key]; ok {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+
+	sort.SliceStable(node.Children, func(i, j int) bool {
+		a, b := node.Children[i], node.Children[j]
+
+		// Manually ordered children come first, in the listed order
+		aPos, aListed := position(a)
+		bPos, bListed := position(b)
+		if aListed != bListed {
+			return aListed
+		}
+		if aListed {
+			return aPos < bPos
+		}
+
+		// Pages before folders, unless the folder asks otherwise
+		if a.IsFolder != b.IsFolder {
+			return a.IsFolder == order.FoldersFirst
+		}
+
+		// Weighted children first, lowest weight first
+		if (a.Weight != nil) != (b.Weight != nil) {
+			return a.Weight != nil
+		}
+		if a.Weight != nil && *a.Weight != *b.Weight {
+			return *a.Weight < *b.Weight
+		}
+
+		switch order.Sort {
+		case SortAlpha:
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		case SortDateAsc, SortDateDesc:
+			aDate, bDate := NodeDate(a), NodeDate(b)
+			if aDate.IsZero() != bDate.IsZero() {
+				return !aDate.IsZero()
+			}
+			if !aDate.Equal(bDate) {
+				if order.Sort == SortDateAsc {
+					return aDate.Before(bDate)
+				}
+				return aDate.After(bDate)
+			}
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
+		return defaultLess(a, b)
+	})
+}
+
+// defaultLess is the default order: dated items first (newest first, date
+// from the filename only), then by number prefix, then by name
+func defaultLess(a, b *Node) bool {
+	aMeta := GetNodeMetadata(a)
+	bMeta := GetNodeMetadata(b)
+
+	// Primary: Date (from filename only)
+	// Items with dates come before items without dates
+	if aMeta.HasDate != bMeta.HasDate {
+		return aMeta.HasDate
+	}
+	// Both have dates - sort by date (newest first)
+	if aMeta.HasDate && bMeta.HasDate && !aMeta.Date.Equal(bMeta.Date) {
+		return aMeta.Date.After(bMeta.Date)
+	}
+
+	// Secondary: Number (lower numbers first, nil sorted last)
+	aNum := getNumberForSort(aMeta.Number, false)
+	bNum := getNumberForSort(bMeta.Number, false)
+	if aNum != bNum {
+		return aNum < bNum
+	}
+
+	// Tertiary: Name (alphabetical, case-insensitive)
+	return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+}
+
+// NodeDate returns a node's date: the filename date prefix, or else the
+// `date` front matter (zero if neither is set)
+func NodeDate(n *Node) time.Time {
+	if meta := GetNodeMetadata(n); meta.HasDate {
+		return meta.Date
+	}
+	return n.Date
+}
@@ -0,0 +1,120 @@
+package tree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func childNames(node *Node) []string {
+	var names []string
+	for _, child := range node.Children {
+		names = append(names, child.Name)
+	}
+	return names
+}
+
+func assertNames(t *testing.T, label string, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", label, got, want)
+		return
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s = %v, want %v", label, got, want)
+			return
+		}
+	}
+}
+
+func TestScanNavOrder(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"index.md":               "# Home",
+		"alpha/index.md":         "---\nsort: alpha\nfolders: first\n---\n# Alpha",
+		"alpha/zebra.md":         "# Zebra",
+		"alpha/apple.md":         "# Apple",
+		"alpha/mango.md":         "---\nweight: 1\n---\n# Mango",
+		"alpha/sub/page.md":      "# Sub",
+		"dated/_folder.yml":      "sort: date-asc\n",
+		"dated/march.md":         "---\ndate: 2024-03-01\n---\n# March",
+		"dated/january.md":       "---\ndate: 2024-01-01\n---\n# January",
+		"dated/undated.md":       "# Undated",
+		"manual/_folder.yml":     "order:\n  - setup\n  - 01-intro.md\n  - extras\n",
+		"manual/01-intro.md":     "# Intro",
+		"manual/setup.md":        "# Setup",
+		"manual/faq.md":          "# FAQ",
+		"manual/extras/x.md":     "# X",
+		"newest/_folder.yml":     "sort: date\n",
+		"newest/2024-01-01-a.md": "# A",
+		"newest/2024-06-01-b.md": "# B",
+	})
+
+	site, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	folders := make(map[string]*Node)
+	for _, child := range site.Root.Children {
+		folders[child.Path] = child
+	}
+
+	assertNames(t, "alpha", childNames(folders["alpha"]), "Sub", "Mango", "Apple", "Zebra")
+	assertNames(t, "dated", childNames(folders["dated"]), "January", "March", "Undated")
+	assertNames(t, "manual", childNames(folders["manual"]), "Setup", "Intro", "Extras", "FAQ")
+	assertNames(t, "newest", childNames(folders["newest"]), "B", "A")
+
+	// Default order is unchanged: pages first, then folders
+	assertNames(t, "root", childNames(site.Root), "Alpha", "Dated", "Manual", "Newest")
+}
+
+func TestScanHiddenPages(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"index.md":            "---\nnav: false\n---\n# Home",
+		"guide.md":            "# Guide",
+		"secret.md":           "---\nnav: false\n---\n# Secret",
+		"draft.md":            "---\nhidden: true\n---\n# Draft",
+		"private/_folder.yml": "hidden: true\n",
+		"private/index.md":    "# Private",
+		"private/page.md":     "# Page",
+	})
+
+	site, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(site.AllPages) != 6 {
+		t.Errorf("got %d pages, want hidden pages still built", len(site.AllPages))
+	}
+	if !site.Root.InNav() {
+		t.Error("hiding the home page should not hide the site")
+	}
+
+	var visible []string
+	for _, child := range site.Root.NavChildren() {
+		visible = append(visible, child.Name)
+	}
+	assertNames(t, "NavChildren()", visible, "Guide")
+
+	for _, page := range site.AllPages {
+		want := page.Name == "Guide"
+		if got := page.InNav(); got != want {
+			t.Errorf("%s InNav() = %v, want %v", page.Name, got, want)
+		}
+	}
+}
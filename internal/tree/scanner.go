@@ -3,26 +3,56 @@ package tree
 import (
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 )
 
+// IncludeFiles are root-level markdown files that are rendered into every
+// page (see the includes package) rather than published as pages
+var IncludeFiles = []string{"_header.md", "_footer.md", "_banner.md"}
+
 // Scan walks the input directory and builds a tree structure of markdown files
 func Scan(inputDir string) (*Site, error) {
+	return ScanExcluding(inputDir, nil)
+}
+
+// ScanExcluding is like Scan but also skips the given files, given as paths
+// relative to inputDir. Root-level IncludeFiles are always skipped.
+func ScanExcluding(inputDir string, exclude []string) (*Site, error) {
+	return ScanLanguage(inputDir, exclude, "", nil)
+}
+
+// ScanLanguage scans one language of a multilingual site. Translations are
+// files named page.<lang>.md next to the original, for each of languages.
+// An empty lang scans the default-language pages and leaves translations
+// out; otherwise only lang's translations are scanned, with paths under
+// "<lang>/" (guides/intro.fr.md becomes fr/guides/intro.md, served at
+// /fr/guides/intro/) while SourcePath still points at the real file.
+func ScanLanguage(inputDir string, exclude []string, lang string, languages []string) (*Site, error) {
 	absPath, err := filepath.Abs(inputDir)
 	if err != nil {
 		return nil, err
 	}
 
-	root := NewNode("", "", true)
+	root := NewNode("", lang, true)
 	root.SourcePath = absPath
 
 	allPages := make([]*Node, 0)
 
-	err = scanDirectory(absPath, absPath, root, &allPages)
+	skip := make(map[string]bool, len(IncludeFiles)+len(exclude))
+	for _, name := range IncludeFiles {
+		skip[name] = true
+	}
+	for _, rel := range exclude {
+		skip[filepath.Clean(rel)] = true
+	}
+
+	s := &scanner{basePath: absPath, allPages: &allPages, skip: skip, lang: lang, languages: languages}
+	err = s.scanDirectory(absPath, root)
 	if err != nil {
 		return nil, err
 	}
+	// The home page can be hidden from navigation, but not the whole site
+	root.Hidden = false
 
 	// Sort children and prune empty folders
 	sortAndPrune(root)
@@ -32,7 +62,7 @@ func Scan(inputDir string) (*Site, error) {
 		// Look for README.md first (case-insensitive) in allPages at root level
 		for _, page := range allPages {
 			dir := filepath.Dir(page.Path)
-			if (dir == "." || dir == "") && isReadmeFile(page.FileName) {
+			if (dir == "." || dir == "" || dir == lang) && isReadmeFile(page.FileName) {
 				root.HasIndex = true
 				root.IndexPath = page.Path
 				break
@@ -67,13 +97,50 @@ func isReadmeFile(filename string) bool {
 	return name == "readme.md" || name == "readme.markdown"
 }
 
+// SplitLanguage splits a translation's language suffix off a markdown file
+// name: "intro.fr.md" gives "intro.md" and "fr" when "fr" is one of
+// languages. Other names are returned unchanged with an empty language.
+func SplitLanguage(filename string, languages []string) (string, string) {
+	ext := filepath.Ext(filename)
+	stem := strings.TrimSuffix(filename, ext)
+	suffix := filepath.Ext(stem)
+	if len(suffix) < 2 {
+		return filename, ""
+	}
+	for _, lang := range languages {
+		if strings.EqualFold(suffix[1:], lang) {
+			return strings.TrimSuffix(stem, suffix) + ext, lang
+		}
+	}
+	return filename, ""
+}
+
+// scanner holds the state of one ScanLanguage walk
+type scanner struct {
+	basePath  string
+	allPages  *[]*Node
+	skip      map[string]bool // Paths relative to basePath to leave out
+	lang      string          // Language being scanned ("" for the default)
+	languages []string        // All translation languages
+}
+
+// nodePath returns the tree path for a file or folder relative to the input
+// directory, placing translations under their language folder
+func (s *scanner) nodePath(relPath string) string {
+	if s.lang == "" {
+		return relPath
+	}
+	return filepath.Join(s.lang, relPath)
+}
+
 // scanDirectory recursively scans a directory for markdown files
-func scanDirectory(basePath, currentPath string, parent *Node, allPages *[]*Node) error {
+func (s *scanner) scanDirectory(currentPath string, parent *Node) error {
 	entries, err := os.ReadDir(currentPath)
 	if err != nil {
 		return err
 	}
 
+	var indexNode *Node
 	for _, entry := range entries {
 		name := entry.Name()
 
@@ -83,45 +150,68 @@ func scanDirectory(basePath, currentPath string, parent *Node, allPages *[]*Node
 		}
 
 		fullPath := filepath.Join(currentPath, name)
-		relPath, err := filepath.Rel(basePath, fullPath)
+		relPath, err := filepath.Rel(s.basePath, fullPath)
 		if err != nil {
 			return err
 		}
+		if s.skip[relPath] {
+			continue
+		}
 
 		if entry.IsDir() {
 			// Create folder node
-			folderNode := NewNode(CleanLabel(name), relPath, true)
+			folderNode := NewNode(CleanLabel(name), s.nodePath(relPath), true)
 			folderNode.SourcePath = fullPath
 			parent.AddChild(folderNode)
 
 			// Recursively scan subdirectory
-			if err := scanDirectory(basePath, fullPath, folderNode, allPages); err != nil {
+			if err := s.scanDirectory(fullPath, folderNode); err != nil {
 				return err
 			}
 		} else if IsMarkdownFile(name) {
+			// Keep only the pages of the language being scanned, named
+			// as the original they translate
+			var fileLang string
+			name, fileLang = SplitLanguage(name, s.languages)
+			if !strings.EqualFold(fileLang, s.lang) {
+				continue
+			}
+			if fileLang != "" {
+				relPath = filepath.Join(filepath.Dir(relPath), name)
+				if s.skip[relPath] {
+					continue
+				}
+			}
+
 			// Create file node with clean label as default name
-			fileNode := NewNode(CleanLabel(name), relPath, false)
+			fileNode := NewNode(CleanLabel(name), s.nodePath(relPath), false)
 			fileNode.SourcePath = fullPath
 			fileNode.FileName = name
 
-			// Try to extract H1 title from the file content
+			// Try to extract H1 title and navigation settings from the file content
+			var frontMatter FrontMatter
 			if content, err := os.ReadFile(fullPath); err == nil {
 				if h1 := ExtractH1(content); h1 != "" {
 					fileNode.H1Title = h1
 					fileNode.Name = h1 // Override display name with H1
 				}
+				frontMatter, _ = ParseFrontMatter(content)
+				applyNavSettings(fileNode, frontMatter)
 			}
 
 			// Check if this is an index file for the parent folder
 			if IsIndexFile(name) && parent.IsFolder {
 				parent.HasIndex = true
-				parent.IndexPath = relPath
+				parent.IndexPath = fileNode.Path
 				// Promote the index's H1 to the folder's display name so the
 				// sidebar label matches the page title (e.g. folder `06-cli`
 				// with `# CLI Reference` shows "CLI Reference", not "Cli").
 				if fileNode.H1Title != "" {
 					parent.Name = fileNode.H1Title
 				}
+				// The index's front matter also positions and orders the folder
+				applyNavSettings(parent, frontMatter)
+				indexNode = fileNode
 			}
 
 			// Skip adding index files to the tree navigation
@@ -129,14 +219,19 @@ func scanDirectory(basePath, currentPath string, parent *Node, allPages *[]*Node
 			if !IsIndexFile(name) {
 				parent.AddChild(fileNode)
 			}
-			*allPages = append(*allPages, fileNode)
+			*s.allPages = append(*s.allPages, fileNode)
 		}
 	}
 
+	readFolderSettings(currentPath, parent)
+	if indexNode != nil && parent.Hidden {
+		indexNode.Hidden = true
+	}
+
 	return nil
 }
 
-// sortAndPrune sorts children (files first, then folders, by date/number/name) and removes empty folders
+// sortAndPrune sorts children (by default files first, then folders, by date/number/name) and removes empty folders
 func sortAndPrune(node *Node) {
 	if !node.IsFolder {
 		return
@@ -157,40 +252,9 @@ func sortAndPrune(node *Node) {
 	}
 	node.Children = filtered
 
-	// Sort: files first, then folders
-	// Within each category: by date (from filename), then number, then alphabetically
-	sort.Slice(node.Children, func(i, j int) bool {
-		a, b := node.Children[i], node.Children[j]
-
-		// Files come before folders
-		if a.IsFolder != b.IsFolder {
-			return !a.IsFolder
-		}
-
-		// Both are same type - sort by date/number/name
-		aMeta := GetNodeMetadata(a)
-		bMeta := GetNodeMetadata(b)
-
-		// Primary: Date (from filename only)
-		// Items with dates come before items without dates
-		if aMeta.HasDate != bMeta.HasDate {
-			return aMeta.HasDate
-		}
-		// Both have dates - sort by date (newest first)
-		if aMeta.HasDate && bMeta.HasDate && !aMeta.Date.Equal(bMeta.Date) {
-			return aMeta.Date.After(bMeta.Date)
-		}
-
-		// Secondary: Number (lower numbers first, nil sorted last)
-		aNum := getNumberForSort(aMeta.Number, false)
-		bNum := getNumberForSort(bMeta.Number, false)
-		if aNum != bNum {
-			return aNum < bNum
-		}
-
-		// Tertiary: Name (alphabetical, case-insensitive)
-		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
-	})
+	// Sort by the folder's settings: by default files first, then folders,
+	// each by date (from filename), then number, then alphabetically
+	sortChildren(node)
 }
 
 // GetOutputPath returns the output path for a file node
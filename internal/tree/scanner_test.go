@@ -570,3 +570,112 @@ func TestScan_FolderNameFromIndexH1(t *testing.T) {
 		t.Errorf("folder.Name = %q, want %q (should come from index.md H1)", got, "CLI Reference")
 	}
 }
+
+func TestScanExcluding(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, path := range []string{"index.md", "_header.md", "_banner.md", "notes/footer.md", "notes/page.md", "guides/_header.md"} {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte("# Page"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	site, err := ScanExcluding(tmpDir, []string{"notes/footer.md"})
+	if err != nil {
+		t.Fatalf("ScanExcluding() error = %v", err)
+	}
+
+	var got []string
+	for _, page := range site.AllPages {
+		rel, _ := filepath.Rel(tmpDir, page.SourcePath)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	want := map[string]bool{"index.md": true, "notes/page.md": true, "guides/_header.md": true}
+	if len(got) != len(want) {
+		t.Fatalf("pages = %v, want %d pages", got, len(want))
+	}
+	for _, path := range got {
+		if !want[path] {
+			t.Errorf("unexpected page %q", path)
+		}
+	}
+}
+
+func TestSplitLanguage(t *testing.T) {
+	languages := []string{"fr", "pt-br"}
+	tests := []struct {
+		filename, wantName, wantLang string
+	}{
+		{"intro.fr.md", "intro.md", "fr"},
+		{"intro.FR.md", "intro.md", "fr"},
+		{"index.pt-br.markdown", "index.markdown", "pt-br"},
+		{"intro.md", "intro.md", ""},
+		{"intro.de.md", "intro.de.md", ""},
+		{"v1.2.md", "v1.2.md", ""},
+	}
+	for _, tt := range tests {
+		name, lang := SplitLanguage(tt.filename, languages)
+		if name != tt.wantName || lang != tt.wantLang {
+			t.Errorf("SplitLanguage(%q) = %q, %q, want %q, %q", tt.filename, name, lang, tt.wantName, tt.wantLang)
+		}
+	}
+}
+
+func TestScanLanguage(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, path := range []string{"index.md", "index.fr.md", "guides/intro.md", "guides/intro.fr.md", "guides/setup.md", "notes/only.fr.md", "_footer.fr.md"} {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte("# Page"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	languages := []string{"fr"}
+
+	pages := func(site *Site) map[string]string {
+		result := make(map[string]string)
+		for _, page := range site.AllPages {
+			rel, _ := filepath.Rel(tmpDir, page.SourcePath)
+			result[GetURLPath(page)] = filepath.ToSlash(rel)
+		}
+		return result
+	}
+
+	site, err := ScanLanguage(tmpDir, nil, "", languages)
+	if err != nil {
+		t.Fatalf("ScanLanguage() error = %v", err)
+	}
+	want := map[string]string{"/": "index.md", "/guides/intro/": "guides/intro.md", "/guides/setup/": "guides/setup.md"}
+	got := pages(site)
+	if len(got) != len(want) {
+		t.Errorf("default pages = %v, want %v", got, want)
+	}
+	for url, source := range want {
+		if got[url] != source {
+			t.Errorf("default page %s = %q, want %q", url, got[url], source)
+		}
+	}
+
+	site, err = ScanLanguage(tmpDir, nil, "fr", languages)
+	if err != nil {
+		t.Fatalf("ScanLanguage(fr) error = %v", err)
+	}
+	if GetURLPath(site.Root) != "/fr/" {
+		t.Errorf("fr root URL = %q, want /fr/", GetURLPath(site.Root))
+	}
+	want = map[string]string{"/fr/": "index.fr.md", "/fr/guides/intro/": "guides/intro.fr.md", "/fr/notes/only/": "notes/only.fr.md"}
+	got = pages(site)
+	if len(got) != len(want) {
+		t.Errorf("fr pages = %v, want %v", got, want)
+	}
+	for url, source := range want {
+		if got[url] != source {
+			t.Errorf("fr page %s = %q, want %q", url, got[url], source)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 // Package tree provides functionality for building a tree structure from markdown files.
 package tree
 
+import "time"
+
 // Node represents a node in the content tree
 type Node struct {
 	Name       string  // Clean display label (from H1 or filename)
@@ -11,8 +13,14 @@ type Node struct {
 	IsFolder   bool    // Whether this is a folder
 	HasIndex   bool    // True if folder contains index.md
 	IndexPath  string  // Path to index.md if exists
-	Children   []*Node // Sorted alphabetically
+	Children   []*Node // Sorted for navigation (see NavOrder)
 	Parent     *Node   // Parent node
+
+	// Navigation settings from front matter (an index page's apply to its folder)
+	Weight   *int      // Position among siblings, lowest first (nil if unset)
+	Date     time.Time // Date from `date` front matter (zero if unset)
+	Hidden   bool      // Left out of navigation (`nav: false` or `hidden: true`)
+	NavOrder *NavOrder // How a folder orders its children (nil for the default)
 }
 
 // Site represents the full site structure
@@ -0,0 +1,255 @@
+// Package versions builds several versions of a site's content side by side
+// and links each page to its counterpart in the other versions.
+package versions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wusher/volcano/internal/config"
+	"github.com/wusher/volcano/internal/tree"
+)
+
+// LatestPrefix is the URL prefix the latest version is built under a
+// second time, so links to it never go stale
+const LatestPrefix = "latest"
+
+// versionFolder matches the folder names found as versions: v1, v2.1, 3.x
+var versionFolder = regexp.MustCompile(`^v?\d+(\.\d+)*(\.x)?$`)
+
+// validName matches names that can be used as a URL prefix
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Version is one version of the site's content
+type Version struct {
+	Name  string // URL prefix, such as "v2"
+	Label string // Text in the version menu
+	Dir   string // Content directory
+}
+
+// Link is a page's counterpart in one version, for the version menu
+type Link struct {
+	Name    string // Version name
+	Label   string // Version label
+	URL     string // The page in this version, or the version's home page
+	Current bool   // Whether this is the version being viewed
+	Latest  bool   // Whether this is the latest version
+	Exists  bool   // Whether the page exists in this version
+}
+
+// Resolve returns the versions to build, oldest first, and the name of the
+// latest. Without a configured list, every folder of inputDir named like a
+// version (v1, v2.1, 3.x) is one, in version order.
+func Resolve(cfg *config.VersionsConfig, inputDir string) ([]Version, string, error) {
+	var list []Version
+	if len(cfg.List) == 0 {
+		found, err := discover(inputDir)
+		if err != nil {
+			return nil, "", err
+		}
+		list = found
+	}
+	for _, vc := range cfg.List {
+		v := Version{Name: vc.Name, Label: vc.Label, Dir: vc.Dir}
+		if v.Label == "" {
+			v.Label = v.Name
+		}
+		if v.Dir == "" {
+			v.Dir = filepath.Join(inputDir, v.Name)
+		} else if _, err := os.Stat(v.Dir); err != nil && !filepath.IsAbs(v.Dir) {
+			v.Dir = filepath.Join(inputDir, v.Dir)
+		}
+		list = append(list, v)
+	}
+
+	seen := make(map[string]bool, len(list))
+	for _, v := range list {
+		switch {
+		case !validName.MatchString(v.Name):
+			return nil, "", fmt.Errorf("invalid version name %q: use letters, digits, '.', '-' or '_'", v.Name)
+		case strings.EqualFold(v.Name, LatestPrefix):
+			return nil, "", fmt.Errorf("version name %q is reserved for the latest version", v.Name)
+		case seen[v.Name]:
+			return nil, "", fmt.Errorf("version %q is listed twice", v.Name)
+		}
+		seen[v.Name] = true
+		if info, err := os.Stat(v.Dir); err != nil || !info.IsDir() {
+			return nil, "", fmt.Errorf("version %q: directory not found: %s", v.Name, v.Dir)
+		}
+	}
+
+	latest := cfg.Latest
+	if latest == "" {
+		latest = list[len(list)-1].Name
+	} else if !seen[latest] {
+		return nil, "", fmt.Errorf("latest version %q is not a listed version", latest)
+	}
+	return list, latest, nil
+}
+
+// discover finds the version folders of inputDir
+func discover(inputDir string) ([]Version, error) {
+	entries, err := os.ReadDir(inputDir)
+	if err != nil {
+		return nil, err
+	}
+	var list []Version
+	for _, entry := range entries {
+		if entry.IsDir() && versionFolder.MatchString(entry.Name()) {
+			list = append(list, Version{
+				Name:  entry.Name(),
+				Label: entry.Name(),
+				Dir:   filepath.Join(inputDir, entry.Name()),
+			})
+		}
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no version folders (such as v1, v2) found in %s", inputDir)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return versionLess(list[i].Name, list[j].Name)
+	})
+	return list, nil
+}
+
+// versionLess orders version names by their numbers ("v2" before "v10")
+func versionLess(a, b string) bool {
+	aParts := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bParts := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+		if aErr == nil && bErr == nil && aNum != bNum {
+			return aNum < bNum
+		}
+		if (aErr == nil) != (bErr == nil) {
+			return aErr == nil // "2.1" before "2.x"
+		}
+	}
+	return len(aParts) < len(bParts)
+}
+
+// Set is every version of a site, with the pages each one has
+type Set struct {
+	Versions []Version
+	Latest   string // Name of the latest version
+	siteURL  string
+	pages    map[string]map[string]bool // URL paths of pages and folders, by version name
+}
+
+// NewSet scans each version's content to find which pages it has. siteURL
+// is the URL of the whole site, without a version prefix.
+func NewSet(list []Version, latest, siteURL string) (*Set, error) {
+	s := &Set{Versions: list, Latest: latest, siteURL: siteURL, pages: make(map[string]map[string]bool, len(list))}
+	for _, v := range list {
+		site, err := tree.Scan(v.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan version %s: %w", v.Name, err)
+		}
+		pages := make(map[string]bool)
+		collect(site.Root, pages)
+		for _, page := range site.AllPages {
+			pages[tree.GetURLPath(page)] = true
+		}
+		s.pages[v.Name] = pages
+	}
+	return s, nil
+}
+
+// collect adds the URL paths of a folder and its subfolders
+func collect(node *tree.Node, pages map[string]bool) {
+	pages[tree.GetURLPath(node)] = true
+	for _, child := range node.Children {
+		if child.IsFolder {
+			collect(child, pages)
+		}
+	}
+}
+
+// Prefixes returns the URL prefixes to build: each version, then the latest
+// version again under LatestPrefix
+func (s *Set) Prefixes() []string {
+	prefixes := make([]string, 0, len(s.Versions)+1)
+	for _, v := range s.Versions {
+		prefixes = append(prefixes, v.Name)
+	}
+	return append(prefixes, LatestPrefix)
+}
+
+// SiteURL returns the site URL of the build under prefix
+func (s *Set) SiteURL(prefix string) string {
+	return strings.TrimSuffix(s.siteURL, "/") + "/" + prefix
+}
+
+// CanonicalSiteURL returns the site URL for canonical links of the build
+// under prefix: the latest version's pages name their LatestPrefix copies.
+// It's empty when the site has no URL.
+func (s *Set) CanonicalSiteURL(prefix string) string {
+	if s.siteURL == "" {
+		return ""
+	}
+	if prefix == s.Latest {
+		prefix = LatestPrefix
+	}
+	return s.SiteURL(prefix)
+}
+
+// Version returns the version built under prefix
+func (s *Set) Version(prefix string) Version {
+	if prefix == LatestPrefix {
+		prefix = s.Latest
+	}
+	for _, v := range s.Versions {
+		if v.Name == prefix {
+			return v
+		}
+	}
+	return Version{}
+}
+
+// Outdated reports whether the version built under prefix isn't the latest
+func (s *Set) Outdated(prefix string) bool {
+	return prefix != LatestPrefix && prefix != s.Latest
+}
+
+// URL returns the page at urlPath (a path within a version) in the version
+// built under prefix, or that version's home page if it has no such page
+func (s *Set) URL(prefix, urlPath string) (string, bool) {
+	exists := s.pages[s.Version(prefix).Name][urlPath]
+	if !exists {
+		urlPath = "/"
+	}
+	return tree.PrefixURL(s.siteURL, "/"+prefix+urlPath), exists
+}
+
+// Links returns the page at urlPath in every version, for the version menu
+// of the version built under prefix. The latest version is linked under
+// LatestPrefix unless it's the one being viewed.
+func (s *Set) Links(prefix, urlPath string) []Link {
+	current := s.Version(prefix).Name
+	links := make([]Link, 0, len(s.Versions))
+	for i := len(s.Versions) - 1; i >= 0; i-- {
+		v := s.Versions[i]
+		target := v.Name
+		if v.Name == s.Latest && v.Name != current {
+			target = LatestPrefix
+		} else if v.Name == current {
+			target = prefix
+		}
+		url, exists := s.URL(target, urlPath)
+		links = append(links, Link{
+			Name:    v.Name,
+			Label:   v.Label,
+			URL:     url,
+			Current: v.Name == current,
+			Latest:  v.Name == s.Latest,
+			Exists:  exists,
+		})
+	}
+	return links
+}
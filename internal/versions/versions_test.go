@@ -0,0 +1,185 @@
+package versions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wusher/volcano/internal/config"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func names(list []Version) []string {
+	result := make([]string, len(list))
+	for i, v := range list {
+		result[i] = v.Name
+	}
+	return result
+}
+
+func TestResolveDiscovers(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"v10/index.md":   "# v10",
+		"v2/index.md":    "# v2",
+		"v1/index.md":    "# v1",
+		"2.x/index.md":   "# 2.x",
+		"2.1/index.md":   "# 2.1",
+		"assets/logo.md": "# Not a version",
+	})
+	list, latest, err := Resolve(&config.VersionsConfig{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(names(list), " "); got != "v1 v2 2.1 2.x v10" {
+		t.Errorf("versions = %s", got)
+	}
+	if latest != "v10" {
+		t.Errorf("latest = %q, want v10", latest)
+	}
+	if list[0].Label != "v1" || list[0].Dir != filepath.Join(dir, "v1") {
+		t.Errorf("v1 = %+v", list[0])
+	}
+
+	if _, _, err := Resolve(&config.VersionsConfig{}, t.TempDir()); err == nil {
+		t.Error("expected an error when there are no version folders")
+	}
+}
+
+func TestResolveList(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"v1/index.md":        "# v1",
+		"next/index.md":      "# next",
+		"archive/old/one.md": "# old",
+	})
+	list, latest, err := Resolve(&config.VersionsConfig{
+		Latest: "v1",
+		List: []config.VersionConfig{
+			{Name: "old", Dir: "archive/old"},
+			{Name: "v1", Label: "1.x"},
+			{Name: "next"},
+		},
+	}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != "v1" || strings.Join(names(list), " ") != "old v1 next" {
+		t.Errorf("Resolve() = %v, %q", names(list), latest)
+	}
+	if list[0].Dir != filepath.Join(dir, "archive/old") || list[1].Label != "1.x" || list[2].Label != "next" {
+		t.Errorf("versions = %+v", list)
+	}
+
+	tests := map[string]config.VersionsConfig{
+		"invalid version name":    {List: []config.VersionConfig{{Name: "a/b"}}},
+		"reserved":                {List: []config.VersionConfig{{Name: "latest"}}},
+		"listed twice":            {List: []config.VersionConfig{{Name: "v1"}, {Name: "v1"}}},
+		"directory not found":     {List: []config.VersionConfig{{Name: "v9"}}},
+		"is not a listed version": {Latest: "v9", List: []config.VersionConfig{{Name: "v1"}}},
+	}
+	for want, cfg := range tests {
+		cfg := cfg
+		if _, _, err := Resolve(&cfg, dir); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Resolve(%+v) error = %v, want %q", cfg, err, want)
+		}
+	}
+}
+
+func newTestSet(t *testing.T, siteURL string) *Set {
+	dir := writeTree(t, map[string]string{
+		"v1/index.md":        "# v1",
+		"v1/guides/intro.md": "# Intro",
+		"v1/old.md":          "# Old",
+		"v2/index.md":        "# v2",
+		"v2/guides/intro.md": "# Intro",
+		"v3/index.md":        "# v3",
+		"v3/guides/setup.md": "# Setup",
+	})
+	list, latest, err := Resolve(&config.VersionsConfig{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := NewSet(list, latest, siteURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return set
+}
+
+func TestSetLinks(t *testing.T) {
+	set := newTestSet(t, "https://example.com/docs/")
+
+	links := set.Links("v1", "/guides/intro/")
+	want := []Link{
+		{Name: "v3", Label: "v3", URL: "/docs/latest/", Latest: true},
+		{Name: "v2", Label: "v2", URL: "/docs/v2/guides/intro/", Exists: true},
+		{Name: "v1", Label: "v1", URL: "/docs/v1/guides/intro/", Current: true, Exists: true},
+	}
+	if len(links) != len(want) {
+		t.Fatalf("Links() = %+v", links)
+	}
+	for i := range want {
+		if links[i] != want[i] {
+			t.Errorf("link %d = %+v, want %+v", i, links[i], want[i])
+		}
+	}
+
+	// Viewing the latest version links it under the prefix being viewed
+	for _, prefix := range []string{"v3", LatestPrefix} {
+		links = set.Links(prefix, "/guides/")
+		if !links[0].Current || links[0].URL != "/docs/"+prefix+"/guides/" {
+			t.Errorf("latest link under %s = %+v", prefix, links[0])
+		}
+	}
+}
+
+func TestSetURLs(t *testing.T) {
+	set := newTestSet(t, "https://example.com/docs")
+
+	if got := strings.Join(set.Prefixes(), " "); got != "v1 v2 v3 latest" {
+		t.Errorf("Prefixes() = %s", got)
+	}
+	if set.Version(LatestPrefix).Name != "v3" || set.Version("v2").Name != "v2" || set.Version("v9").Name != "" {
+		t.Error("Version() should find versions by prefix")
+	}
+	if !set.Outdated("v1") || set.Outdated("v3") || set.Outdated(LatestPrefix) {
+		t.Error("only versions before the latest are outdated")
+	}
+	if got := set.SiteURL("v2"); got != "https://example.com/docs/v2" {
+		t.Errorf("SiteURL(v2) = %q", got)
+	}
+	if got := set.CanonicalSiteURL("v3"); got != "https://example.com/docs/latest" {
+		t.Errorf("CanonicalSiteURL(v3) = %q", got)
+	}
+	if got := set.CanonicalSiteURL("v1"); got != "https://example.com/docs/v1" {
+		t.Errorf("CanonicalSiteURL(v1) = %q", got)
+	}
+	if url, ok := set.URL("v1", "/old/"); !ok || url != "/docs/v1/old/" {
+		t.Errorf("URL(v1, /old/) = %q, %v", url, ok)
+	}
+	if url, ok := set.URL(LatestPrefix, "/old/"); ok || url != "/docs/latest/" {
+		t.Errorf("URL(latest, /old/) = %q, %v, want the home page", url, ok)
+	}
+
+	local := newTestSet(t, "")
+	if got := local.SiteURL("v1"); got != "/v1" {
+		t.Errorf("SiteURL(v1) without a site URL = %q", got)
+	}
+	if got := local.CanonicalSiteURL("v1"); got != "" {
+		t.Errorf("CanonicalSiteURL(v1) without a site URL = %q, want empty", got)
+	}
+}
@@ -60,6 +60,10 @@ func Run(args []string, stdout, stderr io.Writer) int {
 		err = cmd.ServeCommand(args[1:], stdout, stderr)
 	case "init":
 		err = cmd.Init(args[1:], stdout, stderr)
+	case "epub":
+		err = cmd.Epub(args[1:], stdout, stderr)
+	case "search":
+		err = cmd.Search(args[1:], stdout, stderr)
 	default:
 		// Fall through: treat as shorthand for build (backward compatibility)
 		// This allows `volcano ./docs` to work like `volcano build ./docs`
@@ -83,6 +87,8 @@ func printUsage(w io.Writer) {
 	_, _ = fmt.Fprintln(w, "  volcano server [flags] <input>   Alias for serve")
 	_, _ = fmt.Fprintln(w, "  volcano init [flags]             Create/update volcano.json config")
 	_, _ = fmt.Fprintln(w, "  volcano css [-o file]            Output vanilla CSS")
+	_, _ = fmt.Fprintln(w, "  volcano epub [flags] <input>     Export site as an EPUB ebook")
+	_, _ = fmt.Fprintln(w, "  volcano search <input> <query>   Search a site from the terminal")
 	_, _ = fmt.Fprintln(w, "  volcano <input>                  Shorthand for build")
 	_, _ = fmt.Fprintln(w, "  volcano                          Serve the current directory")
 	_, _ = fmt.Fprintln(w, "")